@@ -124,6 +124,12 @@ func (ks keyStorePassphrase) GetCue(filename string) (string, error) {
 	return k.Cue, nil
 }
 
+// DeriveKey derives a key from the passphrase with the scrypt parameters used by
+// the keystore, so other data can be protected as strong as the key files.
+func DeriveKey(auth string, salt []byte, scryptN, scryptP int) ([]byte, error) {
+	return scrypt.Key([]byte(auth), salt, scryptN, scryptR, scryptP, scryptDKLen)
+}
+
 // EncryptKey encrypts a key using the specified scrypt parameters into a json
 // blob that can be decrypted later on.
 func EncryptKey(key *Key, auth string, scryptN, scryptP int, cue string) ([]byte, error) {
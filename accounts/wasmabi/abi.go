@@ -0,0 +1,362 @@
+// Package wasmabi implements the ABI of the WASM contracts, a JSON description
+// of the actions, events and structs of a contract emitted by the contract
+// toolchain, and the typed encoding of the action calls.
+package wasmabi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/crypto"
+)
+
+// InputSeparator separates the action name from the arguments in the call input.
+const InputSeparator = "|"
+
+// The ABI holds information about a WASM contract's actions, events and the
+// structs they use. It will allow you to type check action calls and encodes
+// the call input as `action|{"0":arg0,"1":arg1,...}`.
+type ABI struct {
+	Actions map[string]Action
+	Events  map[string]Event
+	Structs map[string]Type
+}
+
+// Argument holds the name of the argument and the corresponding type.
+type Argument struct {
+	Name string
+	Type Type
+}
+
+type Arguments []Argument
+
+// Action represents a callable action of the contract.
+type Action struct {
+	Name    string
+	Const   bool
+	Inputs  Arguments
+	Outputs Arguments
+}
+
+// Sig returns the action string signature, e.g. `deposit(address,bint,uint64)`.
+func (action Action) Sig() string {
+	types := make([]string, len(action.Inputs))
+	for i, input := range action.Inputs {
+		types[i] = input.Type.String()
+	}
+	return fmt.Sprintf("%v(%v)", action.Name, strings.Join(types, ","))
+}
+
+// Event is a log emitted by the contract with TC_Log1 or TC_Notify, the data of
+// the log holds the inputs as a JSON array.
+type Event struct {
+	Name   string
+	Inputs Arguments
+}
+
+// Topic returns the topic of the event logged by TC_Log1, which is the
+// event name itself.
+func (e Event) Topic() common.Hash {
+	return common.BytesToHash([]byte(e.Name))
+}
+
+// NotifyTopic returns the topic of the event logged by TC_Notify, which is the
+// hash of the event name.
+func (e Event) NotifyTopic() common.Hash {
+	return crypto.Keccak256Hash([]byte(e.Name))
+}
+
+// JSON returns a parsed ABI interface and error if it failed.
+func JSON(reader io.Reader) (ABI, error) {
+	dec := json.NewDecoder(reader)
+
+	var abi ABI
+	if err := dec.Decode(&abi); err != nil {
+		return ABI{}, err
+	}
+
+	return abi, nil
+}
+
+// Pack the given action name and arguments to the call input of the contract.
+func (abi ABI) Pack(name string, args ...interface{}) ([]byte, error) {
+	action, exist := abi.Actions[name]
+	if !exist {
+		return nil, fmt.Errorf("action '%s' not found", name)
+	}
+	if len(args) != len(action.Inputs) {
+		return nil, fmt.Errorf("argument count mismatch: %d for %d", len(args), len(action.Inputs))
+	}
+
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		val, err := action.Inputs[i].Type.encode(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d (%s): %v", i, action.Inputs[i].Name, err)
+		}
+		values[i] = val
+	}
+	return packInput(name, values)
+}
+
+// PackJSON packs the given action name and JSON encoded arguments, as they are
+// received by the RPC or typed in the console.
+func (abi ABI) PackJSON(name string, args []json.RawMessage) ([]byte, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg
+	}
+	return abi.Pack(name, values...)
+}
+
+// PackRaw packs the given action name and JSON encoded arguments without type
+// checking, for contracts without an ABI.
+func PackRaw(name string, args []json.RawMessage) ([]byte, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		if !json.Valid(arg) {
+			return nil, fmt.Errorf("argument %d: invalid json", i)
+		}
+		values[i] = arg
+	}
+	return packInput(name, values)
+}
+
+// packInput encodes the call input, the arguments are keyed by their index
+// in order.
+func packInput(name string, values []interface{}) ([]byte, error) {
+	if name == "" || strings.Contains(name, InputSeparator) {
+		return nil, fmt.Errorf("invalid action name %q", name)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(name)
+	buf.WriteString(InputSeparator)
+	buf.WriteByte('{')
+	for i, val := range values {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		data, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(strconv.Quote(strconv.Itoa(i)))
+		buf.WriteByte(':')
+		buf.Write(data)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnpackInput decodes the call input of the contract to the action name and the
+// arguments, the arguments are converted to the Go types described in Type.
+func (abi ABI) UnpackInput(input []byte) (string, []interface{}, error) {
+	idx := bytes.Index(input, []byte(InputSeparator))
+	if idx < 0 {
+		return "", nil, fmt.Errorf("abi: improperly formatted input")
+	}
+	name := string(input[:idx])
+	action, exist := abi.Actions[name]
+	if !exist {
+		return "", nil, fmt.Errorf("action '%s' not found", name)
+	}
+
+	var obj map[string]interface{}
+	if err := unmarshalJSON(input[idx+1:], &obj); err != nil {
+		return "", nil, err
+	}
+	args, err := action.Inputs.decode(obj)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, args, nil
+}
+
+// Unpack decodes the output of the action. An action returns a single value,
+// strings are returned as is, other types are returned as JSON.
+func (abi ABI) Unpack(name string, output []byte) (interface{}, error) {
+	action, exist := abi.Actions[name]
+	if !exist {
+		return nil, fmt.Errorf("action '%s' not found", name)
+	}
+	if len(action.Outputs) == 0 {
+		return nil, nil
+	}
+	typ := action.Outputs[0].Type
+	if typ.T == StringTy {
+		return string(output), nil
+	}
+	if len(output) == 0 {
+		return nil, fmt.Errorf("abi: unmarshalling empty output")
+	}
+
+	var val interface{}
+	if err := unmarshalJSON(output, &val); err != nil {
+		// scalars may be returned without JSON quoting
+		val = string(output)
+	}
+	return typ.decode(val)
+}
+
+// UnpackEvent decodes the data of the event log. The data is a JSON array of
+// the inputs, or an object keyed by the input indexes.
+func (abi ABI) UnpackEvent(name string, data []byte) ([]interface{}, error) {
+	event, exist := abi.Events[name]
+	if !exist {
+		return nil, fmt.Errorf("event '%s' not found", name)
+	}
+
+	var val interface{}
+	if err := unmarshalJSON(data, &val); err != nil {
+		return nil, err
+	}
+	obj := make(map[string]interface{})
+	switch v := val.(type) {
+	case []interface{}:
+		for i, elem := range v {
+			obj[strconv.Itoa(i)] = elem
+		}
+	case map[string]interface{}:
+		obj = v
+	default:
+		if len(event.Inputs) != 1 {
+			return nil, fmt.Errorf("abi: improperly formatted event data")
+		}
+		obj["0"] = v
+	}
+	return event.Inputs.decode(obj)
+}
+
+// EventByTopic looks up the event by the first topic of its log, returns nil
+// if none found.
+func (abi ABI) EventByTopic(topic common.Hash) *Event {
+	for _, event := range abi.Events {
+		if event.Topic() == topic || event.NotifyTopic() == topic {
+			e := event
+			return &e
+		}
+	}
+	return nil
+}
+
+// decode converts the values keyed by the argument indexes.
+func (arguments Arguments) decode(obj map[string]interface{}) ([]interface{}, error) {
+	if len(obj) != len(arguments) {
+		return nil, fmt.Errorf("argument count mismatch: %d for %d", len(obj), len(arguments))
+	}
+	ret := make([]interface{}, len(arguments))
+	for i, arg := range arguments {
+		v, ok := obj[strconv.Itoa(i)]
+		if !ok {
+			return nil, fmt.Errorf("missing argument %d (%s)", i, arg.Name)
+		}
+		val, err := arg.Type.decode(v)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d (%s): %v", i, arg.Name, err)
+		}
+		ret[i] = val
+	}
+	return ret, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface
+func (abi *ABI) UnmarshalJSON(data []byte) error {
+	type field struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}
+	var def struct {
+		Structs []struct {
+			Name   string  `json:"name"`
+			Fields []field `json:"fields"`
+		} `json:"structs"`
+		Actions []struct {
+			Name     string  `json:"name"`
+			Constant bool    `json:"constant"`
+			Inputs   []field `json:"inputs"`
+			Outputs  []field `json:"outputs"`
+		} `json:"actions"`
+		Events []struct {
+			Name   string  `json:"name"`
+			Inputs []field `json:"inputs"`
+		} `json:"events"`
+	}
+	if err := json.Unmarshal(data, &def); err != nil {
+		return err
+	}
+
+	newArguments := func(fields []field) (Arguments, error) {
+		args := make(Arguments, len(fields))
+		for i, f := range fields {
+			typ, err := NewType(f.Type, abi.Structs)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = Argument{Name: f.Name, Type: typ}
+		}
+		return args, nil
+	}
+
+	// a struct may only refer to the structs defined before it
+	abi.Structs = make(map[string]Type)
+	for _, s := range def.Structs {
+		if _, exist := abi.Structs[s.Name]; exist {
+			return fmt.Errorf("duplicate struct %s", s.Name)
+		}
+		if _, err := NewType(s.Name, nil); err == nil {
+			return fmt.Errorf("struct %s shadows a builtin type", s.Name)
+		}
+		fields, err := newArguments(s.Fields)
+		if err != nil {
+			return fmt.Errorf("struct %s: %v", s.Name, err)
+		}
+		abi.Structs[s.Name] = Type{Fields: fields, T: StructTy, stringKind: s.Name}
+	}
+
+	abi.Actions = make(map[string]Action)
+	for _, a := range def.Actions {
+		inputs, err := newArguments(a.Inputs)
+		if err != nil {
+			return fmt.Errorf("action %s: %v", a.Name, err)
+		}
+		outputs, err := newArguments(a.Outputs)
+		if err != nil {
+			return fmt.Errorf("action %s: %v", a.Name, err)
+		}
+		if len(outputs) > 1 {
+			return fmt.Errorf("action %s: more than one output", a.Name)
+		}
+		abi.Actions[a.Name] = Action{
+			Name:    a.Name,
+			Const:   a.Constant,
+			Inputs:  inputs,
+			Outputs: outputs,
+		}
+	}
+
+	abi.Events = make(map[string]Event)
+	for _, e := range def.Events {
+		inputs, err := newArguments(e.Inputs)
+		if err != nil {
+			return fmt.Errorf("event %s: %v", e.Name, err)
+		}
+		abi.Events[e.Name] = Event{
+			Name:   e.Name,
+			Inputs: inputs,
+		}
+	}
+
+	return nil
+}
+
+func unmarshalJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
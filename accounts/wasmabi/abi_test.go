@@ -0,0 +1,162 @@
+package wasmabi
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+)
+
+const jsondata = `
+{
+	"structs": [
+		{"name": "Candidate", "fields": [
+			{"name": "pub_key", "type": "string"},
+			{"name": "coinbase", "type": "address"},
+			{"name": "voting_power", "type": "int64"},
+			{"name": "score", "type": "int64"},
+			{"name": "punish_height", "type": "uint64"}
+		]}
+	],
+	"actions": [
+		{"name": "deposit", "inputs": [
+			{"name": "elector", "type": "address"},
+			{"name": "amount", "type": "bint"},
+			{"name": "orderid", "type": "uint64"}
+		]},
+		{"name": "setAction", "inputs": [{"name": "act", "type": "int"}, {"name": "stop", "type": "bool"}]},
+		{"name": "SetCandidate", "inputs": [{"name": "cand", "type": "Candidate"}]},
+		{"name": "getVoteCnts", "constant": true, "inputs": [{"name": "electors", "type": "address[]"}], "outputs": [{"name": "", "type": "bint[]"}]},
+		{"name": "getDeposit", "constant": true, "outputs": [{"name": "", "type": "string"}]}
+	],
+	"events": [
+		{"name": "Deposit", "inputs": [
+			{"name": "sender", "type": "address"},
+			{"name": "amount", "type": "bint"},
+			{"name": "orderid", "type": "uint64"}
+		]}
+	]
+}`
+
+var (
+	elector = common.HexToAddress("0x54fb1c7d0f011dd63b08f85ed7b518ab82028110")
+)
+
+func TestPack(t *testing.T) {
+	abi, err := JSON(strings.NewReader(jsondata))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		args  []interface{}
+		input string
+	}{
+		{"deposit", []interface{}{elector, big.NewInt(5), uint64(1)},
+			`deposit|{"0":"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110","1":"5","2":1}`},
+		{"setAction", []interface{}{0, true}, `setAction|{"0":0,"1":true}`},
+		{"SetCandidate", []interface{}{map[string]interface{}{
+			"pub_key": "0x724c", "coinbase": elector.String(), "voting_power": 10, "score": 100, "punish_height": 0}},
+			`SetCandidate|{"0":{"coinbase":"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110","pub_key":"0x724c","punish_height":0,"score":100,"voting_power":10}}`},
+		{"getVoteCnts", []interface{}{[]common.Address{elector}},
+			`getVoteCnts|{"0":["0x54fb1c7d0f011dd63b08f85ed7b518ab82028110"]}`},
+		{"getDeposit", nil, `getDeposit|{}`},
+	}
+	for i, test := range tests {
+		input, err := abi.Pack(test.name, test.args...)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if string(input) != test.input {
+			t.Errorf("test %d: input mismatch, got %s, want %s", i, input, test.input)
+		}
+
+		name, args, err := abi.UnpackInput(input)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if name != test.name || len(args) != len(test.args) {
+			t.Errorf("test %d: unpacked %s %v", i, name, args)
+		}
+	}
+
+	input, err := abi.PackJSON("deposit", []json.RawMessage{
+		json.RawMessage(`"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110"`),
+		json.RawMessage(`"0x5"`),
+		json.RawMessage(`18446744073709551615`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `deposit|{"0":"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110","1":"5","2":18446744073709551615}`; string(input) != exp {
+		t.Errorf("input mismatch, got %s, want %s", input, exp)
+	}
+
+	if _, err := abi.Pack("setAction", 1<<31, true); err == nil {
+		t.Error("expected overflow error")
+	}
+	if _, err := abi.Pack("deposit", "0x54fb", 5, 1); err == nil {
+		t.Error("expected invalid address error")
+	}
+	if _, err := abi.Pack("deposit", elector); err == nil {
+		t.Error("expected argument count error")
+	}
+	if _, err := abi.Pack("withDraw", elector); err == nil {
+		t.Error("expected unknown action error")
+	}
+}
+
+func TestUnpack(t *testing.T) {
+	abi, err := JSON(strings.NewReader(jsondata))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := abi.Unpack("getVoteCnts", []byte(`["10","0x20"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []interface{}{big.NewInt(10), big.NewInt(32)}; !reflect.DeepEqual(out, exp) {
+		t.Errorf("output mismatch, got %v, want %v", out, exp)
+	}
+
+	out, err = abi.Unpack("getDeposit", []byte(`{"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110":"5"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != `{"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110":"5"}` {
+		t.Errorf("output mismatch, got %v", out)
+	}
+
+	args, err := abi.UnpackEvent("Deposit", []byte(`["0x54fb1c7d0f011dd63b08f85ed7b518ab82028110","5",7]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []interface{}{elector, big.NewInt(5), uint64(7)}; !reflect.DeepEqual(args, exp) {
+		t.Errorf("event mismatch, got %v, want %v", args, exp)
+	}
+
+	if e := abi.EventByTopic(common.BytesToHash([]byte("Deposit"))); e == nil || e.Name != "Deposit" {
+		t.Errorf("event by topic mismatch, got %v", e)
+	}
+}
+
+func TestPackRaw(t *testing.T) {
+	input, err := PackRaw("setPoceeds", []json.RawMessage{
+		json.RawMessage(`"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110"`),
+		json.RawMessage(`"100"`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `setPoceeds|{"0":"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110","1":"100"}`; string(input) != exp {
+		t.Errorf("input mismatch, got %s, want %s", input, exp)
+	}
+	if _, err := PackRaw("setPoceeds", []json.RawMessage{json.RawMessage(`{`)}); err == nil {
+		t.Error("expected invalid json error")
+	}
+}
@@ -0,0 +1,363 @@
+package wasmabi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
+)
+
+// Type enumerator
+const (
+	BoolTy byte = iota
+	IntTy
+	UintTy
+	BIntTy
+	StringTy
+	AddressTy
+	BytesTy
+	SliceTy
+	StructTy
+)
+
+// Type is the reflection of the supported argument type. Integers up to 64 bits
+// are JSON numbers, big integers (tc::BInt), addresses and bytes are JSON strings,
+// structs (TC_STRUCT) are JSON objects keyed by the field names.
+type Type struct {
+	Elem   *Type      // element type of SliceTy
+	Fields []Argument // fields of StructTy
+
+	Size int  // bit size of IntTy and UintTy
+	T    byte // Our own type checking
+
+	stringKind string // holds the unparsed string
+}
+
+// NewType creates a new type of abi type given in t, structs holds the struct
+// types the type may refer to.
+func NewType(t string, structs map[string]Type) (typ Type, err error) {
+	if strings.HasSuffix(t, "[]") {
+		elem, err := NewType(strings.TrimSuffix(t, "[]"), structs)
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{Elem: &elem, T: SliceTy, stringKind: t}, nil
+	}
+
+	typ.stringKind = t
+	switch t {
+	case "bool":
+		typ.T = BoolTy
+	case "int":
+		typ.T, typ.Size = IntTy, 32
+	case "uint":
+		typ.T, typ.Size = UintTy, 32
+	case "int8", "int16", "int32", "int64":
+		typ.T = IntTy
+		typ.Size, _ = strconv.Atoi(strings.TrimPrefix(t, "int"))
+	case "uint8", "uint16", "uint32", "uint64":
+		typ.T = UintTy
+		typ.Size, _ = strconv.Atoi(strings.TrimPrefix(t, "uint"))
+	case "bint":
+		typ.T = BIntTy
+	case "string":
+		typ.T = StringTy
+	case "address":
+		typ.T = AddressTy
+	case "bytes":
+		typ.T = BytesTy
+	default:
+		st, ok := structs[t]
+		if !ok {
+			return Type{}, fmt.Errorf("unsupported arg type: %s", t)
+		}
+		return st, nil
+	}
+	return
+}
+
+// String implements Stringer
+func (t Type) String() string {
+	return t.stringKind
+}
+
+// encode converts v to the JSON value the contract expects for the type.
+func (t Type) encode(v interface{}) (interface{}, error) {
+	if raw, ok := v.(json.RawMessage); ok {
+		dec := json.NewDecoder(strings.NewReader(string(raw)))
+		dec.UseNumber()
+		var val interface{}
+		if err := dec.Decode(&val); err != nil {
+			return nil, err
+		}
+		v = val
+	}
+
+	switch t.T {
+	case BoolTy:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			return strconv.ParseBool(b)
+		}
+	case IntTy, UintTy:
+		n, err := toBigInt(v)
+		if err != nil {
+			return nil, err
+		}
+		if !t.inRange(n) {
+			return nil, fmt.Errorf("%v overflows %s", n, t)
+		}
+		return json.Number(n.String()), nil
+	case BIntTy:
+		n, err := toBigInt(v)
+		if err != nil {
+			return nil, err
+		}
+		return n.String(), nil
+	case StringTy:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case AddressTy:
+		switch a := v.(type) {
+		case common.Address:
+			return a.String(), nil
+		case *common.Address:
+			return a.String(), nil
+		case string:
+			if !common.IsHexAddress(a) {
+				return nil, fmt.Errorf("invalid address %q", a)
+			}
+			return common.HexToAddress(a).String(), nil
+		}
+	case BytesTy:
+		switch b := v.(type) {
+		case []byte:
+			return hexutil.Encode(b), nil
+		case hexutil.Bytes:
+			return hexutil.Encode(b), nil
+		case string:
+			if _, err := hexutil.Decode(b); err != nil {
+				return nil, err
+			}
+			return b, nil
+		}
+	case SliceTy:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			break
+		}
+		elems := make([]interface{}, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			elem, err := t.Elem.encode(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = elem
+		}
+		return elems, nil
+	case StructTy:
+		fields, err := structFields(v)
+		if err != nil {
+			return nil, err
+		}
+		obj := make(map[string]interface{}, len(t.Fields))
+		for _, field := range t.Fields {
+			fv, ok := fields[field.Name]
+			if !ok {
+				return nil, fmt.Errorf("missing field %s of %s", field.Name, t)
+			}
+			val, err := field.Type.encode(fv)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", field.Name, err)
+			}
+			obj[field.Name] = val
+		}
+		return obj, nil
+	}
+	return nil, fmt.Errorf("cannot use %T as type %s", v, t)
+}
+
+// decode converts the JSON value v returned by the contract to the Go type of the
+// abi type: bool, int8...int64, uint8...uint64, *big.Int, string, common.Address,
+// []byte, []interface{} or map[string]interface{}.
+func (t Type) decode(v interface{}) (interface{}, error) {
+	switch t.T {
+	case BoolTy:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			return strconv.ParseBool(b)
+		case json.Number:
+			return b.String() != "0", nil
+		}
+	case IntTy, UintTy:
+		n, err := toBigInt(v)
+		if err != nil {
+			return nil, err
+		}
+		if !t.inRange(n) {
+			return nil, fmt.Errorf("%v overflows %s", n, t)
+		}
+		return t.sized(n), nil
+	case BIntTy:
+		return toBigInt(v)
+	case StringTy:
+		switch s := v.(type) {
+		case string:
+			return s, nil
+		case json.Number:
+			return s.String(), nil
+		}
+	case AddressTy:
+		if a, ok := v.(string); ok && common.IsHexAddress(a) {
+			return common.HexToAddress(a), nil
+		}
+	case BytesTy:
+		if b, ok := v.(string); ok {
+			return hexutil.Decode(b)
+		}
+	case SliceTy:
+		elems, ok := v.([]interface{})
+		if !ok {
+			break
+		}
+		ret := make([]interface{}, len(elems))
+		for i, elem := range elems {
+			val, err := t.Elem.decode(elem)
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = val
+		}
+		return ret, nil
+	case StructTy:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			break
+		}
+		ret := make(map[string]interface{}, len(t.Fields))
+		for _, field := range t.Fields {
+			fv, ok := obj[field.Name]
+			if !ok {
+				return nil, fmt.Errorf("missing field %s of %s", field.Name, t)
+			}
+			val, err := field.Type.decode(fv)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", field.Name, err)
+			}
+			ret[field.Name] = val
+		}
+		return ret, nil
+	}
+	return nil, fmt.Errorf("cannot decode %T as type %s", v, t)
+}
+
+// inRange reports whether n fits in the integer type.
+func (t Type) inRange(n *big.Int) bool {
+	if t.T == UintTy {
+		return n.Sign() >= 0 && n.BitLen() <= t.Size
+	}
+	min := new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1)))
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1)), big.NewInt(1))
+	return n.Cmp(min) >= 0 && n.Cmp(max) <= 0
+}
+
+// sized returns n as the Go integer of the type's size.
+func (t Type) sized(n *big.Int) interface{} {
+	if t.T == UintTy {
+		u := n.Uint64()
+		switch t.Size {
+		case 8:
+			return uint8(u)
+		case 16:
+			return uint16(u)
+		case 32:
+			return uint32(u)
+		}
+		return u
+	}
+	i := n.Int64()
+	switch t.Size {
+	case 8:
+		return int8(i)
+	case 16:
+		return int16(i)
+	case 32:
+		return int32(i)
+	}
+	return i
+}
+
+// toBigInt converts an integer, a decimal or 0x prefixed hex string, or a JSON
+// number to a big integer.
+func toBigInt(v interface{}) (*big.Int, error) {
+	switch n := v.(type) {
+	case *big.Int:
+		return new(big.Int).Set(n), nil
+	case big.Int:
+		return new(big.Int).Set(&n), nil
+	case *hexutil.Big:
+		return new(big.Int).Set(n.ToInt()), nil
+	case json.Number:
+		return parseBigInt(n.String())
+	case string:
+		return parseBigInt(n)
+	case float64:
+		if n != float64(int64(n)) {
+			return nil, fmt.Errorf("%v is not an integer", n)
+		}
+		return big.NewInt(int64(n)), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()), nil
+	}
+	return nil, fmt.Errorf("cannot use %T as integer", v)
+}
+
+func parseBigInt(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return n, nil
+}
+
+// structFields returns the fields of a map or of a Go struct, the Go struct
+// fields are named by their json tags, or by their names.
+func structFields(v interface{}) (map[string]interface{}, error) {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m, nil
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot use %T as struct", v)
+	}
+	fields := make(map[string]interface{}, rv.NumField())
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag != "" {
+			name = tag
+		}
+		fields[name] = rv.Field(i).Interface()
+	}
+	return fields, nil
+}
@@ -8,22 +8,24 @@ import (
 	"runtime"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/lianxiangcloud/linkchain/blockchain"
 	"github.com/lianxiangcloud/linkchain/config"
+	"github.com/lianxiangcloud/linkchain/contract/gov"
 	"github.com/lianxiangcloud/linkchain/libs/common"
 	"github.com/lianxiangcloud/linkchain/libs/crypto"
 	lctypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
 	dbm "github.com/lianxiangcloud/linkchain/libs/db"
 	"github.com/lianxiangcloud/linkchain/libs/log"
 	"github.com/lianxiangcloud/linkchain/libs/p2p"
+	"github.com/lianxiangcloud/linkchain/libs/trace"
 	"github.com/lianxiangcloud/linkchain/libs/txmgr"
 	"github.com/lianxiangcloud/linkchain/state"
 	"github.com/lianxiangcloud/linkchain/types"
 	"github.com/lianxiangcloud/linkchain/utxo"
 	"github.com/lianxiangcloud/linkchain/vm/evm"
 	"github.com/lianxiangcloud/linkchain/vm/wasm"
-	"github.com/xunleichain/tc-wasm/vm"
 )
 
 const (
@@ -54,8 +56,8 @@ type ProcessResult struct {
 	isOk      bool
 }
 
-type PoceedHandle func(st *state.StateDB, coinbase common.Address, amount *big.Int, logger log.Logger) error
-type AwardHandle func(st *state.StateDB, logger log.Logger) error
+type PoceedHandle func(st *state.StateDB, header *types.Header, coinbase common.Address, amount *big.Int, logger log.Logger) error
+type AwardHandle func(st *state.StateDB, header *types.Header, logger log.Logger) ([]types.BalanceRecord, error)
 
 func (p *ProcessResult) GetReceipts() *types.Receipts {
 	return p.receipts
@@ -72,6 +74,7 @@ type LinkApplication struct {
 	vmConfig           evm.Config
 	blockChain         *blockchain.BlockStore
 	balanceRecordStore *blockchain.BalanceRecordStore
+	eventIndexStore    *blockchain.EventIndexStore
 	utxoStore          *utxo.UtxoStore
 	currentBlock       *types.Block
 	storeState         *state.StateDB
@@ -156,6 +159,10 @@ func (app *LinkApplication) SetConm(conM *p2p.ConManager) {
 	app.conManager = conM
 }
 
+func (app *LinkApplication) SetEventIndexStore(eis *blockchain.EventIndexStore) {
+	app.eventIndexStore = eis
+}
+
 func (app *LinkApplication) TxMgr() types.TxMgr {
 	return app.crossState
 }
@@ -197,6 +204,7 @@ func (app *LinkApplication) CreateBlock(height uint64, maxTxs int, gasLimit uint
 		return nil
 	}
 
+	start := time.Now()
 	txs := app.mempool.Reap(maxTxs)
 	numTxs := uint64(len(txs))
 
@@ -217,11 +225,22 @@ func (app *LinkApplication) CreateBlock(height uint64, maxTxs int, gasLimit uint
 		},
 	}
 	block.DataHash = block.Data.Hash()
+	if trace.Enabled() {
+		trace.StartTxsAt(start, txs.Hashes(), "app.CreateBlock", "height", height).Finish()
+	}
 
 	app.logger.Info("CreateBlock: done", "height", height, "dataHash", block.DataHash, "NumTxs", len(txs))
 	return block
 }
 
+// traceBlock starts the spans of the txs of the block.
+func traceBlock(block *types.Block, name string) trace.Spans {
+	if !trace.Enabled() {
+		return nil
+	}
+	return trace.StartTxs(block.Data.Txs.Hashes(), name, "height", block.Height)
+}
+
 func (app *LinkApplication) PreRunBlock(block *types.Block) {
 	app.logger.Info("PreRunBlock: begin", "height", block.Height, "NumTxs", block.NumTxs)
 	processResult := ProcessResult{
@@ -420,7 +439,7 @@ func (app *LinkApplication) processBlock(block *types.Block, processResult *Proc
 		totalGasFee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), new(big.Int).SetInt64(types.ParGasPrice))
 		app.logger.Info("processHandle", "foundation_addr", config.ContractFoundationAddr.String(), "totalGasFee", totalGasFee.String())
 		processResult.tmpState.AddBalance(config.ContractFoundationAddr, totalGasFee)
-		if err := app.poceedHandle(processResult.tmpState, block.Coinbase(), totalGasFee, app.logger); err != nil {
+		if err := app.poceedHandle(processResult.tmpState, block.Header, block.Coinbase(), totalGasFee, app.logger); err != nil {
 			app.logger.Warn("processBlock: process failed when setPoceeds", "blockHash", block.Hash(), "err", err)
 			return
 		}
@@ -428,10 +447,20 @@ func (app *LinkApplication) processBlock(block *types.Block, processResult *Proc
 
 	if block.Height%(10*app.lastCoe.VotePeriod) == 0 && len(app.lastTxsResult.Candidates) > 0 && app.awardHandle != nil {
 		app.logger.Info("awardHandle")
-		if err := app.awardHandle(processResult.tmpState, app.logger); err != nil {
+		records, err := app.awardHandle(processResult.tmpState, block.Header, app.logger)
+		if err != nil {
 			app.logger.Warn("processBlock: process failed when allocAward", "blockHash", block.Hash(), "err", err)
 			return
 		}
+		if len(records) > 0 {
+			tbr := types.NewTxBalanceRecords()
+			tbr.Type = types.TxAward
+			tbr.From = config.ContractFoundationAddr
+			for _, br := range records {
+				tbr.AddBalanceRecord(br)
+			}
+			tbrBlock.AddTxBalanceRecord(tbr)
+		}
 	}
 
 	processResult.logs = logs
@@ -530,6 +559,9 @@ func (app *LinkApplication) CommitBlock(block *types.Block, blockParts *types.Pa
 	}
 
 	app.logger.Info("CommitBlock: start", "height", block.Height, "blockHash", blockHash)
+	spans := traceBlock(block, "app.CommitBlock")
+	spans.SetAttributes("fastsync", fastsync)
+	defer spans.Finish()
 
 	// if !app.CheckBlockInCommit(block) {
 	// 	return nil, fmt.Errorf("CommitBlock: CheckBlock failed")
@@ -559,6 +591,9 @@ func (app *LinkApplication) CommitBlock(block *types.Block, blockParts *types.Pa
 	}
 	app.balanceRecordStore.Save(block.Height, processResult.tbrBlock)
 	processResult.tbrBlock.Clear()
+	if app.eventIndexStore != nil {
+		app.eventIndexStore.Save(block.Height, blockHash, processResult.logs)
+	}
 
 	app.blockChain.SaveBlock(block, blockParts, seenCommit, processResult.GetReceipts(), processResult.GetTxsResult())
 	app.utxoStore.SaveUtxo(processResult.txsResult.KeyImages(), processResult.txsResult.UTXOOutputs(), block.Height)
@@ -746,7 +781,7 @@ func (app *LinkApplication) CheckTx(tx types.Tx, checkBasic bool) error {
 
 func (app *LinkApplication) updateCandidatesbyOrder(p *ProcessResult, hash common.Hash) types.CandidateInOrderList {
 	if p.height%app.lastCoe.VotePeriod == 0 {
-		return app.calculateCandidates(p.tmpState, hash)
+		return app.calculateCandidates(p.tmpState, hash, p.height)
 	}
 
 	canList := p.GetTxsResult().Candidates
@@ -771,6 +806,7 @@ func (app *LinkApplication) updateCandidatesbyOrder(p *ProcessResult, hash commo
 }
 
 func (app *LinkApplication) processBlockEvidence(eviList types.EvidenceList, processResult *ProcessResult) {
+	slashing := config.GetChainConfig().SlashingAt(processResult.height)
 	for _, evi := range eviList {
 		switch ev := evi.(type) {
 		case *types.DuplicateVoteEvidence:
@@ -782,6 +818,9 @@ func (app *LinkApplication) processBlockEvidence(eviList types.EvidenceList, pro
 				v.Score = 0
 				app.logger.Warn("Clear Score", "height", processResult.height, "ev", ev)
 			}
+			if slashing != nil {
+				app.slash(processResult, ev.PubKey, &slashing.DoubleSign, "double sign")
+			}
 
 		case *types.FaultValidatorsEvidence:
 			award := ev.Proposer.Address().String()
@@ -809,18 +848,23 @@ func (app *LinkApplication) processBlockEvidence(eviList types.EvidenceList, pro
 						}
 					}
 					app.logger.Warn("Decrease Score", "height", processResult.height, "ev", ev)
+					if slashing != nil && v.ProduceInfo <= -slashing.FaultRounds() {
+						app.slash(processResult, ev.FaultVal, &slashing.FaultValidator, "fault validator")
+					}
 				}
 			}
 		}
 	}
 }
 
-func (app *LinkApplication) getAllCandidates(s *state.StateDB, hash common.Hash) types.CandidateInOrderList {
+func (app *LinkApplication) getAllCandidates(s *state.StateDB, hash common.Hash, height uint64) types.CandidateInOrderList {
 	canState := s.GetAllCandidates(app.logger)
-	app.conManager.SetCandidate(canState) //callback to tell p2p the outside candidates
+	if app.conManager != nil {
+		app.conManager.SetCandidate(canState) //callback to tell p2p the outside candidates
+	}
 	can := make(types.CandidateInOrderList, 0, len(canState))
 	for _, v := range canState {
-		if v.Score > 0 {
+		if v.Score > 0 && !app.isSlashed(s, v.PubKey, height) {
 			h := crypto.Keccak256Hash(hash[:], v.Address)
 			randNum := binary.BigEndian.Uint64(h[:8])
 			can = append(can, &types.CandidateInOrder{
@@ -838,8 +882,8 @@ func (app *LinkApplication) getCandidatesDeposit(s *state.StateDB, addrs []commo
 	return s.GetCandidatesDeposit(addrs, app.logger)
 }
 
-func (app *LinkApplication) calculateCandidates(s *state.StateDB, hash common.Hash) types.CandidateInOrderList {
-	can := app.getAllCandidates(s, hash)
+func (app *LinkApplication) calculateCandidates(s *state.StateDB, hash common.Hash, height uint64) types.CandidateInOrderList {
+	can := app.getAllCandidates(s, hash, height)
 	addrs := make([]common.Address, 0, len(can))
 	for _, v := range can {
 		addrs = append(addrs, v.CoinBase)
@@ -847,23 +891,39 @@ func (app *LinkApplication) calculateCandidates(s *state.StateDB, hash common.Ha
 
 	deposits := app.getCandidatesDeposit(s, addrs)
 
+	rankCandidates(can, deposits, app.lastCoe)
+	for _, v := range can {
+		f, _ := v.RankResult.Float64()
+		app.logger.Debug("candidates rank", "rank", f, "address", v.CoinBase.String())
+	}
+	return can
+}
+
+// rankCandidates sorts the candidates by the rank of their score, deposit(wei) and rand.
+func rankCandidates(can types.CandidateInOrderList, deposits []*big.Int, coe *types.Coefficient) {
 	var maxDeposit = int64(1) //Init maxDeposit in case that set candidate without pledge
 	for i := range can {
-		can[i].Deposit = deposits[i].Div(deposits[i], big.NewInt(config.Ether)).Int64()
+		can[i].Deposit = new(big.Int).Div(deposits[i], big.NewInt(config.Ether)).Int64()
 		if can[i].Deposit > maxDeposit {
 			maxDeposit = can[i].Deposit
 		}
 	}
 	for _, v := range can {
-		v.CalRank(app.lastCoe.Srate, app.lastCoe.Drate, app.lastCoe.Rrate, maxDeposit, app.lastCoe.MaxScore)
+		v.CalRank(coe.Srate, coe.Drate, coe.Rrate, maxDeposit, coe.MaxScore)
 	}
 	sort.Sort(can)
 	for i, v := range can {
-		f, _ := v.RankResult.Float64()
-		app.logger.Debug("candidates rank", "rank", f, "address", v.CoinBase.String())
 		v.Rank = i
 	}
-	return can
+}
+
+// numElected returns the number of the candidates elected as validators.
+func numElected(numCans int, coe *types.Coefficient) int {
+	num := numCans * coe.Nume / coe.Deno
+	if num > coe.UpperLimit {
+		num = coe.UpperLimit
+	}
+	return num
 }
 
 //get from contract
@@ -892,12 +952,7 @@ func (app *LinkApplication) recoverCandidates(cans types.CandidateInOrderList) t
 
 func (app *LinkApplication) getValidators(cans types.CandidateInOrderList) []*types.Validator {
 
-	numValidators := len(cans) * app.lastCoe.Nume / app.lastCoe.Deno
-
-	if numValidators > app.lastCoe.UpperLimit {
-		numValidators = app.lastCoe.UpperLimit
-	}
-	chosenCans := cans[:numValidators]
+	chosenCans := cans[:numElected(len(cans), app.lastCoe)]
 
 	vals := app.getWhiteValidators()
 	for _, v := range chosenCans {
@@ -929,54 +984,15 @@ func (app *LinkApplication) getCoefficient() *types.Coefficient {
 	return types.DefaultCoefficient()
 }
 
-func SetPoceeds(st *state.StateDB, coinbase common.Address, amount *big.Int, logger log.Logger) error {
-	input := `setPoceeds|{"0":"` + coinbase.String() + `","1":"` + amount.String() + `"}`
-	logger.Info("setPoceeds", "input", input)
-	_, err := CallWasmContract(st, common.EmptyAddress, config.ContractFoundationAddr, big.NewInt(0), []byte(input), logger)
-	return err
+func SetPoceeds(st *state.StateDB, header *types.Header, coinbase common.Address, amount *big.Int, logger log.Logger) error {
+	return gov.Foundation.SetPoceeds(st, header, coinbase, amount, logger)
 }
 
-func AllocAward(st *state.StateDB, logger log.Logger) error {
-	input := "allocAward|{}"
-	logger.Info("allocAward")
-	_, err := CallWasmContract(st, common.EmptyAddress, config.ContractFoundationAddr, big.NewInt(0), []byte(input), logger)
-	return err
+func AllocAward(st *state.StateDB, header *types.Header, logger log.Logger) ([]types.BalanceRecord, error) {
+	return gov.Foundation.AllocAward(st, header, logger)
 }
 
-//CallWasmContract only be used by chain inner to call wasm contract directly
-func CallWasmContract(st *state.StateDB, sender, contractAddr common.Address, amount *big.Int, input []byte, logger log.Logger) ([]byte, error) {
-	caller := evm.AccountRef(sender)
-	to := evm.AccountRef(contractAddr)
-	value := amount
-	gas := uint64(10000000000000000000)
-
-	contract := wasm.NewContract(caller, to, value, gas)
-	contract.SetCallCode(&contractAddr, st.GetCodeHash(contractAddr), st.GetCode(contractAddr))
-	contract.Input = input
-
-	innerContract := vm.NewContract(contract.CallerAddress.Bytes(), contract.Address().Bytes(), contract.Value(), contract.Gas)
-	innerContract.SetCallCode(contract.CodeAddr.Bytes(), contract.CodeHash.Bytes(), contract.Code)
-	innerContract.Input = contract.Input
-	innerContract.CreateCall = contract.CreateCall
-	wasm.Inject(nil, st, nil)
-	eng := vm.NewEngine(innerContract, contract.Gas, st, logger)
-	eng.SetTrace(false) // trace app execution.
-
-	app, err := eng.NewApp(contract.Address().String(), contract.Code, false)
-	if err != nil {
-		return nil, fmt.Errorf("exec.NewApp fail: %s", err)
-	}
-
-	app.EntryFunc = vm.APPEntry
-	ret, err := eng.Run(app, contract.Input)
-	if err != nil {
-		return nil, fmt.Errorf("eng.Run fail: err=%s", err)
-	}
-
-	vmem := app.VM.VMemory()
-	result, err := vmem.GetString(ret)
-	if err != nil {
-		return nil, fmt.Errorf("vmem.GetString fail: err=%v", err)
-	}
-	return result, nil
+//CallWasmContract only be used by chain inner to call wasm contract directly in the block of header
+func CallWasmContract(st *state.StateDB, header *types.Header, sender, contractAddr common.Address, amount *big.Int, input []byte, logger log.Logger) ([]byte, error) {
+	return gov.CallContract(st, header, sender, contractAddr, amount, input, logger)
 }
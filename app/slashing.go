@@ -0,0 +1,62 @@
+package app
+
+import (
+	"github.com/lianxiangcloud/linkchain/config"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/crypto"
+	"github.com/lianxiangcloud/linkchain/state"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+// slash punishes the validator of pubkey by the rule: confiscates a share of its
+// deposit in the pledge contract to the foundation, jails and tombstones it. The
+// slashing changes the state of the block, so it runs while processing the block
+// before the state hash is computed, the candidates list of the block drops the
+// validator once committed.
+func (app *LinkApplication) slash(processResult *ProcessResult, pubkey crypto.PubKey, rule *config.SlashingRule, reason string) {
+	if !rule.Punishes() {
+		return
+	}
+	st := processResult.tmpState
+	slashing := st.GetSlashingState(pubkey, app.logger)
+	if slashing.Tombstoned {
+		app.logger.Debug("slash: validator already tombstoned", "height", processResult.height, "validator", pubkey.Address())
+		return
+	}
+
+	cand, isCandidate := processResult.txsResult.CandidatesMap[pubkey.Address().String()]
+	amount := common.Big0
+	if isCandidate && rule.SlashRate > 0 {
+		amount = st.ConfiscateDeposit(cand.CoinBase, rule.SlashRate, app.logger)
+	}
+	// every slash is recorded, a zero amount one for a slash confiscating nothing
+	tbr := types.NewTxBalanceRecords()
+	tbr.Type = types.TxSlash
+	tbr.From = config.ContractPledgeAddr
+	tbr.To = config.ContractFoundationAddr
+	tbr.AddBalanceRecord(types.GenBalanceRecord(config.ContractPledgeAddr, config.ContractFoundationAddr,
+		types.AccountAddress, types.AccountAddress, types.TxSlash, common.EmptyAddress, amount))
+	processResult.tbrBlock.AddTxBalanceRecord(tbr)
+
+	if jailHeight := processResult.height + rule.JailBlocks; rule.JailBlocks > 0 && jailHeight > slashing.JailHeight {
+		slashing.JailHeight = jailHeight
+	}
+	slashing.Tombstoned = rule.Tombstone
+	st.SetSlashingState(pubkey, slashing)
+
+	// drop the jailed candidate from the candidates list until the next vote
+	if isCandidate && (rule.JailBlocks > 0 || rule.Tombstone) {
+		cand.ProduceInfo = config.PunishThreshold
+	}
+	app.logger.Warn("Slash validator", "height", processResult.height, "validator", pubkey.Address(), "reason", reason,
+		"confiscated", amount, "jailHeight", slashing.JailHeight, "tombstoned", slashing.Tombstoned)
+}
+
+// isSlashed reports whether the candidate of pubkey is jailed at height or tombstoned.
+func (app *LinkApplication) isSlashed(st *state.StateDB, pubkey crypto.PubKey, height uint64) bool {
+	if config.GetChainConfig().Slashing == nil {
+		return false
+	}
+	slashing := st.GetSlashingState(pubkey, app.logger)
+	return slashing.Tombstoned || height < slashing.JailHeight
+}
@@ -0,0 +1,200 @@
+package app
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/config"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/crypto"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
+	"github.com/lianxiangcloud/linkchain/libs/log"
+	"github.com/lianxiangcloud/linkchain/state"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+// setSlashing activates slashing at height 0 until the returned func is called
+func setSlashing(slashing *config.SlashingConfig) func() {
+	old := config.GetChainConfig()
+	c := *old
+	c.Slashing = slashing
+	config.SetChainConfig(&c)
+	return func() { config.SetChainConfig(old) }
+}
+
+func newSlashingApp() *LinkApplication {
+	return &LinkApplication{
+		logger:  log.NewNopLogger(),
+		lastCoe: types.DefaultCoefficient(),
+	}
+}
+
+// newSlashingResult return the process result of block height with the candidates of pubkeys
+func newSlashingResult(height uint64, pubkeys ...crypto.PubKey) *ProcessResult {
+	st, _ := state.New(common.EmptyHash, state.NewDatabase(dbm.NewMemDB()))
+	cands := make([]*types.CandidateInOrder, 0, len(pubkeys))
+	for _, pubkey := range pubkeys {
+		cands = append(cands, &types.CandidateInOrder{
+			Candidate: types.Candidate{
+				Address:  pubkey.Address(),
+				PubKey:   pubkey,
+				CoinBase: common.BytesToAddress(pubkey.Address()),
+			},
+			Score: 10,
+		})
+	}
+	result := &ProcessResult{
+		tbrBlock: types.NewBlockBalanceRecords(),
+		tmpState: st,
+		height:   height,
+	}
+	result.txsResult.SetCandidates(cands)
+	return result
+}
+
+// checkSlashRecord check the last balance record of result is a slash of amount
+func checkSlashRecord(t *testing.T, result *ProcessResult, amount int64) {
+	t.Helper()
+	records := result.tbrBlock.TxRecords
+	if len(records) == 0 {
+		t.Fatal("no balance record of the slash")
+	}
+	tbr := records[len(records)-1]
+	if tbr.Type != types.TxSlash || len(tbr.Records) != 1 || tbr.Records[0].Amount.Cmp(big.NewInt(amount)) != 0 {
+		t.Fatalf("got balance record %+v, want a %s of %d", tbr, types.TxSlash, amount)
+	}
+}
+
+func TestSlashFaultThreshold(t *testing.T) {
+	slashing := *config.DefaultSlashingConfig
+	slashing.FaultThreshold = 3
+	defer setSlashing(&slashing)()
+
+	app := newSlashingApp()
+	proposer := crypto.GenPrivKeyEd25519().PubKey()
+	faultVal := crypto.GenPrivKeyEd25519().PubKey()
+	result := newSlashingResult(100, proposer, faultVal)
+	fault := &types.FaultValidatorsEvidence{BlockHeight: 100, Round: 1, Proposer: proposer, FaultVal: faultVal}
+
+	// the first fault rounds only decrease the score
+	for i := 1; i < slashing.FaultRounds(); i++ {
+		app.processBlockEvidence(types.EvidenceList{fault}, result)
+		if app.isSlashed(result.tmpState, faultVal, 101) {
+			t.Fatalf("validator jailed after %d fault rounds, want %d", i, slashing.FaultRounds())
+		}
+	}
+	// a fault of round 0 is no fault of the validator
+	app.processBlockEvidence(types.EvidenceList{&types.FaultValidatorsEvidence{BlockHeight: 100, Proposer: proposer, FaultVal: faultVal}}, result)
+	if app.isSlashed(result.tmpState, faultVal, 101) {
+		t.Fatal("validator jailed for a fault of round 0")
+	}
+
+	if len(result.tbrBlock.TxRecords) != 0 {
+		t.Fatalf("got %d balance records before the slash, want 0", len(result.tbrBlock.TxRecords))
+	}
+
+	app.processBlockEvidence(types.EvidenceList{fault}, result)
+	if !app.isSlashed(result.tmpState, faultVal, 101) {
+		t.Fatalf("validator not jailed after %d fault rounds", slashing.FaultRounds())
+	}
+	// the jail confiscates nothing but is recorded
+	checkSlashRecord(t, result, 0)
+	if app.isSlashed(result.tmpState, faultVal, 100+slashing.FaultValidator.JailBlocks) {
+		t.Fatal("validator still jailed after the jail blocks")
+	}
+	if app.isSlashed(result.tmpState, proposer, 101) {
+		t.Fatal("proposer jailed")
+	}
+}
+
+func appendTLVString(val []byte, str string) []byte {
+	size := make([]byte, 2)
+	binary.LittleEndian.PutUint16(size, uint16(len(str)))
+	val = append(val, state.TagString)
+	val = append(val, size...)
+	return append(val, str...)
+}
+
+// storeCandidates stores the candidates of result in st as SetCandidate of candidates contract does
+func storeCandidates(st *state.StateDB, result *ProcessResult) {
+	pubkeys := []byte{state.TagArray, byte(len(result.txsResult.Candidates)), 0x00}
+	for _, cand := range result.txsResult.Candidates {
+		key := hexutil.Encode(cand.PubKey.Bytes()) + "\x00"
+		pubkeys = appendTLVString(pubkeys, key)
+		bz, _ := json.Marshal(state.CandidateJSON{
+			PubKey:   hexutil.Encode(cand.PubKey.Bytes()),
+			CoinBase: cand.CoinBase,
+			Score:    cand.Score,
+		})
+		candKey := appendTLVString([]byte("cand"), key)
+		st.SetState(config.ContractCandidatesAddr, crypto.Keccak256Hash(candKey), appendTLVString(nil, string(bz)+"\x00"))
+	}
+	st.SetState(config.ContractCandidatesAddr, crypto.Keccak256Hash([]byte("pubkeys")), pubkeys)
+}
+
+func TestSlashEvidence(t *testing.T) {
+	slashing := *config.DefaultSlashingConfig
+	defer setSlashing(&slashing)()
+
+	app := newSlashingApp()
+	proposer := crypto.GenPrivKeyEd25519().PubKey()
+	doubleSigner := crypto.GenPrivKeyEd25519().PubKey()
+	faultVal := crypto.GenPrivKeyEd25519().PubKey()
+	result := newSlashingResult(100, proposer, doubleSigner, faultVal)
+	storeCandidates(result.tmpState, result)
+
+	candidates := func(height uint64) map[string]bool {
+		addrs := make(map[string]bool)
+		for _, cand := range app.getAllCandidates(result.tmpState, common.EmptyHash, height) {
+			addrs[cand.Address.String()] = true
+		}
+		return addrs
+	}
+	if cands := candidates(100); len(cands) != 3 {
+		t.Fatalf("got %d candidates before the slash, want 3", len(cands))
+	}
+
+	// the evidence committed in the block slashes the validators
+	eviList := types.EvidenceList{&types.DuplicateVoteEvidence{PubKey: doubleSigner}}
+	for i := 0; i < slashing.FaultRounds(); i++ {
+		eviList = append(eviList, &types.FaultValidatorsEvidence{BlockHeight: 100, Round: 1, Proposer: proposer, FaultVal: faultVal})
+	}
+	app.processBlockEvidence(eviList, result)
+
+	signer := result.tmpState.GetSlashingState(doubleSigner, app.logger)
+	if !signer.Tombstoned {
+		t.Fatal("double signer not tombstoned")
+	}
+	if cand := result.txsResult.CandidatesMap[doubleSigner.Address().String()]; cand.ProduceInfo != config.PunishThreshold {
+		t.Fatalf("double signer produce info %d, want %d", cand.ProduceInfo, config.PunishThreshold)
+	}
+	if len(result.tbrBlock.TxRecords) != 2 {
+		t.Fatalf("got %d balance records, want one of each slash", len(result.tbrBlock.TxRecords))
+	}
+	checkSlashRecord(t, result, 0)
+
+	// the jailed validator is no candidate until the jail ends, the tombstoned one never
+	// again even with a score back
+	result.tmpState.UpdataeCandidateScore(doubleSigner, state.OPADD, app.lastCoe.MaxScore, 101, app.logger)
+	cands := candidates(101)
+	if len(cands) != 1 || !cands[proposer.Address().String()] {
+		t.Fatalf("got candidates %v while jailed, want only the proposer", cands)
+	}
+	cands = candidates(100 + slashing.FaultValidator.JailBlocks)
+	if len(cands) != 2 || !cands[faultVal.Address().String()] || cands[doubleSigner.Address().String()] {
+		t.Fatalf("got candidates %v after the jail, want the proposer and the fault validator", cands)
+	}
+
+	// a tombstoned validator is not slashed again
+	result.height = 200
+	app.processBlockEvidence(types.EvidenceList{&types.DuplicateVoteEvidence{PubKey: doubleSigner}}, result)
+	if len(result.tbrBlock.TxRecords) != 2 {
+		t.Fatalf("got %d balance records after slashing a tombstoned validator, want 2", len(result.tbrBlock.TxRecords))
+	}
+	if again := result.tmpState.GetSlashingState(doubleSigner, app.logger); again != signer {
+		t.Fatalf("slashing state of the tombstoned validator changed from %+v to %+v", signer, again)
+	}
+}
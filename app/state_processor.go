@@ -80,7 +80,14 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		utxoOutputs = make([]*types.UTXOOutputData, 0)
 		keyImages   = make([]*lctypes.Key, 0)
 		tbrBlock    = types.NewBlockBalanceRecords()
+		spans       = traceBlock(block, "state.Process")
 	)
+	defer spans.Finish()
+
+	// rules of a protocol upgrade take effect from the block at the fork height
+	if fork := config.GetChainConfig().ForkAt(block.Height); fork != "" {
+		log.Info("Process protocol upgrade", "fork", fork, "height", block.Height)
+	}
 
 	vmenv := vm.NewVM()
 	evmGasRate := config.EvmGasRate
@@ -104,6 +111,7 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 
 			if err != nil {
 				log.Error("applytransaction", "height", block.Height, "idx", idx, "tx", txRaw.Hash().String(), "receipt", receipt.Hash().String(), "err", err)
+				spans.SetError(err)
 				return nil, nil, 0, nil, nil, nil, nil, err
 			}
 			payloads := make([]types.Payload, 0)
@@ -150,6 +158,7 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 				receipt, otxs, err = p.applyUTXOTransaction(statedb, tx, usedGas, &vmenv)
 				if err != nil {
 					log.Error("applytransaction", "height", block.Height, "idx", idx, "tx", txRaw.Hash().String(), "receipt", receipt.Hash().String(), "err", err)
+					spans.SetError(err)
 					return nil, nil, 0, nil, nil, nil, nil, err
 				}
 				for _, br := range otxs {
@@ -206,10 +215,12 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 			keyImages = append(keyImages, tx.GetInputKeyImages()...)
 		default:
 			err := fmt.Errorf("unknow tx type")
+			spans.SetError(err)
 			return nil, nil, 0, nil, nil, nil, nil, err
 		}
 	}
 
+	spans.SetAttributes("gas_used", *usedGas)
 	return receipts, allLogs, *usedGas, specialTxs, utxoOutputs, keyImages, tbrBlock, nil
 }
 
@@ -65,7 +65,7 @@ type StateTransition struct {
 }
 
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(data []byte, contractCreation, homestead bool, gasRate uint64) (uint64, error) {
+func IntrinsicGas(data []byte, contractCreation, homestead, istanbul bool, gasRate uint64) (uint64, error) {
 	// Set the starting gas for the raw transaction
 	var gas uint64
 	if contractCreation && homestead {
@@ -82,12 +82,16 @@ func IntrinsicGas(data []byte, contractCreation, homestead bool, gasRate uint64)
 				nz++
 			}
 		}
+		nonZeroGas := cfg.TxDataNonZeroGas
+		if istanbul {
+			nonZeroGas = cfg.TxDataNonZeroGasEIP2028
+		}
 		// Make sure we don't exceed uint64 for all data combinations
-		if (math.MaxUint64-gas)/cfg.TxDataNonZeroGas < nz {
+		if (math.MaxUint64-gas)/nonZeroGas < nz {
 			log.Warn("IntrinsicGas", "gas", gas, "nz", nz)
 			return 0, evm.ErrOutOfGas
 		}
-		gas += nz * cfg.TxDataNonZeroGas
+		gas += nz * nonZeroGas
 
 		z := uint64(len(data)) - nz
 		if (math.MaxUint64-gas)/cfg.TxDataZeroGas < z {
@@ -116,7 +120,8 @@ func NewStateTransition(vmenv vm.VmInterface, msg types.IMessage, tx types.Tx, t
 	}
 	message, _ := msg.AsMessage()
 	log.Debug("NewStateTransition", "message", message)
-	if message.TxType() == types.TxUTXO {
+	// calls of the RPC have no tx, the sender is given by the message
+	if message.TxType() == types.TxUTXO && tx != nil {
 		from, err := message.From(tx, vmenv.GetStateDB())
 		log.Debug("NewStateTransition", "from", from, "err", err)
 	}
@@ -264,7 +269,8 @@ func (st *StateTransition) UTXOTransitionDb() (ret []byte, usedGas uint64, byteC
 		}
 
 		gasRate := st.vmenv.GasRate()
-		gas, _ := IntrinsicGas(contractData, false, true, gasRate)
+		rules := cfg.RulesAt(st.vmenv.GetBlockNumber())
+		gas, _ := IntrinsicGas(contractData, false, true, rules.IsIstanbul, gasRate)
 		log.Debug("UTXOTransitionDb IntrinsicGas", "gas", gas, "gasRate", gasRate, "st.gas", st.gas, "value", inputValue)
 		if err = st.useGas(gas); err != nil {
 			log.Warn("UTXOTransitionDb out of gas", "need IntrinsicGas", gas, "have gas", st.gas)
@@ -310,6 +316,9 @@ func (st *StateTransition) UTXOTransitionDb() (ret []byte, usedGas uint64, byteC
 		}
 
 		sender := evm.AccountRef(msg.MsgFrom())
+		if rules.IsBerlin {
+			st.state.PrepareAccessList(msg.MsgFrom(), &contractAddr, evm.ActivePrecompiles(rules))
+		}
 		log.Debug("UTXOTransitionDb before contract Call", "st.gas", st.gas)
 		ret, st.gas, byteCodeGas, vmerr = st.vmenv.UTXOCall(sender, contractAddr, msg.TokenAddress(), contractData, st.gas, contractValue)
 		log.Debug("UTXOTransitionDb after contract Call", "st.gas", st.gas, "byteCodeGas", byteCodeGas, "vmerr", vmerr)
@@ -372,9 +381,10 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, byteCodeG
 	)
 
 	gasRate := vmenv.GasRate()
+	rules := cfg.RulesAt(vmenv.GetBlockNumber())
 
 	// Pay intrinsic gas
-	intrinsicGas, err = IntrinsicGas(msg.Data(), contractCreation, true, gasRate)
+	intrinsicGas, err = IntrinsicGas(msg.Data(), contractCreation, true, rules.IsIstanbul, gasRate)
 	if err != nil {
 		return
 	}
@@ -386,6 +396,10 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, byteCodeG
 		return
 	}
 
+	if rules.IsBerlin {
+		st.state.PrepareAccessList(msg.MsgFrom(), msg.To(), evm.ActivePrecompiles(rules))
+	}
+
 	if msg.TxType() == types.TxContractUpgrade {
 		vmenv.Upgrade(msg.MsgFrom(), *msg.To(), msg.Data())
 	} else if msg.To() == nil {
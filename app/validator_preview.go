@@ -0,0 +1,181 @@
+package app
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/crypto"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+// reasons a candidate is left out of the ranking
+const (
+	ExcludedNoScore = "no score"
+	ExcludedSlashed = "slashed"
+)
+
+// CandidateAdjust simulates the changes of a candidate before the election.
+type CandidateAdjust struct {
+	CoinBase common.Address
+	Deposit  *big.Int // extra deposit(wei), the votes pledged to the candidate included
+	Score    int64    // extra score, may be negative
+}
+
+// CandidatePreview is the ranking inputs and the result of a candidate.
+type CandidatePreview struct {
+	*types.CandidateInOrder
+	RankResult string `json:"rankResult"`         // -1 if the candidate is excluded
+	Elected    bool   `json:"elected"`            // validator after the election
+	Current    bool   `json:"current"`            // validator now
+	Excluded   string `json:"excluded,omitempty"` // reason the candidate is not ranked
+}
+
+// ValidatorsPreview is the validators elected at the next election computed
+// from the pending state.
+type ValidatorsPreview struct {
+	Height            uint64              `json:"height"`            // height of the latest block
+	ElectionHeight    uint64              `json:"electionHeight"`    // height of the next election
+	LastChangedHeight uint64              `json:"lastChangedHeight"` // height the validators changed last
+	RandSeed          common.Hash         `json:"randSeed"`          // the election block uses the hash of its own commit
+	Coefficient       *types.Coefficient  `json:"coefficient"`
+	Candidates        []*CandidatePreview `json:"candidates"`
+	Validators        []*types.Validator  `json:"validators"` // white list first
+	Joining           []*types.Validator  `json:"joining"`
+	Leaving           []*types.Validator  `json:"leaving"`
+}
+
+// PreviewValidators computes the validators of the next election from the
+// pending state with the adjusts applied. The rand of the candidates is only
+// an estimate by the commit of the latest block.
+func (app *LinkApplication) PreviewValidators(adjusts []*CandidateAdjust) (*ValidatorsPreview, error) {
+	st := app.GetPendingStateDB()
+	block := app.currentBlock
+	coe := GetCoefficient(st, app.logger)
+	lastHeight, lastVals := app.GetLastChangedVals()
+
+	preview := &ValidatorsPreview{
+		Height:            block.Height,
+		ElectionHeight:    (block.Height/coe.VotePeriod + 1) * coe.VotePeriod,
+		LastChangedHeight: lastHeight,
+		RandSeed:          block.LastCommit.Hash(),
+		Coefficient:       coe,
+	}
+
+	canState := st.GetAllCandidates(app.logger)
+	adjustMap := make(map[common.Address]*CandidateAdjust, len(adjusts))
+	for _, adj := range adjusts {
+		found := false
+		for _, v := range canState {
+			if v.CoinBase == adj.CoinBase {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("candidate of coinbase %s not found", adj.CoinBase.String())
+		}
+		adjustMap[adj.CoinBase] = adj
+	}
+
+	can := make(types.CandidateInOrderList, 0, len(canState))
+	excluded := make([]*CandidatePreview, 0)
+	for _, v := range canState {
+		h := crypto.Keccak256Hash(preview.RandSeed[:], v.Address)
+		c := &types.CandidateInOrder{
+			Candidate: v.Candidate,
+			Score:     v.Score,
+			Rand:      int64(binary.BigEndian.Uint64(h[:8]) & math.MaxInt64),
+			Rank:      -1,
+		}
+		if adj := adjustMap[v.CoinBase]; adj != nil {
+			c.Score += adj.Score
+			if c.Score > coe.MaxScore {
+				c.Score = coe.MaxScore
+			}
+			if c.Score < 0 {
+				c.Score = 0
+			}
+		}
+		switch {
+		case c.Score <= 0:
+			excluded = append(excluded, &CandidatePreview{CandidateInOrder: c, Excluded: ExcludedNoScore})
+		case app.isSlashed(st, v.PubKey, preview.ElectionHeight):
+			excluded = append(excluded, &CandidatePreview{CandidateInOrder: c, Excluded: ExcludedSlashed})
+		default:
+			can = append(can, c)
+		}
+	}
+
+	addrs := make([]common.Address, 0, len(can))
+	for _, v := range can {
+		addrs = append(addrs, v.CoinBase)
+	}
+	deposits := st.GetCandidatesDeposit(addrs, app.logger)
+	for i, v := range can {
+		if adj := adjustMap[v.CoinBase]; adj != nil && adj.Deposit != nil {
+			deposits[i] = new(big.Int).Add(deposits[i], adj.Deposit)
+			if deposits[i].Sign() < 0 {
+				deposits[i].SetInt64(0)
+			}
+		}
+	}
+	rankCandidates(can, deposits, coe)
+
+	current := make(map[string]struct{}, len(lastVals))
+	for _, v := range lastVals {
+		current[v.Address.String()] = struct{}{}
+	}
+	elected := numElected(len(can), coe)
+	preview.Validators = st.GetWhiteValidators(app.logger)
+	for i, v := range can {
+		_, isCurrent := current[v.Address.String()]
+		preview.Candidates = append(preview.Candidates, &CandidatePreview{
+			CandidateInOrder: v,
+			RankResult:       v.RankResult.FloatString(8),
+			Elected:          i < elected,
+			Current:          isCurrent,
+		})
+		if i < elected {
+			preview.Validators = append(preview.Validators, &types.Validator{
+				Address:     v.Address,
+				PubKey:      v.PubKey,
+				CoinBase:    v.CoinBase,
+				VotingPower: v.VotingPower,
+			})
+		}
+	}
+	sort.Slice(excluded, func(i, j int) bool { return excluded[i].CoinBase.String() < excluded[j].CoinBase.String() })
+	for _, v := range excluded {
+		_, v.Current = current[v.Address.String()]
+		v.RankResult = "-1"
+		preview.Candidates = append(preview.Candidates, v)
+	}
+
+	preview.Joining, preview.Leaving = diffValidators(lastVals, preview.Validators)
+	return preview, nil
+}
+
+// diffValidators returns the validators joining and leaving the old ones.
+func diffValidators(old, new []*types.Validator) (joining, leaving []*types.Validator) {
+	oldSet := make(map[string]struct{}, len(old))
+	for _, v := range old {
+		oldSet[v.Address.String()] = struct{}{}
+	}
+	newSet := make(map[string]struct{}, len(new))
+	for _, v := range new {
+		newSet[v.Address.String()] = struct{}{}
+		if _, ok := oldSet[v.Address.String()]; !ok {
+			joining = append(joining, v)
+		}
+	}
+	for _, v := range old {
+		if _, ok := newSet[v.Address.String()]; !ok {
+			leaving = append(leaving, v)
+		}
+	}
+	return
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/config"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/crypto"
+	"github.com/lianxiangcloud/linkchain/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankCandidates(t *testing.T) {
+	coe := types.DefaultCoefficient()
+	newCan := func(b byte, score int64) *types.CandidateInOrder {
+		return &types.CandidateInOrder{
+			Candidate: types.Candidate{CoinBase: common.BytesToAddress([]byte{b})},
+			Score:     score,
+			Rand:      1,
+		}
+	}
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(config.Ether)) }
+
+	can := types.CandidateInOrderList{newCan(1, 10), newCan(2, 10), newCan(3, 10)}
+	rankCandidates(can, []*big.Int{ether(1), ether(3), ether(2)}, coe)
+	assert.Equal(t, []byte{2}, can[0].CoinBase.Bytes()[19:])
+	assert.Equal(t, []byte{3}, can[1].CoinBase.Bytes()[19:])
+	assert.Equal(t, []byte{1}, can[2].CoinBase.Bytes()[19:])
+	for i, v := range can {
+		assert.Equal(t, i, v.Rank)
+	}
+	assert.Equal(t, int64(3), can[0].Deposit)
+
+	// the extra deposit moves the last candidate to the first
+	can = types.CandidateInOrderList{newCan(1, 10), newCan(2, 10), newCan(3, 10)}
+	rankCandidates(can, []*big.Int{ether(5), ether(3), ether(2)}, coe)
+	assert.Equal(t, []byte{1}, can[0].CoinBase.Bytes()[19:])
+
+	assert.Equal(t, 3, numElected(5, coe))
+	assert.Equal(t, coe.UpperLimit, numElected(100, coe))
+}
+
+func TestDiffValidators(t *testing.T) {
+	newVal := func(b byte) *types.Validator {
+		return &types.Validator{Address: crypto.Address([]byte{b})}
+	}
+	v1, v2, v3 := newVal(1), newVal(2), newVal(3)
+	joining, leaving := diffValidators([]*types.Validator{v1, v2}, []*types.Validator{v2, v3})
+	assert.Equal(t, []*types.Validator{v3}, joining)
+	assert.Equal(t, []*types.Validator{v1}, leaving)
+}
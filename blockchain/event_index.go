@@ -0,0 +1,160 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/libs/ser"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+const (
+	eventLogKeyPre   string = "cel_" // contract address + position -> log
+	eventTopicKeyPre string = "cet_" // contract address + topic + position -> nil
+
+	// eventPositionLen is the size of the position of an event: block height,
+	// tx index and log index.
+	eventPositionLen = 8 + 4 + 4
+)
+
+var errInvalidEventCursor = errors.New("invalid event cursor")
+
+// EventIndexStore indexes the logs of the contracts by contract address and by
+// the first topic of the logs, which is the event name of the wasm contracts, in
+// order of their position in the chain.
+type EventIndexStore struct {
+	db     dbm.DB
+	isOpen bool
+}
+
+func NewEventIndexStore(db dbm.DB, openFlag bool) *EventIndexStore {
+	return &EventIndexStore{
+		db:     db,
+		isOpen: openFlag,
+	}
+}
+
+// IsOpen returns whether the contract events are indexed.
+func (e *EventIndexStore) IsOpen() bool {
+	return e.isOpen
+}
+
+// Save indexes the logs of the block.
+func (e *EventIndexStore) Save(blockHeight uint64, blockHash common.Hash, logs []*types.Log) {
+	if !e.isOpen || len(logs) == 0 {
+		return
+	}
+	batch := e.db.NewBatch()
+	for _, l := range logs {
+		sl := types.LogForStorage(*l)
+		sl.BlockNumber, sl.BlockHash = blockHeight, blockHash
+		val, err := ser.EncodeToBytes(&sl)
+		if err != nil {
+			panic(err)
+		}
+		pos := eventPosition(blockHeight, uint32(l.TxIndex), uint32(l.Index))
+		batch.Set(calEventLogKey(l.Address, pos), val)
+		if len(l.Topics) > 0 {
+			batch.Set(calEventTopicKey(l.Address, l.Topics[0], pos), []byte{})
+		}
+	}
+	batch.Write()
+}
+
+// Events returns at most limit logs of the contract between the blocks from and
+// to inclusive, whose first topic is any of topics, or all the logs of the
+// contract if no topics given. The logs start from cursor if not empty, next is
+// the cursor of the following logs, or nil if there are no more.
+func (e *EventIndexStore) Events(addr common.Address, topics []common.Hash, from, to uint64, cursor []byte, limit int) (logs []*types.Log, next []byte, err error) {
+	start := eventPosition(from, 0, 0)
+	if len(cursor) > 0 {
+		if len(cursor) != eventPositionLen {
+			return nil, nil, errInvalidEventCursor
+		}
+		if bytes.Compare(cursor, start) > 0 {
+			start = cursor
+		}
+	}
+	var end []byte
+	if to < ^uint64(0) {
+		end = eventPosition(to+1, 0, 0)
+	}
+
+	// one more position tells whether there are following logs
+	var positions [][]byte
+	if len(topics) == 0 {
+		positions = e.positions(calEventLogKey(addr, nil), start, end, limit+1)
+	} else {
+		for _, topic := range topics {
+			positions = append(positions, e.positions(calEventTopicKey(addr, topic, nil), start, end, limit+1)...)
+		}
+		sort.Slice(positions, func(i, j int) bool { return bytes.Compare(positions[i], positions[j]) < 0 })
+	}
+	if len(positions) > limit {
+		next = positions[limit]
+		positions = positions[:limit]
+	}
+
+	logs = make([]*types.Log, 0, len(positions))
+	for _, pos := range positions {
+		val := e.db.Get(calEventLogKey(addr, pos))
+		if len(val) == 0 {
+			continue
+		}
+		var sl types.LogForStorage
+		if err := ser.DecodeBytes(val, &sl); err != nil {
+			return nil, nil, err
+		}
+		logs = append(logs, (*types.Log)(&sl))
+	}
+	return logs, next, nil
+}
+
+// positions returns at most limit positions of the keys with prefix, from
+// start inclusive to end exclusive, or to the end of prefix if end is nil.
+func (e *EventIndexStore) positions(prefix, start, end []byte, limit int) [][]byte {
+	startKey := append(append([]byte{}, prefix...), start...)
+	endKey := dbm.PrefixToEnd(prefix)
+	if end != nil {
+		endKey = append(append([]byte{}, prefix...), end...)
+	}
+
+	var positions [][]byte
+	it := e.db.Iterator(startKey, endKey)
+	defer it.Close()
+	for ; it.Valid() && len(positions) < limit; it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+eventPositionLen {
+			continue
+		}
+		positions = append(positions, append([]byte{}, key[len(prefix):]...))
+	}
+	return positions
+}
+
+func eventPosition(height uint64, txIndex, logIndex uint32) []byte {
+	pos := make([]byte, eventPositionLen)
+	binary.BigEndian.PutUint64(pos, height)
+	binary.BigEndian.PutUint32(pos[8:], txIndex)
+	binary.BigEndian.PutUint32(pos[12:], logIndex)
+	return pos
+}
+
+func calEventLogKey(addr common.Address, pos []byte) []byte {
+	key := make([]byte, 0, len(eventLogKeyPre)+common.AddressLength+len(pos))
+	key = append(key, eventLogKeyPre...)
+	key = append(key, addr.Bytes()...)
+	return append(key, pos...)
+}
+
+func calEventTopicKey(addr common.Address, topic common.Hash, pos []byte) []byte {
+	key := make([]byte, 0, len(eventTopicKeyPre)+common.AddressLength+common.HashLength+len(pos))
+	key = append(key, eventTopicKeyPre...)
+	key = append(key, addr.Bytes()...)
+	key = append(key, topic.Bytes()...)
+	return append(key, pos...)
+}
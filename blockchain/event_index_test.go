@@ -0,0 +1,78 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+func TestEventIndex(t *testing.T) {
+	contract := common.BytesToAddress([]byte{1})
+	other := common.BytesToAddress([]byte{2})
+	transfer := common.BytesToHash([]byte("transfer"))
+	approve := common.BytesToHash([]byte("approve"))
+
+	eis := NewEventIndexStore(dbm.NewMemDB(), true)
+	eis.Save(1, common.EmptyHash, []*types.Log{
+		{Address: contract, Topics: []common.Hash{transfer}, Data: []byte("1"), TxIndex: 0, Index: 0},
+		{Address: other, Topics: []common.Hash{transfer}, Data: []byte("2"), TxIndex: 0, Index: 1},
+		{Address: contract, Topics: []common.Hash{approve}, Data: []byte("3"), TxIndex: 1, Index: 2},
+	})
+	eis.Save(3, common.EmptyHash, []*types.Log{
+		{Address: contract, Data: []byte("4"), TxIndex: 0, Index: 0},
+		{Address: contract, Topics: []common.Hash{transfer}, Data: []byte("5"), TxIndex: 2, Index: 1},
+	})
+
+	tests := []struct {
+		topics   []common.Hash
+		from, to uint64
+		want     []string
+	}{
+		{nil, 0, 10, []string{"1", "3", "4", "5"}},
+		{[]common.Hash{transfer}, 0, 10, []string{"1", "5"}},
+		{[]common.Hash{transfer, approve}, 0, 10, []string{"1", "3", "5"}},
+		{[]common.Hash{transfer}, 2, 10, []string{"5"}},
+		{nil, 0, 2, []string{"1", "3"}},
+		{nil, 3, ^uint64(0), []string{"4", "5"}},
+	}
+	for i, tt := range tests {
+		// page by 2 logs
+		var (
+			have   []string
+			cursor []byte
+		)
+		for {
+			logs, next, err := eis.Events(contract, tt.topics, tt.from, tt.to, cursor, 2)
+			if err != nil {
+				t.Fatalf("test %d: %v", i, err)
+			}
+			for _, l := range logs {
+				have = append(have, string(l.Data))
+			}
+			if next == nil {
+				break
+			}
+			cursor = next
+		}
+		if len(have) != len(tt.want) {
+			t.Fatalf("test %d: have %v, want %v", i, have, tt.want)
+		}
+		for j := range have {
+			if have[j] != tt.want[j] {
+				t.Fatalf("test %d: have %v, want %v", i, have, tt.want)
+			}
+		}
+	}
+
+	if _, _, err := eis.Events(contract, nil, 0, 10, []byte{1}, 2); err != errInvalidEventCursor {
+		t.Errorf("expected invalid cursor error, have %v", err)
+	}
+
+	closed := NewEventIndexStore(dbm.NewMemDB(), false)
+	closed.Save(1, common.EmptyHash, []*types.Log{{Address: contract, Topics: []common.Hash{transfer}}})
+	if logs, _, _ := closed.Events(contract, nil, 0, 10, nil, 10); len(logs) != 0 {
+		t.Errorf("closed store indexed %d logs", len(logs))
+	}
+}
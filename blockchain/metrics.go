@@ -0,0 +1,65 @@
+package blockchain
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Whether the node is fast syncing.
+	Syncing metrics.Gauge
+	// Height applied by the fast sync.
+	SyncHeight metrics.Gauge
+	// Max height of the peers.
+	MaxPeerHeight metrics.Gauge
+	// Blocks applied per second.
+	SyncRate metrics.Gauge
+	// Number of blocks requested but not received.
+	PendingBlocks metrics.Gauge
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+func PrometheusMetrics() *Metrics {
+	return &Metrics{
+		Syncing: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Subsystem: "blockchain",
+			Name:      "syncing",
+			Help:      "Whether the node is fast syncing.",
+		}, []string{}),
+		SyncHeight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Subsystem: "blockchain",
+			Name:      "sync_height",
+			Help:      "Height applied by the fast sync.",
+		}, []string{}),
+		MaxPeerHeight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Subsystem: "blockchain",
+			Name:      "max_peer_height",
+			Help:      "Max height of the peers.",
+		}, []string{}),
+		SyncRate: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Subsystem: "blockchain",
+			Name:      "sync_rate",
+			Help:      "Blocks applied per second.",
+		}, []string{}),
+		PendingBlocks: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Subsystem: "blockchain",
+			Name:      "pending_blocks",
+			Help:      "Number of blocks requested but not received.",
+		}, []string{}),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		Syncing:       discard.NewGauge(),
+		SyncHeight:    discard.NewGauge(),
+		MaxPeerHeight: discard.NewGauge(),
+		SyncRate:      discard.NewGauge(),
+		PendingBlocks: discard.NewGauge(),
+	}
+}
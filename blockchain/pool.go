@@ -32,6 +32,8 @@ const (
 	maxTotalRequesters        = 60
 	maxPendingRequests        = maxTotalRequesters
 	maxPendingRequestsPerPeer = 30
+	// blocks requested from a peer at once
+	maxBlocksPerRangeRequest = 8
 
 	// Minimum recv rate to ensure we're receiving blocks from a peer fast
 	// enough. If a peer is not sending us data at at least that rate, we
@@ -49,8 +51,9 @@ var peerTimeout = 120 * time.Second // not const so we can override with tests
 
 /*
 	Peers self report their heights when we join the block pool.
-	Starting from our latest pool.height, we request blocks
-	in sequence from peers that reported higher heights than ours.
+	Starting from our latest pool.height, we request ranges of blocks
+	in sequence from peers that reported higher heights than ours, so the
+	ranges are downloaded from several peers at once.
 	Every so often we ask peers what height they're on so we can keep going.
 
 	Requests are continuously made for blocks of higher heights until
@@ -64,14 +67,14 @@ type BlockPool struct {
 
 	mtx sync.Mutex
 	// block requests
-	requesters map[uint64]*bpRequester //key:height
+	requesters map[uint64]*bpRequester //key:height, a requester of a range is keyed by each height of the range
 	height     uint64                  // the lowest key in requesters.
 	// peers
 	peers         map[string]*bpPeer
 	maxPeerHeight uint64
 
 	// atomic
-	numPending int32 // number of requests pending assignment or block response
+	numPending int32 // number of blocks pending assignment or block response
 
 	mockCaughtUp  bool
 	neverCaughtUp bool
@@ -108,13 +111,11 @@ func (pool *BlockPool) OnStop() {
 	pool.mtx.Lock()
 	defer pool.mtx.Unlock()
 	// clear the remaining bpRequesters
-	nextHeight := pool.height + pool.requestersLen()
-	height := pool.height
-	for r := pool.requesters[height]; height < nextHeight; height++ {
-		if r != nil {
+	for height, r := range pool.requesters {
+		if r.IsRunning() {
 			r.Stop()
-			delete(pool.requesters, pool.height)
 		}
+		delete(pool.requesters, height)
 	}
 }
 
@@ -232,14 +233,35 @@ func (pool *BlockPool) PeekTwoBlocks() (first *types.Block, second *types.Block)
 	defer pool.mtx.Unlock()
 
 	if r := pool.requesters[pool.height]; r != nil {
-		first = r.getBlock()
+		first = r.getBlock(pool.height)
 	}
 	if r := pool.requesters[pool.height+1]; r != nil {
-		second = r.getBlock()
+		second = r.getBlock(pool.height + 1)
 	}
 	return
 }
 
+// PeekBlocks returns at most n consecutive blocks from pool.height,
+// it stops at the first block not received yet.
+func (pool *BlockPool) PeekBlocks(n int) []*types.Block {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	blocks := make([]*types.Block, 0, n)
+	for height := pool.height; len(blocks) < n; height++ {
+		r := pool.requesters[height]
+		if r == nil {
+			break
+		}
+		block := r.getBlock(height)
+		if block == nil {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
 // Pop the first block at pool.height
 // It must have been validated by 'second'.Commit from PeekTwoBlocks().
 func (pool *BlockPool) PopRequest() {
@@ -252,7 +274,10 @@ func (pool *BlockPool) PopRequest() {
 			PanicSanity("PopRequest() requires a valid block")
 		}
 		*/
-		r.Stop()
+		// the requester is done once all blocks of its range are popped
+		if r.popBlock() {
+			r.Stop()
+		}
 		delete(pool.requesters, pool.height)
 		pool.height++
 	} else {
@@ -297,6 +322,7 @@ func (pool *BlockPool) AddBlock(peerID string, block *types.Block, blockSize int
 		if peer != nil {
 			peer.decrPending(blockSize)
 		}
+		syncDownloadMeter.Mark(int64(blockSize))
 	} else {
 		// Bad peer?
 	}
@@ -309,6 +335,28 @@ func (pool *BlockPool) MaxPeerHeight() uint64 {
 	return pool.maxPeerHeight
 }
 
+// PeersProgress returns the heights and the download throughput of the peers.
+func (pool *BlockPool) PeersProgress() []*PeerSyncProgress {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	peers := make([]*PeerSyncProgress, 0, len(pool.peers))
+	for _, peer := range pool.peers {
+		p := &PeerSyncProgress{
+			ID:      peer.id,
+			Height:  peer.height,
+			Pending: peer.numPending,
+			Blocks:  peer.numBlocks,
+		}
+		if peer.recvMonitor != nil {
+			status := peer.recvMonitor.Status()
+			p.CurRate, p.AvgRate = status.CurRate, status.AvgRate
+		}
+		peers = append(peers, p)
+	}
+	return peers
+}
+
 // Sets the peer's alleged blockchain height.
 func (pool *BlockPool) SetPeerHeight(peerID string, height uint64) {
 	pool.mtx.Lock()
@@ -364,9 +412,9 @@ func (pool *BlockPool) updateMaxPeerHeight() {
 	pool.maxPeerHeight = max
 }
 
-// Pick an available peer with at least the given minHeight.
-// If no peers are available, returns nil.
-func (pool *BlockPool) pickIncrAvailablePeer(minHeight uint64) *bpPeer {
+// Pick an available peer with at least the given minHeight and room for
+// count more blocks. If no peers are available, returns nil.
+func (pool *BlockPool) pickIncrAvailablePeer(minHeight uint64, count int32) *bpPeer {
 	pool.mtx.Lock()
 	defer pool.mtx.Unlock()
 
@@ -377,7 +425,7 @@ func (pool *BlockPool) pickIncrAvailablePeer(minHeight uint64) *bpPeer {
 			pool.removePeer(peer.id)
 			continue
 		}
-		if peer.numPending >= maxPendingRequestsPerPeer {
+		if peer.numPending+count > maxPendingRequestsPerPeer {
 			continue
 		}
 		if peer.height < minHeight {
@@ -392,7 +440,7 @@ func (pool *BlockPool) pickIncrAvailablePeer(minHeight uint64) *bpPeer {
 		}
 	}
 	if best != nil {
-		best.incrPending()
+		best.incrPending(count)
 		return best
 	}
 	return nil
@@ -403,10 +451,23 @@ func (pool *BlockPool) makeNextRequester() {
 	defer pool.mtx.Unlock()
 
 	nextHeight := pool.height + pool.requestersLen()
-	request := newBPRequester(pool, nextHeight)
+	// request a range of blocks the peers have, a single block otherwise
+	count := uint64(1)
+	if pool.maxPeerHeight >= nextHeight {
+		count = pool.maxPeerHeight - nextHeight + 1
+		if count > maxBlocksPerRangeRequest {
+			count = maxBlocksPerRangeRequest
+		}
+		if left := maxTotalRequesters - pool.requestersLen(); count > left && left > 0 {
+			count = left
+		}
+	}
+	request := newBPRequester(pool, nextHeight, count)
 
-	pool.requesters[nextHeight] = request
-	atomic.AddInt32(&pool.numPending, 1)
+	for height := nextHeight; height < nextHeight+count; height++ {
+		pool.requesters[height] = request
+	}
+	atomic.AddInt32(&pool.numPending, int32(count))
 
 	err := request.Start()
 	if err != nil {
@@ -418,11 +479,11 @@ func (pool *BlockPool) requestersLen() uint64 {
 	return uint64(len(pool.requesters))
 }
 
-func (pool *BlockPool) sendRequest(height uint64, peerID string) {
+func (pool *BlockPool) sendRequest(height, count uint64, peerID string) {
 	if !pool.IsRunning() {
 		return
 	}
-	pool.requestsCh <- BlockRequest{height, peerID}
+	pool.requestsCh <- BlockRequest{height, count, peerID}
 }
 
 func (pool *BlockPool) sendError(err error, peerID string) {
@@ -444,7 +505,7 @@ func (pool *BlockPool) debug() string {
 			str += cmn.Fmt("H(%v):X ", h)
 		} else {
 			str += cmn.Fmt("H(%v):", h)
-			str += cmn.Fmt("B?(%v) ", pool.requesters[h].block != nil)
+			str += cmn.Fmt("B?(%v) ", pool.requesters[h].getBlock(h) != nil)
 		}
 	}
 	return str
@@ -459,6 +520,7 @@ type bpPeer struct {
 
 	height     uint64
 	numPending int32
+	numBlocks  uint64 // blocks received from the peer
 	timeout    *time.Timer
 	didTimeout bool
 
@@ -494,15 +556,16 @@ func (peer *bpPeer) resetTimeout() {
 	}
 }
 
-func (peer *bpPeer) incrPending() {
+func (peer *bpPeer) incrPending(count int32) {
 	if peer.numPending == 0 {
 		peer.resetMonitor()
 		peer.resetTimeout()
 	}
-	peer.numPending++
+	peer.numPending += count
 }
 
 func (peer *bpPeer) decrPending(recvSize int) {
+	peer.numBlocks++
 	peer.numPending--
 	if peer.numPending == 0 {
 		peer.timeout.Stop()
@@ -527,16 +590,16 @@ func (peer *bpPeer) onTimeout() {
 type bpRequester struct {
 	cmn.BaseService
 	pool       *BlockPool
-	height     uint64
 	gotBlockCh chan struct{}
 	redoCh     chan struct{}
 
 	mtx    sync.Mutex
+	height uint64 // the lowest height of the range not popped
 	peerID string
-	block  *types.Block
+	blocks []*types.Block // blocks of the range from height
 }
 
-func newBPRequester(pool *BlockPool, height uint64) *bpRequester {
+func newBPRequester(pool *BlockPool, height, count uint64) *bpRequester {
 	bpr := &bpRequester{
 		pool:       pool,
 		height:     height,
@@ -544,7 +607,7 @@ func newBPRequester(pool *BlockPool, height uint64) *bpRequester {
 		redoCh:     make(chan struct{}, 1),
 
 		peerID: "",
-		block:  nil,
+		blocks: make([]*types.Block, count),
 	}
 	bpr.BaseService = *cmn.NewBaseService(nil, "bpRequester", bpr)
 	return bpr
@@ -568,14 +631,22 @@ func (bpr *bpRequester) OnStop() {
 	bpr.mtx.Unlock()
 }
 
+// getRange returns the range of the blocks not popped.
+func (bpr *bpRequester) getRange() (height, count uint64) {
+	bpr.mtx.Lock()
+	defer bpr.mtx.Unlock()
+	return bpr.height, uint64(len(bpr.blocks))
+}
+
 // Returns true if the peer matches and block doesn't already exist.
 func (bpr *bpRequester) setBlock(block *types.Block, peerID string) bool {
 	bpr.mtx.Lock()
-	if bpr.block != nil || bpr.peerID != peerID {
+	if bpr.peerID != peerID || block.Height < bpr.height || block.Height-bpr.height >= uint64(len(bpr.blocks)) ||
+		bpr.blocks[block.Height-bpr.height] != nil {
 		bpr.mtx.Unlock()
 		return false
 	}
-	bpr.block = block
+	bpr.blocks[block.Height-bpr.height] = block
 	bpr.mtx.Unlock()
 
 	select {
@@ -585,10 +656,25 @@ func (bpr *bpRequester) setBlock(block *types.Block, peerID string) bool {
 	return true
 }
 
-func (bpr *bpRequester) getBlock() *types.Block {
+func (bpr *bpRequester) getBlock(height uint64) *types.Block {
 	bpr.mtx.Lock()
 	defer bpr.mtx.Unlock()
-	return bpr.block
+	if height < bpr.height || height-bpr.height >= uint64(len(bpr.blocks)) {
+		return nil
+	}
+	return bpr.blocks[height-bpr.height]
+}
+
+// popBlock drops the lowest block of the range,
+// returns true if no block of the range is left.
+func (bpr *bpRequester) popBlock() bool {
+	bpr.mtx.Lock()
+	defer bpr.mtx.Unlock()
+	if len(bpr.blocks) > 0 {
+		bpr.blocks = bpr.blocks[1:]
+		bpr.height++
+	}
+	return len(bpr.blocks) == 0
 }
 
 func (bpr *bpRequester) getPeerID() string {
@@ -602,12 +688,13 @@ func (bpr *bpRequester) reset() {
 	bpr.mtx.Lock()
 	defer bpr.mtx.Unlock()
 
-	if bpr.block != nil && bpr.pool != nil {
-		atomic.AddInt32(&bpr.pool.numPending, 1)
+	for i, block := range bpr.blocks {
+		if block != nil && bpr.pool != nil {
+			atomic.AddInt32(&bpr.pool.numPending, 1)
+		}
+		bpr.blocks[i] = nil
 	}
-
 	bpr.peerID = ""
-	bpr.block = nil
 }
 
 // Tells bpRequester to pick another peer and try again.
@@ -621,20 +708,23 @@ func (bpr *bpRequester) redo() {
 }
 
 // Responsible for making more requests as necessary
-// Returns only when a block is found (e.g. AddBlock() is called)
+// Returns only when all blocks of the range are popped
 func (bpr *bpRequester) requestRoutine() {
 OUTER_LOOP:
 	for {
-		// Pick a peer to send request to.
+		// Pick a peer having the whole range to send request to.
 		var peer *bpPeer
 		pool := bpr.getPool()
+		height, count := bpr.getRange()
 	PICK_PEER_LOOP:
 		for {
-			if !bpr.IsRunning() || pool == nil || !pool.IsRunning() {
+			if !bpr.IsRunning() || pool == nil || !pool.IsRunning() || count == 0 {
+				bpr.mtx.Lock()
 				bpr.pool = nil
+				bpr.mtx.Unlock()
 				return
 			}
-			peer = pool.pickIncrAvailablePeer(bpr.height)
+			peer = pool.pickIncrAvailablePeer(height+count-1, int32(count))
 			if peer == nil {
 				//log.Info("No peers available", "height", height)
 				time.Sleep(requestIntervalMS * time.Millisecond)
@@ -647,7 +737,7 @@ OUTER_LOOP:
 		bpr.mtx.Unlock()
 
 		// Send request and wait.
-		pool.sendRequest(bpr.height, peer.id)
+		pool.sendRequest(height, count, peer.id)
 	WAIT_LOOP:
 		for {
 			select {
@@ -670,7 +760,9 @@ OUTER_LOOP:
 
 //-------------------------------------
 
+// BlockRequest requests the blocks of Count heights from Height to the peer.
 type BlockRequest struct {
 	Height uint64
+	Count  uint64
 	PeerID string
 }
@@ -78,19 +78,57 @@ func TestBasic(t *testing.T) {
 			t.Error(err)
 		case request := <-requestsCh:
 			t.Logf("Pulled new BlockRequest %v", request)
-			if request.Height == 300 {
+			if request.Height+request.Count > 300 {
 				return // Done!
 			}
-			// Request desired, pretend like we got the block immediately.
+			// Request desired, pretend like we got the blocks immediately.
 			go func() {
-				block := &types.Block{Header: &types.Header{Height: request.Height}}
-				pool.AddBlock(request.PeerID, block, 123)
-				t.Logf("Added block from peer %v (height: %v)", request.PeerID, request.Height)
+				for height := request.Height; height < request.Height+request.Count; height++ {
+					block := &types.Block{Header: &types.Header{Height: height}}
+					pool.AddBlock(request.PeerID, block, 123)
+				}
+				t.Logf("Added blocks from peer %v (height: %v, count: %v)", request.PeerID, request.Height, request.Count)
 			}()
 		}
 	}
 }
 
+func TestRangeRequest(t *testing.T) {
+	errorsCh := make(chan peerError, 1000)
+	requestsCh := make(chan BlockRequest, 1000)
+	pool := NewBlockPool(uint64(1), requestsCh, errorsCh)
+	pool.SetLogger(log.Test())
+	pool.SetPeerHeight("peer", 100)
+	if err := pool.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Stop()
+
+	request := <-requestsCh
+	if request.Height != 1 || request.Count != maxBlocksPerRangeRequest || request.PeerID != "peer" {
+		t.Fatalf("unexpected request %+v", request)
+	}
+	for height := request.Height + request.Count - 1; height >= request.Height; height-- {
+		pool.AddBlock(request.PeerID, &types.Block{Header: &types.Header{Height: height}}, 123)
+	}
+	blocks := pool.PeekBlocks(maxPrefetchBlocks)
+	if len(blocks) != int(request.Count) {
+		t.Fatalf("peek blocks: have %d, want %d", len(blocks), request.Count)
+	}
+	for i, block := range blocks {
+		if block.Height != request.Height+uint64(i) {
+			t.Fatalf("block %d: have height %d", i, block.Height)
+		}
+	}
+	pool.PopRequest()
+	if first, second := pool.PeekTwoBlocks(); first == nil || second == nil || first.Height != 2 {
+		t.Fatalf("unexpected blocks %v %v after pop", first, second)
+	}
+	if peers := pool.PeersProgress(); len(peers) != 1 || peers[0].Blocks != request.Count {
+		t.Fatalf("unexpected peers progress %+v", peers)
+	}
+}
+
 func TestTimeout(t *testing.T) {
 	start := int64(42)
 	peers := makePeers(10, start+1, 1000)
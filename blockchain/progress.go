@@ -0,0 +1,140 @@
+package blockchain
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
+	"github.com/lianxiangcloud/linkchain/libs/metrics"
+)
+
+// rateWindow is the interval the sync rate is sampled at.
+const rateWindow = 5 * time.Second
+
+var (
+	syncDownloadMeter = metrics.NewRegisteredMeter("blockchain/fastsync/download", nil)
+	syncVerifyTimer   = metrics.NewRegisteredTimer("blockchain/fastsync/verify", nil)
+	syncExecuteTimer  = metrics.NewRegisteredTimer("blockchain/fastsync/execute", nil)
+)
+
+// SyncProgress is the progress of the fast sync, the block fields are named as
+// the ones of eth_syncing of ethereum.
+type SyncProgress struct {
+	Syncing       bool                `json:"-"`
+	StartHeight   hexutil.Uint64      `json:"startingBlock"`
+	CurrentHeight hexutil.Uint64      `json:"currentBlock"`
+	MaxPeerHeight hexutil.Uint64      `json:"highestBlock"`
+	PendingBlocks int32               `json:"pendingBlocks"` // blocks requested but not received
+	Rate          float64             `json:"rate"`          // blocks per second
+	ETA           uint64              `json:"eta"`           // seconds to catch up the peers at the rate
+	Elapsed       uint64              `json:"elapsed"`       // seconds since the sync started
+	Peers         []*PeerSyncProgress `json:"peers"`
+}
+
+// PeerSyncProgress is the height and the download throughput of a peer.
+type PeerSyncProgress struct {
+	ID      string `json:"id"`
+	Height  uint64 `json:"height"`
+	Pending int32  `json:"pending"` // blocks requested from the peer but not received
+	Blocks  uint64 `json:"blocks"`  // blocks received from the peer
+	CurRate int64  `json:"curRate"` // bytes per second
+	AvgRate int64  `json:"avgRate"` // bytes per second
+}
+
+// syncProgress tracks the rate of the blocks applied by the fast sync.
+type syncProgress struct {
+	mtx sync.Mutex
+
+	syncing     bool
+	startHeight uint64
+	startTime   time.Time
+	height      uint64
+	rate        float64
+
+	sampleHeight uint64
+	sampleTime   time.Time
+}
+
+func (p *syncProgress) start(height uint64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	now := time.Now()
+	p.syncing = true
+	p.startHeight, p.startTime = height, now
+	p.height, p.rate = height, 0
+	p.sampleHeight, p.sampleTime = height, now
+}
+
+func (p *syncProgress) stop() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.syncing = false
+	p.rate = 0
+}
+
+// update records the height applied, and samples the rate as a moving
+// average every rateWindow.
+func (p *syncProgress) update(height uint64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.height = height
+
+	elapsed := time.Since(p.sampleTime)
+	if elapsed < rateWindow {
+		return
+	}
+	rate := float64(height-p.sampleHeight) / elapsed.Seconds()
+	if p.rate == 0 {
+		p.rate = rate
+	} else {
+		p.rate = 0.8*p.rate + 0.2*rate
+	}
+	p.sampleHeight, p.sampleTime = height, time.Now()
+}
+
+// snapshot returns the progress towards the max height of the peers.
+func (p *syncProgress) snapshot(maxPeerHeight uint64) *SyncProgress {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	progress := &SyncProgress{
+		Syncing:       p.syncing,
+		StartHeight:   hexutil.Uint64(p.startHeight),
+		CurrentHeight: hexutil.Uint64(p.height),
+		MaxPeerHeight: hexutil.Uint64(maxPeerHeight),
+		Rate:          p.rate,
+	}
+	if p.syncing {
+		progress.Elapsed = uint64(time.Since(p.startTime).Seconds())
+		if p.rate > 0 && maxPeerHeight > p.height {
+			progress.ETA = uint64(float64(maxPeerHeight-p.height) / p.rate)
+		}
+	}
+	return progress
+}
+
+// SyncProgress returns the progress of the fast sync.
+func (bcR *BlockchainReactor) SyncProgress() *SyncProgress {
+	_, numPending, _ := bcR.pool.GetStatus()
+	progress := bcR.progress.snapshot(bcR.pool.MaxPeerHeight())
+	progress.PendingBlocks = numPending
+	progress.Peers = bcR.pool.PeersProgress()
+	return progress
+}
+
+// reportProgress updates the metrics of the fast sync.
+func (bcR *BlockchainReactor) reportProgress() {
+	progress := bcR.SyncProgress()
+	syncing := 0.0
+	if progress.Syncing {
+		syncing = 1
+	}
+	bcR.metrics.Syncing.Set(syncing)
+	bcR.metrics.SyncHeight.Set(float64(progress.CurrentHeight))
+	bcR.metrics.MaxPeerHeight.Set(float64(progress.MaxPeerHeight))
+	bcR.metrics.SyncRate.Set(progress.Rate)
+	bcR.metrics.PendingBlocks.Set(float64(progress.PendingBlocks))
+}
@@ -3,6 +3,8 @@ package blockchain
 import (
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -37,6 +39,15 @@ const (
 	maxLaggingBlocks = 5
 )
 
+const (
+	// Version is the version of the blockchain protocol advertised in the Other of
+	// NodeInfo as blockchain_version.
+	Version = 2
+	// rangeRequestVersion is the version the peers handle bcBlockRangeRequestMessage
+	// since, the peers before stop the peer sending it.
+	rangeRequestVersion = 2
+)
+
 type consensusReactor interface {
 	// for when we switch from blockchain reactor and fast sync to
 	// the consensus machine
@@ -71,11 +82,22 @@ type BlockchainReactor struct {
 
 	requestsCh <-chan BlockRequest
 	errorsCh   <-chan peerError
+
+	progress *syncProgress
+	metrics  *Metrics
+}
+
+// BlockchainReactorOption sets an optional parameter on the BlockchainReactor.
+type BlockchainReactorOption func(*BlockchainReactor)
+
+// WithMetrics sets the metrics.
+func WithMetrics(metrics *Metrics) BlockchainReactorOption {
+	return func(bcR *BlockchainReactor) { bcR.metrics = metrics }
 }
 
 // NewBlockchainReactor returns new reactor instance.
 func NewBlockchainReactor(status cs.NewStatus, blockExec *cs.BlockExecutor, app cs.BlockChainApp,
-	fastSync bool, p2pmanager p2p.P2PManager) *BlockchainReactor {
+	fastSync bool, p2pmanager p2p.P2PManager, options ...BlockchainReactorOption) *BlockchainReactor {
 
 	if status.LastBlockHeight != app.Height() {
 		panic(fmt.Sprintf("status (%v) and app (%v) height mismatch", status.LastBlockHeight,
@@ -103,6 +125,11 @@ func NewBlockchainReactor(status cs.NewStatus, blockExec *cs.BlockExecutor, app
 		canFastSync:   0,
 		requestsCh:    requestsCh,
 		errorsCh:      errorsCh,
+		progress:      &syncProgress{},
+		metrics:       NopMetrics(),
+	}
+	for _, option := range options {
+		option(bcR)
 	}
 	bcR.BaseReactor = *p2p.NewBaseReactor("BlockchainReactor", bcR)
 	return bcR
@@ -224,7 +251,24 @@ func (bcR *BlockchainReactor) respondToPeer(msg *bcBlockRequestMessage,
 	return src.TrySend(BlockchainChannel, msgBytes)
 }
 
-// Receive implements Reactor by handling 4 types of messages (look below).
+// respondRangeToPeer sends the blocks of the range to the requesting peer in
+// order, it stops at the first block we don't have.
+func (bcR *BlockchainReactor) respondRangeToPeer(msg *bcBlockRangeRequestMessage,
+	src p2p.Peer) (queued bool) {
+
+	count := msg.Count
+	if count > maxBlocksPerRangeRequest {
+		count = maxBlocksPerRangeRequest
+	}
+	for height := msg.Height; height < msg.Height+count; height++ {
+		if queued = bcR.respondToPeer(&bcBlockRequestMessage{height}, src); !queued {
+			return
+		}
+	}
+	return
+}
+
+// Receive implements Reactor by handling 5 types of messages (look below).
 func (bcR *BlockchainReactor) Receive(chID byte, src p2p.Peer, msgBytes []byte) {
 	msg, err := decodeMsg(msgBytes)
 	if err != nil {
@@ -240,6 +284,10 @@ func (bcR *BlockchainReactor) Receive(chID byte, src p2p.Peer, msgBytes []byte)
 		if queued := bcR.respondToPeer(resp, src); !queued {
 			// Unfortunately not queued since the queue is full.
 		}
+	case *bcBlockRangeRequestMessage:
+		if queued := bcR.respondRangeToPeer(resp, src); !queued {
+			// Unfortunately not queued since the queue is full.
+		}
 	case *bcBlockResponseMessage:
 		// Got a block.
 		block := resp.Block
@@ -268,21 +316,53 @@ func (bcR *BlockchainReactor) Receive(chID byte, src p2p.Peer, msgBytes []byte)
 	}
 }
 
+// requestMessages returns the messages requesting the blocks of the request from
+// peer, a range request if the peer handles it, a request per height otherwise.
+func requestMessages(peer p2p.Peer, request BlockRequest) [][]byte {
+	if request.Count > 1 && peerVersion(peer) >= rangeRequestVersion {
+		return [][]byte{encodeMsg(&bcBlockRangeRequestMessage{request.Height, request.Count})}
+	}
+	msgs := make([][]byte, 0, request.Count)
+	for height := request.Height; height < request.Height+request.Count; height++ {
+		msgs = append(msgs, encodeMsg(&bcBlockRequestMessage{height}))
+	}
+	return msgs
+}
+
+// peerVersion returns the blockchain_version the peer advertises, 0 if none.
+func peerVersion(peer p2p.Peer) int {
+	for _, s := range peer.NodeInfo().Other {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) == 2 && kv[0] == "blockchain_version" {
+			v, _ := strconv.Atoi(kv[1])
+			return v
+		}
+	}
+	return 0
+}
+
 // Handle messages from the poolReactor telling the reactor what to do.
+// The blocks are downloaded by the pool in ranges from several peers, verified
+// by the verifier in parallel ahead of the execution, and executed in order.
 // NOTE: Don't sleep in the FOR_LOOP or otherwise slow it down!
 // (Except for the SYNC_LOOP, which is the primary purpose and must be synchronous.)
 func (bcR *BlockchainReactor) poolRoutine() {
 
 	trySyncTicker := time.NewTicker(trySyncIntervalMS * time.Millisecond)
 	switchToConsensusTicker := time.NewTicker(switchToConsensusIntervalSeconds * time.Second)
+	defer trySyncTicker.Stop()
+	defer switchToConsensusTicker.Stop()
 
 	blocksSynced := 0
 
 	chainID := bcR.initialStatus.ChainID
 	status := bcR.initialStatus
 
-	lastHundred := time.Now()
-	lastRate := 0.0
+	verifier := newBlockVerifier(chainID)
+	verifier.start()
+	defer verifier.stop()
+
+	bcR.progress.start(status.LastBlockHeight)
 
 FOR_LOOP:
 	for {
@@ -292,13 +372,14 @@ FOR_LOOP:
 			if peer == nil {
 				continue FOR_LOOP // Peer has since been disconnected.
 			}
-			bcR.Logger.Info("sendRequest", "peer", peer, "height", request.Height)
-			msgBytes := encodeMsg(&bcBlockRequestMessage{request.Height})
-			queued := peer.TrySend(BlockchainChannel, msgBytes)
-			if !queued {
-				// We couldn't make the request, send-queue full.
-				// The pool handles timeouts, just let it go.
-				continue FOR_LOOP
+			bcR.Logger.Info("sendRequest", "peer", peer, "height", request.Height, "count", request.Count)
+			for _, msgBytes := range requestMessages(peer, request) {
+				queued := peer.TrySend(BlockchainChannel, msgBytes)
+				if !queued {
+					// We couldn't make the request, send-queue full.
+					// The pool handles timeouts, just let it go.
+					continue FOR_LOOP
+				}
 			}
 		case err := <-bcR.errorsCh:
 			peer := bcR.sw.Peers().GetByID(err.peerID)
@@ -310,9 +391,12 @@ FOR_LOOP:
 			outbound, inbound, _ := bcR.sw.NumPeers()
 			bcR.Logger.Debug("Consensus ticker", "numPending", numPending, "total", lenRequesters,
 				"outbound", outbound, "inbound", inbound)
+			bcR.reportProgress()
 			if bcR.pool.IsCaughtUp() && atomic.CompareAndSwapUint32(&bcR.inFastSync, 1, 0) {
 				bcR.Logger.Info("Time to switch to consensus reactor!", "height", height)
 				bcR.pool.Stop()
+				bcR.progress.stop()
+				bcR.reportProgress()
 
 				conR := bcR.sw.Reactor("CONSENSUS").(consensusReactor)
 				conR.SwitchToConsensus(status, blocksSynced)
@@ -329,6 +413,9 @@ FOR_LOOP:
 			// This loop can be slow as long as it's doing syncing work.
 		SYNC_LOOP:
 			for i := 0; i < 10; i++ {
+				// Verify the blocks ahead while executing the first one.
+				verifier.prefetch(bcR.pool.PeekBlocks(maxPrefetchBlocks+1), status.Validators, status.ConsensusParams.BlockPartSizeBytes)
+
 				// See if there are any blocks to sync.
 				first, second := bcR.pool.PeekTwoBlocks()
 				//bcR.Logger.Info("TrySync peeked", "first", first, "second", second)
@@ -336,17 +423,17 @@ FOR_LOOP:
 					// We need both to sync the first block.
 					break SYNC_LOOP
 				}
-				firstParts := first.MakePartSet(status.ConsensusParams.BlockPartSizeBytes)
-				firstPartsHeader := firstParts.Header()
-				firstID := types.BlockID{first.Hash(), firstPartsHeader}
 
 				if first.Recover > 0 {
 					status.Validators = types.NewValidatorSet(bcR.appmgr.GetRecoverValidators(first.Height - 1))
 					status.LastRecover = true
 				}
 
-				err := status.Validators.VerifyCommit(chainID, firstID, first.Height, second.LastCommit)
-				if err != nil {
+				result := verifier.result(first, second, status.Validators, status.ConsensusParams.BlockPartSizeBytes)
+				firstParts := result.parts
+				firstID := result.blockID
+
+				if err := result.commitErr; err != nil {
 					bcR.Logger.Error("Error in validation", "err", err)
 					bcR.Logger.Report("fast sync block", "logID", types.LogIdSyncBlockCheckError, "height", first.Height, "err", err)
 					//close first peer
@@ -364,8 +451,8 @@ FOR_LOOP:
 					break SYNC_LOOP
 				}
 
-				if !bcR.appmgr.CheckBlock(first) {
-					bcR.Logger.Warn("BlockchainReactor CheckBlock failed", "height", first.Height, "blockHash", first.Hash())
+				if err := result.txsErr; err != nil || !bcR.appmgr.CheckBlock(first) {
+					bcR.Logger.Warn("BlockchainReactor CheckBlock failed", "height", first.Height, "blockHash", first.Hash(), "err", err)
 					bcR.Logger.Report("fast sync block", "logID", types.LogIdSyncBlockCheckError, "height", first.Height, "err", "check block failed")
 					peerID := bcR.pool.RedoRequest(first.Height)
 					peer := bcR.sw.Peers().GetByID(peerID)
@@ -398,6 +485,7 @@ FOR_LOOP:
 
 				// verify the first block success
 				bcR.pool.PopRequest()
+				executeStart := time.Now()
 				// TODO: batch saves so we dont persist to disk every block
 				validators, err := bcR.appmgr.CommitBlock(first, firstParts, second.LastCommit, bcR.fastSync)
 				if err != nil {
@@ -415,17 +503,18 @@ FOR_LOOP:
 					cmn.PanicQ(cmn.Fmt("Failed to process committed block (%d:%X): %v",
 						first.Height, first.Hash(), err))
 				}
+				syncExecuteTimer.UpdateSince(executeStart)
 				blocksSynced++
+				bcR.progress.update(first.Height)
 
 				if status.LastHeightValidatorsChanged > oldHeight {
 					bcR.appmgr.SetLastChangedVals(status.LastHeightValidatorsChanged, status.Validators.Copy().Validators)
 				}
 
 				if blocksSynced%100 == 0 {
-					lastRate = 0.9*lastRate + 0.1*(100/time.Since(lastHundred).Seconds())
-					bcR.Logger.Info("Fast Sync Rate", "height", bcR.pool.height,
-						"max_peer_height", bcR.pool.MaxPeerHeight(), "blocks/s", lastRate)
-					lastHundred = time.Now()
+					progress := bcR.SyncProgress()
+					bcR.Logger.Info("Fast Sync Rate", "height", uint64(progress.CurrentHeight),
+						"max_peer_height", uint64(progress.MaxPeerHeight), "blocks/s", progress.Rate, "eta", progress.ETA)
 				}
 			}
 			continue FOR_LOOP
@@ -465,6 +554,7 @@ type BlockchainMessage interface{}
 func RegisterBlockchainMessages() {
 	ser.RegisterInterface((*BlockchainMessage)(nil), nil)
 	ser.RegisterConcrete(&bcBlockRequestMessage{}, "blockchain/BlockRequest", nil)
+	ser.RegisterConcrete(&bcBlockRangeRequestMessage{}, "blockchain/BlockRangeRequest", nil)
 	ser.RegisterConcrete(&bcBlockResponseMessage{}, "blockchain/BlockResponse", nil)
 	ser.RegisterConcrete(&bcNoBlockResponseMessage{}, "blockchain/NoBlockResponse", nil)
 	ser.RegisterConcrete(&bcStatusResponseMessage{}, "blockchainl/StatusResponse", nil)
@@ -496,6 +586,15 @@ func (m *bcBlockRequestMessage) String() string {
 	return cmn.Fmt("[bcBlockRequestMessage %v]", m.Height)
 }
 
+type bcBlockRangeRequestMessage struct {
+	Height uint64
+	Count  uint64
+}
+
+func (m *bcBlockRangeRequestMessage) String() string {
+	return cmn.Fmt("[bcBlockRangeRequestMessage %v+%v]", m.Height, m.Count)
+}
+
 type bcNoBlockResponseMessage struct {
 	Height uint64
 }
@@ -126,6 +126,7 @@ func makeNodeInfo(chainID string, nodeType types.NodeType, moniker string, httpE
 		Other: []string{
 			cmn.Fmt("p2p_version=%v", p2p.Version),
 			cmn.Fmt("consensus_version=%v", cs.Version),
+			cmn.Fmt("blockchain_version=%v", Version),
 		},
 		Type: nodeType,
 	}
@@ -206,12 +207,79 @@ func TestNoBlockResponse(t *testing.T) {
 	time.Sleep(5 * time.Second)
 }
 
+func TestBlockRangeResponse(t *testing.T) {
+	maxBlockHeight := uint64(20)
+
+	bcr := newBlockchainReactor(log.Test(), maxBlockHeight)
+	bcr.StopFastSync()
+	bcr.Start()
+	defer bcr.Stop()
+
+	peer := newbcrTestPeer(cmn.RandStr(12))
+	bcr.AddPeer(peer)
+	defer bcr.RemovePeer(peer, nil)
+
+	reqBytes := ser.MustEncodeToBytesWithType(&bcBlockRangeRequestMessage{Height: 5, Count: 2})
+	bcr.Receive(BlockchainChannel, peer, reqBytes)
+	for height := uint64(5); height < 7; height++ {
+		blockMsg, ok := peer.lastBlockchainMessage().(*bcBlockResponseMessage)
+		if !ok {
+			t.Fatalf("Expected to receive a block response for height %d", height)
+		}
+		if blockMsg.Block.Height != height {
+			t.Fatalf("Expected response to be for height %d, got %d", height, blockMsg.Block.Height)
+		}
+	}
+}
+
 /*
 // NOTE: This is too hard to test without
 // an easy way to add test peer to switch
 // or without significant refactoring of the module.
 // Alternatively we could actually dial a TCP conn but
 // that seems extreme.
+func TestRequestMessages(t *testing.T) {
+	request := BlockRequest{Height: 5, Count: 3, PeerID: "peer"}
+	decode := func(msgs [][]byte) []BlockchainMessage {
+		decoded := make([]BlockchainMessage, len(msgs))
+		for i, msgBytes := range msgs {
+			msg, err := decodeMsg(msgBytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded[i] = msg
+		}
+		return decoded
+	}
+
+	// the peers before blockchain_version 2 stop the peer sending range requests
+	old := newbcrTestPeer("old")
+	old.info = makeNodeInfo("chainID", types.NodeValidator, "old", "")
+	old.info.Other = old.info.Other[:2]
+	msgs := decode(requestMessages(old, request))
+	if len(msgs) != 3 {
+		t.Fatalf("want 3 block requests, have %v", msgs)
+	}
+	for i, msg := range msgs {
+		if m, ok := msg.(*bcBlockRequestMessage); !ok || m.Height != request.Height+uint64(i) {
+			t.Errorf("request %d: unexpected message %v", i, msg)
+		}
+	}
+
+	peer := newbcrTestPeer("new")
+	peer.info = makeNodeInfo("chainID", types.NodeValidator, "new", "")
+	msgs = decode(requestMessages(peer, request))
+	if m, ok := msgs[0].(*bcBlockRangeRequestMessage); len(msgs) != 1 || !ok || m.Height != 5 || m.Count != 3 {
+		t.Errorf("want a range request, have %v", msgs)
+	}
+	request.Count = 1
+	if msgs = decode(requestMessages(peer, request)); len(msgs) != 1 {
+		t.Errorf("want a block request, have %v", msgs)
+	} else if _, ok := msgs[0].(*bcBlockRequestMessage); !ok {
+		t.Errorf("want a block request, have %v", msgs[0])
+	}
+}
+
 func TestBadBlockStopsPeer(t *testing.T) {
 	maxBlockHeight := uint64(20)
 
@@ -268,8 +336,9 @@ func makeBlock(height uint64) *types.Block {
 // The Test peer
 type bcrTestPeer struct {
 	cmn.BaseService
-	id string
-	ch chan interface{}
+	id   string
+	ch   chan interface{}
+	info p2p.NodeInfo
 }
 
 var _ p2p.Peer = (*bcrTestPeer)(nil)
@@ -303,7 +372,7 @@ func (tp *bcrTestPeer) TrySend(chID byte, msgBytes []byte) bool {
 }
 
 func (tp *bcrTestPeer) Send(chID byte, msgBytes []byte) bool { return tp.TrySend(chID, msgBytes) }
-func (tp *bcrTestPeer) NodeInfo() p2p.NodeInfo               { return p2p.NodeInfo{} }
+func (tp *bcrTestPeer) NodeInfo() p2p.NodeInfo               { return tp.info }
 func (tp *bcrTestPeer) Status() p2p.ConnectionStatus         { return p2p.ConnectionStatus{} }
 func (tp *bcrTestPeer) ID() string                           { return tp.id }
 func (tp *bcrTestPeer) IsOutbound() bool                     { return false }
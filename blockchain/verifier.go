@@ -0,0 +1,187 @@
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+// maxPrefetchBlocks is the number of the downloaded blocks verified ahead of
+// the execution.
+const maxPrefetchBlocks = 16
+
+// verifyResult is the result of verifying the first block by the commit of the
+// second block.
+type verifyResult struct {
+	first    *types.Block
+	second   *types.Block
+	vals     *types.ValidatorSet // copy of the validators verifying the commit
+	valsHash []byte
+	partSize int
+	done     chan struct{}
+
+	parts     *types.PartSet
+	blockID   types.BlockID
+	commitErr error // the commit of the second block is invalid
+	txsErr    error // a tx of the first block is invalid
+}
+
+// matches reports whether the result verifies the blocks the same way.
+func (r *verifyResult) matches(first, second *types.Block, valsHash []byte, partSize int) bool {
+	return r.first == first && r.second == second && r.partSize == partSize && bytes.Equal(r.valsHash, valsHash)
+}
+
+// blockVerifier verifies the downloaded blocks in parallel ahead of their
+// execution: the commit signatures of the validators and the signatures of the
+// txs. The results are taken in order of the heights by the execution, a result
+// is thrown away if the validators changed since it was verified.
+type blockVerifier struct {
+	chainID string
+
+	jobs chan *verifyResult
+	quit chan struct{}
+	wg   sync.WaitGroup
+
+	mtx     sync.Mutex
+	results map[uint64]*verifyResult // key:height of the first block
+}
+
+func newBlockVerifier(chainID string) *blockVerifier {
+	return &blockVerifier{
+		chainID: chainID,
+		jobs:    make(chan *verifyResult, maxPrefetchBlocks),
+		quit:    make(chan struct{}),
+		results: make(map[uint64]*verifyResult),
+	}
+}
+
+func (v *blockVerifier) start() {
+	workers := (runtime.NumCPU() + 1) / 2
+	for i := 0; i < workers; i++ {
+		v.wg.Add(1)
+		go v.verifyRoutine()
+	}
+}
+
+func (v *blockVerifier) stop() {
+	close(v.quit)
+	v.wg.Wait()
+}
+
+func (v *blockVerifier) verifyRoutine() {
+	defer v.wg.Done()
+	for {
+		select {
+		case r := <-v.jobs:
+			v.verify(r)
+		case <-v.quit:
+			return
+		}
+	}
+}
+
+// prefetch schedules the verification of the consecutive blocks, each block but
+// the last is verified by the commit of the next one.
+func (v *blockVerifier) prefetch(blocks []*types.Block, vals *types.ValidatorSet, partSize int) {
+	if len(blocks) < 2 {
+		return
+	}
+	valsHash := vals.Hash()
+
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	// drop the results of the blocks executed or redone
+	base := blocks[0].Height
+	for height, r := range v.results {
+		if height < base || (height-base < uint64(len(blocks)) && blocks[height-base] != r.first) {
+			delete(v.results, height)
+		}
+	}
+
+	for i := 0; i+1 < len(blocks); i++ {
+		first, second := blocks[i], blocks[i+1]
+		// the validators are replaced for a recovered block
+		if first.Recover > 0 {
+			return
+		}
+		if r := v.results[first.Height]; r != nil && r.matches(first, second, valsHash, partSize) {
+			continue
+		}
+		r := &verifyResult{
+			first:    first,
+			second:   second,
+			vals:     vals.Copy(),
+			valsHash: valsHash,
+			partSize: partSize,
+			done:     make(chan struct{}),
+		}
+		select {
+		case v.jobs <- r:
+			v.results[first.Height] = r
+		default:
+			// the workers are busy, try later
+			return
+		}
+	}
+}
+
+// result returns the result of verifying the first block by the commit of the
+// second one, it waits for the prefetched result or verifies the block in place.
+func (v *blockVerifier) result(first, second *types.Block, vals *types.ValidatorSet, partSize int) *verifyResult {
+	v.mtx.Lock()
+	r := v.results[first.Height]
+	delete(v.results, first.Height)
+	v.mtx.Unlock()
+
+	if r != nil && r.matches(first, second, vals.Hash(), partSize) {
+		select {
+		case <-r.done:
+			return r
+		case <-v.quit:
+		}
+	}
+
+	r = &verifyResult{
+		first:    first,
+		second:   second,
+		vals:     vals,
+		partSize: partSize,
+		done:     make(chan struct{}),
+	}
+	v.verify(r)
+	return r
+}
+
+func (v *blockVerifier) verify(r *verifyResult) {
+	defer close(r.done)
+	defer syncVerifyTimer.UpdateSince(time.Now())
+
+	r.parts = r.first.MakePartSet(r.partSize)
+	r.blockID = types.BlockID{Hash: r.first.Hash(), PartsHeader: r.parts.Header()}
+	r.commitErr = r.vals.VerifyCommit(v.chainID, r.blockID, r.first.Height, r.second.LastCommit)
+	if r.commitErr == nil {
+		r.txsErr = verifyTxsSign(r.first)
+	}
+}
+
+// verifyTxsSign recovers the senders of the txs signed by accounts, the senders
+// are cached in the txs for the execution.
+func verifyTxsSign(block *types.Block) error {
+	if block.Data == nil {
+		return nil
+	}
+	for _, tx := range block.Data.Txs {
+		switch tx.(type) {
+		case *types.Transaction, *types.TokenTransaction:
+			if _, err := tx.From(); err != nil {
+				return fmt.Errorf("invalid signature of tx %s: %v", tx.Hash().Hex(), err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/lianxiangcloud/linkchain/contract/gov"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
+	"github.com/lianxiangcloud/linkchain/libs/rpc"
+	"github.com/spf13/cobra"
+)
+
+const defaultGovRPC = "http://127.0.0.1:16000"
+
+// govRead is a typed read of a governance contract.
+type govRead struct {
+	contract *gov.Contract
+	action   string
+	args     []string // names of the args
+	short    string
+	parse    func(output []byte) (interface{}, error)
+}
+
+var govReads = map[string]govRead{
+	"deposits": {gov.Pledge.Contract, "getDeposit", nil, "Show the total pledge of each winout elector",
+		func(output []byte) (interface{}, error) { return gov.ParseDeposits(output) }},
+	"elector": {gov.Pledge.Contract, "getElectorInfo", []string{"elector"}, "Show the pledge state of the elector",
+		func(output []byte) (interface{}, error) { return gov.ParseElectorInfo(output) }},
+	"records": {gov.Pledge.Contract, "getPledgeRecord", []string{"elector"}, "Show the pledge records of the elector",
+		func(output []byte) (interface{}, error) { return gov.ParsePledgeRecords(output) }},
+	"candidates": {gov.Candidates.Contract, "GetAllCandidates", nil, "Show the candidates",
+		func(output []byte) (interface{}, error) { return gov.ParseCandidates(output) }},
+	"validators": {gov.Validators.Contract, "GetAllValidators", nil, "Show the validators of the white list",
+		func(output []byte) (interface{}, error) { return gov.ParseValidators(output) }},
+	"committee": {gov.Committee.Contract, "getCommittee", nil, "Show the members of the committee",
+		func(output []byte) (interface{}, error) { return gov.ParseAddresses(output) }},
+	"proposal": {gov.Committee.Contract, "getProposal", []string{"id"}, "Show the proposal of the committee",
+		func(output []byte) (interface{}, error) { return gov.ParseProposal(output) }},
+	"proposals": {gov.Committee.Contract, "getAllProposalID", nil, "Show the ids of the proposals",
+		func(output []byte) (interface{}, error) { return gov.ParseProposalIDs(output) }},
+	"coefficient": {gov.Coefficient.Contract, "getCoefficient", nil, "Show the coefficients",
+		func(output []byte) (interface{}, error) { return gov.ParseCoefficient(output) }},
+	"poceeds": {gov.Foundation.Contract, "getPoceeds", nil, "Show the award pool of each coinbase",
+		func(output []byte) (interface{}, error) { return gov.ParsePoceeds(output) }},
+}
+
+// NewGovCommand returns the command operating the built-in governance contracts
+// through the RPC of a node.
+func NewGovCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gov",
+		Short: "Operate the built-in governance contracts (connect to node)",
+	}
+	cmd.PersistentFlags().String("rpc", defaultGovRPC, "rpc server address")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "contracts",
+		Short: "List the governance contracts and their actions",
+		Args:  cobra.NoArgs,
+		RunE:  govContracts,
+	})
+
+	call := &cobra.Command{
+		Use:     "call <contract> <action> [args...]",
+		Short:   "Call a constant action of the contract",
+		Example: `linkchain gov call pledge getElectorInfo 0x54fb1c7d0f011dd63b08f85ed7b518ab82028110`,
+		Args:    cobra.MinimumNArgs(2),
+		RunE:    govCall,
+	}
+	call.Flags().String("block", "latest", "block number in hex, or latest")
+	cmd.AddCommand(call)
+
+	send := &cobra.Command{
+		Use:     "send <contract> <action> [args...]",
+		Short:   "Send a transaction calling the action of the contract, signed by the node wallet",
+		Example: `linkchain gov send pledge deposit 0x54fb1c7d0f011dd63b08f85ed7b518ab82028110 1000 1 --from 0xa73810e519e1075010678d706533486d8ecc8000 --value 1000`,
+		Args:    cobra.MinimumNArgs(2),
+		RunE:    govSend,
+	}
+	send.Flags().String("from", "", "sender address, unlocked in the node wallet")
+	send.Flags().String("value", "0", "value transferred to the contract, in wei")
+	send.Flags().Uint64("gas", gov.DefaultGasLimit, "gas limit")
+	cmd.AddCommand(send)
+
+	names := make([]string, 0, len(govReads))
+	for name := range govReads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		read := govReads[name]
+		use := name
+		for _, arg := range read.args {
+			use += " <" + arg + ">"
+		}
+		cmd.AddCommand(&cobra.Command{
+			Use:   use,
+			Short: read.short,
+			Args:  cobra.ExactArgs(len(read.args)),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return govReadRun(cmd, read, args)
+			},
+		})
+	}
+	return cmd
+}
+
+func govContracts(cmd *cobra.Command, args []string) error {
+	for _, name := range gov.ContractNames() {
+		c := gov.ContractByName(name)
+		fmt.Printf("%s %s\n", c.Name, c.Address.String())
+		actions := make([]string, 0, len(c.ABI.Actions))
+		for _, action := range c.ABI.Actions {
+			sig := action.Sig()
+			if action.Const {
+				sig += " constant"
+			}
+			actions = append(actions, sig)
+		}
+		sort.Strings(actions)
+		for _, sig := range actions {
+			fmt.Printf("  %s\n", sig)
+		}
+	}
+	return nil
+}
+
+func govCall(cmd *cobra.Command, args []string) error {
+	c, input, err := govPack(args[0], args[1], args[2:])
+	if err != nil {
+		return err
+	}
+	client, err := govDial(cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	block, _ := cmd.Flags().GetString("block")
+	output, err := govEthCall(client, c, input, block)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
+
+func govSend(cmd *cobra.Command, args []string) error {
+	c, input, err := govPack(args[0], args[1], args[2:])
+	if err != nil {
+		return err
+	}
+	from, _ := cmd.Flags().GetString("from")
+	if !common.IsHexAddress(from) {
+		return fmt.Errorf("invalid sender address %q", from)
+	}
+	valueStr, _ := cmd.Flags().GetString("value")
+	value, ok := new(big.Int).SetString(valueStr, 0)
+	if !ok || value.Sign() < 0 {
+		return fmt.Errorf("invalid value %q", valueStr)
+	}
+	gas, _ := cmd.Flags().GetUint64("gas")
+
+	client, err := govDial(cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	data := hexutil.Bytes(input)
+	tx := map[string]interface{}{
+		"from":  common.HexToAddress(from),
+		"to":    c.Address,
+		"value": (*hexutil.Big)(value),
+		"gas":   hexutil.Uint64(gas),
+		"data":  &data,
+	}
+	var hash common.Hash
+	if err := client.Call(&hash, "eth_sendTransaction", tx); err != nil {
+		return err
+	}
+	fmt.Println(hash.Hex())
+	return nil
+}
+
+func govReadRun(cmd *cobra.Command, read govRead, args []string) error {
+	c, input, err := govPack(read.contract.Name, read.action, args)
+	if err != nil {
+		return err
+	}
+	client, err := govDial(cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	output, err := govEthCall(client, c, input, "latest")
+	if err != nil {
+		return err
+	}
+	val, err := read.parse(output)
+	if err != nil {
+		return fmt.Errorf("invalid output %s: %v", output, err)
+	}
+	out, err := json.MarshalIndent(val, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// govPack packs the input calling the action, the args are JSON values, or strings
+// if they are not valid JSON.
+func govPack(contract, action string, args []string) (*gov.Contract, []byte, error) {
+	c := gov.ContractByName(contract)
+	if c == nil {
+		return nil, nil, fmt.Errorf("unknown contract %q, expect one of %s", contract, strings.Join(gov.ContractNames(), ", "))
+	}
+	raws := make([]json.RawMessage, len(args))
+	for i, arg := range args {
+		if json.Valid([]byte(arg)) {
+			raws[i] = json.RawMessage(arg)
+			continue
+		}
+		quoted, err := json.Marshal(arg)
+		if err != nil {
+			return nil, nil, err
+		}
+		raws[i] = quoted
+	}
+	input, err := c.ABI.PackJSON(action, raws)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, input, nil
+}
+
+func govDial(cmd *cobra.Command) (*rpc.Client, error) {
+	endpoint, _ := cmd.Flags().GetString("rpc")
+	if endpoint == "" {
+		return nil, errors.New("expect rpc server address, see help")
+	}
+	return dialRPC(endpoint)
+}
+
+func govEthCall(client *rpc.Client, c *gov.Contract, input []byte, block string) ([]byte, error) {
+	args := map[string]interface{}{
+		"to":   c.Address,
+		"data": hexutil.Bytes(input),
+	}
+	var output hexutil.Bytes
+	if err := client.Call(&output, "eth_call", args, block); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
@@ -38,6 +38,7 @@ func init() {
 
 	InitFilesCmd.Flags().Bool("full_node", config.BaseConfig.FullNode, "light-weight node or full node")
 	InitFilesCmd.Flags().Bool("save_balance_record", config.BaseConfig.SaveBalanceRecord, "open transactions record storage")
+	InitFilesCmd.Flags().Bool("index_contract_event", config.BaseConfig.IndexContractEvent, "open contract events index storage")
 
 	InitFilesCmd.Flags().String("init_state_root", config.BaseConfig.InitStateRoot, "init global state root")
 }
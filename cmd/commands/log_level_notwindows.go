@@ -0,0 +1,33 @@
+// +build !windows
+
+package commands
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/lianxiangcloud/linkchain/libs/log"
+	"github.com/spf13/viper"
+)
+
+// trapLogLevelReload reloads the log_level from the config file on SIGHUP, so
+// the per-module log levels can be changed without restarting the node.
+func trapLogLevelReload() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	go func() {
+		for range c {
+			if err := viper.ReadInConfig(); err != nil {
+				logger.Error("Failed to read config on SIGHUP", "err", err)
+				continue
+			}
+			lvl := viper.GetString("log_level")
+			if err := log.SetLogLevel(lvl); err != nil {
+				logger.Error("Failed to set log level on SIGHUP", "log_level", lvl, "err", err)
+				continue
+			}
+			logger.Info("Log level reloaded", "log_level", lvl)
+		}
+	}()
+}
@@ -0,0 +1,4 @@
+package commands
+
+// trapLogLevelReload does nothing on windows, which has no SIGHUP.
+func trapLogLevelReload() {}
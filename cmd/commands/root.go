@@ -36,6 +36,7 @@ func registerFlagsRootCmd(cmd *cobra.Command) {
 	cmd.PersistentFlags().Bool("log.hourly", config.Log.Hourly, "Rotate hourly")
 	cmd.PersistentFlags().Bool("log.minutely", config.Log.Minutely, "Rotate minutely")
 	cmd.PersistentFlags().Int("log.minutes", config.Log.Minutes, "Rotate minutes M where 60 % M == 0")
+	cmd.PersistentFlags().String("log.format", config.Log.Format, "Log format: terminal, logfmt or json")
 }
 
 // ParseConfig retrieves the default environment configuration,
@@ -74,7 +75,12 @@ var RootCmd = &cobra.Command{
 			return err
 		}
 		logOnce.Do(func() {
-			rotateHandler, err := log.RotateHandler(config.Log, log.TerminalFormat(false))
+			format, err := log.FormatByName(config.Log.Format)
+			if err != nil {
+				logger.Error("log format err", "err", err)
+				return
+			}
+			rotateHandler, err := log.RotateHandler(config.Log, format)
 			if err != nil {
 				logger.Error("rotateHandler err", "err", err)
 				return
@@ -34,6 +34,12 @@ func AddNodeFlags(cmd *cobra.Command) {
 	cmd.Flags().String("rpc.ipc_endpoint", config.RPC.IpcEndpoint, "Filename for IPC socket/pipe within the datadir (explicit paths escape it)")
 	cmd.Flags().Duration("rpc.evm_interval", config.RPC.EVMInterval, "Rate for evm call and estimate")
 	cmd.Flags().Int("rpc.evm_max", config.RPC.EVMMax, "Maximum evm created by evm call and estimate")
+	cmd.Flags().String("rpc.verify_solc", config.RPC.VerifySolc, "Path of solc to verify the solidity contracts")
+	cmd.Flags().String("rpc.verify_wasmcc", config.RPC.VerifyWasmCC, "Path of clang targeting wasm32 to verify the wasm contracts")
+	cmd.Flags().Duration("rpc.verify_timeout", config.RPC.VerifyTimeout, "Time limit of compiling a contract to verify")
+	cmd.Flags().Int("rpc.ready_min_peers", config.RPC.ReadyMinPeers, "Minimum peers of a ready node for the /ready probe")
+	cmd.Flags().Uint64("rpc.ready_max_height_lag", config.RPC.ReadyMaxHeightLag, "Maximum blocks a ready node is behind the peers for the /ready probe")
+	cmd.Flags().Duration("rpc.ready_max_block_age", config.RPC.ReadyMaxBlockAge, "Maximum age of the latest block of a ready node for the /ready probe, 0 for no limit")
 
 	// p2p flags
 	cmd.Flags().String("p2p.laddr", config.P2P.ListenAddress, "Node listen address. (0.0.0.0:0 means any interface, any port)")
@@ -42,18 +48,31 @@ func AddNodeFlags(cmd *cobra.Command) {
 	cmd.Flags().Bool("consensus.create_empty_blocks", config.Consensus.CreateEmptyBlocks, "Set this to false to only produce blocks when there are txs or when the AppHash changes")
 	cmd.Flags().Int("consensus.create_empty_blocks_interval", config.Consensus.CreateEmptyBlocksInterval, "the interval time between two empty block")
 	cmd.Flags().Int("consensus.timeout_commit", config.Consensus.TimeoutCommit, "the interval between blocks in ms(Milliseconds)")
+	cmd.Flags().Bool("consensus.timeline_persist", config.Consensus.TimelinePersist, "persist the timelines of consensus events")
 
 	cmd.Flags().Duration("mempool.life_time", config.Mempool.Lifetime, "Life time of cached future transactions in mempool")
 	cmd.Flags().Bool("mempool.removeFutureTx", config.Mempool.RemoveFutureTx, "Remove future tx when mempool future tx queue is full")
 	cmd.Flags().Int("mempool.size", config.Mempool.Size, "max size in good tx")
 	cmd.Flags().Int("mempool.max_reapSize", config.Mempool.MaxReapSize, "reap txs num of block")
+	cmd.Flags().Int("mempool.log_sample_first", config.Mempool.LogSampleFirst, "Log the first records of a message every second when adding txs, 0 disables sampling")
+	cmd.Flags().Int("mempool.log_sample_thereafter", config.Mempool.LogSampleThereafter, "Log every n-th record of a message after the first ones when adding txs")
 	// log
 	cmd.Flags().String("log.filename", config.Log.Filename, "log file name")
 
+	// instrumentation flags
+	cmd.Flags().Bool("instrumentation.prometheus", config.Instrumentation.Prometheus, "Serve the Prometheus metrics under /metrics")
+	cmd.Flags().String("instrumentation.prometheus_listen_addr", config.Instrumentation.PrometheusListenAddr, "Address of the Prometheus metrics server")
+	cmd.Flags().Bool("instrumentation.tracing", config.Instrumentation.Tracing, "Export the spans of the txs for tracing")
+	cmd.Flags().String("instrumentation.tracing_exporter", config.Instrumentation.TracingExporter, "Exporter of the spans: otlp or file")
+	cmd.Flags().String("instrumentation.tracing_endpoint", config.Instrumentation.TracingEndpoint, "OTLP/HTTP endpoint of the tracing collector")
+	cmd.Flags().String("instrumentation.tracing_file", config.Instrumentation.TracingFile, "File of the spans, relative to the log directory if not absolute")
+	cmd.Flags().Float64("instrumentation.tracing_sample_ratio", config.Instrumentation.TracingSampleRatio, "Ratio of the txs traced, 1 for all")
+
 	cmd.Flags().Bool("full_node", config.BaseConfig.FullNode, "light-weight node or full node")
 	cmd.Flags().Uint64("keep_latest_blocks", config.BaseConfig.KeepLatestBlocks, "number of latest blocks to keep")
 	cmd.Flags().Uint64("clear_data_interval", config.BaseConfig.ClearDataInterval, "number of seconds between two startup cleanups")
 	cmd.Flags().Bool("save_balance_record", config.BaseConfig.SaveBalanceRecord, "open transactions record storage")
+	cmd.Flags().Bool("index_contract_event", config.BaseConfig.IndexContractEvent, "open contract events index storage")
 	//bootnode
 	cmd.Flags().String("bootnode.addr", config.BootNodeSvr.Addr, "Addr or filepath of the bootnode")
 }
@@ -90,6 +109,8 @@ func NewRunNodeCmd(nodeProvider nm.NodeProvider) *cobra.Command {
 				// call log.Report(msg, "logID", 70001, "height", 8, "validators", 9, "peers", 10)
 			}
 
+			trapLogLevelReload()
+
 			// Trap signal, run forever.
 			n.RunForever()
 
@@ -22,6 +22,7 @@ func main() {
 		cmd.ShowValidatorCmd,
 		cmd.VersionCmd,
 		cmd.NewConsoleCommand(),
+		cmd.NewGovCommand(),
 	)
 
 	// NOTE:
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"math/big"
+	"sync/atomic"
+)
+
+// ChainConfig is the core config which determines the blockchain settings.
+// Fork heights are scheduled in the genesis doc, a nil height means the fork
+// is not scheduled, and 0 means the fork is active since genesis.
+type ChainConfig struct {
+	IstanbulHeight *uint64 `json:"istanbul_height,omitempty"` // Istanbul switch height (nil = no fork)
+	BerlinHeight   *uint64 `json:"berlin_height,omitempty"`   // Berlin switch height (nil = no fork, 0 = already on berlin)
+	LondonHeight   *uint64 `json:"london_height,omitempty"`   // London switch height (nil = no fork, 0 = already on london)
+
+	// WasmGasSchedules reprice the wasm host functions, in order of height
+	WasmGasSchedules []*WasmGasSchedule `json:"wasm_gas_schedules,omitempty"`
+
+	// Slashing punishes the validators the evidence is committed against, nil = no slashing
+	Slashing *SlashingConfig `json:"slashing,omitempty"`
+}
+
+// DefaultChainConfig schedules no fork, the rules of the chain never change.
+var DefaultChainConfig = &ChainConfig{}
+
+var chainConfig atomic.Value
+
+func init() {
+	chainConfig.Store(DefaultChainConfig)
+}
+
+// SetChainConfig sets the chain config used by the node.
+func SetChainConfig(c *ChainConfig) {
+	if c == nil {
+		c = DefaultChainConfig
+	}
+	chainConfig.Store(c)
+}
+
+// GetChainConfig returns the chain config used by the node.
+func GetChainConfig() *ChainConfig {
+	return chainConfig.Load().(*ChainConfig)
+}
+
+// String implements the fmt.Stringer interface.
+func (c *ChainConfig) String() string {
+	return fmt.Sprintf("{Istanbul: %v Berlin: %v London: %v}",
+		heightString(c.IstanbulHeight),
+		heightString(c.BerlinHeight),
+		heightString(c.LondonHeight),
+	)
+}
+
+// IsIstanbul returns whether height is either equal to the Istanbul fork height or greater.
+func (c *ChainConfig) IsIstanbul(height uint64) bool {
+	return isForked(c.IstanbulHeight, height)
+}
+
+// IsBerlin returns whether height is either equal to the Berlin fork height or greater.
+func (c *ChainConfig) IsBerlin(height uint64) bool {
+	return isForked(c.BerlinHeight, height)
+}
+
+// IsLondon returns whether height is either equal to the London fork height or greater.
+func (c *ChainConfig) IsLondon(height uint64) bool {
+	return isForked(c.LondonHeight, height)
+}
+
+// ForkAt returns the name of the fork activated exactly at height, or "" if none.
+func (c *ChainConfig) ForkAt(height uint64) string {
+	switch {
+	case c.LondonHeight != nil && *c.LondonHeight == height:
+		return "london"
+	case c.BerlinHeight != nil && *c.BerlinHeight == height:
+		return "berlin"
+	case c.IstanbulHeight != nil && *c.IstanbulHeight == height:
+		return "istanbul"
+	}
+	return ""
+}
+
+// CheckConfigForkOrder checks that we don't "skip" any forks, the forks must be
+// scheduled in order.
+func (c *ChainConfig) CheckConfigForkOrder() error {
+	type fork struct {
+		name   string
+		height *uint64
+	}
+	var lastFork fork
+	for _, cur := range []fork{
+		{"istanbulHeight", c.IstanbulHeight},
+		{"berlinHeight", c.BerlinHeight},
+		{"londonHeight", c.LondonHeight},
+	} {
+		if lastFork.name != "" {
+			switch {
+			case lastFork.height == nil && cur.height != nil:
+				return fmt.Errorf("unsupported fork ordering: %v not enabled, but %v enabled at %v",
+					lastFork.name, cur.name, *cur.height)
+			case lastFork.height != nil && cur.height != nil && *lastFork.height > *cur.height:
+				return fmt.Errorf("unsupported fork ordering: %v enabled at %v, but %v enabled at %v",
+					lastFork.name, *lastFork.height, cur.name, *cur.height)
+			}
+		}
+		lastFork = cur
+	}
+	return nil
+}
+
+// Rules is a one time interface meaning that it shouldn't be used in between transition
+// phases, it is computed for the height of the block being processed.
+type Rules struct {
+	Height                         uint64
+	IsIstanbul, IsBerlin, IsLondon bool
+}
+
+// Rules ensures c's heights are mapped to the rules of the height.
+func (c *ChainConfig) Rules(height uint64) Rules {
+	return Rules{
+		Height:     height,
+		IsIstanbul: c.IsIstanbul(height),
+		IsBerlin:   c.IsBerlin(height),
+		IsLondon:   c.IsLondon(height),
+	}
+}
+
+// RulesAt returns the rules of the node's chain config for the block number, num
+// may be nil before the block number is known.
+func RulesAt(num *big.Int) Rules {
+	var height uint64
+	if num != nil {
+		height = num.Uint64()
+	}
+	return GetChainConfig().Rules(height)
+}
+
+func isForked(forkHeight *uint64, height uint64) bool {
+	return forkHeight != nil && *forkHeight <= height
+}
+
+func heightString(height *uint64) string {
+	if height == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%d", *height)
+}
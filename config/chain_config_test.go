@@ -0,0 +1,97 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainConfigRules(t *testing.T) {
+	assert := assert.New(t)
+
+	istanbul, berlin := uint64(10), uint64(20)
+	c := &ChainConfig{IstanbulHeight: &istanbul, BerlinHeight: &berlin}
+	assert.Nil(c.CheckConfigForkOrder())
+
+	assert.Equal(Rules{Height: 9}, c.Rules(9))
+	assert.Equal(Rules{Height: 10, IsIstanbul: true}, c.Rules(10))
+	assert.Equal(Rules{Height: 30, IsIstanbul: true, IsBerlin: true}, c.Rules(30))
+	assert.Equal("istanbul", c.ForkAt(10))
+	assert.Equal("berlin", c.ForkAt(20))
+	assert.Equal("", c.ForkAt(30))
+
+	assert.Equal(Rules{Height: 30}, DefaultChainConfig.Rules(30))
+
+	london := uint64(15)
+	c.LondonHeight = &london
+	assert.NotNil(c.CheckConfigForkOrder())
+	c.BerlinHeight = nil
+	assert.NotNil(c.CheckConfigForkOrder())
+}
+
+func TestWasmGasSchedules(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &ChainConfig{}
+	assert.Nil(c.CheckWasmGasSchedules())
+	cost, ok := c.WasmGasCost(100, "TC_StorageSetString")
+	assert.True(ok)
+	gas, overflow := cost.Gas(33, 0)
+	assert.False(overflow)
+	assert.Equal(uint64(2+2*5000), gas)
+	_, ok = c.WasmGasCost(100, "TC_Unknown")
+	assert.False(ok)
+
+	c.WasmGasSchedules = []*WasmGasSchedule{
+		{Version: 2, Height: 10, Costs: map[string]WasmGasCost{"TC_Transfer": {Base: 12000}}},
+		{Version: 3, Height: 20, Costs: map[string]WasmGasCost{"TC_Issue": {Base: 8000}}},
+	}
+	assert.Nil(c.CheckWasmGasSchedules())
+
+	cost, _ = c.WasmGasCost(9, "TC_Transfer")
+	assert.Equal(uint64(9000), cost.Base)
+	cost, _ = c.WasmGasCost(25, "TC_Transfer")
+	assert.Equal(uint64(12000), cost.Base)
+	cost, _ = c.WasmGasCost(15, "TC_Issue")
+	assert.Equal(uint64(4000), cost.Base)
+	assert.Equal(uint64(8000), c.WasmGasSchedule(20).Costs["TC_Issue"].Base)
+	assert.Equal(uint64(3), c.WasmGasSchedule(20).Version)
+	assert.Equal(uint64(1), c.WasmGasSchedule(5).Version)
+
+	c.WasmGasSchedules[1].Version = 2
+	assert.NotNil(c.CheckWasmGasSchedules())
+	c.WasmGasSchedules[1].Version, c.WasmGasSchedules[1].Height = 3, 10
+	assert.NotNil(c.CheckWasmGasSchedules())
+	c.WasmGasSchedules[1].Height = 20
+	c.WasmGasSchedules[1].Costs["TC_Unknown"] = WasmGasCost{}
+	assert.NotNil(c.CheckWasmGasSchedules())
+
+	_, overflow = WasmGasCost{WordGas: 1 << 60}.Gas(1<<10, 0)
+	assert.True(overflow)
+}
+
+func TestSlashing(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &ChainConfig{}
+	assert.Nil(c.CheckSlashing())
+	assert.Nil(c.SlashingAt(100))
+
+	slashing := *DefaultSlashingConfig
+	slashing.Height = 100
+	c.Slashing = &slashing
+	assert.Nil(c.CheckSlashing())
+	assert.Nil(c.SlashingAt(99))
+	assert.Equal(&slashing, c.SlashingAt(100))
+	assert.Equal(TwoConsecutive, slashing.FaultRounds())
+	slashing.FaultThreshold = 5
+	assert.Equal(5, slashing.FaultRounds())
+	slashing.FaultThreshold = -1
+	assert.NotNil(c.CheckSlashing())
+	slashing.FaultThreshold = 0
+
+	slashing.DoubleSign.SlashRate = SlashRateDenominator + 1
+	assert.NotNil(c.CheckSlashing())
+	slashing.DoubleSign, slashing.FaultValidator = SlashingRule{}, SlashingRule{}
+	assert.NotNil(c.CheckSlashing())
+}
@@ -175,6 +175,8 @@ type BaseConfig struct {
 
 	SaveBalanceRecord bool `mapstructure:"save_balance_record"`
 
+	IndexContractEvent bool `mapstructure:"index_contract_event"`
+
 	IsTestMode bool `mapstructure:"is_test_mode"`
 }
 
@@ -265,6 +267,7 @@ func DefaultRotateConfig() *log.RotateConfig {
 		Rotate:     true,
 		RotatePerm: "0444",
 		Perm:       "0664",
+		Format:     "terminal",
 	}
 }
 
@@ -276,6 +279,7 @@ func TestRotateConfig() *log.RotateConfig {
 		Rotate:     true,
 		RotatePerm: "0444",
 		Perm:       "0664",
+		Format:     "terminal",
 	}
 }
 
@@ -295,6 +299,21 @@ type RPCConfig struct {
 	WSExposeAll  bool          `mapstructure:"ws_expose_all"`
 	EVMInterval  time.Duration `mapstructure:"evm_interval"`
 	EVMMax       int           `mapstructure:"evm_max"`
+
+	// Compilers verifying the contracts, the contracts are only verified by
+	// artifacts if not set. The compilers run the sources of the requests as
+	// the node user without isolation, leave them unset on public nodes.
+	VerifySolc    string        `mapstructure:"verify_solc"`
+	VerifyWasmCC  string        `mapstructure:"verify_wasmcc"`
+	VerifyTimeout time.Duration `mapstructure:"verify_timeout"`
+
+	// Thresholds of the /ready probe: the node is ready if it is not fast
+	// syncing, at most ReadyMaxHeightLag blocks behind the peers, connected to
+	// at least ReadyMinPeers peers and its latest block is not older than
+	// ReadyMaxBlockAge, 0 for no limit of the age.
+	ReadyMinPeers     int           `mapstructure:"ready_min_peers"`
+	ReadyMaxHeightLag uint64        `mapstructure:"ready_max_height_lag"`
+	ReadyMaxBlockAge  time.Duration `mapstructure:"ready_max_block_age"`
 }
 
 // DefaultRPCConfig returns a default configuration for the RPC server
@@ -302,15 +321,21 @@ func DefaultRPCConfig() *RPCConfig {
 	return &RPCConfig{
 		IpcEndpoint:  "linkchain.ipc",
 		HTTPEndpoint: ":8000",
-		HTTPModules:  []string{"web3", "eth", "personal", "debug", "txpool", "net", "relay", "relaydebug"},
+		HTTPModules:  []string{"web3", "eth", "personal", "debug", "txpool", "net", "gov", "contract", "evidence", "consensus", "relay", "relaydebug"},
 		HTTPCores:    []string{"*"},
 		VHosts:       []string{"*"},
 		WSEndpoint:   ":8001",
-		WSModules:    []string{"web3", "eth", "personal", "debug", "txpool", "net", "lk", "relay", "relaydebug"},
+		WSModules:    []string{"web3", "eth", "personal", "debug", "txpool", "net", "lk", "gov", "contract", "evidence", "consensus", "relay", "relaydebug"},
 		WSExposeAll:  true,
 		WSOrigins:    []string{"*"},
 		EVMInterval:  500 * time.Millisecond,
 		EVMMax:       100,
+
+		VerifyTimeout: 60 * time.Second,
+
+		ReadyMinPeers:     1,
+		ReadyMaxHeightLag: 2,
+		ReadyMaxBlockAge:  0,
 	}
 }
 
@@ -411,6 +436,10 @@ type MempoolConfig struct {
 	Lifetime          time.Duration `mapstructure:"life_time"`     // Maximum amount of time non-executable transaction are queued
 	RemoveFutureTx    bool          `mapstructure:"removeFutureTx"`
 	ReceiveP2pTx      bool          `mapstructure:"receive_p2pTx"`
+	// Sample the logs of adding txs: log the first LogSampleFirst records of a
+	// message every second, then every LogSampleThereafter-th. 0 disables it.
+	LogSampleFirst      int `mapstructure:"log_sample_first"`
+	LogSampleThereafter int `mapstructure:"log_sample_thereafter"`
 }
 
 // DefaultMempoolConfig returns a default configuration for the mempool
@@ -478,6 +507,11 @@ type ConsensusConfig struct {
 	// Reactor sleep duration parameters are in milliseconds
 	PeerGossipSleepDuration     int `mapstructure:"peer_gossip_sleep_duration"`
 	PeerQueryMaj23SleepDuration int `mapstructure:"peer_query_maj23_sleep_duration"`
+
+	// Number of the recent heights the timelines of consensus events are kept in
+	// memory for, and whether the timelines are persisted to the database
+	TimelineHeights int  `mapstructure:"timeline_heights"`
+	TimelinePersist bool `mapstructure:"timeline_persist"`
 }
 
 // DefaultConsensusConfig returns a default configuration for the consensus service
@@ -497,6 +531,8 @@ func DefaultConsensusConfig() *ConsensusConfig {
 		CreateEmptyBlocksInterval:   0,
 		PeerGossipSleepDuration:     100,
 		PeerQueryMaj23SleepDuration: 2000,
+		TimelineHeights:             100,
+		TimelinePersist:             false,
 	}
 }
 
@@ -594,6 +630,23 @@ type InstrumentationConfig struct {
 	// you increase your OS limits.
 	// 0 - unlimited.
 	MaxOpenConnections int `mapstructure:"max_open_connections"`
+
+	// When true, the spans of the txs from the rpc to the commit of the block
+	// are exported by TracingExporter.
+	Tracing bool `mapstructure:"tracing"`
+
+	// Exporter of the spans: "otlp" posts them to the OpenTelemetry collector
+	// at TracingEndpoint, "file" appends them to TracingFile.
+	TracingExporter string `mapstructure:"tracing_exporter"`
+
+	// OTLP/HTTP endpoint of the collector.
+	TracingEndpoint string `mapstructure:"tracing_endpoint"`
+
+	// File of the spans, relative to the log directory if not absolute.
+	TracingFile string `mapstructure:"tracing_file"`
+
+	// Ratio of the txs traced, 1 for all.
+	TracingSampleRatio float64 `mapstructure:"tracing_sample_ratio"`
 }
 
 // DefaultInstrumentationConfig returns a default configuration for metrics
@@ -603,6 +656,11 @@ func DefaultInstrumentationConfig() *InstrumentationConfig {
 		Prometheus:           false,
 		PrometheusListenAddr: ":26660",
 		MaxOpenConnections:   3,
+		Tracing:              false,
+		TracingExporter:      "otlp",
+		TracingEndpoint:      "http://127.0.0.1:4318/v1/traces",
+		TracingFile:          "traces.json",
+		TracingSampleRatio:   1,
 	}
 }
 
@@ -63,6 +63,36 @@ var (
 
 		CreateBySuicide: 25000,
 	}
+
+	// GasTableIstanbul contain the gas re-prices for
+	// the istanbul phase (EIP1884).
+	GasTableIstanbul = GasTable{
+		ExtcodeSize: 700,
+		ExtcodeCopy: 700,
+		ExtcodeHash: 700,
+		Balance:     700,
+		SLoad:       SloadGasEIP2200,
+		Calls:       700,
+		Suicide:     5000,
+		ExpByte:     50,
+
+		CreateBySuicide: 25000,
+	}
+
+	// GasTableBerlin contain the gas prices of warm accesses for
+	// the berlin phase, cold accesses are charged extra (EIP2929).
+	GasTableBerlin = GasTable{
+		ExtcodeSize: WarmStorageReadCostEIP2929,
+		ExtcodeCopy: WarmStorageReadCostEIP2929,
+		ExtcodeHash: WarmStorageReadCostEIP2929,
+		Balance:     WarmStorageReadCostEIP2929,
+		SLoad:       WarmStorageReadCostEIP2929,
+		Calls:       WarmStorageReadCostEIP2929,
+		Suicide:     5000,
+		ExpByte:     50,
+
+		CreateBySuicide: 25000,
+	}
 )
 
 // Gastable returns the gas table corresponding to the current phase (homestead or homestead reprice)
@@ -70,5 +100,12 @@ func Gastable(num *big.Int) GasTable {
 	if num == nil {
 		return GasTableHomestead
 	}
+	rules := RulesAt(num)
+	switch {
+	case rules.IsBerlin:
+		return GasTableBerlin
+	case rules.IsIstanbul:
+		return GasTableIstanbul
+	}
 	return GasTableEIP158
 }
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+)
+
+// SlashRateDenominator is the denominator of the slash rates, a rate is in ten
+// thousandths of the deposit.
+const SlashRateDenominator = 10000
+
+// SlashingStateAddr 0x000000000000000000000000536c617368696e67 is the system
+// account holding the slashing states of the candidates in its storage, apart
+// from the storage of the contracts.
+var SlashingStateAddr = common.BytesToAddress([]byte("Slashing"))
+
+// SlashingRule is the penalty of a kind of evidence committed against a validator.
+type SlashingRule struct {
+	SlashRate  uint64 `json:"slash_rate"`  // ten thousandths of the deposit confiscated to the foundation
+	JailBlocks uint64 `json:"jail_blocks"` // blocks the validator is not elected for
+	Tombstone  bool   `json:"tombstone"`   // the validator is never elected again
+}
+
+// Punishes reports whether the rule punishes the validator at all.
+func (r *SlashingRule) Punishes() bool {
+	return r.SlashRate > 0 || r.JailBlocks > 0 || r.Tombstone
+}
+
+// SlashingConfig is the penalties of the validators the evidence is committed
+// against since Height.
+type SlashingConfig struct {
+	Height         uint64       `json:"height"`
+	DoubleSign     SlashingRule `json:"double_sign"`     // DuplicateVoteEvidence
+	FaultValidator SlashingRule `json:"fault_validator"` // FaultValidatorsEvidence of FaultThreshold consecutive fault rounds
+	FaultThreshold int          `json:"fault_threshold"` // consecutive fault rounds before FaultValidator punishes, TwoConsecutive if 0
+}
+
+// FaultRounds returns the consecutive fault rounds of a validator before the
+// FaultValidator rule punishes it.
+func (c *SlashingConfig) FaultRounds() int {
+	if c.FaultThreshold > 0 {
+		return c.FaultThreshold
+	}
+	return TwoConsecutive
+}
+
+// DefaultSlashingConfig is a suggested config: a double sign confiscates a tenth
+// of the deposit and tombstones the validator, TwoConsecutive fault rounds jail
+// the validator for a day of blocks.
+var DefaultSlashingConfig = &SlashingConfig{
+	DoubleSign: SlashingRule{
+		SlashRate: 1000,
+		Tombstone: true,
+	},
+	FaultValidator: SlashingRule{
+		JailBlocks: 86400,
+	},
+}
+
+// CheckSlashing checks the slash rates are at most the whole deposit.
+func (c *ChainConfig) CheckSlashing() error {
+	s := c.Slashing
+	if s == nil {
+		return nil
+	}
+	for _, r := range []struct {
+		name string
+		rule SlashingRule
+	}{
+		{"double_sign", s.DoubleSign},
+		{"fault_validator", s.FaultValidator},
+	} {
+		if r.rule.SlashRate > SlashRateDenominator {
+			return fmt.Errorf("slashing %s: slash rate %d greater than %d", r.name, r.rule.SlashRate, SlashRateDenominator)
+		}
+	}
+	if s.FaultThreshold < 0 {
+		return fmt.Errorf("slashing fault_threshold: %d less than 0", s.FaultThreshold)
+	}
+	if !s.DoubleSign.Punishes() && !s.FaultValidator.Punishes() {
+		return errors.New("slashing punishes no evidence")
+	}
+	return nil
+}
+
+// SlashingAt returns the slashing config active at height, or nil if the
+// validators are not slashed at height.
+func (c *ChainConfig) SlashingAt(height uint64) *SlashingConfig {
+	if c.Slashing == nil || height < c.Slashing.Height {
+		return nil
+	}
+	return c.Slashing
+}
@@ -136,6 +136,9 @@ rotateperm = "{{ .Log.RotatePerm }}"
 # Log file perm
 perm = "{{ .Log.Perm }}"
 
+# Log format: terminal, logfmt or json
+format = "{{ .Log.Format }}"
+
 ##### rpc server configuration options #####
 [rpc]
 
@@ -155,6 +158,26 @@ evm_interval = "{{ .RPC.EVMInterval }}"
 
 evm_max = {{ .RPC.EVMMax }}
 
+# Path of solc to verify the solidity contracts by compiling their sources,
+# the contracts are only verified by artifacts if empty. The compilers run the
+# sources of the requests as the node user without isolation, leave verify_solc
+# and verify_wasmcc empty on the nodes serving the public RPC
+verify_solc = "{{ js .RPC.VerifySolc }}"
+
+# Path of clang targeting wasm32 to verify the wasm contracts by compiling their sources
+verify_wasmcc = "{{ js .RPC.VerifyWasmCC }}"
+
+# Time limit of compiling a contract to verify
+verify_timeout = "{{ .RPC.VerifyTimeout }}"
+
+# Thresholds of the /ready probe: the node is ready if it is not fast syncing,
+# at most ready_max_height_lag blocks behind the peers, connected to at least
+# ready_min_peers peers and its latest block is not older than
+# ready_max_block_age, "0s" for no limit of the age
+ready_min_peers = {{ .RPC.ReadyMinPeers }}
+ready_max_height_lag = {{ .RPC.ReadyMaxHeightLag }}
+ready_max_block_age = "{{ .RPC.ReadyMaxBlockAge }}"
+
 ##### peer to peer configuration options #####
 [p2p]
 
@@ -196,6 +219,11 @@ future_size = {{ .Mempool.FutureSize }}
 
 removeFutureTx = {{ .Mempool.RemoveFutureTx }}
 
+# Sample the logs of adding txs, log the first log_sample_first records of a
+# message every second, then every log_sample_thereafter-th. 0 disables it.
+log_sample_first = {{ .Mempool.LogSampleFirst }}
+log_sample_thereafter = {{ .Mempool.LogSampleThereafter }}
+
 ##### consensus configuration options #####
 [consensus]
 
@@ -221,6 +249,11 @@ create_empty_blocks_interval = {{ .Consensus.CreateEmptyBlocksInterval }}
 peer_gossip_sleep_duration = {{ .Consensus.PeerGossipSleepDuration }}
 peer_query_maj23_sleep_duration = {{ .Consensus.PeerQueryMaj23SleepDuration }}
 
+# Number of the recent heights the timelines of consensus events are kept in memory for
+timeline_heights = {{ .Consensus.TimelineHeights }}
+# Persist the timelines of consensus events to the database
+timeline_persist = {{ .Consensus.TimelinePersist }}
+
 ##### instrumentation configuration options #####
 [instrumentation]
 
@@ -238,6 +271,23 @@ prometheus_listen_addr = "{{ .Instrumentation.PrometheusListenAddr }}"
 # 0 - unlimited.
 max_open_connections = {{ .Instrumentation.MaxOpenConnections }}
 
+# When true, the spans of the txs from the rpc to the commit of the block
+# are exported by tracing_exporter.
+tracing = {{ .Instrumentation.Tracing }}
+
+# Exporter of the spans: "otlp" posts them to the OpenTelemetry collector
+# at tracing_endpoint, "file" appends them to tracing_file.
+tracing_exporter = "{{ .Instrumentation.TracingExporter }}"
+
+# OTLP/HTTP endpoint of the collector
+tracing_endpoint = "{{ .Instrumentation.TracingEndpoint }}"
+
+# File of the spans, relative to the log directory if not absolute
+tracing_file = "{{ js .Instrumentation.TracingFile }}"
+
+# Ratio of the txs traced, 1 for all
+tracing_sample_ratio = {{ .Instrumentation.TracingSampleRatio }}
+
 [bootnode]
 addr = "{{ .BootNodeSvr.Addr }}"
 `
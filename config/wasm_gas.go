@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// WasmGasCost is the gas charged by a wasm host function per call. The host
+// function charges Base, plus WordGas per 32 bytes word of the data it hashes
+// or stores, plus ByteGas per byte of the data it logs.
+type WasmGasCost struct {
+	Base    uint64 `json:"base"`
+	WordGas uint64 `json:"word"`
+	ByteGas uint64 `json:"byte"`
+}
+
+// Gas returns the gas charged for words bytes of word sized data and bytes bytes
+// of byte sized data, and whether the gas overflows.
+func (c WasmGasCost) Gas(words, bytes uint64) (uint64, bool) {
+	wordGas, overflow := safeMul(toWordSize(words), c.WordGas)
+	if overflow {
+		return 0, true
+	}
+	byteGas, overflow := safeMul(bytes, c.ByteGas)
+	if overflow {
+		return 0, true
+	}
+	gas, overflow := safeAdd(c.Base, wordGas)
+	if overflow {
+		return 0, true
+	}
+	return safeAdd(gas, byteGas)
+}
+
+// WasmGasSchedule is a version of the gas costs of the wasm host functions, keyed
+// by the host function name. A schedule of the chain config only lists the costs
+// it changes since Height, the costs it does not list are inherited from the
+// previous schedules.
+type WasmGasSchedule struct {
+	Version uint64                 `json:"version"`
+	Height  uint64                 `json:"height"`
+	Costs   map[string]WasmGasCost `json:"costs"`
+}
+
+// DefaultWasmGasSchedule is the schedule of version 1 the chain starts with. The
+// host functions registered under several names are charged by the name of their
+// string variant, e.g. TC_StorageGetBytes by TC_StorageGetString.
+var DefaultWasmGasSchedule = &WasmGasSchedule{
+	Version: 1,
+	Costs: map[string]WasmGasCost{
+		"TC_StorageSetString":       {Base: 2, WordGas: 5000},
+		"TC_StorageSetBytes":        {Base: 2, WordGas: 5000},
+		"TC_StoragePureSetString":   {Base: 2, WordGas: 5000},
+		"TC_StoragePureSetBytes":    {Base: 2, WordGas: 5000},
+		"TC_StorageGetString":       {Base: 200},
+		"TC_StoragePureGetString":   {Base: 200},
+		"TC_StorageDel":             {},
+		"TC_ContractStorageGet":     {Base: 200},
+		"TC_ContractStoragePureGet": {Base: 200},
+		"TC_Notify":                 {Base: 375, WordGas: 6, ByteGas: 8},
+		"TC_BlockHash":              {Base: 20 + 96},
+		"TC_GetCoinbase":            {Base: 20 + 60},
+		"TC_GetGasLimit":            {Base: 2},
+		"TC_GetNumber":              {Base: 2},
+		"TC_Now":                    {Base: 2},
+		"TC_GetTxGasPrice":          {Base: 2},
+		"TC_GetTxOrigin":            {Base: 20 + 60},
+		"TC_Log0":                   {Base: 5, ByteGas: 8},
+		"TC_Log1":                   {Base: 5 + 375, ByteGas: 8},
+		"TC_Log2":                   {Base: 5 + 2*375, ByteGas: 8},
+		"TC_Log3":                   {Base: 5 + 3*375, ByteGas: 8},
+		"TC_Log4":                   {Base: 5 + 4*375, ByteGas: 8},
+		"TC_SelfDestruct":           {},
+		"TC_CheckSign":              {Base: 3000},
+		"TC_Ecrecover":              {Base: 3000},
+		"TC_Issue":                  {Base: 4000},
+		"TC_Transfer":               {Base: 9000},
+		"TC_TransferToken":          {Base: 9000},
+		"TC_GetBalance":             {Base: 400},
+		"TC_TokenBalance":           {Base: 400},
+		"TC_TokenAddress":           {Base: 20 + 60},
+		"TC_GetMsgValue":            {Base: 20, WordGas: 3},
+		"TC_GetMsgTokenValue":       {Base: 20, WordGas: 3},
+	},
+}
+
+// CheckWasmGasSchedules checks that the wasm gas schedules are scheduled in order
+// of both heights and versions, and only reprice known host functions.
+func (c *ChainConfig) CheckWasmGasSchedules() error {
+	last := DefaultWasmGasSchedule
+	for i, s := range c.WasmGasSchedules {
+		if s == nil {
+			return fmt.Errorf("wasm gas schedule %d is empty", i)
+		}
+		if s.Version <= last.Version {
+			return fmt.Errorf("wasm gas schedule %d: version %d not greater than %d", i, s.Version, last.Version)
+		}
+		if i > 0 && s.Height <= last.Height {
+			return fmt.Errorf("wasm gas schedule %d: height %d not greater than %d", i, s.Height, last.Height)
+		}
+		for name := range s.Costs {
+			if _, ok := DefaultWasmGasSchedule.Costs[name]; !ok {
+				return fmt.Errorf("wasm gas schedule %d: unknown host function %s", i, name)
+			}
+		}
+		last = s
+	}
+	return nil
+}
+
+// WasmGasCost returns the cost of the host function at height, ok is false if
+// the host function is not priced by the schedules.
+func (c *ChainConfig) WasmGasCost(height uint64, name string) (cost WasmGasCost, ok bool) {
+	for i := len(c.WasmGasSchedules) - 1; i >= 0; i-- {
+		s := c.WasmGasSchedules[i]
+		if s.Height > height {
+			continue
+		}
+		if cost, ok = s.Costs[name]; ok {
+			return cost, true
+		}
+	}
+	cost, ok = DefaultWasmGasSchedule.Costs[name]
+	return cost, ok
+}
+
+// WasmGasSchedule returns the complete schedule active at height.
+func (c *ChainConfig) WasmGasSchedule(height uint64) *WasmGasSchedule {
+	schedule := &WasmGasSchedule{
+		Version: DefaultWasmGasSchedule.Version,
+		Costs:   make(map[string]WasmGasCost, len(DefaultWasmGasSchedule.Costs)),
+	}
+	for name, cost := range DefaultWasmGasSchedule.Costs {
+		schedule.Costs[name] = cost
+	}
+	for _, s := range c.WasmGasSchedules {
+		if s.Height > height {
+			break
+		}
+		schedule.Version, schedule.Height = s.Version, s.Height
+		for name, cost := range s.Costs {
+			schedule.Costs[name] = cost
+		}
+	}
+	return schedule
+}
+
+// Names returns the names of the host functions priced by the schedule in order.
+func (s *WasmGasSchedule) Names() []string {
+	names := make([]string, 0, len(s.Costs))
+	for name := range s.Costs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func toWordSize(size uint64) uint64 {
+	if size > math.MaxUint64-31 {
+		return math.MaxUint64/32 + 1
+	}
+	return (size + 31) / 32
+}
+
+func safeAdd(x, y uint64) (uint64, bool) {
+	return x + y, y > math.MaxUint64-x
+}
+
+func safeMul(x, y uint64) (uint64, bool) {
+	if x == 0 || y == 0 {
+		return 0, false
+	}
+	return x * y, y > math.MaxUint64/x
+}
@@ -38,6 +38,11 @@ type Metrics struct {
 	BlockSizeBytes metrics.Gauge
 	// Total number of transactions.
 	TotalTxs metrics.Gauge
+
+	// Time between the start of the round and the arrival of the proposal.
+	ProposalLatencySeconds metrics.Histogram
+	// Time between the start of the round and the arrival of the votes, by type.
+	VoteLatencySeconds metrics.Histogram
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
@@ -107,6 +112,19 @@ func PrometheusMetrics() *Metrics {
 			Name:      "total_txs",
 			Help:      "Total number of transactions.",
 		}, []string{}),
+
+		ProposalLatencySeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Subsystem: "consensus",
+			Name:      "proposal_latency_seconds",
+			Help:      "Time between the start of the round and the arrival of the proposal.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}, []string{}),
+		VoteLatencySeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Subsystem: "consensus",
+			Name:      "vote_latency_seconds",
+			Help:      "Time between the start of the round and the arrival of the votes.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}, []string{"type"}),
 	}
 }
 
@@ -129,5 +147,8 @@ func NopMetrics() *Metrics {
 		NumTxs:         discard.NewGauge(),
 		BlockSizeBytes: discard.NewGauge(),
 		TotalTxs:       discard.NewGauge(),
+
+		ProposalLatencySeconds: discard.NewHistogram(),
+		VoteLatencySeconds:     discard.NewHistogram(),
 	}
 }
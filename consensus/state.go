@@ -132,6 +132,9 @@ type ConsensusState struct {
 	// for reporting metrics
 	metrics *Metrics
 
+	// timelines of the consensus events of the recent heights
+	timeline *timelineRecorder
+
 	startDeleteHeight uint64
 }
 
@@ -162,6 +165,7 @@ func NewConsensusState(
 		evpool:           evpool,
 		evsw:             tmevents.NewEventSwitch(),
 		metrics:          NopMetrics(),
+		timeline:         newTimelineRecorder(config.TimelineHeights),
 
 		startDeleteHeight: 0,
 	}
@@ -188,6 +192,7 @@ func NewConsensusState(
 func (cs *ConsensusState) SetLogger(l log.Logger) {
 	cs.BaseService.Logger = l
 	cs.timeoutTicker.SetLogger(l)
+	cs.timeline.logger = l
 }
 
 // SetEventBus sets event bus.
@@ -849,6 +854,7 @@ func (cs *ConsensusState) handleMsg(mi msgInfo) {
 		// will not cause transition.
 		// once proposal is set, we can receive block parts
 		err = cs.setProposal(msg.Proposal)
+		cs.recordMsg(msg, peerID, err)
 	case *BlockPartMessage:
 		// if the proposal is complete, we'll enterPrevote or tryFinalizeCommit
 		_, err = cs.addProposalBlockPart(msg, peerID)
@@ -856,10 +862,12 @@ func (cs *ConsensusState) handleMsg(mi msgInfo) {
 			cs.Logger.Debug("Received block part from wrong round", "height", cs.Height, "csRound", cs.Round, "blockRound", msg.Round)
 			err = nil
 		}
+		cs.recordMsg(msg, peerID, err)
 	case *VoteMessage:
 		// attempt to add the vote and dupeout the validator if its a duplicate signature
 		// if the vote gives us a 2/3-any or 2/3-one, we transition
 		err := cs.tryAddVote(msg.Vote, peerID)
+		cs.recordMsg(msg, peerID, err)
 		if err == ErrAddingVote {
 			// TODO: punish peer
 			// We probably don't want to stop the peer here. The vote does not
@@ -894,6 +902,8 @@ func (cs *ConsensusState) handleTimeout(ti timeoutInfo, rs cstypes.RoundState) {
 	cs.mtx.Lock()
 	defer cs.mtx.Unlock()
 
+	cs.recordTimeout(ti)
+
 	switch ti.Step {
 	case cstypes.RoundStepNewHeight:
 		// NewRound event fired from enterNewRound.
@@ -948,6 +958,7 @@ func (cs *ConsensusState) enterNewRound(height uint64, round int) {
 	}
 
 	logger.Info(cmn.Fmt("enterNewRound(%v/%v). Current: %v/%v/%v", height, round, cs.Height, cs.Round, cs.Step))
+	cs.timeline.startRound(height, round, time.Now())
 
 	// Increment validators if necessary
 	validators := cs.Validators
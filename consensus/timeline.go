@@ -0,0 +1,259 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lianxiangcloud/linkchain/libs/crypto"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/libs/log"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+const (
+	// maxTimelineEvents bounds the events kept for a height.
+	maxTimelineEvents = 8192
+
+	// types of the timeline events
+	TimelineNewRound  = "new_round"
+	TimelineProposal  = "proposal"
+	TimelineBlockPart = "block_part"
+	TimelinePrevote   = "prevote"
+	TimelinePrecommit = "precommit"
+	TimelineTimeout   = "timeout"
+)
+
+// TimelineEvent is a consensus event of a height with the time it arrived.
+type TimelineEvent struct {
+	Time      time.Time      `json:"time"`
+	Round     int            `json:"round"`
+	Type      string         `json:"type"`
+	Peer      string         `json:"peer,omitempty"`      // empty for our own messages
+	Validator crypto.Address `json:"validator,omitempty"` // proposer of the proposal or signer of the vote
+	Index     int            `json:"index,omitempty"`     // index of the block part
+	Step      string         `json:"step,omitempty"`      // step of the timeout
+	LatencyMs int64          `json:"latency_ms"`          // milliseconds since the round started, -1 if unknown
+}
+
+// HeightTimeline is the timeline of the consensus events of a height.
+type HeightTimeline struct {
+	Height    uint64          `json:"height"`
+	Events    []TimelineEvent `json:"events"`
+	Truncated bool            `json:"truncated"` // events dropped over maxTimelineEvents
+
+	roundStarts map[int]time.Time
+}
+
+// timelineRecorder keeps the timelines of the recent heights in a ring buffer,
+// and persists them to db if db is not nil.
+type timelineRecorder struct {
+	mtx        sync.Mutex
+	ring       []*HeightTimeline
+	lastHeight uint64
+	db         dbm.DB
+	logger     log.Logger
+}
+
+func newTimelineRecorder(heights int) *timelineRecorder {
+	if heights <= 0 {
+		heights = 1
+	}
+	return &timelineRecorder{
+		ring:   make([]*HeightTimeline, heights),
+		logger: log.NewNopLogger(),
+	}
+}
+
+func calcTimelineKey(height uint64) []byte {
+	return []byte(fmt.Sprintf("timeline:%v", height))
+}
+
+// startRound marks the start of the round, the latencies of the events of the
+// round are measured since then.
+func (r *timelineRecorder) startRound(height uint64, round int, now time.Time) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	t := r.timeline(height)
+	if t == nil {
+		return
+	}
+	if _, ok := t.roundStarts[round]; !ok {
+		t.roundStarts[round] = now
+	}
+	r.add(t, TimelineEvent{Time: now, Round: round, Type: TimelineNewRound})
+}
+
+// record adds the event to the timeline of height, and returns the latency of
+// the event since the round started.
+func (r *timelineRecorder) record(height uint64, ev TimelineEvent) (latency time.Duration, ok bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	t := r.timeline(height)
+	if t == nil {
+		return 0, false
+	}
+	ev.LatencyMs = -1
+	if start, started := t.roundStarts[ev.Round]; started {
+		latency, ok = ev.Time.Sub(start), true
+		ev.LatencyMs = int64(latency / time.Millisecond)
+	}
+	r.add(t, ev)
+	return
+}
+
+// get returns a copy of the timeline of height, or nil if not recorded.
+func (r *timelineRecorder) get(height uint64) *HeightTimeline {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if t := r.ring[height%uint64(len(r.ring))]; t != nil && t.Height == height {
+		cp := *t
+		cp.Events = append([]TimelineEvent(nil), t.Events...)
+		cp.roundStarts = nil
+		return &cp
+	}
+	return r.load(height)
+}
+
+// timeline returns the timeline of height in the ring, the timeline of the
+// slot is replaced if it is of an older height. It returns nil for the heights
+// too old for the ring.
+func (r *timelineRecorder) timeline(height uint64) *HeightTimeline {
+	if height > r.lastHeight {
+		// the last height is done, keep it
+		if last := r.ring[r.lastHeight%uint64(len(r.ring))]; last != nil && last.Height == r.lastHeight {
+			r.save(last)
+		}
+		r.lastHeight = height
+	}
+
+	slot := height % uint64(len(r.ring))
+	t := r.ring[slot]
+	if t != nil && t.Height == height {
+		return t
+	}
+	if t != nil && t.Height > height {
+		return nil
+	}
+	if t != nil {
+		r.save(t)
+	}
+	t = &HeightTimeline{
+		Height:      height,
+		roundStarts: make(map[int]time.Time),
+	}
+	r.ring[slot] = t
+	return t
+}
+
+func (r *timelineRecorder) add(t *HeightTimeline, ev TimelineEvent) {
+	if len(t.Events) >= maxTimelineEvents {
+		t.Truncated = true
+		return
+	}
+	t.Events = append(t.Events, ev)
+}
+
+func (r *timelineRecorder) save(t *HeightTimeline) {
+	if r.db == nil {
+		return
+	}
+	bz, err := json.Marshal(t)
+	if err != nil {
+		r.logger.Error("timeline: marshal failed", "height", t.Height, "err", err)
+		return
+	}
+	r.db.Set(calcTimelineKey(t.Height), bz)
+}
+
+func (r *timelineRecorder) load(height uint64) *HeightTimeline {
+	if r.db == nil {
+		return nil
+	}
+	bz := r.db.Get(calcTimelineKey(height))
+	if len(bz) == 0 {
+		return nil
+	}
+	t := &HeightTimeline{}
+	if err := json.Unmarshal(bz, t); err != nil {
+		r.logger.Error("timeline: unmarshal failed", "height", height, "err", err)
+		return nil
+	}
+	return t
+}
+
+// WithTimelineDB persists the timelines of the consensus events to db.
+func WithTimelineDB(db dbm.DB) CSOption {
+	return func(cs *ConsensusState) { cs.timeline.db = db }
+}
+
+// GetHeightTimeline returns the timeline of the consensus events of height, or
+// nil if the height is not recorded.
+func (cs *ConsensusState) GetHeightTimeline(height uint64) *HeightTimeline {
+	return cs.timeline.get(height)
+}
+
+// recordMsg records the message handled by handleMsg to the timeline, and
+// reports the latencies of the proposals and the votes to the metrics. Only the
+// messages accepted for the heights next to the current one are recorded, a
+// bogus message must not take the slots of the real heights.
+func (cs *ConsensusState) recordMsg(msg ConsensusMessage, peerID string, err error) {
+	if err != nil {
+		return
+	}
+	ev := TimelineEvent{
+		Time: time.Now(),
+		Peer: peerID,
+	}
+
+	var height uint64
+	switch msg := msg.(type) {
+	case *ProposalMessage:
+		height, ev.Round, ev.Type = msg.Proposal.Height, msg.Proposal.Round, TimelineProposal
+		if height == cs.Height && ev.Round == cs.Round && cs.Validators != nil && cs.Validators.GetProposer() != nil {
+			ev.Validator = cs.Validators.GetProposer().Address
+		}
+	case *BlockPartMessage:
+		height, ev.Round, ev.Type = msg.Height, msg.Round, TimelineBlockPart
+		if msg.Part != nil {
+			ev.Index = msg.Part.Index
+		}
+	case *VoteMessage:
+		height, ev.Round, ev.Validator = msg.Vote.Height, msg.Vote.Round, msg.Vote.ValidatorAddress
+		if msg.Vote.Type == types.VoteTypePrevote {
+			ev.Type = TimelinePrevote
+		} else {
+			ev.Type = TimelinePrecommit
+		}
+	default:
+		return
+	}
+	if height+1 < cs.Height || height > cs.Height+1 {
+		return
+	}
+
+	latency, ok := cs.timeline.record(height, ev)
+	if !ok {
+		return
+	}
+	switch ev.Type {
+	case TimelineProposal:
+		cs.metrics.ProposalLatencySeconds.Observe(latency.Seconds())
+	case TimelinePrevote, TimelinePrecommit:
+		cs.metrics.VoteLatencySeconds.With("type", ev.Type).Observe(latency.Seconds())
+	}
+}
+
+// recordTimeout records the timeout handled by handleTimeout to the timeline.
+func (cs *ConsensusState) recordTimeout(ti timeoutInfo) {
+	cs.timeline.record(ti.Height, TimelineEvent{
+		Time:  time.Now(),
+		Round: ti.Round,
+		Type:  TimelineTimeout,
+		Step:  ti.Step.String(),
+	})
+}
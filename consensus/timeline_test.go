@@ -0,0 +1,69 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimelineRecorder(t *testing.T) {
+	r := newTimelineRecorder(2)
+	r.db = dbm.NewMemDB()
+
+	start := time.Now()
+	r.startRound(1, 0, start)
+	latency, ok := r.record(1, TimelineEvent{Time: start.Add(150 * time.Millisecond), Round: 0, Type: TimelineProposal, Peer: "peer"})
+	require.True(t, ok)
+	assert.Equal(t, 150*time.Millisecond, latency)
+	_, ok = r.record(1, TimelineEvent{Time: start, Round: 1, Type: TimelinePrevote})
+	assert.False(t, ok, "latency of a round not started")
+
+	timeline := r.get(1)
+	require.NotNil(t, timeline)
+	require.Len(t, timeline.Events, 3)
+	assert.Equal(t, TimelineNewRound, timeline.Events[0].Type)
+	assert.Equal(t, int64(150), timeline.Events[1].LatencyMs)
+	assert.Equal(t, int64(-1), timeline.Events[2].LatencyMs)
+
+	// height 3 takes the slot of height 1, which is kept in the db
+	r.record(2, TimelineEvent{Time: start, Type: TimelineTimeout})
+	r.record(3, TimelineEvent{Time: start, Type: TimelineTimeout})
+	timeline = r.get(1)
+	require.NotNil(t, timeline)
+	assert.Len(t, timeline.Events, 3)
+	assert.NotNil(t, r.get(3))
+	assert.Nil(t, r.get(4))
+
+	// events of the heights dropped from the ring are ignored
+	_, ok = r.record(1, TimelineEvent{Time: start, Type: TimelinePrecommit})
+	assert.False(t, ok)
+}
+
+func TestRecordMsg(t *testing.T) {
+	cs := &ConsensusState{timeline: newTimelineRecorder(4), metrics: NopMetrics()}
+	cs.Height = 10
+	vote := func(height uint64) *VoteMessage {
+		return &VoteMessage{&types.Vote{Height: height, Type: types.VoteTypePrevote}}
+	}
+
+	cs.recordMsg(vote(10), "peer", nil)
+	cs.recordMsg(vote(11), "peer", nil)
+	cs.recordMsg(vote(9), "peer", nil)
+	require.NotNil(t, cs.GetHeightTimeline(10))
+	require.NotNil(t, cs.GetHeightTimeline(11))
+	require.NotNil(t, cs.GetHeightTimeline(9))
+
+	// a bogus vote neither moves the timeline ahead nor records a far height
+	cs.recordMsg(vote(1000000), "peer", nil)
+	cs.recordMsg(vote(8), "peer", nil)
+	assert.Nil(t, cs.GetHeightTimeline(1000000))
+	assert.Nil(t, cs.GetHeightTimeline(8))
+	assert.Equal(t, uint64(11), cs.timeline.lastHeight)
+
+	cs.recordMsg(vote(10), "peer", ErrAddingVote)
+	assert.Len(t, cs.GetHeightTimeline(10).Events, 1)
+}
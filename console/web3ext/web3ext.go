@@ -21,7 +21,11 @@ var Modules = map[string]string{
 	"admin":      Admin_JS,
 	"chequebook": Chequebook_JS,
 	"clique":     Clique_JS,
+	"consensus":  Consensus_JS,
+	"contract":   Contract_JS,
 	"ethash":     Ethash_JS,
+	"evidence":   Evidence_JS,
+	"gov":        Gov_JS,
 	"debug":      Debug_JS,
 	"eth":        Eth_JS,
 	"miner":      Miner_JS,
@@ -198,6 +202,33 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'setLogLevel',
+			call: 'admin_setLogLevel',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getLogLevel',
+			call: 'admin_getLogLevel'
+		}),
+		new web3._extend.Method({
+			name: 'startWasmProfile',
+			call: 'admin_startWasmProfile',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'stopWasmProfile',
+			call: 'admin_stopWasmProfile',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'wasmProfile',
+			call: 'admin_wasmProfile',
+			params: 1,
+			inputFormatter: [null]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -297,6 +328,12 @@ web3._extend({
 			call: 'debug_goTrace',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'wasmGasSchedule',
+			call: 'debug_wasmGasSchedule',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'startGoTrace',
 			call: 'debug_startGoTrace',
@@ -478,6 +515,22 @@ web3._extend({
 			call: 'eth_getLogs',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'wasmCall',
+			call: 'eth_wasmCall',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getContractEvents',
+			call: 'eth_getContractEvents',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'estimateUTXOGas',
+			call: 'eth_estimateUTXOGas',
+			params: 1
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -488,6 +541,150 @@ web3._extend({
 });
 `
 
+const Gov_JS = `
+web3._extend({
+	property: 'gov',
+	methods: [
+		new web3._extend.Method({
+			name: 'getElectors',
+			call: 'gov_getElectors',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getElector',
+			call: 'gov_getElector',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getPledgeRecordsByElector',
+			call: 'gov_getPledgeRecordsByElector',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getPledgeRecordsByDepositor',
+			call: 'gov_getPledgeRecordsByDepositor',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getCommittee',
+			call: 'gov_getCommittee',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getProposals',
+			call: 'gov_getProposals',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getProposal',
+			call: 'gov_getProposal',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getCoefficients',
+			call: 'gov_getCoefficients',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getBlockAwards',
+			call: 'gov_getBlockAwards',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getPoceeds',
+			call: 'gov_getPoceeds',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getLastAward',
+			call: 'gov_getLastAward',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'previewValidators',
+			call: 'gov_previewValidators',
+			params: 1,
+			inputFormatter: [null]
+		}),
+	]
+});
+`
+
+const Contract_JS = `
+web3._extend({
+	property: 'contract',
+	methods: [
+		new web3._extend.Method({
+			name: 'verify',
+			call: 'contract_verify',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getVerified',
+			call: 'contract_getVerified',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+	]
+});
+`
+
+const Evidence_JS = `
+web3._extend({
+	property: 'evidence',
+	methods: [
+		new web3._extend.Method({
+			name: 'getEvidenceByHeight',
+			call: 'evidence_getEvidenceByHeight',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getEvidenceByValidator',
+			call: 'evidence_getEvidenceByValidator',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'submitEvidence',
+			call: 'evidence_submitEvidence',
+			params: 1
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'pendingEvidence',
+			getter: 'evidence_pendingEvidence'
+		}),
+	]
+});
+`
+
+const Consensus_JS = `
+web3._extend({
+	property: 'consensus',
+	methods: [
+		new web3._extend.Method({
+			name: 'getHeightTimeline',
+			call: 'consensus_getHeightTimeline',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+	]
+});
+`
+
 const Miner_JS = `
 web3._extend({
 	property: 'miner',
@@ -0,0 +1,80 @@
+package gov
+
+import (
+	"encoding/json"
+
+	"github.com/lianxiangcloud/linkchain/config"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/state"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+// CandidatesABI is the ABI of the candidates contract.
+const CandidatesABI = `{
+	"structs": [
+		{"name": "Candidate", "fields": [
+			{"name": "pub_key", "type": "string"},
+			{"name": "voting_power", "type": "uint64"},
+			{"name": "coinbase", "type": "address"},
+			{"name": "score", "type": "uint64"},
+			{"name": "punish_height", "type": "uint64"}
+		]}
+	],
+	"actions": [
+		{"name": "SetCandidate", "inputs": [{"name": "c", "type": "Candidate"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "DeleteCandidate", "inputs": [{"name": "pub_key", "type": "string"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "GetAllCandidates", "constant": true, "outputs": [{"name": "", "type": "string"}]}
+	]
+}`
+
+// Candidate is a candidate stored in the candidates contract.
+type Candidate struct {
+	PubKey       string         `json:"pub_key"` // hex of the amino encoded public key
+	VotingPower  uint64         `json:"voting_power"`
+	CoinBase     common.Address `json:"coinbase"`
+	Score        uint64         `json:"score"`
+	PunishHeight uint64         `json:"punish_height"`
+}
+
+// CandidatesContract is the client of the candidates contract.
+type CandidatesContract struct {
+	*Contract
+}
+
+// Candidates is the client of the candidates contract.
+var Candidates = &CandidatesContract{newContract("candidates", config.ContractCandidatesAddr, CandidatesABI)}
+
+// AllCandidates returns the candidates ordered by their public keys.
+func (c *CandidatesContract) AllCandidates(st *state.StateDB) ([]*Candidate, error) {
+	output, err := c.Call(st, "GetAllCandidates")
+	if err != nil {
+		return nil, err
+	}
+	return ParseCandidates(output)
+}
+
+// SetCandidate returns the transaction adding or updating the candidate.
+func (c *CandidatesContract) SetCandidate(nonce uint64, cand *Candidate) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "SetCandidate", cand)
+}
+
+// DeleteCandidate returns the transaction deleting the candidate of the public key.
+func (c *CandidatesContract) DeleteCandidate(nonce uint64, pubKey string) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "DeleteCandidate", pubKey)
+}
+
+// ParseCandidates parses the output of GetAllCandidates.
+func ParseCandidates(output []byte) ([]*Candidate, error) {
+	values, err := indexedStrings(output)
+	if err != nil {
+		return nil, err
+	}
+	cands := make([]*Candidate, len(values))
+	for i, v := range values {
+		cands[i] = new(Candidate)
+		if err := json.Unmarshal([]byte(v), cands[i]); err != nil {
+			return nil, err
+		}
+	}
+	return cands, nil
+}
@@ -0,0 +1,96 @@
+package gov
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/lianxiangcloud/linkchain/config"
+	"github.com/lianxiangcloud/linkchain/state"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+// CoefficientABI is the ABI of the coefficient contract.
+const CoefficientABI = `{
+	"structs": [
+		{"name": "VoteRate", "fields": [
+			{"name": "Deno", "type": "int"},
+			{"name": "Nume", "type": "int"},
+			{"name": "UpperLimit", "type": "int"}
+		]},
+		{"name": "CalRate", "fields": [
+			{"name": "Srate", "type": "int64"},
+			{"name": "Drate", "type": "int64"},
+			{"name": "Rrate", "type": "int64"}
+		]}
+	],
+	"actions": [
+		{"name": "updateVoteRate", "inputs": [{"name": "vr", "type": "VoteRate"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "updateCalRate", "inputs": [{"name": "cr", "type": "CalRate"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "updateVotePeriod", "inputs": [{"name": "vp", "type": "int64"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "updateMaxScore", "inputs": [{"name": "ms", "type": "int64"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "updateUTXOFee", "inputs": [{"name": "uf", "type": "bint"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "getCoefficient", "constant": true, "outputs": [{"name": "", "type": "string"}]}
+	]
+}`
+
+// CoefficientContract is the client of the coefficient contract.
+type CoefficientContract struct {
+	*Contract
+}
+
+// Coefficient is the client of the coefficient contract.
+var Coefficient = &CoefficientContract{newContract("coefficient", config.ContractCoefficientAddr, CoefficientABI)}
+
+// Coefficient returns the coefficients stored in the contract.
+func (c *CoefficientContract) Coefficient(st *state.StateDB) (*types.Coefficient, error) {
+	output, err := c.Call(st, "getCoefficient")
+	if err != nil {
+		return nil, err
+	}
+	return ParseCoefficient(output)
+}
+
+// UpdateVoteRate returns the transaction updating the vote rate.
+func (c *CoefficientContract) UpdateVoteRate(nonce uint64, vr types.VoteRate) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "updateVoteRate", vr)
+}
+
+// UpdateCalRate returns the transaction updating the rank rate of the candidates.
+func (c *CoefficientContract) UpdateCalRate(nonce uint64, cr types.CalRate) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "updateCalRate", cr)
+}
+
+// UpdateVotePeriod returns the transaction updating the vote period in blocks.
+func (c *CoefficientContract) UpdateVotePeriod(nonce uint64, period int64) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "updateVotePeriod", period)
+}
+
+// UpdateMaxScore returns the transaction updating the max score of the candidates.
+func (c *CoefficientContract) UpdateMaxScore(nonce uint64, score int64) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "updateMaxScore", score)
+}
+
+// UpdateUTXOFee returns the transaction updating the fee of the utxo transactions.
+func (c *CoefficientContract) UpdateUTXOFee(nonce uint64, fee *big.Int) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "updateUTXOFee", fee)
+}
+
+// ParseCoefficient parses the output of getCoefficient.
+func ParseCoefficient(output []byte) (*types.Coefficient, error) {
+	var coJSON state.CoefficientJSON
+	if err := json.Unmarshal(output, &coJSON); err != nil {
+		return nil, err
+	}
+	fee, ok := new(big.Int).SetString(coJSON.UTXOFee, 0)
+	if !ok {
+		return nil, fmt.Errorf("gov: invalid UTXOFee %q", coJSON.UTXOFee)
+	}
+	return &types.Coefficient{
+		VotePeriod: coJSON.VotePeriod,
+		VoteRate:   coJSON.VoteRate,
+		CalRate:    coJSON.CalRate,
+		MaxScore:   coJSON.MaxScore,
+		UTXOFee:    fee,
+	}, nil
+}
@@ -0,0 +1,209 @@
+package gov
+
+import (
+	"encoding/json"
+
+	"github.com/lianxiangcloud/linkchain/config"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/state"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+// CommitteeABI is the ABI of the committee contract.
+const CommitteeABI = `{
+	"structs": [
+		{"name": "Member", "fields": [{"name": "address", "type": "address"}]},
+		{"name": "AuthorizedObject", "fields": [
+			{"name": "address", "type": "address"},
+			{"name": "rights", "type": "string"}
+		]}
+	],
+	"actions": [
+		{"name": "proposaAddMember", "inputs": [{"name": "s", "type": "Member"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "proposaDeleteMember", "inputs": [{"name": "s", "type": "Member"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "proposaAccountAuthorize", "inputs": [{"name": "s", "type": "AuthorizedObject"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "finishProposal", "inputs": [{"name": "proposalID", "type": "string"}]},
+		{"name": "voteProposal", "inputs": [{"name": "proposalID", "type": "string"}]},
+		{"name": "execProposal", "inputs": [{"name": "proposalID", "type": "string"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "getProposal", "constant": true, "inputs": [{"name": "proposalID", "type": "string"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "getAllProposalID", "constant": true, "outputs": [{"name": "", "type": "string"}]},
+		{"name": "getCommittee", "constant": true, "outputs": [{"name": "", "type": "string"}]},
+		{"name": "getRightsAccount", "constant": true, "inputs": [{"name": "right", "type": "string"}], "outputs": [{"name": "", "type": "address"}]}
+	]
+}`
+
+// Operations of the committee proposals.
+const (
+	OpAddMember        = "add_member"
+	OpDeleteMember     = "delete_member"
+	OpAccountAuthorize = "account_authorize"
+)
+
+// Rights the committee authorizes accounts with.
+const (
+	RightValidators  = "validators"
+	RightCandidates  = "candidates"
+	RightCoefficient = "coefficient"
+	RightPledge      = "pledge"
+)
+
+// Proposal is a proposal of the committee.
+type Proposal struct {
+	ID         string           `json:"id"`
+	Operation  string           `json:"operation"`
+	Creator    common.Address   `json:"creator"`
+	Parameters string           `json:"parameters"` // JSON of the member or the authorized object
+	Committees []common.Address `json:"committees"` // members voted for the proposal
+	Finished   bool             `json:"finished"`
+}
+
+// Tally is the votes of the proposal counted against the current committee.
+type Tally struct {
+	Votes   int  `json:"votes"`   // votes of the current members
+	Members int  `json:"members"` // size of the current committee
+	Passed  bool `json:"passed"`  // at least 2/3 of the members voted, the proposal can be executed
+}
+
+// NewTally counts the votes of the proposal the same way execProposal does.
+func NewTally(p *Proposal, members []common.Address) *Tally {
+	memberSet := make(map[common.Address]struct{}, len(members))
+	for _, m := range members {
+		memberSet[m] = struct{}{}
+	}
+	t := &Tally{Members: len(members)}
+	for _, c := range p.Committees {
+		if _, ok := memberSet[c]; ok {
+			t.Votes++
+		}
+	}
+	t.Passed = t.Votes*3 >= t.Members*2
+	return t
+}
+
+// CommitteeContract is the client of the committee contract.
+type CommitteeContract struct {
+	*Contract
+}
+
+// Committee is the client of the committee contract.
+var Committee = &CommitteeContract{newContract("committee", config.ContractCommitteeAddr, CommitteeABI)}
+
+// Members returns the members of the committee.
+func (c *CommitteeContract) Members(st *state.StateDB) ([]common.Address, error) {
+	output, err := c.Call(st, "getCommittee")
+	if err != nil {
+		return nil, err
+	}
+	return ParseAddresses(output)
+}
+
+// ProposalIDs returns the ids of all the proposals.
+func (c *CommitteeContract) ProposalIDs(st *state.StateDB) ([]string, error) {
+	output, err := c.Call(st, "getAllProposalID")
+	if err != nil {
+		return nil, err
+	}
+	return ParseProposalIDs(output)
+}
+
+// Proposal returns the proposal of the id.
+func (c *CommitteeContract) Proposal(st *state.StateDB, id string) (*Proposal, error) {
+	output, err := c.Call(st, "getProposal", id)
+	if err != nil {
+		return nil, err
+	}
+	p, err := ParseProposal(output)
+	if err != nil {
+		return nil, err
+	}
+	p.ID = id
+	return p, nil
+}
+
+// RightsAccount returns the account authorized with the right.
+func (c *CommitteeContract) RightsAccount(st *state.StateDB, right string) (common.Address, error) {
+	output, err := c.Call(st, "getRightsAccount", right)
+	if err != nil {
+		return common.EmptyAddress, err
+	}
+	v, err := c.ABI.Unpack("getRightsAccount", output)
+	if err != nil {
+		return common.EmptyAddress, err
+	}
+	return v.(common.Address), nil
+}
+
+// ProposalAddMember returns the transaction proposing to add the member.
+func (c *CommitteeContract) ProposalAddMember(nonce uint64, member common.Address) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "proposaAddMember", map[string]interface{}{"address": member})
+}
+
+// ProposalDeleteMember returns the transaction proposing to delete the member.
+func (c *CommitteeContract) ProposalDeleteMember(nonce uint64, member common.Address) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "proposaDeleteMember", map[string]interface{}{"address": member})
+}
+
+// ProposalAccountAuthorize returns the transaction proposing to authorize the account with the right.
+func (c *CommitteeContract) ProposalAccountAuthorize(nonce uint64, account common.Address, right string) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "proposaAccountAuthorize", map[string]interface{}{"address": account, "rights": right})
+}
+
+// VoteProposal returns the transaction voting for the proposal.
+func (c *CommitteeContract) VoteProposal(nonce uint64, id string) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "voteProposal", id)
+}
+
+// FinishProposal returns the transaction closing the proposal by its creator.
+func (c *CommitteeContract) FinishProposal(nonce uint64, id string) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "finishProposal", id)
+}
+
+// ExecProposal returns the transaction executing the passed proposal.
+func (c *CommitteeContract) ExecProposal(nonce uint64, id string) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "execProposal", id)
+}
+
+// ParseProposal parses the output of getProposal.
+func ParseProposal(output []byte) (*Proposal, error) {
+	var dec struct {
+		Operation  string          `json:"Operation"`
+		Creator    common.Address  `json:"Creator"`
+		Parameters string          `json:"Parameters"`
+		Committees json.RawMessage `json:"Committees"`
+		Finished   int             `json:"finished"`
+	}
+	if err := json.Unmarshal(output, &dec); err != nil {
+		return nil, err
+	}
+	committees, err := ParseAddresses(dec.Committees)
+	if err != nil {
+		return nil, err
+	}
+	return &Proposal{
+		Operation:  dec.Operation,
+		Creator:    dec.Creator,
+		Parameters: dec.Parameters,
+		Committees: committees,
+		Finished:   dec.Finished != 0,
+	}, nil
+}
+
+// ParseProposalIDs parses the output of getAllProposalID.
+func ParseProposalIDs(output []byte) ([]string, error) {
+	return indexedStrings(output)
+}
+
+// ParseAddresses parses the addresses listed by getCommittee.
+func ParseAddresses(output []byte) ([]common.Address, error) {
+	values, err := indexedValues(output)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]common.Address, len(values))
+	for i, v := range values {
+		if err := json.Unmarshal(v, &addrs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return addrs, nil
+}
@@ -0,0 +1,83 @@
+package gov
+
+import (
+	"math/big"
+
+	"github.com/lianxiangcloud/linkchain/config"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/log"
+	"github.com/lianxiangcloud/linkchain/state"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+// FoundationABI is the ABI of the foundation contract.
+const FoundationABI = `{
+	"actions": [
+		{"name": "allocAward"},
+		{"name": "setPoceeds", "inputs": [{"name": "coinbase", "type": "address"}, {"name": "amount", "type": "bint"}]},
+		{"name": "getLastAward", "constant": true, "inputs": [{"name": "coinbase", "type": "address"}, {"name": "support", "type": "address"}], "outputs": [{"name": "", "type": "bint"}]},
+		{"name": "getPoceeds", "constant": true, "outputs": [{"name": "", "type": "string"}]},
+		{"name": "getCandidateAward", "constant": true, "inputs": [{"name": "coinbase", "type": "address"}], "outputs": [{"name": "", "type": "string"}]}
+	]
+}`
+
+// FoundationContract is the client of the foundation contract.
+type FoundationContract struct {
+	*Contract
+}
+
+// Foundation is the client of the foundation contract.
+var Foundation = &FoundationContract{newContract("foundation", config.ContractFoundationAddr, FoundationABI)}
+
+// Poceeds returns the award pool of each coinbase.
+func (c *FoundationContract) Poceeds(st *state.StateDB) (map[common.Address]*big.Int, error) {
+	output, err := c.Call(st, "getPoceeds")
+	if err != nil {
+		return nil, err
+	}
+	return ParsePoceeds(output)
+}
+
+// LastAward returns the award the supporter got from the coinbase in the last allocation.
+func (c *FoundationContract) LastAward(st *state.StateDB, coinbase, supporter common.Address) (*big.Int, error) {
+	output, err := c.Call(st, "getLastAward", coinbase, supporter)
+	if err != nil {
+		return nil, err
+	}
+	v, err := c.ABI.Unpack("getLastAward", output)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*big.Int), nil
+}
+
+// SetPoceeds adds the amount to the award pool of the coinbase, it runs over st
+// in the block of header directly and is only used by the chain itself.
+func (c *FoundationContract) SetPoceeds(st *state.StateDB, header *types.Header, coinbase common.Address, amount *big.Int, logger log.Logger) error {
+	input, err := c.Pack("setPoceeds", coinbase, amount)
+	if err != nil {
+		return err
+	}
+	logger.Info("setPoceeds", "input", string(input))
+	_, err = CallContract(st, header, common.EmptyAddress, c.Address, big.NewInt(0), input, logger)
+	return err
+}
+
+// AllocAward allocates the awards to the candidates and their supporters, it runs
+// over st in the block of header directly and is only used by the chain itself.
+// The balance records of the awards paid are returned.
+func (c *FoundationContract) AllocAward(st *state.StateDB, header *types.Header, logger log.Logger) ([]types.BalanceRecord, error) {
+	input, err := c.Pack("allocAward")
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("allocAward")
+	_, records, err := callContract(st, header, common.EmptyAddress, c.Address, big.NewInt(0), input, logger)
+	return records, err
+}
+
+// ParsePoceeds parses the output of getPoceeds, which is keyed by the coinbases
+// like the output of getDeposit.
+func ParsePoceeds(output []byte) (map[common.Address]*big.Int, error) {
+	return ParseDeposits(output)
+}
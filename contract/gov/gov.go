@@ -0,0 +1,211 @@
+// Package gov implements the Go clients of the built-in governance contracts,
+// pledge, candidates, committee, coefficient, foundation and validators. The
+// clients read the contracts by running their constant actions over a StateDB,
+// and build the transactions calling their actions.
+package gov
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/lianxiangcloud/linkchain/accounts/wasmabi"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/log"
+	"github.com/lianxiangcloud/linkchain/state"
+	"github.com/lianxiangcloud/linkchain/types"
+	"github.com/lianxiangcloud/linkchain/vm/evm"
+	"github.com/lianxiangcloud/linkchain/vm/wasm"
+	"github.com/xunleichain/tc-wasm/vm"
+)
+
+// DefaultGasLimit is the gas limit of the transactions built by the clients.
+const DefaultGasLimit uint64 = 10000000
+
+// callGas is the gas of the calls run by the chain itself, which are not metered.
+const callGas uint64 = 10000000000000000000
+
+// Contract is the client of a built-in wasm contract.
+type Contract struct {
+	Name    string
+	Address common.Address
+	ABI     wasmabi.ABI
+}
+
+var contracts = make(map[string]*Contract)
+
+func newContract(name string, addr common.Address, abiJSON string) *Contract {
+	abi, err := wasmabi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		panic(fmt.Sprintf("gov: invalid abi of %s: %v", name, err))
+	}
+	c := &Contract{Name: name, Address: addr, ABI: abi}
+	contracts[name] = c
+	return c
+}
+
+// ContractByName returns the client of the contract, or nil if none found.
+func ContractByName(name string) *Contract {
+	return contracts[name]
+}
+
+// ContractNames returns the names of the built-in contracts.
+func ContractNames() []string {
+	names := make([]string, 0, len(contracts))
+	for name := range contracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Pack packs the input calling the action of the contract.
+func (c *Contract) Pack(action string, args ...interface{}) ([]byte, error) {
+	return c.ABI.Pack(action, args...)
+}
+
+// Call runs the constant action of the contract over a copy of st, st is not modified.
+func (c *Contract) Call(st *state.StateDB, action string, args ...interface{}) ([]byte, error) {
+	input, err := c.Pack(action, args...)
+	if err != nil {
+		return nil, err
+	}
+	return CallContract(st.Copy(), nil, common.EmptyAddress, c.Address, big.NewInt(0), input, log.Root())
+}
+
+// NewTx returns the unsigned transaction calling the action of the contract, the
+// value is transferred to the contract.
+func (c *Contract) NewTx(nonce uint64, value *big.Int, gasLimit uint64, action string, args ...interface{}) (*types.Transaction, error) {
+	input, err := c.Pack(action, args...)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	if gasLimit == 0 {
+		gasLimit = DefaultGasLimit
+	}
+	return types.NewTransaction(nonce, c.Address, value, gasLimit, big.NewInt(types.ParGasPrice), input), nil
+}
+
+// CallContract runs the wasm contract with the input over st directly, it is only
+// used by the chain itself and by the clients, the gas is not metered. The chain
+// runs the contract in the block of header, by the rules and the gas schedule of
+// its height. The constant calls of the clients run in no block, header is nil.
+func CallContract(st *state.StateDB, header *types.Header, sender, contractAddr common.Address, amount *big.Int, input []byte, logger log.Logger) ([]byte, error) {
+	result, _, err := callContract(st, header, sender, contractAddr, amount, input, logger)
+	return result, err
+}
+
+// callContract runs the wasm contract like CallContract, and returns the balance
+// records of the transfers made by the contract.
+func callContract(st *state.StateDB, header *types.Header, sender, contractAddr common.Address, amount *big.Int, input []byte, logger log.Logger) ([]byte, []types.BalanceRecord, error) {
+	caller := evm.AccountRef(sender)
+	to := evm.AccountRef(contractAddr)
+	value := amount
+	gas := callGas
+
+	contract := wasm.NewContract(caller, to, value, gas)
+	contract.SetCallCode(&contractAddr, st.GetCodeHash(contractAddr), st.GetCode(contractAddr))
+	contract.Input = input
+
+	innerContract := vm.NewContract(contract.CallerAddress.Bytes(), contract.Address().Bytes(), contract.Value(), contract.Gas)
+	innerContract.SetCallCode(contract.CodeAddr.Bytes(), contract.CodeHash.Bytes(), contract.Code)
+	innerContract.Input = contract.Input
+	innerContract.CreateCall = contract.CreateCall
+	var w *wasm.WASM
+	if header != nil {
+		ctx := wasm.Context{
+			CanTransfer:    wasm.CanTransfer,
+			Transfer:       wasm.Transfer,
+			UnsafeTransfer: wasm.UnsafeTransfer,
+			Coinbase:       header.Coinbase,
+			GasLimit:       header.GasLimit,
+			BlockNumber:    new(big.Int).SetUint64(header.Height),
+			Time:           new(big.Int).SetUint64(header.Time),
+		}
+		w = wasm.NewWASM(ctx, st, nil)
+		wasm.Inject(&ctx, st, w)
+	} else {
+		w = wasm.NewWASM(wasm.Context{}, st, nil)
+		wasm.Inject(nil, st, w)
+	}
+	eng := vm.NewEngine(innerContract, contract.Gas, st, logger)
+	eng.SetTrace(false) // trace app execution.
+
+	app, err := eng.NewApp(contract.Address().String(), contract.Code, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("exec.NewApp fail: %s", err)
+	}
+
+	app.EntryFunc = vm.APPEntry
+	ret, err := eng.Run(app, contract.Input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("eng.Run fail: err=%s", err)
+	}
+
+	vmem := app.VM.VMemory()
+	result, err := vmem.GetString(ret)
+	if err != nil {
+		return nil, nil, fmt.Errorf("vmem.GetString fail: err=%v", err)
+	}
+	return result, w.GetOTxs(), nil
+}
+
+// bigInt is a big integer the contracts encode as a JSON string or number.
+type bigInt big.Int
+
+func (b *bigInt) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" {
+		s = "0"
+	}
+	if _, ok := (*big.Int)(b).SetString(s, 0); !ok {
+		return fmt.Errorf("gov: invalid big integer %s", data)
+	}
+	return nil
+}
+
+func (b *bigInt) toInt() *big.Int {
+	return new(big.Int).Set((*big.Int)(b))
+}
+
+// indexedValues parses the JSON object keyed by the indexes "0", "1"... the
+// contracts return lists as, and returns the values in order.
+func indexedValues(data []byte) ([]json.RawMessage, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	values := make([]json.RawMessage, len(obj))
+	for i := range values {
+		v, ok := obj[fmt.Sprint(i)]
+		if !ok {
+			return nil, fmt.Errorf("gov: missing index %d", i)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// indexedStrings parses the indexed JSON object of strings, such as the proposal
+// ids, or the candidates each encoded as a JSON string.
+func indexedStrings(data []byte) ([]string, error) {
+	values, err := indexedValues(data)
+	if err != nil {
+		return nil, err
+	}
+	strs := make([]string, len(values))
+	for i, v := range values {
+		if err := json.Unmarshal(v, &strs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return strs, nil
+}
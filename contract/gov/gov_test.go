@@ -0,0 +1,95 @@
+package gov
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+)
+
+var elector = common.HexToAddress("0x54fb1c7d0f011dd63b08f85ed7b518ab82028110")
+
+func TestPack(t *testing.T) {
+	tests := []struct {
+		contract *Contract
+		action   string
+		args     []interface{}
+		input    string
+	}{
+		{Foundation.Contract, "setPoceeds", []interface{}{elector, big.NewInt(100)},
+			`setPoceeds|{"0":"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110","1":"100"}`},
+		{Foundation.Contract, "allocAward", nil, `allocAward|{}`},
+		{Pledge.Contract, "participate", []interface{}{elector, big.NewInt(5), uint64(1), uint32(30)},
+			`participate|{"0":"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110","1":"5","2":1,"3":30}`},
+		{Pledge.Contract, "setAction", []interface{}{int(ActionPledge), true}, `setAction|{"0":1,"1":true}`},
+		{Candidates.Contract, "SetCandidate", []interface{}{&Candidate{PubKey: "0x724c", VotingPower: 10, CoinBase: elector, Score: 100}},
+			`SetCandidate|{"0":{"coinbase":"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110","punish_height":0,"pub_key":"0x724c","score":100,"voting_power":10}}`},
+		{Committee.Contract, "proposaAddMember", []interface{}{map[string]interface{}{"address": elector}},
+			`proposaAddMember|{"0":{"address":"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110"}}`},
+	}
+	for i, test := range tests {
+		input, err := test.contract.Pack(test.action, test.args...)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if string(input) != test.input {
+			t.Errorf("test %d: input mismatch, got %s, want %s", i, input, test.input)
+		}
+	}
+
+	tx, err := Pledge.Deposit(3, elector, big.NewInt(7), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *tx.To() != Pledge.Address || tx.Value().Cmp(big.NewInt(7)) != 0 || tx.Nonce() != 3 || tx.Gas() != DefaultGasLimit {
+		t.Errorf("unexpected deposit tx %v", tx)
+	}
+}
+
+func TestParse(t *testing.T) {
+	deposits, err := ParseDeposits([]byte(`{"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110":"500"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deposits[elector].Cmp(big.NewInt(500)) != 0 {
+		t.Errorf("deposit mismatch, got %v", deposits)
+	}
+
+	records, err := ParsePledgeRecords([]byte(`{"0":{"orderid":1,"sender":"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110","amount":"5","timestamp":10,"hasWithdraw":false},` +
+		`"1":{"orderid":2,"sender":"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110","amount":"6","timestamp":11,"hasWithdraw":true}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1].OrderID != 2 || records[1].Amount.Int64() != 6 || !records[1].HasWithdraw {
+		t.Errorf("records mismatch, got %v", records)
+	}
+
+	cands, err := ParseCandidates([]byte(`{"0":"{\"pub_key\":\"0x724c\",\"voting_power\":10,\"coinbase\":\"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110\",\"score\":100,\"punish_height\":0}"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cands) != 1 || cands[0].CoinBase != elector || cands[0].Score != 100 {
+		t.Errorf("candidates mismatch, got %v", cands)
+	}
+
+	p, err := ParseProposal([]byte(`{"Operation":"add_member","Creator":"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110","Parameters":"{}",` +
+		`"Committees":{"0":"0x54fb1c7d0f011dd63b08f85ed7b518ab82028110"},"finished":0}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Operation != OpAddMember || p.Finished || len(p.Committees) != 1 {
+		t.Errorf("proposal mismatch, got %v", p)
+	}
+	tally := NewTally(p, []common.Address{elector, common.HexToAddress("0x01")})
+	if tally.Votes != 1 || tally.Passed {
+		t.Errorf("tally mismatch, got %v", tally)
+	}
+
+	co, err := ParseCoefficient([]byte(`{"voteRate":{"Deno":5,"Nume":3,"UpperLimit":12},"calRate":{"Srate":4,"Drate":4,"Rrate":2},"VotePeriod":1321,"MaxScore":500,"UTXOFee":"500000"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if co.VotePeriod != 1321 || co.Deno != 5 || co.Rrate != 2 || co.UTXOFee.Int64() != 500000 {
+		t.Errorf("coefficient mismatch, got %v", co)
+	}
+}
@@ -0,0 +1,259 @@
+package gov
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/lianxiangcloud/linkchain/config"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/state"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+// PledgeABI is the ABI of the pledge contract.
+const PledgeABI = `{
+	"structs": [
+		{"name": "ElectorInfo", "fields": [
+			{"name": "totalAmount", "type": "bint"},
+			{"name": "status", "type": "int"},
+			{"name": "voteCnts", "type": "bint"},
+			{"name": "shareRate", "type": "uint"}
+		]}
+	],
+	"actions": [
+		{"name": "participate", "inputs": [
+			{"name": "elector", "type": "address"},
+			{"name": "amount", "type": "bint"},
+			{"name": "orderid", "type": "uint64"},
+			{"name": "shareRate", "type": "uint"}
+		]},
+		{"name": "deposit", "inputs": [
+			{"name": "elector", "type": "address"},
+			{"name": "amount", "type": "bint"},
+			{"name": "orderid", "type": "uint64"}
+		]},
+		{"name": "vote", "inputs": [{"name": "elector", "type": "address"}]},
+		{"name": "setElectorStatus", "inputs": [{"name": "elector", "type": "address"}, {"name": "status", "type": "int"}]},
+		{"name": "setVoteCnts", "inputs": [{"name": "elector", "type": "address"}, {"name": "voteCnts", "type": "bint"}]},
+		{"name": "withDraw", "inputs": [{"name": "elector", "type": "address"}]},
+		{"name": "confiscate", "inputs": [{"name": "elector", "type": "address"}]},
+		{"name": "setAction", "inputs": [{"name": "act", "type": "int"}, {"name": "stop", "type": "bool"}]},
+		{"name": "setShareRate", "inputs": [{"name": "elector", "type": "address"}, {"name": "shareRate", "type": "uint"}]},
+		{"name": "getDeposit", "constant": true, "outputs": [{"name": "", "type": "string"}]},
+		{"name": "getElectorInfo", "constant": true, "inputs": [{"name": "elector", "type": "address"}], "outputs": [{"name": "", "type": "ElectorInfo"}]},
+		{"name": "getPledgeRecord", "constant": true, "inputs": [{"name": "elector", "type": "address"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "getWhoVote", "constant": true, "inputs": [{"name": "voter", "type": "address"}], "outputs": [{"name": "", "type": "address"}]}
+	],
+	"events": [
+		{"name": "Deposit", "inputs": [
+			{"name": "sender", "type": "address"},
+			{"name": "amount", "type": "bint"},
+			{"name": "orderid", "type": "uint64"}
+		]}
+	]
+}`
+
+// ElectorStatus is the status of an elector in the pledge contract.
+type ElectorStatus int
+
+// ElectorStatus enumerator
+const (
+	ElectorDefault ElectorStatus = iota
+	ElectorInitial
+	ElectorNoPass // examine Nok
+	ElectorGoing  // examine ok and pledge on going
+	ElectorWinOut
+	ElectorFail
+	ElectorDetain // disqualify and confiscate deposit
+	ElectorQuit
+)
+
+var electorStatusNames = []string{"default", "initial", "nopass", "going", "winout", "fail", "detain", "quit"}
+
+func (s ElectorStatus) String() string {
+	if s < 0 || int(s) >= len(electorStatusNames) {
+		return "unknown"
+	}
+	return electorStatusNames[s]
+}
+
+// PledgeAction is the action stopped or resumed by setAction.
+type PledgeAction int
+
+// PledgeAction enumerator
+const (
+	ActionVote PledgeAction = iota
+	ActionPledge
+)
+
+// ElectorInfo is the pledge state of an elector.
+type ElectorInfo struct {
+	TotalAmount *big.Int      `json:"totalAmount"`
+	Status      ElectorStatus `json:"status"`
+	VoteCnts    *big.Int      `json:"voteCnts"`
+	ShareRate   uint32        `json:"shareRate"` // percent
+}
+
+// PledgeRecord is a pledge of a depositor to an elector.
+type PledgeRecord struct {
+	OrderID     uint64         `json:"orderid"`
+	Sender      common.Address `json:"sender"`
+	Amount      *big.Int       `json:"amount"`
+	Timestamp   uint64         `json:"timestamp"`
+	HasWithdraw bool           `json:"hasWithdraw"`
+}
+
+// PledgeContract is the client of the pledge contract.
+type PledgeContract struct {
+	*Contract
+}
+
+// Pledge is the client of the pledge contract.
+var Pledge = &PledgeContract{newContract("pledge", config.ContractPledgeAddr, PledgeABI)}
+
+// Deposits returns the total pledge amount of each winout elector.
+func (c *PledgeContract) Deposits(st *state.StateDB) (map[common.Address]*big.Int, error) {
+	output, err := c.Call(st, "getDeposit")
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeposits(output)
+}
+
+// ElectorInfo returns the pledge state of the elector.
+func (c *PledgeContract) ElectorInfo(st *state.StateDB, elector common.Address) (*ElectorInfo, error) {
+	output, err := c.Call(st, "getElectorInfo", elector)
+	if err != nil {
+		return nil, err
+	}
+	return ParseElectorInfo(output)
+}
+
+// PledgeRecords returns the pledge records of the elector.
+func (c *PledgeContract) PledgeRecords(st *state.StateDB, elector common.Address) ([]*PledgeRecord, error) {
+	output, err := c.Call(st, "getPledgeRecord", elector)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePledgeRecords(output)
+}
+
+// WhoVote returns the elector the voter voted for.
+func (c *PledgeContract) WhoVote(st *state.StateDB, voter common.Address) (common.Address, error) {
+	output, err := c.Call(st, "getWhoVote", voter)
+	if err != nil {
+		return common.EmptyAddress, err
+	}
+	v, err := c.ABI.Unpack("getWhoVote", output)
+	if err != nil {
+		return common.EmptyAddress, err
+	}
+	return v.(common.Address), nil
+}
+
+// Participate returns the transaction applying for the elector with the initial pledge amount.
+func (c *PledgeContract) Participate(nonce uint64, elector common.Address, amount *big.Int, orderid uint64, shareRate uint32) (*types.Transaction, error) {
+	return c.NewTx(nonce, amount, 0, "participate", elector, amount, orderid, shareRate)
+}
+
+// Deposit returns the transaction pledging the amount to the elector.
+func (c *PledgeContract) Deposit(nonce uint64, elector common.Address, amount *big.Int, orderid uint64) (*types.Transaction, error) {
+	return c.NewTx(nonce, amount, 0, "deposit", elector, amount, orderid)
+}
+
+// Vote returns the transaction voting for the elector.
+func (c *PledgeContract) Vote(nonce uint64, elector common.Address) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "vote", elector)
+}
+
+// WithDraw returns the transaction refunding the pledges of the elector.
+func (c *PledgeContract) WithDraw(nonce uint64, elector common.Address) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "withDraw", elector)
+}
+
+// SetElectorStatus returns the transaction setting the status of the elector.
+func (c *PledgeContract) SetElectorStatus(nonce uint64, elector common.Address, status ElectorStatus) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "setElectorStatus", elector, int(status))
+}
+
+// SetVoteCnts returns the transaction setting the vote counts of the elector.
+func (c *PledgeContract) SetVoteCnts(nonce uint64, elector common.Address, voteCnts *big.Int) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "setVoteCnts", elector, voteCnts)
+}
+
+// Confiscate returns the transaction confiscating the pledges of the detained elector.
+func (c *PledgeContract) Confiscate(nonce uint64, elector common.Address) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "confiscate", elector)
+}
+
+// SetAction returns the transaction stopping or resuming the action.
+func (c *PledgeContract) SetAction(nonce uint64, action PledgeAction, stop bool) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "setAction", int(action), stop)
+}
+
+// SetShareRate returns the transaction raising the share rate of the elector.
+func (c *PledgeContract) SetShareRate(nonce uint64, elector common.Address, shareRate uint32) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "setShareRate", elector, shareRate)
+}
+
+// ParseDeposits parses the output of getDeposit.
+func ParseDeposits(output []byte) (map[common.Address]*big.Int, error) {
+	var obj map[common.Address]bigInt
+	if err := json.Unmarshal(output, &obj); err != nil {
+		return nil, err
+	}
+	deposits := make(map[common.Address]*big.Int, len(obj))
+	for addr, amount := range obj {
+		amount := amount
+		deposits[addr] = amount.toInt()
+	}
+	return deposits, nil
+}
+
+// ParseElectorInfo parses the output of getElectorInfo.
+func ParseElectorInfo(output []byte) (*ElectorInfo, error) {
+	var dec struct {
+		TotalAmount bigInt        `json:"totalAmount"`
+		Status      ElectorStatus `json:"status"`
+		VoteCnts    bigInt        `json:"voteCnts"`
+		ShareRate   uint32        `json:"shareRate"`
+	}
+	if err := json.Unmarshal(output, &dec); err != nil {
+		return nil, err
+	}
+	return &ElectorInfo{
+		TotalAmount: dec.TotalAmount.toInt(),
+		Status:      dec.Status,
+		VoteCnts:    dec.VoteCnts.toInt(),
+		ShareRate:   dec.ShareRate,
+	}, nil
+}
+
+// ParsePledgeRecords parses the output of getPledgeRecord.
+func ParsePledgeRecords(output []byte) ([]*PledgeRecord, error) {
+	values, err := indexedValues(output)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*PledgeRecord, len(values))
+	for i, v := range values {
+		var dec struct {
+			OrderID     uint64         `json:"orderid"`
+			Sender      common.Address `json:"sender"`
+			Amount      bigInt         `json:"amount"`
+			Timestamp   uint64         `json:"timestamp"`
+			HasWithdraw bool           `json:"hasWithdraw"`
+		}
+		if err := json.Unmarshal(v, &dec); err != nil {
+			return nil, err
+		}
+		records[i] = &PledgeRecord{
+			OrderID:     dec.OrderID,
+			Sender:      dec.Sender,
+			Amount:      dec.Amount.toInt(),
+			Timestamp:   dec.Timestamp,
+			HasWithdraw: dec.HasWithdraw,
+		}
+	}
+	return records, nil
+}
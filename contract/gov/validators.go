@@ -0,0 +1,76 @@
+package gov
+
+import (
+	"encoding/json"
+
+	"github.com/lianxiangcloud/linkchain/config"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/state"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+// ValidatorsABI is the ABI of the validators contract.
+const ValidatorsABI = `{
+	"structs": [
+		{"name": "Validator", "fields": [
+			{"name": "pub_key", "type": "string"},
+			{"name": "voting_power", "type": "int64"},
+			{"name": "coinbase", "type": "address"}
+		]}
+	],
+	"actions": [
+		{"name": "SetValidator", "inputs": [{"name": "c", "type": "Validator"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "DeleteValidator", "inputs": [{"name": "pub_key", "type": "string"}], "outputs": [{"name": "", "type": "string"}]},
+		{"name": "GetAllValidators", "constant": true, "outputs": [{"name": "", "type": "string"}]}
+	]
+}`
+
+// Validator is a validator stored in the validators contract.
+type Validator struct {
+	PubKey      string         `json:"pub_key"` // hex of the amino encoded public key
+	VotingPower int64          `json:"voting_power"`
+	CoinBase    common.Address `json:"coinbase"`
+}
+
+// ValidatorsContract is the client of the validators contract.
+type ValidatorsContract struct {
+	*Contract
+}
+
+// Validators is the client of the validators contract.
+var Validators = &ValidatorsContract{newContract("validators", config.ContractValidatorsAddr, ValidatorsABI)}
+
+// AllValidators returns the validators ordered by their public keys.
+func (c *ValidatorsContract) AllValidators(st *state.StateDB) ([]*Validator, error) {
+	output, err := c.Call(st, "GetAllValidators")
+	if err != nil {
+		return nil, err
+	}
+	return ParseValidators(output)
+}
+
+// SetValidator returns the transaction adding or updating the validator.
+func (c *ValidatorsContract) SetValidator(nonce uint64, val *Validator) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "SetValidator", val)
+}
+
+// DeleteValidator returns the transaction deleting the validator of the public key.
+func (c *ValidatorsContract) DeleteValidator(nonce uint64, pubKey string) (*types.Transaction, error) {
+	return c.NewTx(nonce, nil, 0, "DeleteValidator", pubKey)
+}
+
+// ParseValidators parses the output of GetAllValidators.
+func ParseValidators(output []byte) ([]*Validator, error) {
+	values, err := indexedStrings(output)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]*Validator, len(values))
+	for i, v := range values {
+		vals[i] = new(Validator)
+		if err := json.Unmarshal([]byte(v), vals[i]); err != nil {
+			return nil, err
+		}
+	}
+	return vals, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/lianxiangcloud/linkchain/config"
 	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/log"
 	"github.com/lianxiangcloud/linkchain/state"
 	"github.com/lianxiangcloud/linkchain/vm/wasm"
 )
@@ -517,6 +519,67 @@ func TestPledgeVote(t *testing.T) {
 	}
 }
 
+// TestPledgeConfiscateDeposit pins the storage of pledge contract rewritten by
+// state.ConfiscateDeposit: the contract must read the cut deposit and withDraw
+// must pay every depositor the pledge record left.
+func TestPledgeConfiscateDeposit(t *testing.T) {
+	st := InitState()
+	RegiesterCommittee(st)
+	pledgeContract := Regiester("pledge")
+	CallContractByInput(st, pledgeContract, "init|{}")
+
+	elector := "0x54fb1c7d0f011dd63b08f85ed7b518ab82028110"
+	supporter := "0x54fb1c7d0f011dd63b08f85ed7b518ab82028120"
+	pledgeInit(st, elector, 0, t)
+	addPledge(st, supporter, elector, big.NewInt(10000), 1, t)
+	// the value of the calls is not transferred by CallContract
+	st.AddBalance(config.ContractPledgeAddr, big.NewInt(510000))
+
+	amount := st.ConfiscateDeposit(common.HexToAddress(elector), 1000, log.NewNopLogger())
+	if amount.Cmp(big.NewInt(51000)) != 0 {
+		t.Fatalf("confiscated %v, want 51000", amount)
+	}
+
+	ret, err := CallContractByInput(st, pledgeContract, "getDeposit|{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var deposits map[string]string
+	if err := json.Unmarshal([]byte(ret), &deposits); err != nil {
+		t.Fatal(err)
+	}
+	if len(deposits) != 1 {
+		t.Fatalf("getDeposit got %s, want the deposit of %s", ret, elector)
+	}
+	for _, deposit := range deposits {
+		if deposit != "459000" {
+			t.Fatalf("getDeposit got %s, want 459000", deposit)
+		}
+	}
+
+	inputs := []string{
+		`setElectorStatus|{"0":"` + elector + `","1":3}`,
+		`withDraw|{"0":"` + elector + `"}`,
+	}
+	for _, input := range inputs {
+		if _, err := CallContractByInput(st, pledgeContract, input); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if balance := st.GetBalance(common.HexToAddress(elector)); balance.Cmp(big.NewInt(450000)) != 0 {
+		t.Errorf("elector paid %v, want 450000", balance)
+	}
+	if balance := st.GetBalance(common.HexToAddress(supporter)); balance.Cmp(big.NewInt(9000)) != 0 {
+		t.Errorf("supporter paid %v, want 9000", balance)
+	}
+	if balance := st.GetBalance(config.ContractPledgeAddr); balance.Sign() != 0 {
+		t.Errorf("pledge balance after withDraw: have %v, want 0", balance)
+	}
+	if deposit := st.GetCandidatesDeposit([]common.Address{common.HexToAddress(elector)}, log.NewNopLogger())[0]; deposit.Sign() != 0 {
+		t.Errorf("deposit after withDraw: have %v, want 0", deposit)
+	}
+}
+
 func BlockAward(st *state.StateDB, coinbase string, foundationContract *wasm.Contract, t *testing.T) {
 	input := `setPoceeds|{"0":"` + coinbase + `","1":"3333333333"}`
 	_, err := CallContract(st, foundCall, foundationContract, input, nil)
@@ -0,0 +1,344 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxOutputSize limits the output of a compiler.
+const maxOutputSize = 32 * 1024 * 1024
+
+// wasmCFlags are the flags building a wasm contract without libc, the contract
+// imports the host functions of the chain and exports its actions.
+var wasmCFlags = []string{
+	"--target=wasm32",
+	"-nostdlib",
+	"-Wl,--no-entry",
+	"-Wl,--export-dynamic",
+	"-Wl,--allow-undefined",
+}
+
+// wasmFlags are the flags besides -D and -U the wasm contracts may be built
+// with, none of them passes arguments to the linker or the preprocessor, or
+// names a file.
+var wasmFlags = map[string]bool{
+	"-O0": true, "-O1": true, "-O2": true, "-O3": true, "-Os": true, "-Oz": true,
+	"-g": true, "-g0": true, "-w": true, "-Wall": true, "-Wextra": true, "-Werror": true,
+	"-std=c99": true, "-std=c11": true, "-std=gnu99": true, "-std=gnu11": true,
+	"-std=c++11": true, "-std=c++14": true, "-std=c++17": true,
+	"-std=gnu++11": true, "-std=gnu++14": true, "-std=gnu++17": true,
+	"-ffreestanding": true, "-fno-builtin": true, "-fno-exceptions": true, "-fno-rtti": true,
+	"-fno-threadsafe-statics": true, "-fvisibility=hidden": true, "-fvisibility=default": true,
+	"-mbulk-memory": true, "-msign-ext": true, "-mnontrapping-fptoint": true,
+}
+
+var (
+	// macroFlag is -DNAME, -DNAME=VALUE or -UNAME
+	macroFlag = regexp.MustCompile(`^(-D[A-Za-z_][A-Za-z0-9_]*(=[A-Za-z0-9_.+-]*)?|-U[A-Za-z_][A-Za-z0-9_]*)$`)
+	// includeDirective is a directive reading a file, by digraph or trigraph too
+	includeDirective = regexp.MustCompile(`(#|%:|\?\?=)\s*(include_next|include|import|embed)\b[ \t]*([^\r\n]*)`)
+	// includeFile is the file of an include directive, a trailing comment allowed
+	includeFile = regexp.MustCompile(`^["<]([A-Za-z0-9_.+/-]+)[">]\s*(//.*)?$`)
+	// blockComment is a comment the preprocessor takes as a space
+	blockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	// diagnostic is a diagnostic line of clang, file:line:col: severity: message
+	diagnostic = regexp.MustCompile(`^([^:]+):\d+:\d+: (error|warning|note): `)
+)
+
+// fileReaders are the words of the sources reading files besides the include
+// directives, by the assembler or by testing a file.
+var fileReaders = []string{"incbin", ".include", "__has_include"}
+
+type solcInput struct {
+	Language string                       `json:"language"`
+	Sources  map[string]map[string]string `json:"sources"`
+	Settings map[string]interface{}       `json:"settings"`
+}
+
+type solcOutput struct {
+	Errors []struct {
+		Severity         string `json:"severity"`
+		FormattedMessage string `json:"formattedMessage"`
+	} `json:"errors"`
+	Contracts map[string]map[string]struct {
+		ABI json.RawMessage `json:"abi"`
+		EVM struct {
+			DeployedBytecode struct {
+				Object string `json:"object"`
+			} `json:"deployedBytecode"`
+		} `json:"evm"`
+	} `json:"contracts"`
+}
+
+// compileSolidity compiles the sources by the standard JSON interface of solc,
+// and returns the runtime bytecode and the ABI of the contract.
+func (v *Verifier) compileSolidity(ctx context.Context, req *Request) ([]byte, json.RawMessage, error) {
+	if v.conf.Solc == "" {
+		return nil, nil, ErrNoCompiler
+	}
+	if err := v.checkVersion(ctx, v.conf.Solc, req.Compiler); err != nil {
+		return nil, nil, err
+	}
+
+	settings := make(map[string]interface{})
+	if len(req.Settings) > 0 {
+		if err := json.Unmarshal(req.Settings, &settings); err != nil {
+			return nil, nil, fmt.Errorf("invalid settings: %v", err)
+		}
+	}
+	settings["outputSelection"] = map[string]interface{}{
+		"*": map[string]interface{}{"*": []string{"abi", "evm.deployedBytecode.object"}},
+	}
+	input := solcInput{
+		Language: "Solidity",
+		Sources:  make(map[string]map[string]string, len(req.Sources)),
+		Settings: settings,
+	}
+	for name, content := range req.Sources {
+		input.Sources[name] = map[string]string{"content": content}
+	}
+	stdin, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "solc")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+	// solc reads no files but the sources in the standard JSON input without --allow-paths
+	out, err := runCompiler(ctx, dir, v.conf.Solc, []string{"--standard-json"}, stdin, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var output solcOutput
+	if err := json.Unmarshal(out, &output); err != nil {
+		return nil, nil, fmt.Errorf("invalid solc output: %v", err)
+	}
+	var msgs []string
+	for _, e := range output.Errors {
+		if e.Severity == "error" {
+			msgs = append(msgs, e.FormattedMessage)
+		}
+	}
+	if len(msgs) > 0 {
+		return nil, nil, fmt.Errorf("compile fail: %s", strings.Join(msgs, "\n"))
+	}
+
+	// the contract is named by "file:name", or by its name if unique
+	var names []string
+	for file, contracts := range output.Contracts {
+		for name := range contracts {
+			names = append(names, file+":"+name)
+		}
+	}
+	sort.Strings(names)
+	var found []string
+	for _, name := range names {
+		if req.ContractName == "" || name == req.ContractName || strings.HasSuffix(name, ":"+req.ContractName) {
+			found = append(found, name)
+		}
+	}
+	if len(found) != 1 {
+		return nil, nil, fmt.Errorf("contract %q not found in %v", req.ContractName, names)
+	}
+	i := strings.LastIndex(found[0], ":")
+	contract := output.Contracts[found[0][:i]][found[0][i+1:]]
+	code, err := hex.DecodeString(strings.TrimPrefix(contract.EVM.DeployedBytecode.Object, "0x"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid bytecode: %v", err)
+	}
+	return code, contract.ABI, nil
+}
+
+// compileWasm builds the sources by clang targeting wasm32, and returns the
+// wasm binary.
+func (v *Verifier) compileWasm(ctx context.Context, req *Request) ([]byte, error) {
+	if v.conf.WasmCC == "" {
+		return nil, ErrNoCompiler
+	}
+	if err := v.checkVersion(ctx, v.conf.WasmCC, req.Compiler); err != nil {
+		return nil, err
+	}
+
+	var settings struct {
+		Flags []string `json:"flags"`
+	}
+	if len(req.Settings) > 0 {
+		if err := json.Unmarshal(req.Settings, &settings); err != nil {
+			return nil, fmt.Errorf("invalid settings: %v", err)
+		}
+	}
+	if err := checkWasmFlags(settings.Flags); err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "wasmcc")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	args := append(append([]string{}, wasmCFlags...), settings.Flags...)
+	args = append(args, "-o", "contract.wasm")
+	var files []string
+	for name, content := range req.Sources {
+		if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+			return nil, fmt.Errorf("invalid source file name %q", name)
+		}
+		switch filepath.Ext(name) {
+		case ".c", ".cc", ".cpp":
+			files = append(files, name)
+		case ".h", ".hpp":
+		default:
+			return nil, fmt.Errorf("unknown source file %q", name)
+		}
+		if err := checkIncludes(name, content); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			return nil, err
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no source files to compile")
+	}
+	sort.Strings(files)
+	if _, err := runCompiler(ctx, dir, v.conf.WasmCC, append(args, files...), nil, req.Sources); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(filepath.Join(dir, "contract.wasm"))
+}
+
+// checkWasmFlags only allows the flags changing the code generation, the flags
+// naming files are rejected to keep the compilation within its directory.
+func checkWasmFlags(flags []string) error {
+	for _, flag := range flags {
+		if !wasmFlags[flag] && !macroFlag.MatchString(flag) {
+			return fmt.Errorf("flag %q not allowed", flag)
+		}
+	}
+	return nil
+}
+
+// checkIncludes checks the source only includes the files by literal relative
+// paths within the include directories, and reads no other files. The compiler
+// runs as the node, so an absolute path or a path out of the directories could
+// read any file the node reads. The directives are searched anywhere in the
+// source with and without the comments, so a comment hides none of them.
+func checkIncludes(name, content string) error {
+	for _, reader := range fileReaders {
+		if strings.Contains(content, reader) {
+			return fmt.Errorf("%s: %s not allowed", name, reader)
+		}
+	}
+	content = strings.Replace(content, "\\\r\n", "", -1)
+	content = strings.Replace(content, "\\\n", "", -1)
+	for _, text := range []string{content, blockComment.ReplaceAllString(content, " ")} {
+		for _, m := range includeDirective.FindAllStringSubmatch(text, -1) {
+			f := includeFile.FindStringSubmatch(strings.TrimSpace(m[3]))
+			if f == nil || strings.HasPrefix(f[1], "/") || strings.Contains(f[1], "..") {
+				return fmt.Errorf("%s: %q not allowed, only includes by relative paths within the include directories", name, strings.TrimSpace(m[0]))
+			}
+		}
+	}
+	return nil
+}
+
+// compileDiagnostics returns the diagnostics of the compiler in the sources,
+// the other lines of stderr may quote files out of the sources.
+func compileDiagnostics(stderr string, sources map[string]string) string {
+	var lines []string
+	for _, line := range strings.Split(stderr, "\n") {
+		if m := diagnostic.FindStringSubmatch(line); m != nil {
+			if _, ok := sources[m[1]]; ok {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkVersion checks the version of the compiler is the version the contract
+// was compiled with.
+func (v *Verifier) checkVersion(ctx context.Context, compiler, version string) error {
+	dir, err := ioutil.TempDir("", "version")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	out, err := runCompiler(ctx, dir, compiler, []string{"--version"}, nil, nil)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(string(out), version) {
+		return fmt.Errorf("compiler version mismatch: have %s, want %s", strings.TrimSpace(string(out)), version)
+	}
+	return nil
+}
+
+// runCompiler runs the compiler in dir with an empty environment, and returns
+// its output. The compiler is killed once ctx is done. Only the diagnostics of
+// stderr in the sources are returned to the caller on failure.
+//
+// The compiler is not isolated: it runs as the node user and can read and
+// write what the node can. The source checks only reject the known ways to
+// read files, so the compiler binary is trusted and a compiler bug is a bug of
+// the node. Nodes serving the public RPC must leave the compilers unset and
+// verify by artifacts only.
+func runCompiler(ctx context.Context, dir, name string, args []string, stdin []byte, sources map[string]string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = []string{"HOME=" + dir, "TMPDIR=" + dir}
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	stdout := &limitedBuffer{limit: maxOutputSize}
+	stderr := &limitedBuffer{limit: 64 * 1024}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("compile fail: %v", ctx.Err())
+		}
+		if diag := compileDiagnostics(stderr.String(), sources); diag != "" {
+			return nil, fmt.Errorf("compile fail: %v: %s", err, diag)
+		}
+		return nil, fmt.Errorf("compile fail: %v", err)
+	}
+	if stdout.overflow {
+		return nil, errors.New("compile fail: output too large")
+	}
+	return stdout.Bytes(), nil
+}
+
+// limitedBuffer drops the output beyond the limit.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if n := b.limit - b.Len(); len(p) > n {
+		b.overflow = true
+		if n > 0 {
+			b.Buffer.Write(p[:n])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,73 @@
+package verify
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/libs/ser"
+)
+
+const (
+	key_pre string = "ctv_"
+)
+
+// ErrVerified is returned saving a contract verified before by a method at
+// least as strong.
+var ErrVerified = errors.New("contract already verified")
+
+// methodRank ranks the methods by strength, the compiled sources prove the ABI
+// and the sources while the artifact only proves the code.
+func methodRank(method string) int {
+	switch method {
+	case MethodCompiled:
+		return 2
+	case MethodArtifact:
+		return 1
+	}
+	return 0
+}
+
+// Store keeps the verified contracts by address.
+type Store struct {
+	db dbm.DB
+	mu sync.Mutex
+}
+
+func NewStore(db dbm.DB) *Store {
+	return &Store{db: db}
+}
+
+// Save saves the verified contract. It only replaces the contract verified
+// before for other code, or by a weaker method.
+func (s *Store) Save(c *Contract) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old := s.Get(c.Address); old != nil && old.CodeHash == c.CodeHash && methodRank(old.Method) >= methodRank(c.Method) {
+		return ErrVerified
+	}
+	val, err := ser.EncodeToBytes(c)
+	if err != nil {
+		return err
+	}
+	s.db.SetSync(calContractKey(c.Address), val)
+	return nil
+}
+
+// Get returns the verified contract at addr, or nil if not verified.
+func (s *Store) Get(addr common.Address) *Contract {
+	val := s.db.Get(calContractKey(addr))
+	if len(val) == 0 {
+		return nil
+	}
+	c := &Contract{}
+	if err := ser.DecodeBytes(val, c); err != nil {
+		return nil
+	}
+	return c
+}
+
+func calContractKey(addr common.Address) []byte {
+	return append([]byte(key_pre), addr.Bytes()...)
+}
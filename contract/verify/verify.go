@@ -0,0 +1,295 @@
+// Package verify implements the verification of the deployed contracts against
+// their sources. A contract is verified by recompiling the sources with the
+// compiler settings it was built with, or by comparing a supplied artifact, to
+// the code of the contract on chain. The ABIs of the verified contracts are kept
+// for the explorers to decode the calls and the events of the contracts.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	solabi "github.com/lianxiangcloud/linkchain/accounts/abi"
+	"github.com/lianxiangcloud/linkchain/accounts/wasmabi"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/crypto"
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
+	"github.com/xunleichain/tc-wasm/vm"
+)
+
+// The languages of the contracts.
+const (
+	Solidity = "solidity"
+	Wasm     = "wasm"
+)
+
+// The methods the contracts are verified by.
+const (
+	MethodCompiled = "compiled"
+	MethodArtifact = "artifact"
+)
+
+// DefaultTimeout is the default time limit of a compilation.
+const DefaultTimeout = 60 * time.Second
+
+var (
+	ErrNoCode       = errors.New("no contract code at the address")
+	ErrCodeMismatch = errors.New("code mismatch")
+	ErrNoCompiler   = errors.New("compiler not configured, verify by artifact")
+	ErrHasCompiler  = errors.New("compiler configured, verify by compiling")
+)
+
+// Config is the configuration of the compilers. The compilers run untrusted
+// sources without isolation, see runCompiler, they must not be set on the
+// nodes serving the public RPC.
+type Config struct {
+	Solc    string        // path of solc, the solidity contracts are only verified by artifacts if empty
+	WasmCC  string        // path of clang targeting wasm32, the wasm contracts are only verified by artifacts if empty
+	Timeout time.Duration // time limit of a compilation
+}
+
+// Artifact is the compiled code of a contract.
+type Artifact struct {
+	Code hexutil.Bytes   `json:"code"` // runtime bytecode of a solidity contract, or the wasm binary
+	ABI  json.RawMessage `json:"abi"`
+}
+
+// Request requests the verification of the contract at Address. Settings are
+// the standard JSON settings of solc for the solidity contracts, or the
+// {"flags": [...]} of clang for the wasm contracts. clang emits no ABI, the ABI
+// of a compiled wasm contract is given by ABI.
+type Request struct {
+	Address      common.Address    `json:"address"`
+	Language     string            `json:"language"`
+	Compiler     string            `json:"compiler"` // compiler version
+	Settings     json.RawMessage   `json:"settings"`
+	ContractName string            `json:"contractName"` // contract of the solidity sources, or "file:name"
+	Sources      map[string]string `json:"sources"`      // file name to content
+	ABI          json.RawMessage   `json:"abi"`          // ABI of the compiled wasm contract
+	Artifact     *Artifact         `json:"artifact"`     // compares the artifact instead of compiling if given
+}
+
+// SourceFile is a source file of a verified contract.
+type SourceFile struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Contract is a verified contract.
+type Contract struct {
+	Address      common.Address  `json:"address"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Language     string          `json:"language"`
+	Compiler     string          `json:"compiler"`
+	Settings     json.RawMessage `json:"settings,omitempty"`
+	ContractName string          `json:"contractName,omitempty"`
+	Sources      []*SourceFile   `json:"sources"`
+	ABI          json.RawMessage `json:"abi"`
+	Method       string          `json:"method"` // compiled or artifact
+	Height       uint64          `json:"height"` // block height the contract is verified at
+	Time         uint64          `json:"time"`
+}
+
+// Verifier verifies the contracts and keeps the verified ones in the store.
+type Verifier struct {
+	store *Store
+	conf  Config
+	sem   chan struct{} // one compilation at a time
+}
+
+func NewVerifier(store *Store, conf Config) *Verifier {
+	if conf.Timeout <= 0 {
+		conf.Timeout = DefaultTimeout
+	}
+	return &Verifier{
+		store: store,
+		conf:  conf,
+		sem:   make(chan struct{}, 1),
+	}
+}
+
+// Get returns the verified contract at addr, or nil if not verified.
+func (v *Verifier) Get(addr common.Address) *Contract {
+	return v.store.Get(addr)
+}
+
+// Verify verifies the contract of the request against code, the code of the
+// contract on chain at height, and saves the verified contract. The sources of
+// an artifact are not checked, so the artifact is only accepted if the compiler
+// of the language is not configured.
+func (v *Verifier) Verify(ctx context.Context, req *Request, code []byte, height uint64) (*Contract, error) {
+	if len(code) == 0 {
+		return nil, ErrNoCode
+	}
+	isWasm := bytes.HasPrefix(code, vm.WasmBytes)
+	switch req.Language {
+	case Solidity:
+		if isWasm {
+			return nil, fmt.Errorf("wasm contract verified as %s", req.Language)
+		}
+	case Wasm:
+		if !isWasm {
+			return nil, fmt.Errorf("evm contract verified as %s", req.Language)
+		}
+	default:
+		return nil, fmt.Errorf("unknown language %q", req.Language)
+	}
+	if len(req.Sources) == 0 {
+		return nil, errors.New("no sources")
+	}
+	if req.Compiler == "" {
+		return nil, errors.New("no compiler version")
+	}
+
+	var (
+		compiled []byte
+		abi      json.RawMessage
+		method   string
+		err      error
+	)
+	if req.Artifact != nil {
+		if (req.Language == Solidity && v.conf.Solc != "") || (req.Language == Wasm && v.conf.WasmCC != "") {
+			return nil, ErrHasCompiler
+		}
+		compiled, abi, method = req.Artifact.Code, req.Artifact.ABI, MethodArtifact
+	} else {
+		select {
+		case v.sem <- struct{}{}:
+			defer func() { <-v.sem }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		ctx, cancel := context.WithTimeout(ctx, v.conf.Timeout)
+		defer cancel()
+		if req.Language == Solidity {
+			compiled, abi, err = v.compileSolidity(ctx, req)
+		} else {
+			compiled, err = v.compileWasm(ctx, req)
+			abi = req.ABI
+		}
+		if err != nil {
+			return nil, err
+		}
+		method = MethodCompiled
+	}
+
+	if req.Language == Solidity {
+		if !bytes.Equal(stripMetadata(compiled), stripMetadata(code)) {
+			return nil, ErrCodeMismatch
+		}
+	} else if !bytes.Equal(compiled, code) {
+		return nil, ErrCodeMismatch
+	}
+	if err := checkABI(req.Language, abi, code); err != nil {
+		return nil, err
+	}
+
+	c := &Contract{
+		Address:      req.Address,
+		CodeHash:     crypto.Keccak256Hash(code),
+		Language:     req.Language,
+		Compiler:     req.Compiler,
+		Settings:     req.Settings,
+		ContractName: req.ContractName,
+		Sources:      sortedSources(req.Sources),
+		ABI:          abi,
+		Method:       method,
+		Height:       height,
+		Time:         uint64(time.Now().Unix()),
+	}
+	if err := v.store.Save(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// checkABI checks the ABI is a wasm ABI for the wasm contracts, or a solidity
+// ABI for the solidity contracts, matching code. The actions of a wasm ABI are
+// dispatched by name, so each name must be in the data of the wasm binary as a
+// C string. The functions of a solidity ABI are dispatched by selector, so each
+// selector must be pushed by the code.
+func checkABI(language string, abi json.RawMessage, code []byte) error {
+	if len(abi) == 0 {
+		return errors.New("no abi")
+	}
+	if language == Wasm {
+		wabi, err := wasmabi.JSON(bytes.NewReader(abi))
+		if err != nil {
+			return fmt.Errorf("invalid abi: %v", err)
+		}
+		for name := range wabi.Actions {
+			if !bytes.Contains(code, append([]byte(name), 0)) {
+				return fmt.Errorf("abi mismatch: action %s not in code", name)
+			}
+		}
+		return nil
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(abi, &entries); err != nil {
+		return fmt.Errorf("invalid abi: %v", err)
+	}
+	sabi, err := solabi.JSON(bytes.NewReader(abi))
+	if err != nil {
+		return fmt.Errorf("invalid abi: %v", err)
+	}
+	for _, method := range sabi.Methods {
+		if !pushesSelector(code, method.Id()) {
+			return fmt.Errorf("abi mismatch: function %s not in code", method.Sig())
+		}
+	}
+	return nil
+}
+
+// pushesSelector reports whether code pushes the selector, solc pushes it by
+// the shortest PUSH without the leading zero bytes.
+func pushesSelector(code, selector []byte) bool {
+	for len(selector) > 1 && selector[0] == 0 {
+		selector = selector[1:]
+	}
+	push := append([]byte{0x5f + byte(len(selector))}, selector...)
+	for i := 0; i < len(code); {
+		op := code[i]
+		if op < 0x60 || op > 0x7f {
+			i++
+			continue
+		}
+		if bytes.HasPrefix(code[i:], push) {
+			return true
+		}
+		i += int(op-0x5f) + 1
+	}
+	return false
+}
+
+// stripMetadata strips the CBOR encoded metadata solc appends to the runtime
+// bytecode, whose hash changes with the source file paths and comments. The
+// length of the metadata is the last two bytes.
+func stripMetadata(code []byte) []byte {
+	if len(code) < 2 {
+		return code
+	}
+	n := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	start := len(code) - 2 - n
+	if n == 0 || start < 0 {
+		return code
+	}
+	// the metadata is a CBOR map of at most a few entries
+	if code[start] < 0xa1 || code[start] > 0xa5 {
+		return code
+	}
+	return code[:start]
+}
+
+func sortedSources(sources map[string]string) []*SourceFile {
+	files := make([]*SourceFile, 0, len(sources))
+	for name, content := range sources {
+		files = append(files, &SourceFile{Name: name, Content: content})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files
+}
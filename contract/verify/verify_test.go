@@ -0,0 +1,336 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
+)
+
+var (
+	// runtime bytecode with the metadata of solc 0.5
+	evmCode  = hexutil.MustDecode("0x6080604052600080fdfea165627a7a72305820" + "1111111111111111111111111111111111111111111111111111111111111111" + "0029")
+	evmCode2 = hexutil.MustDecode("0x6080604052600080fdfea165627a7a72305820" + "2222222222222222222222222222222222222222222222222222222222222222" + "0029")
+	wasmCode = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+)
+
+func TestStripMetadata(t *testing.T) {
+	if have := stripMetadata(evmCode); !bytes.Equal(have, evmCode[:10]) {
+		t.Errorf("strip metadata: have %x, want %x", have, evmCode[:10])
+	}
+	for _, code := range [][]byte{nil, {0x00}, {0x60, 0x80}, {0x60, 0x80, 0x00, 0x01}} {
+		if have := stripMetadata(code); !bytes.Equal(have, code) {
+			t.Errorf("code without metadata stripped: have %x, want %x", have, code)
+		}
+	}
+}
+
+func TestVerifyArtifact(t *testing.T) {
+	v := NewVerifier(NewStore(dbm.NewMemDB()), Config{})
+	addr := common.BytesToAddress([]byte{1})
+	sources := map[string]string{"a.sol": "contract A {}"}
+	abi := json.RawMessage(`[]`)
+
+	tests := []struct {
+		req  *Request
+		code []byte
+		ok   bool
+	}{
+		{&Request{Address: addr, Language: Solidity, Compiler: "0.5.10", Sources: sources, Artifact: &Artifact{Code: evmCode2, ABI: abi}}, evmCode, true},
+		{&Request{Address: addr, Language: Solidity, Compiler: "0.5.10", Sources: sources, Artifact: &Artifact{Code: wasmCode, ABI: abi}}, evmCode, false},
+		{&Request{Address: addr, Language: Solidity, Compiler: "0.5.10", Sources: sources, Artifact: &Artifact{Code: evmCode}}, evmCode, false},
+		{&Request{Address: addr, Language: Solidity, Compiler: "0.5.10", Sources: sources, Artifact: &Artifact{Code: evmCode, ABI: abi}}, nil, false},
+		{&Request{Address: addr, Language: Wasm, Compiler: "clang-8", Sources: sources, Artifact: &Artifact{Code: evmCode, ABI: abi}}, evmCode, false},
+		{&Request{Address: addr, Language: Wasm, Compiler: "clang-8", Sources: sources, Artifact: &Artifact{Code: wasmCode, ABI: json.RawMessage(`{"actions":[]}`)}}, wasmCode, true},
+		{&Request{Address: addr, Language: Wasm, Compiler: "clang-8", Sources: sources}, wasmCode, false},
+	}
+	for i, tt := range tests {
+		c, err := v.Verify(context.Background(), tt.req, tt.code, uint64(i))
+		if (err == nil) != tt.ok {
+			t.Errorf("test %d: unexpected error %v", i, err)
+			continue
+		}
+		if !tt.ok {
+			continue
+		}
+		if c.Method != MethodArtifact || c.Height != uint64(i) {
+			t.Errorf("test %d: unexpected contract %+v", i, c)
+		}
+		saved := v.Get(addr)
+		if saved == nil || saved.CodeHash != c.CodeHash || saved.Language != tt.req.Language || !bytes.Equal(saved.ABI, c.ABI) {
+			t.Errorf("test %d: saved contract mismatch: have %+v, want %+v", i, saved, c)
+		}
+	}
+	if v.Get(common.BytesToAddress([]byte{2})) != nil {
+		t.Error("unverified contract found")
+	}
+}
+
+// fakeSolc writes a solc script compiling any sources to the code and the abi.
+func fakeSolc(t *testing.T, dir string, code []byte, abi string) string {
+	solc := filepath.Join(dir, "solc")
+	script := `#!/bin/sh
+if [ "$1" = "--version" ]; then
+	echo "Version: 0.5.10+commit.5a6ea5b1.Linux.g++"
+	exit 0
+fi
+printf '%s' '{"contracts":{"a.sol":{"A":{"abi":` + abi + `,"evm":{"deployedBytecode":{"object":"` + hexutil.Encode(code)[2:] + `"}}}}}}'
+`
+	if err := ioutil.WriteFile(solc, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return solc
+}
+
+func TestVerifyCompiled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no shell")
+	}
+	dir, err := ioutil.TempDir("", "verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	solc := fakeSolc(t, dir, evmCode2, "[]")
+
+	v := NewVerifier(NewStore(dbm.NewMemDB()), Config{Solc: solc})
+	req := &Request{
+		Address:  common.BytesToAddress([]byte{1}),
+		Language: Solidity,
+		Compiler: "0.5.10",
+		Sources:  map[string]string{"a.sol": "contract A {}"},
+	}
+	c, err := v.Verify(context.Background(), req, evmCode, 1)
+	if err != nil {
+		t.Fatalf("verify fail: %v", err)
+	}
+	if c.Method != MethodCompiled {
+		t.Errorf("method mismatch: have %s, want %s", c.Method, MethodCompiled)
+	}
+
+	req.Compiler = "0.4.24"
+	if _, err := v.Verify(context.Background(), req, evmCode, 1); err == nil {
+		t.Error("expected compiler version mismatch")
+	}
+	req.Compiler, req.ContractName = "0.5.10", "B"
+	if _, err := v.Verify(context.Background(), req, evmCode, 1); err == nil {
+		t.Error("expected contract not found")
+	}
+}
+
+func TestVerifyReplace(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no shell")
+	}
+	dir, err := ioutil.TempDir("", "verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// PUSH4 set(uint256) EQ
+	code := hexutil.MustDecode("0x608060405263" + "60fe47b1" + "1400")
+	abi := `[{"constant":false,"inputs":[{"name":"v","type":"uint256"}],"name":"set","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"}]`
+
+	store := NewStore(dbm.NewMemDB())
+	byArtifact := NewVerifier(store, Config{})
+	byCompiling := NewVerifier(store, Config{Solc: fakeSolc(t, dir, code, abi)})
+	req := &Request{
+		Address:  common.BytesToAddress([]byte{1}),
+		Language: Solidity,
+		Compiler: "0.5.10",
+		Sources:  map[string]string{"a.sol": "contract A { function set(uint v) public {} }"},
+		Artifact: &Artifact{Code: code, ABI: json.RawMessage(`[{"inputs":[],"name":"get","outputs":[],"type":"function"}]`)},
+	}
+	if _, err := byArtifact.Verify(context.Background(), req, code, 1); err == nil {
+		t.Error("expected abi mismatch")
+	}
+	req.Artifact.ABI = json.RawMessage(abi)
+	if _, err := byArtifact.Verify(context.Background(), req, code, 1); err != nil {
+		t.Fatalf("verify fail: %v", err)
+	}
+	if _, err := byArtifact.Verify(context.Background(), req, code, 2); err != ErrVerified {
+		t.Errorf("artifact replaced artifact: %v", err)
+	}
+	if _, err := byCompiling.Verify(context.Background(), req, code, 3); err != ErrHasCompiler {
+		t.Errorf("artifact verified with compiler: %v", err)
+	}
+
+	req.Artifact = nil
+	if _, err := byCompiling.Verify(context.Background(), req, code, 4); err != nil {
+		t.Fatalf("compiled not replacing artifact: %v", err)
+	}
+	if _, err := byCompiling.Verify(context.Background(), req, code, 5); err != ErrVerified {
+		t.Errorf("compiled replaced compiled: %v", err)
+	}
+	req.Artifact = &Artifact{Code: code, ABI: json.RawMessage(abi)}
+	if _, err := byArtifact.Verify(context.Background(), req, code, 6); err != ErrVerified {
+		t.Errorf("artifact replaced compiled: %v", err)
+	}
+	if c := store.Get(req.Address); c == nil || c.Method != MethodCompiled || c.Height != 4 {
+		t.Errorf("unexpected contract %+v", c)
+	}
+}
+
+func TestPushesSelector(t *testing.T) {
+	tests := []struct {
+		code     string
+		selector string
+		ok       bool
+	}{
+		{"0x63aabbccdd14", "0xaabbccdd", true},
+		{"0x6200bbcc14", "0x0000bbcc", false},
+		{"0x61bbcc14", "0x0000bbcc", true},
+		{"0x6763aabbccdd000000", "0xaabbccdd", false}, // data of PUSH8
+		{"0x63aabbcc", "0xaabbccdd", false},
+	}
+	for i, tt := range tests {
+		if ok := pushesSelector(hexutil.MustDecode(tt.code), hexutil.MustDecode(tt.selector)); ok != tt.ok {
+			t.Errorf("test %d: have %v, want %v", i, ok, tt.ok)
+		}
+	}
+}
+
+func TestVerifyCompiledWasm(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no shell")
+	}
+	dir, err := ioutil.TempDir("", "verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	clang := filepath.Join(dir, "clang")
+	script := `#!/bin/sh
+if [ "$1" = "--version" ]; then
+	echo "clang version 8.0.0 (tags/RELEASE_800/final)"
+	exit 0
+fi
+printf '\000asm\001\000\000\000set\000' > contract.wasm
+`
+	if err := ioutil.WriteFile(clang, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	code := append(append([]byte{}, wasmCode...), "set\x00"...)
+
+	v := NewVerifier(NewStore(dbm.NewMemDB()), Config{WasmCC: clang})
+	req := &Request{
+		Address:  common.BytesToAddress([]byte{1}),
+		Language: Wasm,
+		Compiler: "clang version 8.0.0",
+		Settings: json.RawMessage(`{"flags":["-O2"]}`),
+		Sources:  map[string]string{"a.cpp": "void set(uint64_t v) {}"},
+	}
+	if _, err := v.Verify(context.Background(), req, code, 1); err == nil {
+		t.Error("expected no abi")
+	}
+	req.ABI = json.RawMessage(`{"actions":[{"name":"get","inputs":[]}]}`)
+	if _, err := v.Verify(context.Background(), req, code, 1); err == nil {
+		t.Error("expected abi mismatch")
+	}
+	req.ABI = json.RawMessage(`{"actions":[{"name":"set","inputs":[{"name":"v","type":"uint64"}]}]}`)
+	c, err := v.Verify(context.Background(), req, code, 1)
+	if err != nil {
+		t.Fatalf("verify fail: %v", err)
+	}
+	if c.Method != MethodCompiled || !bytes.Equal(c.ABI, req.ABI) {
+		t.Errorf("unexpected contract %+v", c)
+	}
+	if _, err := v.Verify(context.Background(), req, wasmCode, 1); err != ErrCodeMismatch {
+		t.Errorf("expected code mismatch, have %v", err)
+	}
+}
+
+func TestCheckWasmFlags(t *testing.T) {
+	for _, flag := range []string{"-O2", "-DNDEBUG", "-DVERSION=2", "-UNDEBUG", "-std=c++11", "-fno-exceptions"} {
+		if err := checkWasmFlags([]string{flag}); err != nil {
+			t.Errorf("flag %s rejected: %v", flag, err)
+		}
+	}
+	for _, flag := range []string{"-I/etc", "-include", "@args", "-o", "-Wl,--export=Init", "-Wl,-o,../x", "-Wp,-MD,x",
+		"-Wa,-I.", "-fplugin=x.so", "-mllvm", "-DX=$(id)", "-D X", "-O2 -Wl,x", "main.c"} {
+		if err := checkWasmFlags([]string{flag}); err == nil {
+			t.Errorf("flag %s allowed", flag)
+		}
+	}
+}
+
+func TestCheckIncludes(t *testing.T) {
+	for _, src := range []string{
+		"#include \"contract.h\"\nint a;",
+		"  #  include <stdint.h> // ints",
+		"#include \"lib/util.hpp\"",
+		"char *s = \"#\";",
+	} {
+		if err := checkIncludes("a.cpp", src); err != nil {
+			t.Errorf("source %q rejected: %v", src, err)
+		}
+	}
+	for _, src := range []string{
+		"#include \"/etc/passwd\"",
+		"#include <../../etc/passwd>",
+		"#include_next \"/etc/passwd\"",
+		"#import </etc/passwd>",
+		"%:include \"/etc/passwd\"",
+		"??=include \"/etc/passwd\"",
+		"#inc\\\nlude \"/etc/passwd\"",
+		"# /* hidden */ include \"/etc/passwd\"",
+		"/*\n*/ #include \"/etc/passwd\"",
+		"char *s = \"/*\";\n#include \"/etc/passwd\"\n// */",
+		"#define F \"/etc/passwd\"\n#include F",
+		"__asm__(\".incbin \\\"/etc/passwd\\\"\");",
+		"#if __has_include(\"/etc/passwd\")\n#endif",
+	} {
+		if err := checkIncludes("a.cpp", src); err == nil {
+			t.Errorf("source %q allowed", src)
+		}
+	}
+}
+
+func TestCompileDiagnostics(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no shell")
+	}
+	dir, err := ioutil.TempDir("", "verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	clang := filepath.Join(dir, "clang")
+	script := `#!/bin/sh
+if [ "$1" = "--version" ]; then
+	echo "clang version 8.0.0"
+	exit 0
+fi
+echo "In file included from a.cpp:1:" >&2
+echo "secret.h:1:1: error: unknown type name 'root'" >&2
+echo "root:x:0:0:root:/root:/bin/sh" >&2
+echo "a.cpp:2:1: error: expected ';'" >&2
+exit 1
+`
+	if err := ioutil.WriteFile(clang, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	v := NewVerifier(NewStore(dbm.NewMemDB()), Config{WasmCC: clang})
+	req := &Request{
+		Address:  common.BytesToAddress([]byte{1}),
+		Language: Wasm,
+		Compiler: "8.0.0",
+		Sources:  map[string]string{"a.cpp": "int a"},
+	}
+	_, err = v.Verify(context.Background(), req, wasmCode, 1)
+	if err == nil {
+		t.Fatal("expected compile fail")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "a.cpp:2:1: error: expected ';'") || strings.Contains(msg, "root") {
+		t.Errorf("unexpected error %q", msg)
+	}
+}
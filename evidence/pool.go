@@ -26,6 +26,8 @@ type EvidencePool struct {
 	// latest state
 	mtx    sync.Mutex
 	status cs.NewStatus
+
+	eventBus *types.EventBus // publishes the new evidence
 }
 
 func NewEvidencePool(statusDB dbm.DB, evidenceStore *EvidenceStore, status cs.NewStatus) *EvidencePool {
@@ -52,6 +54,11 @@ func (evpool *EvidencePool) SetLogger(l log.Logger) {
 	evpool.logger = l
 }
 
+// SetEventBus sets the event bus the new evidence is published to.
+func (evpool *EvidencePool) SetEventBus(b *types.EventBus) {
+	evpool.eventBus = b
+}
+
 // PriorityEvidence returns the priority evidence.
 func (evpool *EvidencePool) PriorityEvidence() []types.Evidence {
 	return evpool.evidenceStore.PriorityEvidence()
@@ -62,6 +69,11 @@ func (evpool *EvidencePool) PendingEvidence() []types.Evidence {
 	return evpool.evidenceStore.PendingEvidence()
 }
 
+// CommittedEvidence returns the committed evidence of the validator.
+func (evpool *EvidencePool) CommittedEvidence(address []byte) []types.Evidence {
+	return evpool.evidenceStore.CommittedEvidence(address)
+}
+
 // Status returns the current status of the evpool.
 func (evpool *EvidencePool) Status() cs.NewStatus {
 	evpool.mtx.Lock()
@@ -113,6 +125,10 @@ func (evpool *EvidencePool) AddEvidence(evidence types.Evidence) (err error) {
 	// add evidence to clist
 	evpool.evidenceList.PushBack(evidence)
 
+	if evpool.eventBus != nil {
+		evpool.eventBus.PublishEventNewEvidence(types.EventDataNewEvidence{Evidence: evidence})
+	}
+
 	return nil
 }
 
@@ -121,7 +137,10 @@ func (evpool *EvidencePool) MarkEvidenceAsCommitted(height uint64, evidence []ty
 	// make a map of committed evidence to remove from the clist
 	blockEvidenceMap := make(map[string]struct{})
 	for _, ev := range evidence {
-		if _, ok := ev.(*types.FaultValidatorsEvidence); ok {
+		if fve, ok := ev.(*types.FaultValidatorsEvidence); ok {
+			if fve.FaultVal != nil {
+				evpool.evidenceStore.AddCommittedEvidence(ev)
+			}
 			continue
 		}
 		evpool.evidenceStore.MarkEvidenceAsCommitted(ev)
@@ -24,6 +24,7 @@ Schema for indexing evidence (note you need both height and hash to find a piece
 "evidence-lookup"/<evidence-height>/<evidence-hash> -> EvidenceInfo
 "evidence-outqueue"/<priority>/<evidence-height>/<evidence-hash> -> EvidenceInfo
 "evidence-pending"/<evidence-height>/<evidence-hash> -> EvidenceInfo
+"evidence-validator"/<validator-address>/<evidence-height>/<evidence-hash> -> EvidenceInfo
 */
 
 type EvidenceInfo struct {
@@ -33,9 +34,10 @@ type EvidenceInfo struct {
 }
 
 const (
-	baseKeyLookup   = "evidence-lookup"   // all evidence
-	baseKeyOutqueue = "evidence-outqueue" // not-yet broadcast
-	baseKeyPending  = "evidence-pending"  // broadcast but not committed
+	baseKeyLookup    = "evidence-lookup"    // all evidence
+	baseKeyOutqueue  = "evidence-outqueue"  // not-yet broadcast
+	baseKeyPending   = "evidence-pending"   // broadcast but not committed
+	baseKeyValidator = "evidence-validator" // committed, by validator
 )
 
 func keyLookup(evidence types.Evidence) []byte {
@@ -59,6 +61,14 @@ func keyPending(evidence types.Evidence) []byte {
 	return _key("%s/%s/%X", baseKeyPending, bE(evidence.Height()), evidence.Hash())
 }
 
+func keyValidator(evidence types.Evidence) []byte {
+	return _key("%s/%X/%s/%X", baseKeyValidator, evidence.Address(), bE(evidence.Height()), evidence.Hash())
+}
+
+func keyValidatorPrefix(address []byte) string {
+	return fmt.Sprintf("%s/%X/", baseKeyValidator, address)
+}
+
 func _key(fmt_ string, o ...interface{}) []byte {
 	return []byte(fmt.Sprintf(fmt_, o...))
 }
@@ -109,6 +119,11 @@ func (store *EvidenceStore) ListEvidence(prefixKey string) (evidence []types.Evi
 	return evidence
 }
 
+// CommittedEvidence returns the committed evidence of the validator, sorted by height.
+func (store *EvidenceStore) CommittedEvidence(address []byte) (evidence []types.Evidence) {
+	return store.ListEvidence(keyValidatorPrefix(address))
+}
+
 // GetEvidence fetches the evidence with the given height and hash.
 func (store *EvidenceStore) GetEvidence(height uint64, hash []byte) *EvidenceInfo {
 	key := keyLookupFromHeightAndHash(height, hash)
@@ -172,8 +187,21 @@ func (store *EvidenceStore) MarkEvidenceAsCommitted(evidence types.Evidence) {
 	ei := store.getEvidenceInfo(evidence)
 	ei.Committed = true
 
+	eiBytes := ser.MustEncodeToBytes(ei)
+	store.db.Set(keyValidator(evidence), eiBytes)
+
 	lookupKey := keyLookup(evidence)
-	store.db.SetSync(lookupKey, ser.MustEncodeToBytes(ei))
+	store.db.SetSync(lookupKey, eiBytes)
+}
+
+// AddCommittedEvidence indexes the committed evidence which is never added to the
+// pool, as the fault validators evidence of the proposer, by its validator.
+func (store *EvidenceStore) AddCommittedEvidence(evidence types.Evidence) {
+	ei := EvidenceInfo{
+		Committed: true,
+		Evidence:  evidence,
+	}
+	store.db.SetSync(keyValidator(evidence), ser.MustEncodeToBytes(ei))
 }
 
 //---------------------------------------------------
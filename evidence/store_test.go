@@ -107,3 +107,37 @@ func TestStorePriority(t *testing.T) {
 		assert.Equal(ev, cases[i].ev)
 	}
 }
+
+func TestStoreCommittedEvidence(t *testing.T) {
+	assert := assert.New(t)
+
+	db := dbm.NewMemDB()
+	store := NewEvidenceStore(db)
+
+	ev1 := types.NewMockGoodEvidence(5, 1, []byte("val1"))
+	ev2 := types.NewMockGoodEvidence(2, 1, []byte("val1"))
+	ev3 := types.NewMockGoodEvidence(3, 2, []byte("val2"))
+	for _, ev := range []types.MockGoodEvidence{ev1, ev2, ev3} {
+		assert.True(store.AddNewEvidence(ev, 10))
+	}
+	assert.Equal(0, len(store.CommittedEvidence([]byte("val1"))))
+
+	store.MarkEvidenceAsCommitted(ev1)
+	store.MarkEvidenceAsCommitted(ev2)
+
+	// sorted by height, only the committed
+	evList := store.CommittedEvidence([]byte("val1"))
+	if assert.Equal(2, len(evList)) {
+		assert.Equal(ev2, evList[0])
+		assert.Equal(ev1, evList[1])
+	}
+	assert.Equal(0, len(store.CommittedEvidence([]byte("val2"))))
+
+	// evidence never added to the pool
+	ev4 := types.NewMockGoodEvidence(4, 2, []byte("val2"))
+	store.AddCommittedEvidence(ev4)
+	evList = store.CommittedEvidence([]byte("val2"))
+	if assert.Equal(1, len(evList)) {
+		assert.Equal(ev4, evList[0])
+	}
+}
@@ -0,0 +1,62 @@
+package blake2b
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+// test vectors of EIP-152, the input is rounds || h || m || t || f
+var fTests = []struct {
+	input, expected string
+}{
+	{
+		"0000000048c9bdf267e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d182e6ad7f520e511f6c3e2b8c68059b6bbd41fbabd9831f79217e1319cde05b61626300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000300000000000000000000000000000001",
+		"08c9bcf367e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d282e6ad7f520e511f6c3e2b8c68059b9442be0454267ce079217e1319cde05b",
+	},
+	{
+		"0000000c48c9bdf267e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d182e6ad7f520e511f6c3e2b8c68059b6bbd41fbabd9831f79217e1319cde05b61626300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000300000000000000000000000000000001",
+		"ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923",
+	},
+	{
+		"0000000c48c9bdf267e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d182e6ad7f520e511f6c3e2b8c68059b6bbd41fbabd9831f79217e1319cde05b61626300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000300000000000000000000000000000000",
+		"75ab69d3190a562c51aef8d88f1c2775876944407270c42c9844252c26d2875298743e7f6d5ea2f2d3e8d226039cd31b4e426ac4f2d3d666a610c2116fde4735",
+	},
+	{
+		"0000000148c9bdf267e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d182e6ad7f520e511f6c3e2b8c68059b6bbd41fbabd9831f79217e1319cde05b61626300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000300000000000000000000000000000001",
+		"b63a380cb2897d521994a85234ee2c181b5f844d2c624c002677e9703449d2fba551b3a8333bcdf5f2f7e08993d53923de3d64fcc68c034e717b9293fed7a421",
+	},
+}
+
+func TestF(t *testing.T) {
+	for i, test := range fTests {
+		input, err := hex.DecodeString(test.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var (
+			rounds = binary.BigEndian.Uint32(input[0:4])
+			h      [8]uint64
+			m      [16]uint64
+			c      [2]uint64
+		)
+		for j := 0; j < 8; j++ {
+			h[j] = binary.LittleEndian.Uint64(input[4+j*8:])
+		}
+		for j := 0; j < 16; j++ {
+			m[j] = binary.LittleEndian.Uint64(input[68+j*8:])
+		}
+		c[0] = binary.LittleEndian.Uint64(input[196:])
+		c[1] = binary.LittleEndian.Uint64(input[204:])
+
+		F(&h, m, c, input[212] == 1, rounds)
+
+		output := make([]byte, 64)
+		for j := 0; j < 8; j++ {
+			binary.LittleEndian.PutUint64(output[j*8:], h[j])
+		}
+		if got := hex.EncodeToString(output); got != test.expected {
+			t.Errorf("test %d: F got %s, want %s", i, got, test.expected)
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -37,7 +39,17 @@ var canReport = false
 var report *reporter = nil
 
 type Filter struct {
-	next           Logger
+	cached  uint64 // generation<<8 | allowed level, see allowedLevel
+	next    Logger
+	levels  *filterLevels // shared by the filters derived with With
+	keyvals []interface{} // context matched against the allowedKeyvals
+}
+
+// filterLevels holds the levels of the filters, they can be changed at runtime
+// by SetLevels.
+type filterLevels struct {
+	generation     uint64
+	mtx            sync.RWMutex
 	allowed        level            // XOR'd levels for default case
 	allowedKeyvals map[keyval]level // When key-value match, use this level
 }
@@ -53,8 +65,11 @@ type keyval struct {
 // Error helper methods are squelched.
 func NewFilter(next Logger, options ...Option) Logger {
 	l := &Filter{
-		next:           next,
-		allowedKeyvals: make(map[keyval]level),
+		next: next,
+		levels: &filterLevels{
+			generation:     1,
+			allowedKeyvals: make(map[keyval]level),
+		},
 	}
 	for _, option := range options {
 		option(l)
@@ -76,7 +91,7 @@ func (l *Filter) Println(format string, params ...interface{}) {
 }
 
 func (l *Filter) Trace(msg string, ctx ...interface{}) {
-	levelAllowed := l.allowed&levelTrace != 0
+	levelAllowed := l.allowedLevel()&levelTrace != 0
 	if !levelAllowed {
 		return
 	}
@@ -84,7 +99,7 @@ func (l *Filter) Trace(msg string, ctx ...interface{}) {
 }
 
 func (l *Filter) Debug(msg string, ctx ...interface{}) {
-	levelAllowed := l.allowed&levelDebug != 0
+	levelAllowed := l.allowedLevel()&levelDebug != 0
 	if !levelAllowed {
 		return
 	}
@@ -92,7 +107,7 @@ func (l *Filter) Debug(msg string, ctx ...interface{}) {
 }
 
 func (l *Filter) Info(msg string, ctx ...interface{}) {
-	levelAllowed := l.allowed&levelInfo != 0
+	levelAllowed := l.allowedLevel()&levelInfo != 0
 	if !levelAllowed {
 		return
 	}
@@ -100,7 +115,7 @@ func (l *Filter) Info(msg string, ctx ...interface{}) {
 }
 
 func (l *Filter) Warn(msg string, ctx ...interface{}) {
-	levelAllowed := l.allowed&levelWarn != 0
+	levelAllowed := l.allowedLevel()&levelWarn != 0
 	if !levelAllowed {
 		return
 	}
@@ -108,7 +123,7 @@ func (l *Filter) Warn(msg string, ctx ...interface{}) {
 }
 
 func (l *Filter) Error(msg string, ctx ...interface{}) {
-	levelAllowed := l.allowed&levelError != 0
+	levelAllowed := l.allowedLevel()&levelError != 0
 	if !levelAllowed {
 		return
 	}
@@ -116,7 +131,7 @@ func (l *Filter) Error(msg string, ctx ...interface{}) {
 }
 
 func (l *Filter) Crit(msg string, ctx ...interface{}) {
-	levelAllowed := l.allowed&levelCrit != 0
+	levelAllowed := l.allowedLevel()&levelCrit != 0
 	if !levelAllowed {
 		return
 	}
@@ -127,7 +142,7 @@ func (l *Filter) Report(msg string, ctx ...interface{}) {
 	if canReport && len(ctx) > 1 && ctx[0].(string) == "logID" {
 		l.reportMsg(ctx...)
 	}
-	levelAllowed := l.allowed&levelInfo != 0
+	levelAllowed := l.allowedLevel()&levelInfo != 0
 	if !levelAllowed {
 		return
 	}
@@ -135,7 +150,7 @@ func (l *Filter) Report(msg string, ctx ...interface{}) {
 }
 
 func (l *Filter) Dump(msg string, ctx ...interface{}) {
-	levelAllowed := l.allowed&levelInfo != 0
+	levelAllowed := l.allowedLevel()&levelInfo != 0
 	if !levelAllowed {
 		return
 	}
@@ -250,14 +265,52 @@ func (l *Filter) reportMsg(ctx ...interface{}) {
 //     logger = log.NewFilter(logger, log.AllowError(), log.AllowInfoWith("module", "crypto"), log.AllowNoneWith("user", "Sam"))
 //		 logger.With("user", "Sam").With("module", "crypto").Info("Hello") # produces "I... Hello module=crypto user=Sam"
 func (l *Filter) With(ctx ...interface{}) Logger {
+	keyvals := make([]interface{}, 0, len(l.keyvals)+len(ctx))
+	keyvals = append(keyvals, l.keyvals...)
+	keyvals = append(keyvals, ctx...)
+	return &Filter{next: l.next.With(ctx...), levels: l.levels, keyvals: keyvals}
+}
+
+// SetLevels replaces the levels of the filter and all the filters derived from
+// it with With. It is safe to call it while logging.
+func (l *Filter) SetLevels(options ...Option) {
+	tmp := &Filter{levels: &filterLevels{allowedKeyvals: make(map[keyval]level)}}
+	for _, option := range options {
+		option(tmp)
+	}
+
+	l.levels.mtx.Lock()
+	l.levels.allowed = tmp.levels.allowed
+	l.levels.allowedKeyvals = tmp.levels.allowedKeyvals
+	atomic.AddUint64(&l.levels.generation, 1)
+	l.levels.mtx.Unlock()
+}
+
+// allowedLevel returns the levels allowed for the context of the filter. The
+// result is cached until the levels are changed by SetLevels.
+func (l *Filter) allowedLevel() level {
+	generation := atomic.LoadUint64(&l.levels.generation)
+	if cached := atomic.LoadUint64(&l.cached); cached>>8 == generation {
+		return level(cached)
+	}
+
+	l.levels.mtx.RLock()
+	generation = atomic.LoadUint64(&l.levels.generation)
+	allowed := l.levels.allowed
+	ctx := l.keyvals
+match:
 	for i := len(ctx) - 2; i >= 0; i -= 2 {
-		for kv, allowed := range l.allowedKeyvals {
+		for kv, lvl := range l.levels.allowedKeyvals {
 			if ctx[i] == kv.key && ctx[i+1] == kv.value {
-				return &Filter{next: l.next.With(ctx...), allowed: allowed, allowedKeyvals: l.allowedKeyvals}
+				allowed = lvl
+				break match
 			}
 		}
 	}
-	return &Filter{next: l.next.With(ctx...), allowed: l.allowed, allowedKeyvals: l.allowedKeyvals}
+	l.levels.mtx.RUnlock()
+
+	atomic.StoreUint64(&l.cached, generation<<8|uint64(allowed))
+	return allowed
 }
 
 //--------------------------------------------------------------------------------
@@ -325,33 +378,33 @@ func AllowNone() Option {
 }
 
 func allowed(allowed level) Option {
-	return func(l *Filter) { l.allowed = allowed }
+	return func(l *Filter) { l.levels.allowed = allowed }
 }
 
 func AllowTranceWith(key interface{}, value interface{}) Option {
-	return func(l *Filter) { l.allowedKeyvals[keyval{key, value}] = lvlBaseTrace }
+	return func(l *Filter) { l.levels.allowedKeyvals[keyval{key, value}] = lvlBaseTrace }
 }
 
 func AllowDebugWith(key interface{}, value interface{}) Option {
-	return func(l *Filter) { l.allowedKeyvals[keyval{key, value}] = lvlBaseDebug }
+	return func(l *Filter) { l.levels.allowedKeyvals[keyval{key, value}] = lvlBaseDebug }
 }
 
 func AllowInfoWith(key interface{}, value interface{}) Option {
-	return func(l *Filter) { l.allowedKeyvals[keyval{key, value}] = lvlBaseInfo }
+	return func(l *Filter) { l.levels.allowedKeyvals[keyval{key, value}] = lvlBaseInfo }
 }
 
 func AllowWarnWith(key interface{}, value interface{}) Option {
-	return func(l *Filter) { l.allowedKeyvals[keyval{key, value}] = lvlBaseWarn }
+	return func(l *Filter) { l.levels.allowedKeyvals[keyval{key, value}] = lvlBaseWarn }
 }
 
 func AllowErrorWith(key interface{}, value interface{}) Option {
-	return func(l *Filter) { l.allowedKeyvals[keyval{key, value}] = lvlBaseError }
+	return func(l *Filter) { l.levels.allowedKeyvals[keyval{key, value}] = lvlBaseError }
 }
 
 func AllowCritWith(key interface{}, value interface{}) Option {
-	return func(l *Filter) { l.allowedKeyvals[keyval{key, value}] = levelCrit }
+	return func(l *Filter) { l.levels.allowedKeyvals[keyval{key, value}] = levelCrit }
 }
 
 func AllowNoneWith(key interface{}, value interface{}) Option {
-	return func(l *Filter) { l.allowedKeyvals[keyval{key, value}] = 0 }
+	return func(l *Filter) { l.levels.allowedKeyvals[keyval{key, value}] = 0 }
 }
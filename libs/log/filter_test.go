@@ -0,0 +1,60 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func newCountingLogger(count *int) Logger {
+	l := &logger{[]interface{}{}, new(swapHandler)}
+	l.SetHandler(FuncHandler(func(r *Record) error {
+		*count++
+		return nil
+	}))
+	return l
+}
+
+func TestFilterSetLevels(t *testing.T) {
+	var count int
+	filter := NewFilter(newCountingLogger(&count), AllowInfo()).(*Filter)
+	consensus := filter.With("module", "consensus")
+	mempool := filter.With("module", "mempool").With("peer", "p1")
+
+	consensus.Debug("hidden")
+	mempool.Debug("hidden")
+	if count != 0 {
+		t.Fatalf("debug logged at info level: %d", count)
+	}
+
+	filter.SetLevels(AllowError(), AllowDebugWith("module", "mempool"))
+	consensus.Info("hidden")
+	mempool.Debug("shown")
+	if count != 1 {
+		t.Fatalf("after SetLevels: want 1 record, got %d", count)
+	}
+
+	filter.SetLevels(AllowInfo())
+	mempool.Debug("hidden")
+	consensus.Info("shown")
+	if count != 2 {
+		t.Fatalf("after SetLevels again: want 2 records, got %d", count)
+	}
+}
+
+func TestSamplingLogger(t *testing.T) {
+	var count int
+	sampling := NewSamplingLogger(newCountingLogger(&count), time.Hour, 2, 5)
+	for i := 0; i < 12; i++ {
+		sampling.Info("hot")
+	}
+	// 1, 2, 7, 12
+	if count != 4 {
+		t.Fatalf("want 4 sampled records, got %d", count)
+	}
+
+	sampling.With("module", "mempool").Info("other")
+	sampling.Error("hot")
+	if count != 6 {
+		t.Fatalf("want 6 records, got %d", count)
+	}
+}
@@ -251,6 +251,72 @@ func JSONFormatEx(pretty, lineSeparated bool) Format {
 	})
 }
 
+// stableKeys maps the context keys used across the modules to the field names
+// of StableJSONFormat, so log pipelines can rely on them.
+var stableKeys = map[string]string{
+	"Height":       "height",
+	"height":       "height",
+	"blockHeight":  "height",
+	"block_height": "height",
+	"Round":        "round",
+	"round":        "round",
+	"Peer":         "peer",
+	"peer":         "peer",
+	"peerID":       "peer",
+	"peer_id":      "peer",
+	"TxHash":       "tx_hash",
+	"txHash":       "tx_hash",
+	"txhash":       "tx_hash",
+	"tx_hash":      "tx_hash",
+}
+
+// StableJSONFormat formats log records as JSON objects separated by newlines
+// for the log pipelines. The record is written with the "time", "level", "msg"
+// and "caller" fields, and the context keys in stableKeys are renamed to the
+// stable field names: height, round, peer and tx_hash.
+func StableJSONFormat() Format {
+	return FormatFunc(func(r *Record) []byte {
+		props := make(map[string]interface{}, 4+len(r.Ctx)/2)
+		for i := 0; i < len(r.Ctx); i += 2 {
+			k, ok := r.Ctx[i].(string)
+			if !ok {
+				props[errorKey] = fmt.Sprintf("%+v is not a string key", r.Ctx[i])
+				continue
+			}
+			if stable, ok := stableKeys[k]; ok {
+				k = stable
+			}
+			props[k] = formatJSONValue(r.Ctx[i+1])
+		}
+		props["time"] = r.Time.Format(timeFormat)
+		props["level"] = r.Lvl.String()
+		props["msg"] = r.Msg
+		props["caller"] = fmt.Sprintf("%+v", r.Call)
+
+		b, err := json.Marshal(props)
+		if err != nil {
+			b, _ = json.Marshal(map[string]string{
+				errorKey: err.Error(),
+			})
+		}
+		return append(b, '\n')
+	})
+}
+
+// FormatByName returns the Format by the name: "terminal", "logfmt" or "json".
+func FormatByName(name string) (Format, error) {
+	switch name {
+	case "", "terminal":
+		return TerminalFormat(false), nil
+	case "logfmt":
+		return LogfmtFormat(), nil
+	case "json":
+		return StableJSONFormat(), nil
+	default:
+		return nil, fmt.Errorf("Expected either \"terminal\", \"logfmt\" or \"json\" log format, given %s", name)
+	}
+}
+
 func formatShared(value interface{}) (result interface{}) {
 	defer func() {
 		if err := recover(); err != nil {
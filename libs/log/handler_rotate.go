@@ -63,6 +63,7 @@ type RotateConfig struct {
 	Rotate     bool   `mapstructure:"rotate"`
 	Perm       string `mapstructure:"perm"`
 	RotatePerm string `mapstructure:"rotateperm"`
+	Format     string `mapstructure:"format"` // terminal, logfmt or json
 }
 
 // fileLogWriter implements LoggerInterface.
@@ -3,6 +3,7 @@ package log
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 )
@@ -11,6 +12,12 @@ const (
 	defaultLogLevelKey = "*"
 )
 
+var (
+	logLevelMtx     sync.Mutex
+	logLevel        string // the log level set by ParseLogLevel or SetLogLevel
+	defaultLogLevel string // the default level used when "*" is not provided
+)
+
 // ParseLogLevel parses complex log level - comma-separated
 // list of module:level pairs with an optional *:level pair (* means
 // all other modules).
@@ -18,6 +25,50 @@ const (
 // Example:
 //		ParseLogLevel("consensus:debug,mempool:debug,*:error", log.Root().SetHandler(StdoutHandler), "info")
 func ParseLogLevel(lvl string, logger Logger, defaultLogLevelValue string) (Logger, error) {
+	options, err := parseLogLevelOptions(lvl, defaultLogLevelValue)
+	if err != nil {
+		return nil, err
+	}
+
+	logLevelMtx.Lock()
+	logLevel, defaultLogLevel = lvl, defaultLogLevelValue
+	logLevelMtx.Unlock()
+
+	//root.SetHandler(LvlFilterHandler(level, root.GetHandler()))
+	base = NewFilter(logger, options...)
+	return base, nil
+}
+
+// SetLogLevel changes the levels of the logger returned by ParseLogLevel and
+// all the loggers derived from it, lvl is in the same form as ParseLogLevel.
+//
+// Example:
+//		SetLogLevel("consensus:debug,*:info")
+func SetLogLevel(lvl string) error {
+	filter, ok := base.(*Filter)
+	if !ok {
+		return errors.New("Log level is not parsed")
+	}
+
+	logLevelMtx.Lock()
+	defer logLevelMtx.Unlock()
+	options, err := parseLogLevelOptions(lvl, defaultLogLevel)
+	if err != nil {
+		return err
+	}
+	filter.SetLevels(options...)
+	logLevel = lvl
+	return nil
+}
+
+// GetLogLevel returns the log level set by ParseLogLevel or SetLogLevel.
+func GetLogLevel() string {
+	logLevelMtx.Lock()
+	defer logLevelMtx.Unlock()
+	return logLevel
+}
+
+func parseLogLevelOptions(lvl string, defaultLogLevelValue string) ([]Option, error) {
 	if lvl == "" {
 		return nil, errors.New("Empty log level")
 	}
@@ -85,8 +136,5 @@ func ParseLogLevel(lvl string, logger Logger, defaultLogLevelValue string) (Logg
 		}
 		options = append(options, option)
 	}
-
-	//root.SetHandler(LvlFilterHandler(level, root.GetHandler()))
-	base = NewFilter(logger, options...)
-	return base, nil
+	return options, nil
 }
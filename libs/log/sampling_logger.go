@@ -0,0 +1,117 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// NewSamplingLogger returns a Logger for the hot paths. In every tick it logs
+// the first records of each message, then every thereafter-th of them and
+// drops the others. Error and Crit records are always logged.
+//
+// Example:
+//
+//	logger = log.NewSamplingLogger(logger, time.Second, 10, 100)
+func NewSamplingLogger(next Logger, tick time.Duration, first, thereafter int) Logger {
+	if thereafter <= 0 {
+		thereafter = 1
+	}
+	return &samplingLogger{
+		next: next,
+		sampler: &sampler{
+			tick:       tick,
+			first:      first,
+			thereafter: thereafter,
+			counts:     make(map[string]int),
+		},
+	}
+}
+
+type sampler struct {
+	mtx        sync.Mutex
+	tick       time.Duration
+	first      int
+	thereafter int
+	reset      time.Time
+	counts     map[string]int
+}
+
+// allow counts the record of msg and returns whether it should be logged.
+func (s *sampler) allow(msg string) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if now := time.Now(); now.After(s.reset) {
+		s.reset = now.Add(s.tick)
+		s.counts = make(map[string]int, len(s.counts))
+	}
+	n := s.counts[msg] + 1
+	s.counts[msg] = n
+	return n <= s.first || (n-s.first)%s.thereafter == 0
+}
+
+type samplingLogger struct {
+	next    Logger
+	sampler *sampler // shared by the loggers derived with With
+}
+
+func (l *samplingLogger) Printf(format string, params ...interface{}) {
+	l.next.Info(fmt.Sprintf(format, params...))
+}
+
+func (l *samplingLogger) Println(format string, params ...interface{}) {
+	l.next.Info(fmt.Sprintf(format, params...))
+}
+
+func (l *samplingLogger) Trace(msg string, ctx ...interface{}) {
+	if l.sampler.allow(msg) {
+		l.next.Trace(msg, ctx...)
+	}
+}
+
+func (l *samplingLogger) Debug(msg string, ctx ...interface{}) {
+	if l.sampler.allow(msg) {
+		l.next.Debug(msg, ctx...)
+	}
+}
+
+func (l *samplingLogger) Info(msg string, ctx ...interface{}) {
+	if l.sampler.allow(msg) {
+		l.next.Info(msg, ctx...)
+	}
+}
+
+func (l *samplingLogger) Warn(msg string, ctx ...interface{}) {
+	if l.sampler.allow(msg) {
+		l.next.Warn(msg, ctx...)
+	}
+}
+
+func (l *samplingLogger) Error(msg string, ctx ...interface{}) {
+	l.next.Error(msg, ctx...)
+}
+
+func (l *samplingLogger) Crit(msg string, ctx ...interface{}) {
+	l.next.Crit(msg, ctx...)
+}
+
+func (l *samplingLogger) Report(msg string, ctx ...interface{}) {
+	l.next.Report(msg, ctx...)
+}
+
+func (l *samplingLogger) Dump(msg string, ctx ...interface{}) {
+	l.next.Dump(msg, ctx...)
+}
+
+func (l *samplingLogger) With(ctx ...interface{}) Logger {
+	return &samplingLogger{next: l.next.With(ctx...), sampler: l.sampler}
+}
+
+func (l *samplingLogger) GetHandler() Handler {
+	return l.next.GetHandler()
+}
+
+func (l *samplingLogger) SetHandler(h Handler) {
+	l.next.SetHandler(h)
+}
@@ -0,0 +1,57 @@
+package p2p
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Number of peers.
+	Peers metrics.Gauge
+	// Number of bytes received, by channel.
+	ChannelReceiveBytesTotal metrics.Counter
+	// Number of bytes sent, by channel.
+	ChannelSendBytesTotal metrics.Counter
+	// Number of messages waiting in the send queues of all the peers.
+	SendQueueSize metrics.Gauge
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+func PrometheusMetrics() *Metrics {
+	return &Metrics{
+		Peers: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Subsystem: "p2p",
+			Name:      "peers",
+			Help:      "Number of peers.",
+		}, []string{}),
+		ChannelReceiveBytesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Subsystem: "p2p",
+			Name:      "channel_receive_bytes_total",
+			Help:      "Number of bytes received, by channel.",
+		}, []string{"chID"}),
+		ChannelSendBytesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Subsystem: "p2p",
+			Name:      "channel_send_bytes_total",
+			Help:      "Number of bytes sent, by channel.",
+		}, []string{"chID"}),
+		SendQueueSize: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Subsystem: "p2p",
+			Name:      "send_queue_size",
+			Help:      "Number of messages waiting in the send queues of all the peers.",
+		}, []string{}),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		Peers:                    discard.NewGauge(),
+		ChannelReceiveBytesTotal: discard.NewCounter(),
+		ChannelSendBytesTotal:    discard.NewCounter(),
+		SendQueueSize:            discard.NewGauge(),
+	}
+}
@@ -16,6 +16,9 @@ import (
 
 var testIPSuffix uint32
 
+// metricsTickerDuration is the interval the send queues are reported at.
+const metricsTickerDuration = 10 * time.Second
+
 // Peer is an interface representing a peer connected on a reactor.
 //----------------------------------------------------------
 
@@ -46,6 +49,8 @@ type peer struct {
 
 	// User data
 	Data *cmn.CMap
+
+	metrics *Metrics
 }
 
 func newPeer(
@@ -55,12 +60,14 @@ func newPeer(
 	reactorsByCh map[byte]Reactor,
 	chDescs []*tmconn.ChannelDescriptor,
 	onPeerError func(Peer, interface{}),
+	metrics *Metrics,
 ) *peer {
 	p := &peer{
 		peerConn: pc,
 		nodeInfo: nodeInfo,
 		channels: nodeInfo.Channels,
 		Data:     cmn.NewCMap(),
+		metrics:  metrics,
 	}
 
 	p.mconn = createMConnection(
@@ -157,6 +164,9 @@ func (p *peer) OnStart() error {
 		return err
 	}
 	err := p.mconn.Start()
+	if err == nil {
+		go p.metricsReporter()
+	}
 	return err
 }
 
@@ -218,7 +228,11 @@ func (p *peer) Send(chID byte, msgBytes []byte) bool {
 	} else if !p.hasChannel(chID) {
 		return false
 	}
-	return p.mconn.Send(chID, msgBytes)
+	res := p.mconn.Send(chID, msgBytes)
+	if res {
+		p.metrics.ChannelSendBytesTotal.With("chID", chLabel(chID)).Add(float64(len(msgBytes)))
+	}
+	return res
 }
 
 // TrySend msg bytes to the channel identified by chID byte. Immediately returns
@@ -229,7 +243,11 @@ func (p *peer) TrySend(chID byte, msgBytes []byte) bool {
 	} else if !p.hasChannel(chID) {
 		return false
 	}
-	return p.mconn.TrySend(chID, msgBytes)
+	res := p.mconn.TrySend(chID, msgBytes)
+	if res {
+		p.metrics.ChannelSendBytesTotal.With("chID", chLabel(chID)).Add(float64(len(msgBytes)))
+	}
+	return res
 }
 
 // Get the data for a given key.
@@ -262,6 +280,33 @@ func (p *peer) hasChannel(chID byte) bool {
 	return false
 }
 
+// metricsReporter adds the changes of the messages waiting in the send queues
+// of the peer to the gauge of all the peers, and takes them out when the peer
+// stops.
+func (p *peer) metricsReporter() {
+	ticker := time.NewTicker(metricsTickerDuration)
+	defer ticker.Stop()
+	var reported int
+	for {
+		select {
+		case <-ticker.C:
+			var size int
+			for _, ch := range p.mconn.Status().Channels {
+				size += ch.SendQueueSize
+			}
+			p.metrics.SendQueueSize.Add(float64(size - reported))
+			reported = size
+		case <-p.Quit():
+			p.metrics.SendQueueSize.Add(float64(-reported))
+			return
+		}
+	}
+}
+
+func chLabel(chID byte) string {
+	return fmt.Sprintf("%#x", chID)
+}
+
 func (p *peer) Close() error {
 	err := p.Stop()
 	return err
@@ -369,6 +414,7 @@ func createMConnection(
 			// which does onPeerError.
 			panic(cmn.Fmt("Unknown channel %X", chID))
 		}
+		p.metrics.ChannelReceiveBytesTotal.With("chID", chLabel(chID)).Add(float64(len(msgBytes)))
 		reactor.Receive(chID, p, msgBytes)
 	}
 
@@ -82,6 +82,16 @@ type Switch struct {
 	inboundMap     map[string]int //record connection num for single ip,only record public ip  key:ip
 	whitelist      *netutil.Netlist
 	blacklist      *netutil.Netlist
+
+	metrics *Metrics
+}
+
+// SwitchOption sets an optional parameter on the Switch.
+type SwitchOption func(*Switch)
+
+// WithMetrics sets the metrics.
+func WithMetrics(metrics *Metrics) SwitchOption {
+	return func(sw *Switch) { sw.metrics = metrics }
 }
 
 //TransNodeToEndpoint translate nodes to array of ip:port
@@ -95,7 +105,7 @@ func TransNodeToEndpoint(nodes []*common.Node) []string {
 
 // NewP2pManager creates a new Switch with the given config.
 func NewP2pManager(logger log.Logger, bootnodeAddr string, myPrivKey crypto.PrivKey, cfg *config.P2PConfig,
-	localNodeInfo NodeInfo, seeds []*common.Node, db dbm.DB) (*Switch, error) {
+	localNodeInfo NodeInfo, seeds []*common.Node, db dbm.DB, options ...SwitchOption) (*Switch, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("cfg is nil")
 	}
@@ -110,6 +120,10 @@ func NewP2pManager(logger log.Logger, bootnodeAddr string, myPrivKey crypto.Priv
 		rng:          cmn.NewRand(), // Ensure we have a completely undeterministic PRNG.
 		bootnodeAddr: bootnodeAddr,
 		inboundMap:   make(map[string]int),
+		metrics:      NopMetrics(),
+	}
+	for _, option := range options {
+		option(sw)
 	}
 
 	mConfig := conn.DefaultMConnConfig()
@@ -349,6 +363,7 @@ func (sw *Switch) OnStop() {
 		peer.Stop()
 		sw.peers.Remove(peer)
 	}
+	sw.metrics.Peers.Set(0)
 	// Stop reactors
 	sw.Logger.Debug("Switch: Stopping reactors")
 	for _, reactor := range sw.reactors {
@@ -459,6 +474,7 @@ func (sw *Switch) StopPeerForError(peer Peer, reason interface{}) {
 
 func (sw *Switch) stopAndRemovePeer(peer Peer, reason interface{}) {
 	sw.peers.Remove(peer)
+	sw.metrics.Peers.Set(float64(sw.peers.Size()))
 	err := peer.Stop()
 	if err != nil {
 		return
@@ -726,7 +742,7 @@ func (sw *Switch) addPeer(pc peerConn, isInCon bool) error {
 		return err
 	}
 
-	peer := newPeer(pc, sw.mConfig, peerNodeInfo, sw.reactorsByCh, sw.chDescs, sw.StopPeerForError)
+	peer := newPeer(pc, sw.mConfig, peerNodeInfo, sw.reactorsByCh, sw.chDescs, sw.StopPeerForError, sw.metrics)
 	peer.SetLogger(sw.Logger.With("peer", addr))
 
 	peer.Logger.Info("Successful handshake with peer", "peerNodeInfo", peerNodeInfo)
@@ -736,6 +752,7 @@ func (sw *Switch) addPeer(pc peerConn, isInCon bool) error {
 	if err := sw.peers.Add(peer); err != nil {
 		return err
 	}
+	sw.metrics.Peers.Set(float64(sw.peers.Size()))
 	if isInCon {
 		remoteIP := netutil.AddrIP(pc.conn.RemoteAddr())
 		sw.addInboundCon(remoteIP)
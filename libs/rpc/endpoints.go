@@ -18,12 +18,22 @@ package rpc
 
 import (
 	"net"
+	"net/http"
 
 	"github.com/lianxiangcloud/linkchain/libs/log"
 )
 
+// HTTPRoute is a plain HTTP handler served on the HTTP RPC endpoint besides the
+// JSON-RPC, e.g. the probes of the orchestrators. It is not restricted by the
+// cors and the vhosts.
+type HTTPRoute struct {
+	Path    string
+	Handler http.Handler
+}
+
 // StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
-func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string) (net.Listener, *Server, error) {
+// and the plain HTTP routes
+func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, routes ...HTTPRoute) (net.Listener, *Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -48,6 +58,14 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 		return nil, nil, err
 	}
 	svr := NewHTTPServer(cors, vhosts, handler)
+	if len(routes) > 0 {
+		mux := http.NewServeMux()
+		for _, route := range routes {
+			mux.Handle(route.Path, route.Handler)
+		}
+		mux.Handle("/", svr.Handler)
+		svr.Handler = mux
+	}
 	svr.SetKeepAlivesEnabled(true)
 	go svr.Serve(listener)
 	return listener, handler, err
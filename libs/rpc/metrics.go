@@ -0,0 +1,67 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Time of handling the requests, by method.
+	RequestSeconds metrics.Histogram
+	// Number of the requests failed, by method.
+	RequestErrors metrics.Counter
+	// Number of the active subscriptions, by namespace.
+	Subscriptions metrics.Gauge
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+func PrometheusMetrics() *Metrics {
+	return &Metrics{
+		RequestSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Subsystem: "rpc",
+			Name:      "request_seconds",
+			Help:      "Time of handling the requests, by method.",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+		}, []string{"method"}),
+		RequestErrors: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Subsystem: "rpc",
+			Name:      "request_errors",
+			Help:      "Number of the requests failed, by method.",
+		}, []string{"method"}),
+		Subscriptions: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Subsystem: "rpc",
+			Name:      "subscriptions",
+			Help:      "Number of the active subscriptions, by namespace.",
+		}, []string{"namespace"}),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		RequestSeconds: discard.NewHistogram(),
+		RequestErrors:  discard.NewCounter(),
+		Subscriptions:  discard.NewGauge(),
+	}
+}
+
+var rpcMetrics = NopMetrics()
+
+// SetMetrics sets the metrics of the rpc servers.
+func SetMetrics(metrics *Metrics) {
+	rpcMetrics = metrics
+}
+
+// observeRequest reports the time and the result of the request.
+func observeRequest(method string, start time.Time, failed bool) {
+	rpcMetrics.RequestSeconds.With("method", method).Observe(time.Since(start).Seconds())
+	if failed {
+		rpcMetrics.RequestErrors.With("method", method).Add(1)
+	}
+}
@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"testing"
+
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSubscriptionsMetrics(t *testing.T) {
+	subs := stdprometheus.NewGaugeVec(stdprometheus.GaugeOpts{Name: "subscriptions"}, []string{"namespace"})
+	metrics := NopMetrics()
+	metrics.Subscriptions = prometheus.NewGauge(subs)
+	SetMetrics(metrics)
+	defer SetMetrics(NopMetrics())
+
+	n := newNotifier(nil)
+	s1, s2, s3 := n.CreateSubscription(), n.CreateSubscription(), n.CreateSubscription()
+	n.activate(s1.ID, "eth")
+	n.activate(s2.ID, "eth")
+	n.activate(s3.ID, "lk")
+	if v := testutil.ToFloat64(subs.WithLabelValues("eth")); v != 2 {
+		t.Fatalf("eth subscriptions: want 2, got %v", v)
+	}
+
+	if err := n.unsubscribe(s1.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.unsubscribe(s1.ID); err != ErrSubscriptionNotFound {
+		t.Fatalf("unsubscribe twice: want %v, got %v", ErrSubscriptionNotFound, err)
+	}
+	if v := testutil.ToFloat64(subs.WithLabelValues("eth")); v != 1 {
+		t.Fatalf("eth subscriptions: want 1, got %v", v)
+	}
+
+	n.dropAll()
+	if v := testutil.ToFloat64(subs.WithLabelValues("eth")); v != 0 {
+		t.Fatalf("eth subscriptions after drop: want 0, got %v", v)
+	}
+	if v := testutil.ToFloat64(subs.WithLabelValues("lk")); v != 0 {
+		t.Fatalf("lk subscriptions after drop: want 0, got %v", v)
+	}
+}
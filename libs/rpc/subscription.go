@@ -115,6 +115,7 @@ func (n *Notifier) unsubscribe(id ID) error {
 	if s, found := n.active[id]; found {
 		close(s.err)
 		delete(n.active, id)
+		rpcMetrics.Subscriptions.With("namespace", s.namespace).Add(-1)
 		return nil
 	}
 	return ErrSubscriptionNotFound
@@ -131,5 +132,16 @@ func (n *Notifier) activate(id ID, namespace string) {
 		sub.namespace = namespace
 		n.active[id] = sub
 		delete(n.inactive, id)
+		rpcMetrics.Subscriptions.With("namespace", namespace).Add(1)
+	}
+}
+
+// dropAll forgets the active subscriptions when the connection is closed.
+func (n *Notifier) dropAll() {
+	n.subMu.Lock()
+	defer n.subMu.Unlock()
+	for id, sub := range n.active {
+		delete(n.active, id)
+		rpcMetrics.Subscriptions.With("namespace", sub.namespace).Add(-1)
 	}
 }
@@ -57,6 +57,7 @@ type service struct {
 type serverRequest struct {
 	id            interface{}
 	svcname       string
+	method        string // service and method name the request called
 	callb         *callback
 	args          []reflect.Value
 	isUnsubscribe bool
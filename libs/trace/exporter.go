@@ -0,0 +1,338 @@
+package trace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lianxiangcloud/linkchain/libs/log"
+)
+
+const (
+	queueSize     = 4096
+	batchSize     = 512
+	flushInterval = 2 * time.Second
+	exportTimeout = 10 * time.Second
+)
+
+// Exporter writes the finished spans out.
+type Exporter interface {
+	Export(spans []*Span) error
+	Close() error
+}
+
+// batcher queues the finished spans and exports them in batches, the spans are
+// dropped if the queue is full so tracing never blocks the node.
+type batcher struct {
+	exporter Exporter
+	queue    chan *Span
+	quit     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+	dropped  uint64 // atomic
+}
+
+func newBatcher(exporter Exporter) *batcher {
+	b := &batcher{
+		exporter: exporter,
+		queue:    make(chan *Span, queueSize),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go b.loop()
+	return b
+}
+
+func (b *batcher) add(s *Span) {
+	select {
+	case b.queue <- s:
+	default:
+		atomic.AddUint64(&b.dropped, 1)
+	}
+}
+
+func (b *batcher) stop() {
+	b.stopOnce.Do(func() {
+		close(b.quit)
+		<-b.done
+	})
+}
+
+func (b *batcher) loop() {
+	defer close(b.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := b.exporter.Export(batch); err != nil {
+			log.Warn("trace: export spans failed", "spans", len(batch), "err", err)
+		}
+		if dropped := atomic.SwapUint64(&b.dropped, 0); dropped > 0 {
+			log.Warn("trace: spans dropped for the full queue", "dropped", dropped)
+		}
+		batch = make([]*Span, 0, batchSize)
+	}
+	for {
+		select {
+		case s := <-b.queue:
+			batch = append(batch, s)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-b.quit:
+			for {
+				select {
+				case s := <-b.queue:
+					batch = append(batch, s)
+				default:
+					flush()
+					b.exporter.Close()
+					return
+				}
+			}
+		}
+	}
+}
+
+//-----------------------------------------------------------------------------
+
+// FileExporter writes the spans to a file, one JSON object per line.
+type FileExporter struct {
+	file *os.File
+	w    *bufio.Writer
+}
+
+type fileSpan struct {
+	Name       string                 `json:"name"`
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Start      time.Time              `json:"start"`
+	End        time.Time              `json:"end"`
+	DurationMs float64                `json:"duration_ms"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// NewFileExporter returns a FileExporter appending the spans to the file.
+func NewFileExporter(filename string) (*FileExporter, error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0664)
+	if err != nil {
+		return nil, err
+	}
+	return &FileExporter{file: f, w: bufio.NewWriter(f)}, nil
+}
+
+// Export implements Exporter.
+func (e *FileExporter) Export(spans []*Span) error {
+	enc := json.NewEncoder(e.w)
+	for _, s := range spans {
+		fs := fileSpan{
+			Name:       s.Name,
+			TraceID:    s.TraceID.String(),
+			SpanID:     s.SpanID.String(),
+			Start:      s.Start,
+			End:        s.End,
+			DurationMs: float64(s.End.Sub(s.Start)) / float64(time.Millisecond),
+			Error:      s.Err,
+		}
+		if !s.ParentID.IsZero() {
+			fs.ParentID = s.ParentID.String()
+		}
+		if len(s.Attributes) > 0 {
+			fs.Attributes = make(map[string]interface{}, len(s.Attributes))
+			for _, attr := range s.Attributes {
+				fs.Attributes[attr.Key] = attributeValue(attr.Value)
+			}
+		}
+		if err := enc.Encode(&fs); err != nil {
+			return err
+		}
+	}
+	return e.w.Flush()
+}
+
+// Close implements Exporter.
+func (e *FileExporter) Close() error {
+	e.w.Flush()
+	return e.file.Close()
+}
+
+//-----------------------------------------------------------------------------
+
+// OTLPExporter posts the spans to an OpenTelemetry collector by OTLP/HTTP with
+// the JSON encoding, e.g. to http://127.0.0.1:4318/v1/traces.
+type OTLPExporter struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+}
+
+// NewOTLPExporter returns an OTLPExporter posting to endpoint, the spans are
+// reported as of the service.
+func NewOTLPExporter(endpoint string, serviceName string) *OTLPExporter {
+	return &OTLPExporter{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: exportTimeout},
+	}
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+const (
+	otlpSpanKindInternal = 1
+	otlpStatusError      = 2
+)
+
+// Export implements Exporter.
+func (e *OTLPExporter) Export(spans []*Span) error {
+	scope := otlpScopeSpans{Spans: make([]otlpSpan, 0, len(spans))}
+	scope.Scope.Name = "github.com/lianxiangcloud/linkchain/libs/trace"
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           s.TraceID.String(),
+			SpanID:            s.SpanID.String(),
+			Name:              s.Name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+		}
+		if !s.ParentID.IsZero() {
+			span.ParentSpanID = s.ParentID.String()
+		}
+		for _, attr := range s.Attributes {
+			span.Attributes = append(span.Attributes, otlpAttr(attr.Key, attr.Value))
+		}
+		if s.Err != "" {
+			span.Status = &otlpStatus{Code: otlpStatusError, Message: s.Err}
+		}
+		scope.Spans = append(scope.Spans, span)
+	}
+	resource := otlpResourceSpans{ScopeSpans: []otlpScopeSpans{scope}}
+	resource.Resource.Attributes = []otlpAttribute{otlpAttr("service.name", e.serviceName)}
+
+	body, err := json.Marshal(&otlpRequest{ResourceSpans: []otlpResourceSpans{resource}})
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("otlp collector returns %s: %s", resp.Status, msg)
+	}
+	return nil
+}
+
+// Close implements Exporter.
+func (e *OTLPExporter) Close() error {
+	return nil
+}
+
+func otlpAttr(key string, value interface{}) otlpAttribute {
+	attr := otlpAttribute{Key: key}
+	switch v := attributeValue(value).(type) {
+	case bool:
+		attr.Value.BoolValue = &v
+	case int:
+		s := strconv.FormatInt(int64(v), 10)
+		attr.Value.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		attr.Value.IntValue = &s
+	case uint64:
+		s := strconv.FormatUint(v, 10)
+		attr.Value.IntValue = &s
+	case float64:
+		attr.Value.DoubleValue = &v
+	case string:
+		attr.Value.StringValue = &v
+	}
+	return attr
+}
+
+// attributeValue converts the value to bool, int, int64, uint64, float64 or
+// string.
+func attributeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bool, int, int64, uint64, float64, string:
+		return v
+	case int32:
+		return int64(v)
+	case uint:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case float32:
+		return float64(v)
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
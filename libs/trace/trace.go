@@ -0,0 +1,254 @@
+// Package trace records the spans of the txs from the rpc to the commit of the
+// block, and exports them to an OTLP collector or a local file.
+//
+// The trace ID of a tx is taken from its hash, so the spans of a tx started in
+// different goroutines, or even on different nodes, belong to the same trace
+// without carrying a context around. The first span of a tx on the node is the
+// root, the later spans of the tx are its children.
+package trace
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+)
+
+const (
+	// maxRoots is the number of the root spans remembered to parent the later
+	// spans of the txs.
+	maxRoots = 100000
+)
+
+// TraceID identifies a trace, the first 16 bytes of the tx hash.
+type TraceID [16]byte
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID identifies a span in the trace.
+type SpanID [8]byte
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// IsZero returns whether the span id is not set.
+func (s SpanID) IsZero() bool {
+	return s == SpanID{}
+}
+
+// TxTraceID returns the trace id of the tx.
+func TxTraceID(hash common.Hash) TraceID {
+	var id TraceID
+	copy(id[:], hash[:])
+	return id
+}
+
+// Attribute is a key-value pair of the span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span is an operation on a tx, it is nil if tracing is disabled or the tx is
+// not sampled, and all the methods of a nil span do nothing.
+type Span struct {
+	tracer *Tracer
+
+	Name       string
+	TraceID    TraceID
+	SpanID     SpanID
+	ParentID   SpanID
+	Start      time.Time
+	End        time.Time
+	Attributes []Attribute
+	Err        string
+}
+
+// SetAttributes adds the key-value pairs to the span, in the form of the log
+// context, e.g. SetAttributes("height", 10, "peer", id).
+func (s *Span) SetAttributes(kv ...interface{}) {
+	if s == nil {
+		return
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprintf("%v", kv[i])
+		}
+		s.Attributes = append(s.Attributes, Attribute{Key: key, Value: kv[i+1]})
+	}
+}
+
+// SetError marks the span as failed if err is not nil.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.Err = err.Error()
+}
+
+// Finish ends the span and sends it to the exporter.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	s.End = time.Now()
+	s.tracer.export(s)
+}
+
+// Spans are the spans of an operation on many txs, e.g. the txs of a block.
+type Spans []*Span
+
+// SetAttributes adds the key-value pairs to all the spans.
+func (ss Spans) SetAttributes(kv ...interface{}) {
+	for _, s := range ss {
+		s.SetAttributes(kv...)
+	}
+}
+
+// SetError marks all the spans as failed if err is not nil.
+func (ss Spans) SetError(err error) {
+	for _, s := range ss {
+		s.SetError(err)
+	}
+}
+
+// Finish ends all the spans.
+func (ss Spans) Finish() {
+	now := time.Now()
+	for _, s := range ss {
+		s.End = now
+		s.tracer.export(s)
+	}
+}
+
+// Tracer starts the spans and sends the finished ones to the exporter.
+type Tracer struct {
+	threshold uint64 // traces with the low 8 bytes of id below it are sampled
+	exporter  *batcher
+
+	mtx   sync.Mutex
+	rand  *rand.Rand
+	roots map[TraceID]SpanID
+	order []TraceID // ring of the roots to evict the oldest
+	next  int
+}
+
+// NewTracer returns a Tracer exporting the spans by exporter, it samples the
+// ratio of the txs, 1 for all.
+func NewTracer(exporter Exporter, ratio float64) *Tracer {
+	var threshold uint64 = math.MaxUint64
+	if ratio < 1 {
+		threshold = uint64(ratio * math.MaxUint64)
+	}
+	return &Tracer{
+		threshold: threshold,
+		exporter:  newBatcher(exporter),
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		roots:     make(map[TraceID]SpanID),
+		order:     make([]TraceID, maxRoots),
+	}
+}
+
+// StartTx starts the span of the tx. The key-value pairs are set as the
+// attributes.
+func (t *Tracer) StartTx(hash common.Hash, name string, kv ...interface{}) *Span {
+	if t == nil {
+		return nil
+	}
+	traceID := TxTraceID(hash)
+	if binary.BigEndian.Uint64(traceID[8:]) > t.threshold {
+		return nil
+	}
+
+	span := &Span{tracer: t, Name: name, TraceID: traceID, Start: time.Now()}
+	t.mtx.Lock()
+	t.rand.Read(span.SpanID[:])
+	if root, ok := t.roots[traceID]; ok {
+		span.ParentID = root
+	} else {
+		if old := t.order[t.next]; old != (TraceID{}) {
+			delete(t.roots, old)
+		}
+		t.order[t.next] = traceID
+		t.next = (t.next + 1) % len(t.order)
+		t.roots[traceID] = span.SpanID
+	}
+	t.mtx.Unlock()
+
+	span.SetAttributes("tx_hash", hash.Hex())
+	span.SetAttributes(kv...)
+	return span
+}
+
+// StartTxs starts the spans of the txs for an operation on all of them.
+func (t *Tracer) StartTxs(hashes []common.Hash, name string, kv ...interface{}) Spans {
+	return t.StartTxsAt(time.Now(), hashes, name, kv...)
+}
+
+// StartTxsAt starts the spans of the txs at start, for the operation which
+// knows the txs only when it is done, e.g. reaping the txs of a block.
+func (t *Tracer) StartTxsAt(start time.Time, hashes []common.Hash, name string, kv ...interface{}) Spans {
+	if t == nil {
+		return nil
+	}
+	spans := make(Spans, 0, len(hashes))
+	for _, hash := range hashes {
+		if span := t.StartTx(hash, name, kv...); span != nil {
+			span.Start = start
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// Stop flushes the spans and stops the exporter.
+func (t *Tracer) Stop() {
+	if t == nil {
+		return
+	}
+	t.exporter.stop()
+}
+
+func (t *Tracer) export(s *Span) {
+	t.exporter.add(s)
+}
+
+//-----------------------------------------------------------------------------
+
+var global *Tracer
+
+// SetTracer sets the tracer used by StartTx and StartTxs, nil disables tracing.
+// It should be called before the node starts.
+func SetTracer(t *Tracer) {
+	global = t
+}
+
+// Enabled returns whether tracing is enabled, so the callers can skip
+// collecting the tx hashes.
+func Enabled() bool {
+	return global != nil
+}
+
+// StartTx starts the span of the tx by the global tracer.
+func StartTx(hash common.Hash, name string, kv ...interface{}) *Span {
+	return global.StartTx(hash, name, kv...)
+}
+
+// StartTxs starts the spans of the txs by the global tracer.
+func StartTxs(hashes []common.Hash, name string, kv ...interface{}) Spans {
+	return global.StartTxs(hashes, name, kv...)
+}
+
+// StartTxsAt starts the spans of the txs at start by the global tracer.
+func StartTxsAt(start time.Time, hashes []common.Hash, name string, kv ...interface{}) Spans {
+	return global.StartTxsAt(start, hashes, name, kv...)
+}
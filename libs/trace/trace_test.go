@@ -0,0 +1,136 @@
+package trace
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+)
+
+func TestTracerParent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "traces.json")
+	exporter, err := NewFileExporter(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer := NewTracer(exporter, 1)
+
+	hash := common.HexToHash("0x6f2a0e3bd2cfb4d3a8cb8f3d8a3c1e2f1f8b1e2c3d4e5f60718293a4b5c6d7e8")
+	root := tracer.StartTx(hash, "rpc.SendRawTx")
+	child := tracer.StartTx(hash, "mempool.AddTx", "peer", "")
+	blocks := tracer.StartTxs([]common.Hash{hash}, "app.CommitBlock", "height", uint64(10))
+	child.Finish()
+	blocks.Finish()
+	root.Finish()
+	tracer.Stop()
+
+	if !root.ParentID.IsZero() {
+		t.Fatalf("root span has parent %s", root.ParentID)
+	}
+	if child.ParentID != root.SpanID || blocks[0].ParentID != root.SpanID {
+		t.Fatalf("want parent %s, got %s and %s", root.SpanID, child.ParentID, blocks[0].ParentID)
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var spans []fileSpan
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s fileSpan
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			t.Fatal(err)
+		}
+		spans = append(spans, s)
+	}
+	if len(spans) != 3 {
+		t.Fatalf("want 3 spans in the file, got %d", len(spans))
+	}
+	for _, s := range spans {
+		if s.TraceID != TxTraceID(hash).String() {
+			t.Fatalf("span %s: want trace id %s, got %s", s.Name, TxTraceID(hash), s.TraceID)
+		}
+		if s.Attributes["tx_hash"] != hash.Hex() {
+			t.Fatalf("span %s: want tx_hash %s, got %v", s.Name, hash.Hex(), s.Attributes["tx_hash"])
+		}
+	}
+}
+
+func TestTracerSample(t *testing.T) {
+	tracer := NewTracer(NewOTLPExporter("http://127.0.0.1:0", "test"), 0.5)
+	defer tracer.Stop()
+
+	var low, high common.Hash
+	low[8] = 0x10
+	high[8] = 0xf0
+	if tracer.StartTx(low, "sampled") == nil {
+		t.Fatal("low trace id should be sampled")
+	}
+	if tracer.StartTx(high, "dropped") != nil {
+		t.Fatal("high trace id should not be sampled")
+	}
+
+	var nilTracer *Tracer
+	span := nilTracer.StartTx(low, "disabled")
+	span.SetAttributes("height", 1)
+	span.SetError(os.ErrClosed)
+	span.Finish()
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var req otlpRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}))
+	defer collector.Close()
+
+	tracer := NewTracer(NewOTLPExporter(collector.URL+"/v1/traces", "linkchain"), 1)
+	var hash common.Hash
+	hash[0] = 1
+	span := tracer.StartTx(hash, "state.Process", "height", uint64(7))
+	span.SetError(os.ErrClosed)
+	span.Finish()
+	tracer.Stop()
+
+	if len(req.ResourceSpans) != 1 || len(req.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("want 1 span, got %d", len(spans))
+	}
+	s := spans[0]
+	if s.Name != "state.Process" || s.TraceID != TxTraceID(hash).String() || s.Status == nil || s.Status.Code != otlpStatusError {
+		t.Fatalf("unexpected span: %+v", s)
+	}
+	var height string
+	for _, attr := range s.Attributes {
+		if attr.Key == "height" && attr.Value.IntValue != nil {
+			height = *attr.Value.IntValue
+		}
+	}
+	if height != "7" {
+		t.Fatalf("want height 7, got %q", height)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/lianxiangcloud/linkchain/libs/log"
 	"github.com/lianxiangcloud/linkchain/libs/p2p"
 	"github.com/lianxiangcloud/linkchain/libs/ser"
+	"github.com/lianxiangcloud/linkchain/libs/trace"
 	"github.com/lianxiangcloud/linkchain/types"
 )
 
@@ -107,7 +108,8 @@ type Mempool struct {
 	// This reduces the pressure on the proxyApp.
 	cache txCache
 
-	logger log.Logger
+	logger   log.Logger
+	txLogger log.Logger // logger of adding txs, sampled if configured
 
 	metrics *Metrics
 
@@ -137,6 +139,7 @@ func NewMempool(config *cfg.MempoolConfig, height uint64, sw p2p.P2PManager, opt
 		sw:              sw,
 		broadcastTxChan: make(chan *RecieveMessage, config.BroadcastChanSize),
 		logger:          log.NewNopLogger(),
+		txLogger:        log.NewNopLogger(),
 		metrics:         NopMetrics(),
 		quit:            make(chan bool),
 		sem:             sem,
@@ -248,6 +251,10 @@ func (mem *Mempool) SetApp(a App) {
 // SetLogger sets the Logger.
 func (mem *Mempool) SetLogger(l log.Logger) {
 	mem.logger = l
+	mem.txLogger = l
+	if mem.config.LogSampleFirst > 0 {
+		mem.txLogger = log.NewSamplingLogger(l, time.Second, mem.config.LogSampleFirst, mem.config.LogSampleThereafter)
+	}
 }
 
 // WithMetrics sets the metrics.
@@ -309,7 +316,7 @@ func (mem *Mempool) addUTXOTx(tx types.Tx) (err error) {
 }
 
 func (mem *Mempool) addLocalTx(tx types.Tx) (err error) {
-	log.Debug("addLocalTx", "hash", tx.Hash())
+	mem.txLogger.Debug("addLocalTx", "hash", tx.Hash())
 	if err = mem.app.CheckTx(tx, StateCheck); err == nil {
 		if mem.goodTxs.Len() < mem.config.Size {
 			mem.addGoodTx(tx, true)
@@ -323,7 +330,7 @@ func (mem *Mempool) addLocalTx(tx types.Tx) (err error) {
 		}
 		err = mem.addFutureTx(tx)
 	} else {
-		mem.logger.Warn("addLocalTx", "CheckTx failed", err, "txHash", tx.Hash().Hex())
+		mem.txLogger.Warn("addLocalTx", "CheckTx failed", err, "txHash", tx.Hash().Hex())
 	}
 	return err
 }
@@ -337,7 +344,7 @@ func (mem *Mempool) addLocalSpecTx(tx types.Tx) (err error) {
 		}
 	} else {
 		if err != types.ErrNonceTooHigh {
-			mem.logger.Warn("addLocalSpecTx", "CheckSpecTx failed", err, "tx", tx.TypeName(), "txHash", tx.Hash())
+			mem.txLogger.Warn("addLocalSpecTx", "CheckSpecTx failed", err, "tx", tx.TypeName(), "txHash", tx.Hash())
 		}
 	}
 	return err
@@ -345,6 +352,12 @@ func (mem *Mempool) addLocalSpecTx(tx types.Tx) (err error) {
 
 //AddTx add good txs in a concurrent linked-list
 func (mem *Mempool) AddTx(peerID string, tx types.Tx) (err error) {
+	span := trace.StartTx(tx.Hash(), "mempool.AddTx", "peer", peerID)
+	defer func() {
+		span.SetError(err)
+		span.Finish()
+	}()
+
 	// CACHE
 	if !mem.cache.Push(tx) {
 		return types.ErrTxDuplicate
@@ -390,7 +403,7 @@ func (mem *Mempool) AddTx(peerID string, tx types.Tx) (err error) {
 		select {
 		case mem.broadcastTxChan <- &RecieveMessage{PeerID: peerID, Tx: tx}:
 		default:
-			mem.logger.Info("broadcastTxChan is full", "size", mem.config.BroadcastChanSize, "hash", tx.Hash().Hex())
+			mem.txLogger.Info("broadcastTxChan is full", "size", mem.config.BroadcastChanSize, "hash", tx.Hash().Hex())
 		}
 	}
 	return err
@@ -401,7 +414,7 @@ func (mem *Mempool) add(data interface{}) (err error) {
 	case *RecieveMessage:
 		err = mem.AddTx(v.PeerID, v.Tx)
 		if err != nil && err != types.ErrTxDuplicate && err != types.ErrMempoolIsFull {
-			mem.logger.Error("mempool add data from peers failed", "err", err, "v", v.Tx.Hash())
+			mem.txLogger.Error("mempool add data from peers failed", "err", err, "v", v.Tx.Hash())
 		}
 	}
 	return err
@@ -442,6 +455,9 @@ func (mem *Mempool) broadcastTxRoutine() {
 }
 
 func defaultBroadcastTx(peerID string, tx types.Tx, sw p2p.P2PManager, logger log.Logger) {
+	span := trace.StartTx(tx.Hash(), "mempool.Broadcast", "peers", sw.Peers().Size())
+	defer span.Finish()
+
 	msg := &TxMessage{Tx: tx}
 	logger.Debug("mempool broadcast tx to peers", "hash", tx.Hash().String(), "peers", sw.Peers().List())
 	data, err := ser.EncodeToBytesWithType(msg)
@@ -462,7 +478,7 @@ func (mem *Mempool) addSpecGoodTx(tx types.Tx) {
 	addtime := time.Now()
 	memTx := &mempoolTx{tx: tx, addtime: &addtime}
 	mem.specGoodTxs.PushBack(memTx)
-	mem.logger.Debug("Added Specgood transaction", "tx", tx.Hash().Hex(), "type", tx.TypeName())
+	mem.txLogger.Debug("Added Specgood transaction", "tx", tx.Hash().Hex(), "type", tx.TypeName())
 	mem.notifyTxsAvailable()
 }
 
@@ -470,7 +486,7 @@ func (mem *Mempool) addSpecGoodTx(tx types.Tx) {
 func (mem *Mempool) addGoodTx(tx types.Tx, promote bool) {
 	memTx := &mempoolTx{tx: tx}
 	mem.goodTxs.PushBack(memTx)
-	mem.logger.Debug("Added good transaction", "tx", tx.Hash().Hex(), "type", tx.TypeName())
+	mem.txLogger.Debug("Added good transaction", "tx", tx.Hash().Hex(), "type", tx.TypeName())
 	mem.metrics.Size.Set(float64(mem.GoodTxsSize()))
 	mem.notifyTxsAvailable()
 
@@ -490,10 +506,10 @@ func (mem *Mempool) addTofutureTxs(from common.Address, tx types.RegularTx) erro
 
 	inserted, _ := mem.futureTxs[from].Add(tx)
 	if !inserted {
-		mem.logger.Warn("futureTxs Add tx duplicate cached", "txNonce", tx.Nonce(), "txHash", tx.Hash())
+		mem.txLogger.Warn("futureTxs Add tx duplicate cached", "txNonce", tx.Nonce(), "txHash", tx.Hash())
 		return types.ErrTxDuplicate
 	}
-	mem.logger.Debug("Added future transaction", "tx", tx.Hash().Hex(), "type", tx.TypeName(), "from", from.Hex(), "nonce", tx.Nonce())
+	mem.txLogger.Debug("Added future transaction", "tx", tx.Hash().Hex(), "type", tx.TypeName(), "from", from.Hex(), "nonce", tx.Nonce())
 	if mem.config.RemoveFutureTx {
 		mem.futureTxsCount = mem.removeFutureTxs()
 	}
@@ -668,6 +684,7 @@ func (mem *Mempool) notifyTxsAvailable() {
 // Reap returns a list of transactions currently in the mempool.
 // If maxTxs is -1, there is no cap on the number of returned transactions.
 func (mem *Mempool) Reap(maxTxs int) types.Txs {
+	start := time.Now()
 	mem.proxyMtx.Lock()
 	defer mem.proxyMtx.Unlock()
 
@@ -690,6 +707,9 @@ func (mem *Mempool) Reap(maxTxs int) types.Txs {
 	txs := mem.collectTxs(mem.goodTxs, maxTxs)
 	mem.logger.Debug("Reap end", "specTxs", len(specTxs), "txsLen", len(txs), "maxTxs", maxTxs)
 	txs = append(txs, specTxs...)
+	if trace.Enabled() {
+		trace.StartTxsAt(start, txs.Hashes(), "mempool.Reap").Finish()
+	}
 	return txs
 }
 
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeNode serves the rpc of a chain validated by AA and BB, BB does not
+// sign the odd heights.
+func fakeNode(t *testing.T, height *uint64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64   `json:"id"`
+			Method string   `json:"method"`
+			Params []string `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+			return
+		}
+		var result string
+		switch req.Method {
+		case "eth_blockNumber":
+			result = fmt.Sprintf(`"0x%x"`, *height)
+		case "eth_validators":
+			result = `{"block_height":"1","validators":[{"address":"AA","voting_power":"10"},{"address":"BB","voting_power":"10"}]}`
+		case "debug_getBlockBinary":
+			h, _ := strconv.ParseUint(strings.TrimPrefix(req.Params[0], "0x"), 16, 64)
+			b := `{"validator_address":"BB","height":"1"}`
+			if (h-1)%2 == 1 {
+				b = `null`
+			}
+			result = fmt.Sprintf(`{"block":{"last_commit":{"precommits":[{"validator_address":"AA","height":"1"},%s]}}}`, b)
+		default:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"method not found"}}`, req.ID)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, result)
+	}))
+}
+
+func sampleValue(t *testing.T, samples []sample, name string, labels ...string) float64 {
+	for _, s := range findSamples(samples, name) {
+		match := true
+		for i := 0; i+1 < len(labels); i += 2 {
+			match = match && s.Labels[labels[i]] == labels[i+1]
+		}
+		if match {
+			return s.Value
+		}
+	}
+	t.Fatalf("sample %s%v not found", name, labels)
+	return 0
+}
+
+func TestMissedBlocks(t *testing.T) {
+	height := uint64(11)
+	node := fakeNode(t, &height)
+	defer node.Close()
+
+	a := &agent{configs: &lkBlockAgentConfigs{NodeRPCUrl: node.URL}, node: newRPCClient(node.URL)}
+	a.configs.Alerts.MissedBlocks = 3
+	a.configs.Alerts.MissedBlocksWindow = 6
+	c, err := newMissedBlocksCollector(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newAlertManager(&a.configs.Alerts)
+
+	// the commits of the heights 5-10 are checked, B missed 5, 7 and 9
+	samples, err := c.Collect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := sampleValue(t, samples, namespace+"_commit_checked_height"); v != 10 {
+		t.Fatalf("want checked height 10, got %v", v)
+	}
+	if v := sampleValue(t, samples, namespace+"_validator_missed_blocks", "validator", "AA"); v != 0 {
+		t.Fatalf("want AA missed 0, got %v", v)
+	}
+	if v := sampleValue(t, samples, namespace+"_validator_missed_blocks", "validator", "BB"); v != 3 {
+		t.Fatalf("want BB missed 3, got %v", v)
+	}
+	alerts := m.eval(samples, time.Now())
+	if len(alerts) != 1 || alerts[0].Labels["validator"] != "BB" {
+		t.Fatalf("want alert of BB, got %v", alerts)
+	}
+
+	// 5 drops out of the window
+	height = 13
+	if samples, err = c.Collect(); err != nil {
+		t.Fatal(err)
+	}
+	if v := sampleValue(t, samples, namespace+"_validator_missed_blocks", "validator", "BB"); v != 3 {
+		t.Fatalf("want BB missed 3, got %v", v)
+	}
+	if v := sampleValue(t, samples, namespace+"_validator_missed_blocks_total", "validator", "BB"); v != 4 {
+		t.Fatalf("want BB missed 4 in total, got %v", v)
+	}
+	if v := sampleValue(t, samples, namespace+"_validator_signed", "validator", "BB"); v != 1 {
+		t.Fatalf("want BB signed height 12, got %v", v)
+	}
+}
+
+func TestStalledHeight(t *testing.T) {
+	conf := &alertConfigs{}
+	conf.StalledHeight.Duration = time.Minute
+	m := newAlertManager(conf)
+
+	now := time.Now()
+	height := []sample{newSample(namespace+"_node_height", 10)}
+	if alerts := m.eval(height, now); len(alerts) != 0 {
+		t.Fatalf("want no alert, got %v", alerts)
+	}
+	// the node is down
+	if alerts := m.eval(nil, now.Add(time.Minute)); len(alerts) != 1 || alerts[0].Labels["alertname"] != "StalledHeight" {
+		t.Fatalf("want StalledHeight, got %v", alerts)
+	}
+	height[0].Value = 11
+	if alerts := m.eval(height, now.Add(2*time.Minute)); len(alerts) != 0 {
+		t.Fatalf("want resolved, got %v", alerts)
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	samples := []sample{
+		newSample("b_metric", 2, "db", "state"),
+		newSample("a_metric", 1.5, "label", "say \"hi\"\n"),
+		newSample("b_metric", 3, "db", "utxo"),
+	}
+	var buf bytes.Buffer
+	if err := writeText(&buf, samples, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "a_metric{label=\"say \\\"hi\\\"\\n\"} 1.5\nb_metric{db=\"state\"} 2\nb_metric{db=\"utxo\"} 3\n"
+	if buf.String() != want {
+		t.Fatalf("want\n%s\ngot\n%s", want, buf.String())
+	}
+
+	parsed, err := parseText("# TYPE a_metric gauge\n" + buf.String() + "c_metric 7 1600000000000\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 4 || parsed[0].Labels["label"] != "say \"hi\"\n" || parsed[3].Name != "c_metric" || parsed[3].Value != 7 {
+		t.Fatalf("unexpected samples: %+v", parsed)
+	}
+}
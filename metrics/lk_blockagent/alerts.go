@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// alert is a firing alert of a rule.
+type alert struct {
+	Name        string            `json:"alertname"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Description string            `json:"description"`
+}
+
+// key identifies the alert across the evaluations.
+func (a *alert) key() string {
+	names := make([]string, 0, len(a.Labels))
+	for name := range a.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(a.Name)
+	for _, name := range names {
+		fmt.Fprintf(&b, ",%s=%s", name, a.Labels[name])
+	}
+	return b.String()
+}
+
+// alertRule evaluates the collected samples and returns the firing alerts.
+type alertRule interface {
+	Eval(samples []sample, now time.Time) []alert
+}
+
+// findSamples returns the samples of the metric.
+func findSamples(samples []sample, name string) []*sample {
+	var found []*sample
+	for i := range samples {
+		if samples[i].Name == name {
+			found = append(found, &samples[i])
+		}
+	}
+	return found
+}
+
+//-----------------------------------------------------------------------------
+
+// stalledHeightRule fires if the height of the node does not grow in the
+// duration. It keeps firing while the node is down.
+type stalledHeightRule struct {
+	duration time.Duration
+
+	height  float64
+	changed time.Time
+}
+
+func (r *stalledHeightRule) Eval(samples []sample, now time.Time) []alert {
+	if heights := findSamples(samples, namespace+"_node_height"); len(heights) != 0 {
+		if h := heights[0].Value; r.changed.IsZero() || h != r.height {
+			r.height, r.changed = h, now
+		}
+	}
+	if r.changed.IsZero() || now.Sub(r.changed) < r.duration {
+		return nil
+	}
+	return []alert{{
+		Name:        "StalledHeight",
+		Description: fmt.Sprintf("height %d not grown for %v", uint64(r.height), now.Sub(r.changed).Round(time.Second)),
+	}}
+}
+
+// missedSignaturesRule fires for the validators missing the signatures of too
+// many blocks in the window of the missed_blocks collector.
+type missedSignaturesRule struct {
+	threshold  int
+	window     int
+	validators map[string]bool // all the validators if empty
+}
+
+func (r *missedSignaturesRule) Eval(samples []sample, now time.Time) []alert {
+	var alerts []alert
+	for _, s := range findSamples(samples, namespace+"_validator_missed_blocks") {
+		addr := s.Labels["validator"]
+		if len(r.validators) != 0 && !r.validators[addr] {
+			continue
+		}
+		if int(s.Value) < r.threshold {
+			continue
+		}
+		alerts = append(alerts, alert{
+			Name:        "MissedSignatures",
+			Labels:      map[string]string{"validator": addr},
+			Description: fmt.Sprintf("validator %s missed %d of the last %d blocks", addr, int(s.Value), r.window),
+		})
+	}
+	return alerts
+}
+
+//-----------------------------------------------------------------------------
+
+// alertManager evaluates the alert rules, logs and notifies the webhook of the
+// firing and the resolved alerts.
+type alertManager struct {
+	rules   []alertRule
+	webhook string
+	client  *http.Client
+	firing  map[string]alert
+}
+
+func newAlertManager(conf *alertConfigs) *alertManager {
+	m := &alertManager{
+		webhook: conf.Webhook,
+		client:  &http.Client{Timeout: requestTimeout},
+		firing:  make(map[string]alert),
+	}
+	if conf.StalledHeight.Duration > 0 {
+		m.rules = append(m.rules, &stalledHeightRule{duration: conf.StalledHeight.Duration})
+	}
+	if conf.MissedBlocks > 0 {
+		rule := &missedSignaturesRule{
+			threshold:  conf.MissedBlocks,
+			window:     conf.MissedBlocksWindow,
+			validators: make(map[string]bool),
+		}
+		for _, addr := range conf.Validators {
+			rule.validators[strings.ToUpper(strings.TrimPrefix(addr, "0x"))] = true
+		}
+		m.rules = append(m.rules, rule)
+	}
+	return m
+}
+
+// eval evaluates the rules on the samples and returns the samples of the firing
+// alerts.
+func (m *alertManager) eval(samples []sample, now time.Time) []sample {
+	firing := make(map[string]alert)
+	for _, rule := range m.rules {
+		for _, a := range rule.Eval(samples, now) {
+			firing[a.key()] = a
+		}
+	}
+
+	for key, a := range firing {
+		if _, ok := m.firing[key]; !ok {
+			log.Error("alert firing", "alert", a.Name, "labels", a.Labels, "description", a.Description)
+			m.notify("firing", a, now)
+		}
+	}
+	for key, a := range m.firing {
+		if _, ok := firing[key]; !ok {
+			log.Info("alert resolved", "alert", a.Name, "labels", a.Labels)
+			m.notify("resolved", a, now)
+		}
+	}
+	m.firing = firing
+
+	var alertSamples []sample
+	for _, a := range firing {
+		s := newSample(namespace+"_alert_firing", 1, "alertname", a.Name)
+		for name, value := range a.Labels {
+			s.Labels[name] = value
+		}
+		alertSamples = append(alertSamples, s)
+	}
+	return alertSamples
+}
+
+type alertNotification struct {
+	alert
+	Status string    `json:"status"`
+	Time   time.Time `json:"time"`
+}
+
+// notify posts the alert to the webhook.
+func (m *alertManager) notify(status string, a alert, now time.Time) {
+	if len(m.webhook) == 0 {
+		return
+	}
+	body, err := json.Marshal(&alertNotification{alert: a, Status: status, Time: now})
+	if err != nil {
+		log.Error("marshal alert failed", "err", err)
+		return
+	}
+	resp, err := m.client.Post(m.webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Error("notify alert failed", "alert", a.Name, "err", err)
+		return
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		log.Error("notify alert failed", "alert", a.Name, "err", err)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	succ = 0
+)
+
+type endPoint struct {
+	IP   []string       `json:"ip"`
+	Port map[string]int `json:"port"`
+}
+
+type node struct {
+	Type     int      `json:"type,omitempty"`
+	HostName string   `json:"hostname,omitempty"`
+	PubKey   string   `json:"pubkey,omitempty"`
+	EndPoint endPoint `json:"endpoint"`
+}
+
+type bootSvrEndPoints struct {
+	Code  int    `json:"code,omitempty"`
+	Nodes []node `json:"nodes"`
+}
+
+// parseNodeTypes parses the comma separated node types.
+func parseNodeTypes(s string) ([]int, error) {
+	var nodeTypes []int
+	for _, nodeTypeStr := range strings.Split(s, ",") {
+		nodeTypeStr = strings.TrimSpace(nodeTypeStr)
+		if len(nodeTypeStr) == 0 {
+			continue
+		}
+		nodeType, err := strconv.Atoi(nodeTypeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ForeachNodeType %q: %v", nodeTypeStr, err)
+		}
+		nodeTypes = append(nodeTypes, nodeType)
+	}
+	return nodeTypes, nil
+}
+
+// getConfigCenterData requests the nodes of the chain from the config center.
+func getConfigCenterData(url string) (*bootSvrEndPoints, error) {
+	client := &http.Client{Timeout: requestTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewBuffer(nil))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	endpointBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var endpoints bootSvrEndPoints
+	if err := json.Unmarshal(endpointBytes, &endpoints); err != nil {
+		return nil, errors.Wrap(err, "json unmarshal bootSvrEndPoints failed")
+	}
+	if endpoints.Code != succ {
+		return nil, fmt.Errorf("request bootSvrEndPoints failed, code %d", endpoints.Code)
+	}
+	return &endpoints, nil
+}
+
+// rpcURLs returns the http rpc addresses of the nodes of the types, all the
+// types if nodeTypes is empty. The addresses of the ip skip are skipped.
+func (b *bootSvrEndPoints) rpcURLs(nodeTypes []int, skip string) []string {
+	var urls []string
+	for _, n := range b.Nodes {
+		if len(nodeTypes) != 0 && !containsInt(nodeTypes, n.Type) {
+			continue
+		}
+		port, ok := n.EndPoint.Port["http"]
+		if !ok {
+			continue
+		}
+		for _, ip := range n.EndPoint.IP {
+			if len(skip) != 0 && strings.Contains(ip, skip) {
+				continue
+			}
+			urls = append(urls, fmt.Sprintf("http://%s:%d", ip, port))
+		}
+	}
+	return urls
+}
+
+func containsInt(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
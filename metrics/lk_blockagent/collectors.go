@@ -0,0 +1,516 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
+	"github.com/pkg/errors"
+)
+
+// collector collects a kind of the metrics.
+type collector interface {
+	Name() string
+	Collect() ([]sample, error)
+}
+
+// errNotConfigured is returned by the collector creators if the configs the
+// collector requires are missing.
+var errNotConfigured = errors.New("not configured")
+
+// collectorCreators are the collectors by name. The collectors enabled by
+// default are all the ones configured.
+var collectorCreators = map[string]func(a *agent) (collector, error){
+	"host":          newHostCollector,
+	"node_status":   newNodeStatusCollector,
+	"peers":         newPeersCollector,
+	"node_metrics":  newNodeMetricsCollector,
+	"block_lag":     newBlockLagCollector,
+	"disk":          newDiskCollector,
+	"missed_blocks": newMissedBlocksCollector,
+}
+
+func newCollectors(a *agent) ([]collector, error) {
+	names := a.configs.Collectors
+	explicit := len(names) != 0
+	if !explicit {
+		for name := range collectorCreators {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	var collectors []collector
+	for _, name := range names {
+		create, ok := collectorCreators[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown collector %q", name)
+		}
+		c, err := create(a)
+		if err == errNotConfigured && !explicit {
+			log.Info("collector disabled", "collector", name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("collector %s: %v", name, err)
+		}
+		collectors = append(collectors, c)
+	}
+	if len(collectors) == 0 {
+		return nil, errors.New("no collector enabled")
+	}
+	return collectors, nil
+}
+
+//-----------------------------------------------------------------------------
+
+// hostCollector collects the cpu and memory usage of the host from /proc.
+type hostCollector struct {
+	lastIdle, lastTotal uint64
+}
+
+func newHostCollector(a *agent) (collector, error) {
+	return &hostCollector{}, nil
+}
+
+func (c *hostCollector) Name() string { return "host" }
+
+func (c *hostCollector) Collect() ([]sample, error) {
+	var samples []sample
+	idle, total, err := readCPUTimes()
+	if err != nil {
+		return nil, err
+	}
+	// the usage is measured since the last collection
+	if c.lastTotal != 0 && total > c.lastTotal {
+		usage := 1 - float64(idle-c.lastIdle)/float64(total-c.lastTotal)
+		samples = append(samples, newSample(namespace+"_host_cpu_usage_ratio", usage))
+	}
+	c.lastIdle, c.lastTotal = idle, total
+
+	mem, err := readMemInfo()
+	if err != nil {
+		return samples, err
+	}
+	samples = append(samples,
+		newSample(namespace+"_host_memory_total_bytes", float64(mem["MemTotal"])),
+		newSample(namespace+"_host_memory_available_bytes", float64(mem["MemAvailable"])),
+	)
+	return samples, nil
+}
+
+// readCPUTimes returns the idle and the total cpu time of the host.
+func readCPUTimes() (idle, total uint64, err error) {
+	data, err := ioutil.ReadFile("/proc/stat")
+	if err != nil {
+		return 0, 0, err
+	}
+	line := strings.SplitN(string(data), "\n", 2)[0]
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, 0, fmt.Errorf("invalid /proc/stat line %q", line)
+	}
+	for i, field := range fields[1:] {
+		v, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		total += v
+		if i == 3 || i == 4 { // idle and iowait
+			idle += v
+		}
+	}
+	return idle, total, nil
+}
+
+// readMemInfo returns the fields of /proc/meminfo in bytes.
+func readMemInfo() (map[string]uint64, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	mem := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 2 && fields[2] == "kB" {
+			v *= 1024
+		}
+		mem[strings.TrimSuffix(fields[0], ":")] = v
+	}
+	return mem, scanner.Err()
+}
+
+//-----------------------------------------------------------------------------
+
+// nodeStatusCollector collects the height, the sync state and the voting power
+// of the node by eth_status.
+type nodeStatusCollector struct {
+	node *rpcClient
+}
+
+func newNodeStatusCollector(a *agent) (collector, error) {
+	if a.node == nil {
+		return nil, errNotConfigured
+	}
+	return &nodeStatusCollector{node: a.node}, nil
+}
+
+func (c *nodeStatusCollector) Name() string { return "node_status" }
+
+func (c *nodeStatusCollector) Collect() ([]sample, error) {
+	var status nodeStatus
+	if err := c.node.call(&status, "eth_status"); err != nil {
+		return []sample{newSample(namespace+"_node_up", 0)}, err
+	}
+	info := status.NodeInfo
+	sync := status.SyncInfo
+	samples := []sample{
+		newSample(namespace+"_node_up", 1),
+		newSample(namespace+"_node_info", 1, "moniker", info.Moniker, "network", info.Network, "version", info.Version),
+		newSample(namespace+"_node_height", float64(sync.LatestBlockHeight)),
+		newSample(namespace+"_node_catching_up", boolValue(sync.CatchingUp)),
+		newSample(namespace+"_node_voting_power", float64(status.ValidatorInfo.VotingPower), "validator", status.ValidatorInfo.Address),
+	}
+	if !sync.LatestBlockTime.IsZero() {
+		samples = append(samples, newSample(namespace+"_node_block_age_seconds", time.Since(sync.LatestBlockTime).Seconds()))
+	}
+	return samples, nil
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+//-----------------------------------------------------------------------------
+
+// peersCollector collects the number of the peers of the node by net_info.
+type peersCollector struct {
+	node *rpcClient
+}
+
+func newPeersCollector(a *agent) (collector, error) {
+	if a.node == nil {
+		return nil, errNotConfigured
+	}
+	return &peersCollector{node: a.node}, nil
+}
+
+func (c *peersCollector) Name() string { return "peers" }
+
+func (c *peersCollector) Collect() ([]sample, error) {
+	var info netInfo
+	if err := c.node.call(&info, "net_info"); err != nil {
+		return nil, err
+	}
+	return []sample{newSample(namespace+"_node_peers", float64(info.NPeers))}, nil
+}
+
+//-----------------------------------------------------------------------------
+
+// nodeMetricsCollector passes through the metrics of the node by
+// eth_prometheusMetrics.
+type nodeMetricsCollector struct {
+	node *rpcClient
+}
+
+func newNodeMetricsCollector(a *agent) (collector, error) {
+	if a.node == nil {
+		return nil, errNotConfigured
+	}
+	return &nodeMetricsCollector{node: a.node}, nil
+}
+
+func (c *nodeMetricsCollector) Name() string { return "node_metrics" }
+
+func (c *nodeMetricsCollector) Collect() ([]sample, error) {
+	var text string
+	if err := c.node.call(&text, "eth_prometheusMetrics"); err != nil {
+		return nil, err
+	}
+	return parseText(text)
+}
+
+//-----------------------------------------------------------------------------
+
+// refreshCenterDataInterval is the interval to refresh the nodes from the
+// config center.
+const refreshCenterDataInterval = 10 * time.Minute
+
+// blockLagCollector collects the heights of the nodes from the config center,
+// and the lag of the node behind the highest of them.
+type blockLagCollector struct {
+	node      *rpcClient
+	url       string
+	nodeTypes []int
+	skipIP    string
+
+	peers     []*rpcClient
+	refreshed time.Time
+}
+
+func newBlockLagCollector(a *agent) (collector, error) {
+	if a.node == nil || len(a.configs.BootNodeEndPointUrl) == 0 {
+		return nil, errNotConfigured
+	}
+	nodeTypes, err := parseNodeTypes(a.configs.ForeachNodeType)
+	if err != nil {
+		return nil, err
+	}
+	return &blockLagCollector{
+		node:      a.node,
+		url:       a.configs.BootNodeEndPointUrl,
+		nodeTypes: nodeTypes,
+		skipIP:    a.extIP,
+	}, nil
+}
+
+func (c *blockLagCollector) Name() string { return "block_lag" }
+
+func (c *blockLagCollector) Collect() ([]sample, error) {
+	if time.Since(c.refreshed) > refreshCenterDataInterval {
+		endpoints, err := getConfigCenterData(c.url)
+		if err != nil {
+			if len(c.peers) == 0 {
+				return nil, err
+			}
+			log.Warn("getConfigCenterData failed, use the last nodes", "err", err)
+		} else {
+			c.peers = c.peers[:0]
+			for _, url := range endpoints.rpcURLs(c.nodeTypes, c.skipIP) {
+				c.peers = append(c.peers, newRPCClient(url))
+			}
+			c.refreshed = time.Now()
+		}
+	}
+
+	height, err := c.node.blockNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	// query the peers concurrently, some of them may be slow or down
+	heights := make([]uint64, len(c.peers))
+	var wg sync.WaitGroup
+	for i, peer := range c.peers {
+		wg.Add(1)
+		go func(i int, peer *rpcClient) {
+			defer wg.Done()
+			h, err := peer.blockNumber()
+			if err != nil {
+				log.Debug("get peer height failed", "peer", peer.url, "err", err)
+				return
+			}
+			heights[i] = h
+		}(i, peer)
+	}
+	wg.Wait()
+
+	var samples []sample
+	var maxHeight uint64
+	for i, peer := range c.peers {
+		if heights[i] == 0 {
+			continue
+		}
+		samples = append(samples, newSample(namespace+"_peer_height", float64(heights[i]), "peer", peer.url))
+		if heights[i] > maxHeight {
+			maxHeight = heights[i]
+		}
+	}
+	var lag uint64
+	if maxHeight > height {
+		lag = maxHeight - height
+	}
+	samples = append(samples,
+		newSample(namespace+"_peer_max_height", float64(maxHeight)),
+		newSample(namespace+"_block_lag", float64(lag)),
+	)
+	return samples, nil
+}
+
+//-----------------------------------------------------------------------------
+
+// diskCollector collects the size of each db in the data directory of the node.
+type diskCollector struct {
+	dir string
+}
+
+func newDiskCollector(a *agent) (collector, error) {
+	if len(a.configs.DBDir) == 0 {
+		return nil, errNotConfigured
+	}
+	return &diskCollector{dir: a.configs.DBDir}, nil
+}
+
+func (c *diskCollector) Name() string { return "disk" }
+
+func (c *diskCollector) Collect() ([]sample, error) {
+	entries, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return nil, err
+	}
+	var samples []sample
+	var total int64
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		size, err := dirSize(filepath.Join(c.dir, entry.Name()))
+		if err != nil {
+			return samples, err
+		}
+		total += size
+		db := strings.TrimSuffix(entry.Name(), ".db")
+		samples = append(samples, newSample(namespace+"_db_size_bytes", float64(size), "db", db))
+	}
+	samples = append(samples, newSample(namespace+"_db_total_size_bytes", float64(total)))
+	return samples, nil
+}
+
+// dirSize returns the total size of the files in the directory.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// the files may be removed by the compaction of the db
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+//-----------------------------------------------------------------------------
+
+// missedBlocksCollector counts the blocks the validators did not sign in the
+// recent window, by the precommits in the last commit of the blocks.
+type missedBlocksCollector struct {
+	node   *rpcClient
+	window uint64
+
+	height     uint64              // the last height the commit is checked
+	missed     map[string][]uint64 // the heights missed by the validators in the window
+	total      map[string]uint64   // the blocks missed by the validators since started
+	validators []string            // the validators of the last checked height
+	signed     map[string]bool     // whether the validators signed the last checked height
+}
+
+func newMissedBlocksCollector(a *agent) (collector, error) {
+	if a.node == nil {
+		return nil, errNotConfigured
+	}
+	return &missedBlocksCollector{
+		node:   a.node,
+		window: uint64(a.configs.Alerts.MissedBlocksWindow),
+		missed: make(map[string][]uint64),
+		total:  make(map[string]uint64),
+	}, nil
+}
+
+func (c *missedBlocksCollector) Name() string { return "missed_blocks" }
+
+func (c *missedBlocksCollector) Collect() ([]sample, error) {
+	latest, err := c.node.blockNumber()
+	if err != nil {
+		return nil, err
+	}
+	// the commit of a height is in the next block
+	from := c.height + 2
+	if latest >= c.window && from+c.window <= latest {
+		from = latest - c.window + 1
+	}
+	if from < 2 {
+		from = 2
+	}
+	for h := from; h <= latest; h++ {
+		if err := c.check(h); err != nil {
+			return c.samples(), err
+		}
+	}
+	return c.samples(), nil
+}
+
+// check checks the commit of the height h-1 in the block h.
+func (c *missedBlocksCollector) check(h uint64) error {
+	height := h - 1
+	var block blockCommit
+	if err := c.node.call(&block, "debug_getBlockBinary", hexutil.Uint64(h)); err != nil {
+		return err
+	}
+	var set validatorSet
+	if err := c.node.call(&set, "eth_validators", hexutil.Uint64(height)); err != nil {
+		return err
+	}
+
+	signers := make(map[string]bool)
+	for _, vote := range block.Block.LastCommit.Precommits {
+		if vote != nil {
+			signers[strings.ToUpper(vote.ValidatorAddress)] = true
+		}
+	}
+	c.validators = c.validators[:0]
+	c.signed = make(map[string]bool, len(set.Validators))
+	for _, v := range set.Validators {
+		addr := strings.ToUpper(v.Address)
+		c.validators = append(c.validators, addr)
+		c.signed[addr] = signers[addr]
+		if !signers[addr] {
+			c.missed[addr] = append(c.missed[addr], height)
+			c.total[addr]++
+		}
+	}
+
+	// drop the heights out of the window
+	for addr, heights := range c.missed {
+		i := 0
+		for i < len(heights) && heights[i]+c.window <= height {
+			i++
+		}
+		if i == len(heights) {
+			delete(c.missed, addr)
+		} else {
+			c.missed[addr] = heights[i:]
+		}
+	}
+	c.height = height
+	return nil
+}
+
+func (c *missedBlocksCollector) samples() []sample {
+	if c.height == 0 {
+		return nil
+	}
+	samples := []sample{newSample(namespace+"_commit_checked_height", float64(c.height))}
+	for _, addr := range c.validators {
+		samples = append(samples,
+			newSample(namespace+"_validator_missed_blocks", float64(len(c.missed[addr])), "validator", addr),
+			newSample(namespace+"_validator_missed_blocks_total", float64(c.total[addr]), "validator", addr),
+			newSample(namespace+"_validator_signed", boolValue(c.signed[addr]), "validator", addr),
+		)
+	}
+	return samples
+}
@@ -1,7 +1,97 @@
 package main
 
+import (
+	"time"
+)
+
 type lkBlockAgentConfigs struct {
+	// BootNodeEndPointUrl is the config center returning the nodes of the
+	// chain, the block lag is measured against them.
 	BootNodeEndPointUrl string
-	ForeachNodeType     string
+	// ForeachNodeType is the comma separated types of the nodes to measure the
+	// block lag against, all the nodes if empty.
+	ForeachNodeType string
+	// MetricsCollectorUrl is the legacy collector the metrics are posted to,
+	// used if no sink is configured.
 	MetricsCollectorUrl string
+
+	// Interval is the interval to collect the metrics, 1m by default.
+	Interval duration
+	// NodeRPCUrl is the http rpc of the monitored node, e.g. http://127.0.0.1:8000.
+	NodeRPCUrl string
+	// DBDir is the data directory of the node, the disk usage of each db in it
+	// is collected.
+	DBDir string
+	// Collectors are the names of the enabled collectors, all the configured
+	// ones if empty.
+	Collectors []string
+	// Sinks are where the metrics are sent.
+	Sinks []sinkConfig
+	// Alerts are the alert rules.
+	Alerts alertConfigs
+}
+
+type sinkConfig struct {
+	// Type is one of pushgateway, remote_write, file and collector.
+	Type string
+	// Url is the address of the pushgateway, the remote-write endpoint or the
+	// collector.
+	Url string
+	// Job is the job of the metrics pushed to the pushgateway.
+	Job string
+	// Path is the file the metrics are appended to.
+	Path string
+}
+
+type alertConfigs struct {
+	// StalledHeight fires if the height of the node does not grow in it, 0
+	// disables the rule.
+	StalledHeight duration
+	// MissedBlocks fires if a validator misses the signatures of so many blocks
+	// in the last MissedBlocksWindow blocks, 0 disables the rule.
+	MissedBlocks int
+	// MissedBlocksWindow is the number of the recent blocks the missed
+	// signatures are counted in.
+	MissedBlocksWindow int
+	// Validators are the addresses of the validators watched for the missed
+	// signatures, all the validators if empty.
+	Validators []string
+	// Webhook is posted the firing and resolved alerts as JSON if not empty,
+	// the alerts are always logged.
+	Webhook string
+}
+
+const (
+	defaultInterval           = time.Minute
+	defaultMissedBlocksWindow = 100
+	defaultPushgatewayJob     = "lk_blockagent"
+)
+
+// setDefaults fills the missing configs with the defaults.
+func (c *lkBlockAgentConfigs) setDefaults() {
+	if c.Interval.Duration <= 0 {
+		c.Interval.Duration = defaultInterval
+	}
+	if len(c.Sinks) == 0 && len(c.MetricsCollectorUrl) != 0 {
+		c.Sinks = []sinkConfig{{Type: "collector", Url: c.MetricsCollectorUrl}}
+	}
+	for i := range c.Sinks {
+		if c.Sinks[i].Type == "pushgateway" && len(c.Sinks[i].Job) == 0 {
+			c.Sinks[i].Job = defaultPushgatewayJob
+		}
+	}
+	if c.Alerts.MissedBlocksWindow <= 0 {
+		c.Alerts.MissedBlocksWindow = defaultMissedBlocksWindow
+	}
+}
+
+// duration is a time.Duration decoded from a string like "5m" in the toml.
+type duration struct {
+	time.Duration
+}
+
+func (d *duration) UnmarshalText(text []byte) error {
+	var err error
+	d.Duration, err = time.ParseDuration(string(text))
+	return err
 }
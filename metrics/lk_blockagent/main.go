@@ -3,11 +3,13 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 
 	"github.com/BurntSushi/toml"
-	flog "github.com/lianxiangcloud/linkchain/libs/log"
 	cfg "github.com/lianxiangcloud/linkchain/config"
+	flog "github.com/lianxiangcloud/linkchain/libs/log"
 )
 
 var (
@@ -16,8 +18,8 @@ var (
 
 func logInit() {
 	logConfig := cfg.DefaultRotateConfig()
-	logConfig.Daily    = true
-	logConfig.Hourly   = false
+	logConfig.Daily = true
+	logConfig.Hourly = false
 	logConfig.Filename = "logs/lk_blockagent.log"
 	if !filepath.IsAbs(logConfig.Filename) {
 		dir, err := os.Getwd()
@@ -43,7 +45,7 @@ func logInit() {
 
 func main() {
 	if len(os.Args) != 2 {
-		fmt.Println("Usage: ./metrics_collector <config_file_path>")
+		fmt.Println("Usage: ./lk_blockagent <config_file_path>")
 		os.Exit(1)
 	}
 	configPath := os.Args[1]
@@ -55,5 +57,18 @@ func main() {
 	}
 	logInit()
 
-	runLKBlockAgent(&configs)
+	agent, err := newAgent(&configs)
+	if err != nil {
+		log.Error("create lk_blockagent failed", "err", err)
+		fmt.Println("create lk_blockagent failed.", "err", err)
+		os.Exit(1)
+	}
+	log.Info("Start lk_blockagent", "collectors", len(agent.collectors), "sinks", len(agent.sinks))
+	agent.start()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-sigs
+	log.Info("Stop lk_blockagent", "signal", sig)
+	agent.stop()
 }
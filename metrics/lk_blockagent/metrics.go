@@ -1,318 +1,286 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net"
-	"net/http"
 	"os"
-	"os/exec"
-	"strings"
-	"time"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
-const (
-	succ                                 = 0
-	refreshCenterDataTimeIntervalMinutes = 10
-)
-
-type endPoint struct {
-	IP   []string       `json:"ip"`
-	Port map[string]int `json:"port"`
-}
-
-type node struct {
-	Type     int      `json:"type,omitempty"`
-	HostName string   `json:"hostname,omitempty"`
-	PubKey   string   `json:"pubkey,omitempty"`
-	EndPoint endPoint `json:"endpoint"`
-}
+// namespace prefixes the metrics of the agent.
+const namespace = "linkchain_agent"
 
-type bootSvrEndPoints struct {
-	Code  int    `json:"code,omitempty"`
-	Nodes []node `json:"nodes"`
+// sample is a metric value of a collection.
+type sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
 }
 
-func runLKBlockAgent(configs *lkBlockAgentConfigs) {
-	m := &metrics{
-		configs:           configs,
-		forEachtNodeTypes: make([]int, 0),
+func newSample(name string, value float64, labels ...string) sample {
+	s := sample{Name: name, Value: value, Labels: make(map[string]string, len(labels)/2)}
+	for i := 0; i+1 < len(labels); i += 2 {
+		s.Labels[labels[i]] = labels[i+1]
 	}
-	log.Info("Start getCommonMetrics.")
-
-	cMetrics := &commonMetrics{}
-	err := m.getCommonMetrics(cMetrics)
-	if err != nil {
-		log.Error("runLKBlockAgent get common metrifcs failed.", "err", err.Error())
-		return
-	}
-	m.cMetrics = cMetrics
+	return s
+}
 
-	err = m.getConfigCenterData()
-	if err != nil {
-		log.Error("getConfigCenterData exec failed.", "err", err.Error())
-		return
+// labelNames returns the label names of the sample in order.
+func (s *sample) labelNames() []string {
+	names := make([]string, 0, len(s.Labels))
+	for name := range s.Labels {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
 
-	go func() {
-		for {
-			m.globalMu.Lock()
-			defer m.globalMu.Unlock()
-
-			log.Info("Start getMetrics.")
-			prometheusMetrics := m.getMetrics()
-			log.Info("start send metrics to collector", "metrics", prometheusMetrics)
-			m.sendMetricsToMetricsCollector(prometheusMetrics)
-			time.Sleep(time.Minute)
-		}
-	}()
-
-	go func() {
-		for {
-			m.globalMu.Lock()
-			defer m.globalMu.Unlock()
-
-			time.Sleep(refreshCenterDataTimeIntervalMinutes * time.Minute)
-			log.Info("Start to update getConfigCenterData")
-			err = m.getConfigCenterData()
-			if err != nil {
-				log.Error("getConfigCenterData exec failed.", "err", err.Error())
+// writeText writes the samples in the Prometheus text format, with the
+// timestamp in milliseconds if ts is not zero. The samples of a metric are
+// written together.
+func writeText(w io.Writer, samples []sample, ts time.Time) error {
+	sorted := make([]sample, len(samples))
+	copy(sorted, samples)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var b strings.Builder
+	for i := range sorted {
+		s := &sorted[i]
+		b.WriteString(s.Name)
+		if len(s.Labels) > 0 {
+			b.WriteByte('{')
+			for j, name := range s.labelNames() {
+				if j > 0 {
+					b.WriteByte(',')
+				}
+				fmt.Fprintf(&b, "%s=\"%s\"", name, escapeLabelValue(s.Labels[name]))
 			}
+			b.WriteByte('}')
 		}
-	}()
-
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatFloat(s.Value, 'g', -1, 64))
+		if !ts.IsZero() {
+			b.WriteByte(' ')
+			b.WriteString(strconv.FormatInt(timestampMs(ts), 10))
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
 }
 
-type metrics struct {
-	configs           *lkBlockAgentConfigs
-	forEachtNodeTypes []int
-	cMetrics          *commonMetrics
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
 
-	globalMu          sync.Mutex
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
 }
 
-type commonMetrics struct {
-	hostname            string
-	extIpaddr           string
-	bootSvrEndPointsObj bootSvrEndPoints
+func timestampMs(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
 }
 
-func (m *metrics) getCommonMetrics(cMetrics *commonMetrics) error {
-	// get hostname
-	hostname, err := os.Hostname()
-	if err != nil {
-		return err
-	}
-	cMetrics.hostname = hostname
-
-	// get ext ip address
-	addrs, err := net.InterfaceAddrs()
-	for _, address := range addrs {
-		// Check ip address. Can
-		if ipnet, ok := address.(*net.IPNet); ok && !ipnet.IP.IsLoopback() {
-			if ipnet.IP.To4() != nil {
-				if ipnet.IP.To4()[0] != 10 &&
-					ipnet.IP.To4()[0] != 172 &&
-					ipnet.IP.To4()[0] != 192 {
-					cMetrics.extIpaddr = ipnet.IP.String()
-				}
+// parseText parses the samples of the Prometheus text format, the comments and
+// the timestamps are dropped.
+func parseText(text string) ([]sample, error) {
+	var samples []sample
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		s := sample{Labels: make(map[string]string)}
+		end := strings.IndexAny(line, "{ ")
+		if end <= 0 {
+			return nil, fmt.Errorf("invalid metric line %q", line)
+		}
+		s.Name, line = line[:end], line[end:]
+		if line[0] == '{' {
+			rest, err := parseLabels(line[1:], s.Labels)
+			if err != nil {
+				return nil, fmt.Errorf("invalid labels of %s: %v", s.Name, err)
 			}
+			line = rest
 		}
-	}
-
-	if len(cMetrics.hostname) == 0 || len(cMetrics.extIpaddr) == 0 {
-		log.Error("Get common metrics faield.", "hostname", cMetrics.hostname,
-			"ext_ipaddr", cMetrics.extIpaddr)
-		return errors.New("Get common metrics failed. hostname or ext_ipaddr is nil")
-	}
-	log.Info("Get common metrics success.", "hostname", cMetrics.hostname,
-		"ext_ipaddr", cMetrics.extIpaddr)
-
-	if len(m.configs.ForeachNodeType) == 0 {
-		log.Error("ForeachNodeType is empty.")
-		return errors.New("ForeachNodeType is empty.")
-	}
-	forEachtNodeTypesStr := strings.Split(m.configs.ForeachNodeType, ",")
-	for _, nodeTypeStr := range forEachtNodeTypesStr {
-		nodeType, err := strconv.Atoi(nodeTypeStr)
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("no value of %s", s.Name)
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
 		if err != nil {
-			log.Error("split ForeachNodeType failed.", "err", err.Error())
+			return nil, fmt.Errorf("invalid value of %s: %v", s.Name, err)
 		}
-		m.forEachtNodeTypes = append(m.forEachtNodeTypes, nodeType)
-	}
-
-	if len(m.configs.BootNodeEndPointUrl) == 0 {
-		log.Error("BootNodeEndPointUrl is empty.")
-		return errors.New("BootNodeEndPointUrl is empty.")
+		s.Value = value
+		samples = append(samples, s)
 	}
-
-	return nil
+	return samples, nil
 }
 
-
-func (m *metrics) isNodeTypeNeedForeach(nodeType int) bool {
-	for _, foreachNodeType := range m.forEachtNodeTypes {
-		if foreachNodeType == nodeType {
-			return true
+// parseLabels parses the labels after '{' into labels, it returns the text
+// after '}'.
+func parseLabels(text string, labels map[string]string) (string, error) {
+	for {
+		text = strings.TrimLeft(text, " ,")
+		if len(text) == 0 {
+			return "", errors.New("unterminated labels")
 		}
-	}
-	return false
-}
-
-func (m *metrics) getMetrics() string {
-	prometheusMetrics := ""
-	// 1. get local machine cpu and memory metrics
-	prometheusMetrics += m.getCpuMemoryMetrics()
-
-	// 3. get all metrics
-	for _, xnodeDataObj := range m.cMetrics.bootSvrEndPointsObj.Nodes {
-		if !m.isNodeTypeNeedForeach(xnodeDataObj.Type) {
-			continue
+		if text[0] == '}' {
+			return text[1:], nil
 		}
-		//if xnodeDataObj.HostName != m.cMetrics.hostname {
-		//	continue
-		//}
-		for _, endPointIp := range xnodeDataObj.EndPoint.IP {
-			if strings.Index(endPointIp, m.cMetrics.extIpaddr) == -1 {
-				continue
-			}
-			url := fmt.Sprintf("http://%s:%d", endPointIp, xnodeDataObj.EndPoint.Port["http"])
-			post := []byte("{\"jsonrpc\":\"2.0\",\"method\":\"eth_prometheusMetrics\",\"params\":[],\"id\":1}")
-			req, err := http.NewRequest("POST", url, bytes.NewBuffer(post))
-			if err != nil {
-				log.Error("new request failed.", "err", err.Error())
-				continue
-			}
-			req.Header.Set("Content-Type", "application/json")
-			client := &http.Client{}
-			resp, err := client.Do(req)
-			if err != nil {
-				log.Error("client.Do failed", "err", err.Error())
-				continue
-			}
-			defer resp.Body.Close()
-
-			metricsBytes, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				log.Error("ioutil.ReadAll failed", "err", err.Error())
-				continue
+		eq := strings.Index(text, "=\"")
+		if eq <= 0 {
+			return "", errors.New("label value not quoted")
+		}
+		name := strings.TrimSpace(text[:eq])
+		text = text[eq+2:]
+
+		var value strings.Builder
+		i := 0
+		for ; i < len(text) && text[i] != '"'; i++ {
+			if text[i] == '\\' && i+1 < len(text) {
+				i++
+				if text[i] == 'n' {
+					value.WriteByte('\n')
+					continue
+				}
 			}
-			prometheusMetrics += string(metricsBytes)
+			value.WriteByte(text[i])
 		}
+		if i == len(text) {
+			return "", errors.New("unterminated label value")
+		}
+		labels[name] = value.String()
+		text = text[i+1:]
 	}
-
-	return prometheusMetrics
 }
 
-func (m *metrics) genCpuMetric(cpuInfo string) string {
-	return fmt.Sprintf("linkchain_Cpuinfo{hostname=\"%s\",cpu_info=\"%s\"} 0",
-		m.cMetrics.hostname, cpuInfo)
-}
+//-----------------------------------------------------------------------------
+
+// agent collects the metrics by the collectors in every interval, evaluates
+// the alert rules on them and sends them to the sinks.
+type agent struct {
+	configs  *lkBlockAgentConfigs
+	hostname string
+	extIP    string
+	node     *rpcClient
 
-func (m *metrics) genMemoryMetric(memInfo string) string {
-	return fmt.Sprintf("linkchain_Cpuinfo{hostname=\"%s\",mem_info=\"%s\"} 0",
-		m.cMetrics.hostname, memInfo)
+	collectors []collector
+	sinks      []sink
+	alerts     *alertManager
+
+	quit chan struct{}
+	wg   sync.WaitGroup
 }
 
-func (m *metrics) getCpuMemoryMetrics() string {
-	cmd := exec.Command("bash", "-c", "top n 1 b i | grep Cpu")
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		log.Error("command exec failed.", "err", err.Error())
-		return ""
-	}
-	err = cmd.Start()
+func newAgent(configs *lkBlockAgentConfigs) (*agent, error) {
+	configs.setDefaults()
+	hostname, err := os.Hostname()
 	if err != nil {
-		log.Error("cpu info command exec failed.", "err", err.Error())
-		return ""
+		return nil, err
 	}
-	cpuInfo, err := ioutil.ReadAll(stdout)
-	if err != nil {
-		log.Error("read cpuinfo failed.", "err", err.Error(), "info", string(cpuInfo))
-		return ""
+	a := &agent{
+		configs:  configs,
+		hostname: hostname,
+		extIP:    externalIP(),
+		quit:     make(chan struct{}),
 	}
-	if err := cmd.Wait(); err != nil {
-		log.Error("cmd wait failed.", "err", err.Error())
-		return ""
+	if len(configs.NodeRPCUrl) != 0 {
+		a.node = newRPCClient(configs.NodeRPCUrl)
 	}
-	cpuMetric := m.genCpuMetric(string(cpuInfo))
+	log.Info("lk_blockagent host", "hostname", a.hostname, "ext_ipaddr", a.extIP)
 
-	cmd = exec.Command("bash", "-c", "top n 1 b i | grep Mem")
-	stdout, err = cmd.StdoutPipe()
-	if err != nil {
-		log.Error("command exec failed.", "err", err.Error())
-		return ""
-	}
-	err = cmd.Start()
-	if err != nil {
-		log.Error("memory info command exec failed.", "err", err.Error())
-		return ""
+	if a.collectors, err = newCollectors(a); err != nil {
+		return nil, err
 	}
-	memInfo, err := ioutil.ReadAll(stdout)
-	if err != nil {
-		log.Info("read memInfo failed.", "err", err.Error(), "info", string(memInfo))
-		return ""
+	if len(configs.Sinks) == 0 {
+		return nil, errors.New("no sink configured")
 	}
-	if err := cmd.Wait(); err != nil {
-		log.Error("cmd wait failed.", "err", err.Error())
-		return ""
+	for i := range configs.Sinks {
+		s, err := newSink(&configs.Sinks[i], a)
+		if err != nil {
+			return nil, err
+		}
+		a.sinks = append(a.sinks, s)
 	}
-	memMetric := m.genMemoryMetric(string(memInfo))
+	a.alerts = newAlertManager(&configs.Alerts)
+	return a, nil
+}
 
-	return cpuMetric + memMetric
+// start runs the collections in the background until stop.
+func (a *agent) start() {
+	a.wg.Add(1)
+	go a.loop()
 }
 
-func (m *metrics) getConfigCenterData() error {
-	url := m.configs.BootNodeEndPointUrl
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(nil))
-	if err != nil {
-		log.Error("new request failed.", "err", err.Error())
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Error("client.Do failed", "err", err.Error())
-		return err
-	}
-	defer resp.Body.Close()
+func (a *agent) stop() {
+	close(a.quit)
+	a.wg.Wait()
+}
 
-	endpointBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Error("ioutil.ReadAll failed", "err", err.Error())
-		return err
+func (a *agent) loop() {
+	defer a.wg.Done()
+	ticker := time.NewTicker(a.configs.Interval.Duration)
+	defer ticker.Stop()
+	for {
+		a.runOnce(time.Now())
+		select {
+		case <-ticker.C:
+		case <-a.quit:
+			return
+		}
 	}
-	err = json.Unmarshal(endpointBytes, &m.cMetrics.bootSvrEndPointsObj)
-	if err != nil {
-		log.Error("json unmarshal bootSvrEndPoints failed.", "err", err.Error())
-		return err
+}
+
+// runOnce collects the metrics, evaluates the alerts and sends the metrics.
+func (a *agent) runOnce(now time.Time) {
+	var samples []sample
+	for _, c := range a.collectors {
+		start := time.Now()
+		collected, err := c.Collect()
+		up := 1.0
+		if err != nil {
+			up = 0
+			log.Warn("collect metrics failed", "collector", c.Name(), "err", err)
+		}
+		samples = append(samples, collected...)
+		samples = append(samples,
+			newSample(namespace+"_collector_up", up, "collector", c.Name()),
+			newSample(namespace+"_collector_duration_seconds", time.Since(start).Seconds(), "collector", c.Name()),
+		)
 	}
-	if m.cMetrics.bootSvrEndPointsObj.Code != succ {
-		log.Error("request bootSvrEndPoints failed.", "code", m.cMetrics.bootSvrEndPointsObj.Code)
-		return errors.New("request bootSvrEndPoints failed.")
+	samples = append(samples, a.alerts.eval(samples, now)...)
+	for i := range samples {
+		if _, ok := samples[i].Labels["hostname"]; !ok {
+			samples[i].Labels["hostname"] = a.hostname
+		}
 	}
 
-	return nil
+	for _, s := range a.sinks {
+		if err := s.Send(samples, now); err != nil {
+			log.Error("send metrics failed", "sink", s.Name(), "err", err)
+		}
+	}
+	log.Debug("metrics sent", "samples", len(samples), "sinks", len(a.sinks))
 }
 
-func (m *metrics) sendMetricsToMetricsCollector(promethuesMetrics string) {
-	url := m.configs.MetricsCollectorUrl
-	post := []byte(promethuesMetrics)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(post))
+// externalIP returns the public ipv4 address of the host, empty if not found.
+func externalIP() string {
+	addrs, err := net.InterfaceAddrs()
 	if err != nil {
-		log.Error("new request failed.", "err", err.Error())
+		return ""
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Error("client.Do failed", "err", err.Error())
+	for _, address := range addrs {
+		if ipnet, ok := address.(*net.IPNet); ok && !ipnet.IP.IsLoopback() {
+			if ip := ipnet.IP.To4(); ip != nil && ip[0] != 10 && ip[0] != 172 && ip[0] != 192 {
+				return ip.String()
+			}
+		}
 	}
-	defer resp.Body.Close()
+	return ""
 }
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
+)
+
+const requestTimeout = 10 * time.Second
+
+// rpcClient calls the json-rpc methods of a node by http.
+type rpcClient struct {
+	url    string
+	client *http.Client
+	id     uint64
+}
+
+func newRPCClient(url string) *rpcClient {
+	return &rpcClient{url: url, client: &http.Client{Timeout: requestTimeout}}
+}
+
+type rpcRequest struct {
+	Version string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call calls the method with the params and decodes the result into result.
+func (c *rpcClient) call(result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(&rpcRequest{
+		Version: "2.0",
+		ID:      atomic.AddUint64(&c.id, 1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returns %s: %s", method, resp.Status, data)
+	}
+	var r rpcResponse
+	if err := json.Unmarshal(data, &r); err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+	if r.Error != nil {
+		return fmt.Errorf("%s: %s (%d)", method, r.Error.Message, r.Error.Code)
+	}
+	if err := json.Unmarshal(r.Result, result); err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+	return nil
+}
+
+// blockNumber returns the height of the chain head.
+func (c *rpcClient) blockNumber() (uint64, error) {
+	var height hexutil.Big
+	if err := c.call(&height, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return height.ToInt().Uint64(), nil
+}
+
+// jsonUint64 decodes the integers quoted by ser, as well as the plain ones.
+type jsonUint64 uint64
+
+func (n *jsonUint64) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseUint(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return err
+	}
+	*n = jsonUint64(v)
+	return nil
+}
+
+// the results of the node rpc, only the fields used by the collectors.
+type (
+	nodeStatus struct {
+		NodeInfo struct {
+			Moniker string `json:"moniker"`
+			Network string `json:"network"`
+			Version string `json:"version"`
+		} `json:"node_info"`
+		SyncInfo struct {
+			LatestBlockHeight jsonUint64 `json:"latest_block_height"`
+			LatestBlockTime   time.Time  `json:"latest_block_time"`
+			CatchingUp        bool       `json:"catching_up"`
+		} `json:"sync_info"`
+		ValidatorInfo struct {
+			Address     string     `json:"address"`
+			VotingPower jsonUint64 `json:"voting_power"`
+		} `json:"validator_info"`
+	}
+
+	netInfo struct {
+		NPeers jsonUint64 `json:"n_peers"`
+	}
+
+	validatorSet struct {
+		BlockHeight jsonUint64 `json:"block_height"`
+		Validators  []struct {
+			Address     string     `json:"address"`
+			VotingPower jsonUint64 `json:"voting_power"`
+		} `json:"validators"`
+	}
+
+	blockCommit struct {
+		Block struct {
+			LastCommit struct {
+				Precommits []*struct {
+					ValidatorAddress string     `json:"validator_address"`
+					Height           jsonUint64 `json:"height"`
+				} `json:"precommits"`
+			} `json:"last_commit"`
+		} `json:"block"`
+	}
+)
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+)
+
+// sink sends the collected metrics out.
+type sink interface {
+	Name() string
+	Send(samples []sample, ts time.Time) error
+}
+
+// sinkCreators are the sinks by type.
+var sinkCreators = map[string]func(conf *sinkConfig, a *agent) (sink, error){
+	"pushgateway":  newPushgatewaySink,
+	"remote_write": newRemoteWriteSink,
+	"file":         newFileSink,
+	"collector":    newCollectorSink,
+}
+
+func newSink(conf *sinkConfig, a *agent) (sink, error) {
+	create, ok := sinkCreators[conf.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown sink type %q", conf.Type)
+	}
+	s, err := create(conf, a)
+	if err != nil {
+		return nil, fmt.Errorf("sink %s: %v", conf.Type, err)
+	}
+	return s, nil
+}
+
+// checkResponse returns an error with the body if the status is not 2xx.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	msg, _ := ioutil.ReadAll(resp.Body)
+	return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+}
+
+//-----------------------------------------------------------------------------
+
+// pushgatewaySink replaces the metrics of the host in the group of the job on
+// a Prometheus pushgateway.
+type pushgatewaySink struct {
+	url    string
+	client *http.Client
+}
+
+func newPushgatewaySink(conf *sinkConfig, a *agent) (sink, error) {
+	if len(conf.Url) == 0 {
+		return nil, errors.New("Url is empty")
+	}
+	return &pushgatewaySink{
+		url: fmt.Sprintf("%s/metrics/job/%s/instance/%s", strings.TrimRight(conf.Url, "/"),
+			url.PathEscape(conf.Job), url.PathEscape(a.hostname)),
+		client: &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+func (s *pushgatewaySink) Name() string { return "pushgateway" }
+
+func (s *pushgatewaySink) Send(samples []sample, ts time.Time) error {
+	// the pushgateway rejects the samples with the timestamps
+	var body bytes.Buffer
+	if err := writeText(&body, samples, time.Time{}); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, s.url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
+
+//-----------------------------------------------------------------------------
+
+// remoteWriteSink sends the metrics by the Prometheus remote-write protocol,
+// a snappy compressed protobuf WriteRequest.
+type remoteWriteSink struct {
+	url    string
+	client *http.Client
+}
+
+func newRemoteWriteSink(conf *sinkConfig, a *agent) (sink, error) {
+	if len(conf.Url) == 0 {
+		return nil, errors.New("Url is empty")
+	}
+	return &remoteWriteSink{url: conf.Url, client: &http.Client{Timeout: requestTimeout}}, nil
+}
+
+func (s *remoteWriteSink) Name() string { return "remote_write" }
+
+func (s *remoteWriteSink) Send(samples []sample, ts time.Time) error {
+	data := snappy.Encode(nil, encodeWriteRequest(samples, ts))
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
+
+// the wire types of protobuf
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// encodeWriteRequest encodes the samples as the prometheus.WriteRequest:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+//
+// The encoding to a proto.Buffer never fails.
+func encodeWriteRequest(samples []sample, ts time.Time) []byte {
+	req := proto.NewBuffer(nil)
+	for i := range samples {
+		s := &samples[i]
+		// the labels of a series are sorted by name
+		names := append(s.labelNames(), "__name__")
+		sort.Strings(names)
+		series := proto.NewBuffer(nil)
+		for _, name := range names {
+			value := s.Labels[name]
+			if name == "__name__" {
+				value = s.Name
+			}
+			writeLabel(series, name, value)
+		}
+		value := proto.NewBuffer(nil)
+		value.EncodeVarint(1<<3 | wireFixed64)
+		value.EncodeFixed64(math.Float64bits(s.Value))
+		value.EncodeVarint(2<<3 | wireVarint)
+		value.EncodeVarint(uint64(timestampMs(ts)))
+		series.EncodeVarint(2<<3 | wireBytes)
+		series.EncodeRawBytes(value.Bytes())
+
+		req.EncodeVarint(1<<3 | wireBytes)
+		req.EncodeRawBytes(series.Bytes())
+	}
+	return req.Bytes()
+}
+
+func writeLabel(b *proto.Buffer, name, value string) {
+	label := proto.NewBuffer(nil)
+	label.EncodeVarint(1<<3 | wireBytes)
+	label.EncodeStringBytes(name)
+	label.EncodeVarint(2<<3 | wireBytes)
+	label.EncodeStringBytes(value)
+	b.EncodeVarint(1<<3 | wireBytes)
+	b.EncodeRawBytes(label.Bytes())
+}
+
+//-----------------------------------------------------------------------------
+
+// fileSink appends the metrics in the Prometheus text format with the
+// timestamps to a local file.
+type fileSink struct {
+	path string
+}
+
+func newFileSink(conf *sinkConfig, a *agent) (sink, error) {
+	if len(conf.Path) == 0 {
+		return nil, errors.New("Path is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(conf.Path), 0755); err != nil {
+		return nil, err
+	}
+	return &fileSink{path: conf.Path}, nil
+}
+
+func (s *fileSink) Name() string { return "file" }
+
+func (s *fileSink) Send(samples []sample, ts time.Time) error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if err := writeText(f, samples, ts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//-----------------------------------------------------------------------------
+
+// collectorSink posts the metrics in the Prometheus text format to the legacy
+// metrics collector.
+type collectorSink struct {
+	url    string
+	client *http.Client
+}
+
+func newCollectorSink(conf *sinkConfig, a *agent) (sink, error) {
+	if len(conf.Url) == 0 {
+		return nil, errors.New("Url is empty")
+	}
+	return &collectorSink{url: conf.Url, client: &http.Client{Timeout: requestTimeout}}, nil
+}
+
+func (s *collectorSink) Name() string { return "collector" }
+
+func (s *collectorSink) Send(samples []sample, ts time.Time) error {
+	var body bytes.Buffer
+	if err := writeText(&body, samples, time.Time{}); err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "text/plain; version=0.0.4", &body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
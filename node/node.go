@@ -2,8 +2,10 @@ package node
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	_ "net/http/pprof"
+	"path/filepath"
 	"time"
 
 	"fmt"
@@ -15,6 +17,7 @@ import (
 	"github.com/lianxiangcloud/linkchain/bootcli"
 	cfg "github.com/lianxiangcloud/linkchain/config"
 	cs "github.com/lianxiangcloud/linkchain/consensus"
+	"github.com/lianxiangcloud/linkchain/contract/verify"
 	"github.com/lianxiangcloud/linkchain/evidence"
 	cmn "github.com/lianxiangcloud/linkchain/libs/common"
 	dbm "github.com/lianxiangcloud/linkchain/libs/db"
@@ -22,6 +25,8 @@ import (
 	"github.com/lianxiangcloud/linkchain/libs/log"
 	"github.com/lianxiangcloud/linkchain/libs/p2p"
 	p2pcmn "github.com/lianxiangcloud/linkchain/libs/p2p/common"
+	"github.com/lianxiangcloud/linkchain/libs/rpc"
+	"github.com/lianxiangcloud/linkchain/libs/trace"
 	"github.com/lianxiangcloud/linkchain/libs/txmgr"
 	mempl "github.com/lianxiangcloud/linkchain/mempool"
 	"github.com/lianxiangcloud/linkchain/metrics"
@@ -30,6 +35,7 @@ import (
 	"github.com/lianxiangcloud/linkchain/types"
 	"github.com/lianxiangcloud/linkchain/utxo"
 	"github.com/lianxiangcloud/linkchain/version"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 //------------------------------------------------------------------------------
@@ -50,16 +56,35 @@ func DefaultDBProvider(ctx *DBContext) (dbm.DB, error) {
 	return dbm.NewDB(ctx.ID, dbType, ctx.Config.DBDir(), ctx.Config.DBCounts), nil
 }
 
-// MetricsProvider returns a consensus, p2p and mempool Metrics.
-type MetricsProvider func() (*cs.Metrics, *mempl.Metrics)
+// Metrics is the metrics of the modules of the node.
+type Metrics struct {
+	Consensus  *cs.Metrics
+	P2P        *p2p.Metrics
+	Mempool    *mempl.Metrics
+	Blockchain *bc.Metrics
+	Utxo       *utxo.Metrics
+	State      *state.Metrics
+	RPC        *rpc.Metrics
+}
+
+// MetricsProvider returns the Metrics of the modules.
+type MetricsProvider func() *Metrics
 
 // NodeProvider takes a config and a logger and returns a ready to go Node.
 type NodeProvider func(*cfg.Config, log.Logger) (*Node, error)
 
-// DefaultMetricsProvider returns consensus, p2p and mempool Metrics build
-// using Prometheus client library.
-func DefaultMetricsProvider() (*cs.Metrics, *mempl.Metrics) {
-	return cs.PrometheusMetrics(), mempl.PrometheusMetrics()
+// DefaultMetricsProvider returns the Metrics of the modules build using
+// Prometheus client library.
+func DefaultMetricsProvider() *Metrics {
+	return &Metrics{
+		Consensus:  cs.PrometheusMetrics(),
+		P2P:        p2p.PrometheusMetrics(),
+		Mempool:    mempl.PrometheusMetrics(),
+		Blockchain: bc.PrometheusMetrics(),
+		Utxo:       utxo.PrometheusMetrics(),
+		State:      state.PrometheusMetrics(),
+		RPC:        rpc.PrometheusMetrics(),
+	}
 }
 
 // DefaultNewNode returns a node with default settings for the
@@ -74,9 +99,17 @@ func DefaultNewNode(config *cfg.Config, logger log.Logger) (*Node, error) {
 	)
 }
 
-// NopMetricsProvider returns consensus, p2p and mempool Metrics as no-op.
-func NopMetricsProvider() (*cs.Metrics, *mempl.Metrics) {
-	return cs.NopMetrics(), mempl.NopMetrics()
+// NopMetricsProvider returns the Metrics of the modules as no-op.
+func NopMetricsProvider() *Metrics {
+	return &Metrics{
+		Consensus:  cs.NopMetrics(),
+		P2P:        p2p.NopMetrics(),
+		Mempool:    mempl.NopMetrics(),
+		Blockchain: bc.NopMetrics(),
+		Utxo:       utxo.NopMetrics(),
+		State:      state.NopMetrics(),
+		RPC:        rpc.NopMetrics(),
+	}
 }
 
 //------------------------------------------------------------------------------
@@ -108,7 +141,10 @@ type Node struct {
 
 	// rpc
 	//rpcContext *service.Context
-	rpcService *service.Service
+	rpcService    *service.Service
+	prometheusSrv *http.Server
+
+	tracer *trace.Tracer // exports the spans of the txs, nil if disabled
 }
 
 func makeAccountManager(config *cfg.Config) (*accounts.Manager, error) {
@@ -124,6 +160,23 @@ func makeAccountManager(config *cfg.Config) (*accounts.Manager, error) {
 	return accounts.NewManager(backends...), nil
 }
 
+// loadChainConfig sets the chain config scheduled in the genesis file, no fork is
+// scheduled if the node has no genesis file.
+func loadChainConfig(config *cfg.Config, logger log.Logger) error {
+	genFile := config.GenesisFile()
+	if !cmn.FileExists(genFile) {
+		logger.Info("Genesis file not found, no protocol upgrade scheduled", "path", genFile)
+		return nil
+	}
+	genDoc, err := types.GenesisDocFromFile(genFile)
+	if err != nil {
+		return err
+	}
+	cfg.SetChainConfig(genDoc.ChainConfig)
+	logger.Info("Load chain config", "config", cfg.GetChainConfig())
+	return nil
+}
+
 // NewNode returns a new, ready to go.
 func NewNode(config *cfg.Config,
 	privValidator types.PrivValidator,
@@ -147,6 +200,11 @@ func NewNode(config *cfg.Config,
 
 	logger.Info("GetSeeds:", "BootNodeSvr addr", config.BootNodeSvr.Addr, "localpubky hex", localPubKeyHex, "localNodeType", localNodeType)
 
+	// Load the fork heights of protocol upgrades
+	if err := loadChainConfig(config, logger); err != nil {
+		return nil, err
+	}
+
 	// Get BlockStore
 	blockStoreDB, err := dbProvider(&DBContext{"blockstore", config})
 	if err != nil {
@@ -161,6 +219,24 @@ func NewNode(config *cfg.Config,
 	}
 	balanceRecord := bc.NewBalanceRecordStore(balanceRecordStoreDB, config.SaveBalanceRecord)
 
+	// Get Contract Events Index Store
+	eventIndexDB, err := dbProvider(&DBContext{"event_index", config})
+	if err != nil {
+		return nil, err
+	}
+	eventIndex := bc.NewEventIndexStore(eventIndexDB, config.IndexContractEvent)
+
+	// Get Contract Verification Store
+	contractVerifyDB, err := dbProvider(&DBContext{"contract_verify", config})
+	if err != nil {
+		return nil, err
+	}
+	contractVerifier := verify.NewVerifier(verify.NewStore(contractVerifyDB), verify.Config{
+		Solc:    config.RPC.VerifySolc,
+		WasmCC:  config.RPC.VerifyWasmCC,
+		Timeout: config.RPC.VerifyTimeout,
+	})
+
 	// Get TxService
 	txDB, err := dbProvider(&DBContext{"txmgr", config})
 	if err != nil {
@@ -174,6 +250,26 @@ func NewNode(config *cfg.Config,
 	metrics.PrometheusMetricInstance().Init(config, privValidator.GetPubKey(), logger.With("module", "prometheus_metrics"))
 	metrics.PrometheusMetricInstance().SetRole(localNodeType)
 
+	// metrics of the modules
+	var nodeMetrics *Metrics
+	if config.Instrumentation.Prometheus {
+		nodeMetrics = metricsProvider()
+	} else {
+		nodeMetrics = NopMetricsProvider()
+	}
+	state.SetMetrics(nodeMetrics.State)
+	rpc.SetMetrics(nodeMetrics.RPC)
+
+	// tracing of the txs
+	var tracer *trace.Tracer
+	if config.Instrumentation.Tracing {
+		tracer, err = makeTracer(config)
+		if err != nil {
+			return nil, err
+		}
+		trace.SetTracer(tracer)
+	}
+
 	// Get Consensus Status
 	statusDB, err := dbProvider(&DBContext{"consensus_state", config})
 	if err != nil {
@@ -222,6 +318,7 @@ func NewNode(config *cfg.Config,
 	evidenceStore := evidence.NewEvidenceStore(evidenceDB)
 	evidencePool := evidence.NewEvidencePool(statusDB, evidenceStore, status.Copy())
 	evidencePool.SetLogger(evidenceLogger)
+	evidencePool.SetEventBus(eventBus)
 	evidenceReactor := evidence.NewEvidenceReactor(evidencePool)
 	evidenceReactor.SetLogger(evidenceLogger)
 
@@ -241,7 +338,7 @@ func NewNode(config *cfg.Config,
 	if err != nil {
 		return nil, err
 	}
-	utxoStore := utxo.NewUtxoStore(utxoDB, utxoOutputDB, utxoOutputTokenDB)
+	utxoStore := utxo.NewUtxoStore(utxoDB, utxoOutputDB, utxoOutputTokenDB, utxo.WithMetrics(nodeMetrics.Utxo))
 	utxoStore.SetLogger(logger.With("module", "utxoStore"))
 
 	//create app
@@ -251,6 +348,7 @@ func NewNode(config *cfg.Config,
 		return nil, err
 	}
 	appHandle.SetLogger(logger.With("module", "app"))
+	appHandle.SetEventIndexStore(eventIndex)
 
 	// make block executor for update consensus status
 	blockExec := cs.NewBlockExecutor(statusDB, logger, evidencePool)
@@ -273,16 +371,6 @@ func NewNode(config *cfg.Config,
 		status = newStatus.Copy()
 	}
 
-	// metrics
-	var (
-		csMetrics    *cs.Metrics
-		memplMetrics *mempl.Metrics
-	)
-	if config.Instrumentation.Prometheus {
-		csMetrics, memplMetrics = metricsProvider()
-	} else {
-		csMetrics, memplMetrics = NopMetricsProvider()
-	}
 	//types
 	typesLogger := logger.With("module", "types")
 	types.SetLogger(typesLogger)
@@ -294,7 +382,7 @@ func NewNode(config *cfg.Config,
 	p2pLogger := logger.With("module", "p2p")
 	localNodeInfo := MakeNodeInfo(status.ChainID, localNodeType, config.Moniker, config.RPC.HTTPEndpoint)
 	p2pmanager, err := p2p.NewP2pManager(p2pLogger, config.BootNodeSvr.Addr, privValidator.GetPrikey(), config.P2P,
-		localNodeInfo, seeds, p2pDB)
+		localNodeInfo, seeds, p2pDB, p2p.WithMetrics(nodeMetrics.P2P))
 	if err != nil {
 		logger.Warn("NewP2pManager failed")
 		return nil, err
@@ -308,7 +396,7 @@ func NewNode(config *cfg.Config,
 		config.Mempool,
 		status.LastBlockHeight,
 		p2pmanager,
-		mempl.WithMetrics(memplMetrics),
+		mempl.WithMetrics(nodeMetrics.Mempool),
 	)
 	mempool.SetLogger(mempoolLogger)
 	mempool.SetApp(appHandle)
@@ -334,7 +422,7 @@ func NewNode(config *cfg.Config,
 	}
 
 	// Make BlockchainReactor
-	bcReactor := bc.NewBlockchainReactor(status.Copy(), blockExec, appHandle, fastSync, p2pmanager)
+	bcReactor := bc.NewBlockchainReactor(status.Copy(), blockExec, appHandle, fastSync, p2pmanager, bc.WithMetrics(nodeMetrics.Blockchain))
 	bcReactor.SetLogger(logger.With("module", "blockchain"))
 	bcReactor.KeepFastSync(isTrie)
 
@@ -346,6 +434,14 @@ func NewNode(config *cfg.Config,
 	}
 
 	// Make ConsensusReactor
+	csOptions := []cs.CSOption{cs.WithMetrics(nodeMetrics.Consensus)}
+	if config.Consensus.TimelinePersist {
+		timelineDB, err := dbProvider(&DBContext{"cs_timeline", config})
+		if err != nil {
+			return nil, err
+		}
+		csOptions = append(csOptions, cs.WithTimelineDB(timelineDB))
+	}
 	consensusState := cs.NewConsensusState(
 		config.Consensus,
 		status.Copy(),
@@ -353,7 +449,7 @@ func NewNode(config *cfg.Config,
 		appHandle,
 		mempool,
 		evidencePool,
-		cs.WithMetrics(csMetrics),
+		csOptions...,
 	)
 	consensusState.SetEventBus(eventBus)
 	consensusState.SetLogger(consensusLogger)
@@ -386,12 +482,16 @@ func NewNode(config *cfg.Config,
 	rpcContext.SetAccountManager(accountManager)
 	rpcContext.SetBlockstore(blockStore)
 	rpcContext.SetBalanceRecordStore(balanceRecord)
+	rpcContext.SetEventIndexStore(eventIndex)
+	rpcContext.SetContractVerifier(contractVerifier)
+	rpcContext.SetEvidencePool(evidencePool)
 	rpcContext.SetTrieDB(newDB, isTrie)
 	rpcContext.SetStateDB(statusDB)
 	rpcContext.SetPubKey(privValidator.GetPubKey())
 	rpcContext.SetSwitch(p2pmanager)
 	rpcContext.SetConsensus(consensusState)
 	rpcContext.SetConsensusReactor(consensusReactor)
+	rpcContext.SetBlockchainReactor(bcReactor)
 	rpcContext.SetMempool(mempool)
 	rpcContext.SetApp(appHandle)
 	rpcContext.SetUTXO(utxoStore)
@@ -425,12 +525,36 @@ func NewNode(config *cfg.Config,
 		evidencePool:     evidencePool,
 		eventBus:         eventBus,
 		rpcService:       rpcService,
+		tracer:           tracer,
 	}
 
 	node.BaseService = *cmn.NewBaseService(logger, "Node", node)
 	return node, nil
 }
 
+// makeTracer returns the Tracer exporting the spans by the exporter configured.
+func makeTracer(config *cfg.Config) (*trace.Tracer, error) {
+	conf := config.Instrumentation
+	var exporter trace.Exporter
+	switch conf.TracingExporter {
+	case "otlp":
+		exporter = trace.NewOTLPExporter(conf.TracingEndpoint, "linkchain")
+	case "file":
+		filename := conf.TracingFile
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(config.LogDir(), filename)
+		}
+		fileExporter, err := trace.NewFileExporter(filename)
+		if err != nil {
+			return nil, err
+		}
+		exporter = fileExporter
+	default:
+		return nil, fmt.Errorf("Unknown tracing exporter: %s", conf.TracingExporter)
+	}
+	return trace.NewTracer(exporter, conf.TracingSampleRatio), nil
+}
+
 // OnStart starts the Node. It implements cmn.Service.
 func (n *Node) OnStart() error {
 	err := n.eventBus.Start()
@@ -453,9 +577,33 @@ func (n *Node) OnStart() error {
 		go n.ClearHistoricalData()
 	}
 
+	if n.config.Instrumentation.Prometheus && n.config.Instrumentation.PrometheusListenAddr != "" {
+		n.prometheusSrv = n.startPrometheusServer(n.config.Instrumentation.PrometheusListenAddr)
+	}
+
 	return nil
 }
 
+// startPrometheusServer serves the Prometheus metrics under /metrics and the
+// probes of the node on addr.
+func (n *Node) startPrometheusServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	for _, route := range n.rpcService.ProbeRoutes() {
+		mux.Handle(route.Path, route.Handler)
+	}
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			n.Logger.Error("Prometheus HTTP server ListenAndServe", "err", err)
+		}
+	}()
+	return srv
+}
+
 // OnStop stops the Node. It implements cmn.Service.
 func (n *Node) OnStop() {
 	n.BaseService.OnStop()
@@ -470,6 +618,14 @@ func (n *Node) OnStop() {
 	n.p2pmanager.Stop()
 
 	n.rpcService.Stop()
+
+	if n.prometheusSrv != nil {
+		if err := n.prometheusSrv.Shutdown(context.Background()); err != nil {
+			n.Logger.Error("Prometheus HTTP server Shutdown", "err", err)
+		}
+	}
+
+	n.tracer.Stop()
 }
 
 // RunForever waits for an interrupt signal and stops the node.
@@ -539,6 +695,7 @@ func MakeNodeInfo(chainID string, nodeType types.NodeType, moniker string, httpE
 		Other: []string{
 			cmn.Fmt("p2p_version=%v", p2p.Version),
 			cmn.Fmt("consensus_version=%v", cs.Version),
+			cmn.Fmt("blockchain_version=%v", bc.Version),
 		},
 		Type: nodeType,
 	}
@@ -342,6 +342,15 @@ func (s *PublicDebugAPI) GetBlockHeader(ctx context.Context, number rpc.BlockNum
 	return &rtypes.ResultBlockHeader{header}, nil
 }
 
+// WasmGasSchedule returns the wasm gas schedule active at the block.
+func (s *PublicDebugAPI) WasmGasSchedule(ctx context.Context, number rpc.BlockNumber) (*cfg.WasmGasSchedule, error) {
+	header, _ := s.b.HeaderByNumber(ctx, number)
+	if header == nil {
+		return nil, fmt.Errorf("block meta #%d not found", number)
+	}
+	return cfg.GetChainConfig().WasmGasSchedule(header.Height), nil
+}
+
 type PublicMempoolAPI struct {
 	b Backend
 }
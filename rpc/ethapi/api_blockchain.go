@@ -2,13 +2,17 @@ package ethapi
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
 
+	"github.com/lianxiangcloud/linkchain/accounts/wasmabi"
 	"github.com/lianxiangcloud/linkchain/app"
 	cfg "github.com/lianxiangcloud/linkchain/config"
 	"github.com/lianxiangcloud/linkchain/libs/common"
+	lktypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
 	"github.com/lianxiangcloud/linkchain/libs/hexutil"
 	"github.com/lianxiangcloud/linkchain/libs/log"
 	"github.com/lianxiangcloud/linkchain/libs/math"
@@ -63,6 +67,16 @@ func (s *PublicBlockChainAPI) Status() (*rtypes.ResultStatus, error) {
 	return s.b.Status()
 }
 
+// Syncing returns false if the node is not fast syncing, otherwise the progress
+// of the fast sync: the heights, the rate, the ETA and the throughput of the peers.
+func (s *PublicBlockChainAPI) Syncing() (interface{}, error) {
+	progress := s.b.SyncProgress()
+	if progress == nil || !progress.Syncing {
+		return false, nil
+	}
+	return progress, nil
+}
+
 // BlockNumber returns the block number of the chain head.
 func (s *PublicBlockChainAPI) BlockNumber() *big.Int {
 	header, _ := s.b.HeaderByNumber(context.Background(), rpc.LatestBlockNumber) // latest header should always be available
@@ -186,6 +200,37 @@ type CallArgs struct {
 	Nonce        hexutil.Uint64     `json:"nonce"`
 	UTXOKind     types.UTXOKind     `json:"utxokind"`
 	Outputs      []types.OutputData `json:"outputs"`
+	// Method and Args call the action of a wasm contract with typed args instead
+	// of data, the args are type checked if the ABI of the contract is given.
+	Method string            `json:"method"`
+	Args   []json.RawMessage `json:"args"`
+	ABI    *wasmabi.ABI      `json:"abi"`
+}
+
+// packWasmInput sets the data to the input of the wasm action call if a method
+// is given.
+func (args *CallArgs) packWasmInput() error {
+	if args.Method == "" {
+		return nil
+	}
+	if len(args.Data) > 0 {
+		return errors.New("both data and method are given")
+	}
+
+	var (
+		input []byte
+		err   error
+	)
+	if args.ABI != nil {
+		input, err = args.ABI.PackJSON(args.Method, args.Args)
+	} else {
+		input, err = wasmabi.PackRaw(args.Method, args.Args)
+	}
+	if err != nil {
+		return err
+	}
+	args.Data = input
+	return nil
 }
 
 func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg evm.Config, timeout time.Duration) ([]byte, uint64, uint64, bool, error) {
@@ -283,6 +328,9 @@ func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr r
 	if !s.b.EVMAllowed() {
 		return nil, types.ErrMempoolIsFull
 	}
+	if err := args.packWasmInput(); err != nil {
+		return nil, err
+	}
 	result, _, _, _, err := s.doCall(ctx, args, blockNr, evm.Config{}, 5*time.Second)
 	return (hexutil.Bytes)(result), err
 }
@@ -293,12 +341,117 @@ func (s *PublicBlockChainAPI) TokenCall(ctx context.Context, args CallArgs, bloc
 	return s.Call(ctx, args, blockNr)
 }
 
+// WasmCall calls the action of a wasm contract given by the method, args and ABI
+// on the state for the given block number, and returns the output decoded by the ABI.
+func (s *PublicBlockChainAPI) WasmCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (interface{}, error) {
+	if args.ABI == nil || args.Method == "" {
+		return nil, errors.New("abi and method are required")
+	}
+	result, err := s.Call(ctx, args, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	return args.ABI.Unpack(args.Method, result)
+}
+
+const (
+	defaultContractEventsLimit = 100
+	maxContractEventsLimit     = 1000
+)
+
+// ContractEventsArgs represents the arguments to query the indexed events of a contract.
+type ContractEventsArgs struct {
+	Address   common.Address   `json:"address"`
+	Event     string           `json:"event"` // all the events of the contract if empty
+	ABI       *wasmabi.ABI     `json:"abi"`   // decodes the events if given
+	FromBlock *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock   *rpc.BlockNumber `json:"toBlock"`
+	Cursor    hexutil.Bytes    `json:"cursor"` // the next of the previous page
+	Limit     int              `json:"limit"`
+}
+
+// ContractEvent is an indexed event log, decoded if its event is described by the ABI.
+type ContractEvent struct {
+	Name string        `json:"name,omitempty"`
+	Args []interface{} `json:"args,omitempty"`
+	Log  *types.Log    `json:"log"`
+}
+
+// ContractEventsResult is a page of the indexed events, Next is the cursor of
+// the following page, or empty if this is the last page.
+type ContractEventsResult struct {
+	Events []*ContractEvent `json:"events"`
+	Next   hexutil.Bytes    `json:"next,omitempty"`
+}
+
+// GetContractEvents returns the events of the contract from the contract event
+// index in order of the blocks, a page at a time. The node has to be started with
+// --index_contract_event.
+func (s *PublicBlockChainAPI) GetContractEvents(ctx context.Context, args ContractEventsArgs) (*ContractEventsResult, error) {
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultContractEventsLimit
+	}
+	if limit > maxContractEventsLimit {
+		limit = maxContractEventsLimit
+	}
+
+	header, err := s.b.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if header == nil || err != nil {
+		return nil, fmt.Errorf("latest header not found: %v", err)
+	}
+	from, to := uint64(0), header.Height
+	if args.FromBlock != nil && *args.FromBlock >= 0 {
+		from = uint64(*args.FromBlock)
+	}
+	if args.ToBlock != nil && *args.ToBlock >= 0 && uint64(*args.ToBlock) < to {
+		to = uint64(*args.ToBlock)
+	}
+	if from > to {
+		return &ContractEventsResult{Events: []*ContractEvent{}}, nil
+	}
+
+	// the wasm contracts log the event name by TC_Log1, or its hash by TC_Notify
+	var topics []common.Hash
+	if args.Event != "" {
+		event := wasmabi.Event{Name: args.Event}
+		topics = []common.Hash{event.Topic()}
+		if event.NotifyTopic() != event.Topic() {
+			topics = append(topics, event.NotifyTopic())
+		}
+	}
+
+	logs, next, err := s.b.ContractEvents(ctx, args.Address, topics, from, to, args.Cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := &ContractEventsResult{
+		Events: make([]*ContractEvent, 0, len(logs)),
+		Next:   next,
+	}
+	for _, l := range logs {
+		e := &ContractEvent{Log: l}
+		if args.ABI != nil && len(l.Topics) > 0 {
+			if event := args.ABI.EventByTopic(l.Topics[0]); event != nil {
+				e.Name = event.Name
+				// events the ABI mismatches are returned undecoded
+				e.Args, _ = args.ABI.UnpackEvent(event.Name, l.Data)
+			}
+		}
+		result.Events = append(result.Events, e)
+	}
+	return result, nil
+}
+
 // EstimateGas returns an estimate of the amount of gas needed to execute the
 // given transaction against the current pending block.
 func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (hexutil.Uint64, error) {
 	if !s.b.EVMAllowed() {
 		return hexutil.Uint64(0), types.ErrMempoolIsFull
 	}
+	if err := args.packWasmInput(); err != nil {
+		return hexutil.Uint64(0), err
+	}
 
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
@@ -376,6 +529,15 @@ func (s *PublicBlockChainAPI) GetOutputs(ctx context.Context, args []OutputArg)
 	return outputs, nil
 }
 
+// GetKeyImagesSpent report whether each of the given key images has been spent on chain
+func (s *PublicBlockChainAPI) GetKeyImagesSpent(ctx context.Context, keyImages []rtypes.RPCKey) ([]bool, error) {
+	spent := make([]bool, len(keyImages))
+	for i := 0; i < len(keyImages); i++ {
+		spent[i] = s.b.IsKeyImageSpent(ctx, lktypes.Key(keyImages[i]))
+	}
+	return spent, nil
+}
+
 //GetAllCandidates Get All Candidates from contracts
 func (s *PublicBlockChainAPI) GetAllCandidates(ctx context.Context, blockNr rpc.BlockNumber) ([]*types.CandidateState, error) {
 	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
@@ -412,3 +574,164 @@ func (s *PublicBlockChainAPI) GetBlockUTXOsByNumber(ctx context.Context, blockNr
 func (s *PublicBlockChainAPI) GetUTXOGas(ctx context.Context) (hexutil.Uint64, error) {
 	return hexutil.Uint64(s.b.GetUTXOGas()), nil
 }
+
+// UTXOGasArgs is the template of a UTXO transaction to estimate the fee of, the
+// transaction needs not be signed. The kind of the transaction is given by the
+// inputs and the outputs of the template.
+type UTXOGasArgs struct {
+	From           common.Address     `json:"from"`           // owner of the account input
+	Nonce          *hexutil.Uint64    `json:"nonce"`          // nonce of the account input, the pending nonce if omitted
+	AccountInput   *hexutil.Big       `json:"accountInput"`   // amount spent from the account excluding the fee, no account input if omitted
+	UTXOInputs     hexutil.Uint64     `json:"utxoInputs"`     // number of the UTXO inputs
+	UTXOOutputs    hexutil.Uint64     `json:"utxoOutputs"`    // number of the UTXO outputs
+	AccountOutputs []types.OutputData `json:"accountOutputs"` // calls the contract if the first output is a contract
+	TokenID        common.Address     `json:"tokenID"`
+}
+
+// UTXOGasResult is the estimated gas of a UTXO transaction, Fee is the fee of the
+// transaction paying the gas.
+type UTXOGasResult struct {
+	Gas hexutil.Uint64 `json:"gas"`
+	Fee *hexutil.Big   `json:"fee"`
+}
+
+// kind returns the UTXO kind of the transaction.
+func (args *UTXOGasArgs) kind() types.UTXOKind {
+	var kind types.UTXOKind
+	if args.UTXOInputs > 0 {
+		kind |= types.Uin
+	}
+	if args.AccountInput != nil {
+		kind |= types.Ain
+	}
+	if args.UTXOOutputs > 0 {
+		kind |= types.Uout
+	}
+	if len(args.AccountOutputs) > 0 {
+		kind |= types.Aout
+	}
+	return kind
+}
+
+// EstimateUTXOGas returns the gas and the fee the UTXO transaction needs against
+// the current pending block. The fee covers the value transfer and the UTXO inputs
+// as the mempool requires, and, if the transaction calls a contract, is binary
+// searched to the lowest one the call succeeds with.
+func (s *PublicBlockChainAPI) EstimateUTXOGas(ctx context.Context, args UTXOGasArgs) (*UTXOGasResult, error) {
+	if !s.b.EVMAllowed() {
+		return nil, types.ErrMempoolIsFull
+	}
+	kind := args.kind()
+	if kind&(types.Uin|types.Ain) == 0 || kind&(types.Uout|types.Aout) == 0 {
+		return nil, errors.New("inputs and outputs are required")
+	}
+	if int(args.UTXOOutputs) > types.BULLETPROOF_MAX_OUTPUTS {
+		return nil, fmt.Errorf("too many utxo outputs, should be at most %d", types.BULLETPROOF_MAX_OUTPUTS)
+	}
+	for _, output := range args.AccountOutputs {
+		if output.Amount == nil || output.Amount.Sign() < 0 {
+			return nil, errors.New("invalid account output amount")
+		}
+	}
+
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, rpc.PendingBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+
+	// the gas the mempool requires, the fee is not part of the transferred value
+	var (
+		accountInput = new(big.Int)
+		neededGas    uint64
+	)
+	if args.AccountInput != nil {
+		accountInput = args.AccountInput.ToInt()
+		if accountInput.Sign() < 0 {
+			return nil, errors.New("invalid account input amount")
+		}
+		if accountInput.Sign() > 0 {
+			neededGas += types.CalNewAmountGas(accountInput)
+		}
+	}
+	if kind&types.Uin == types.Uin {
+		neededGas += s.b.GetUTXOGas()
+	}
+	if kind&types.Aout != types.Aout || !state.IsContract(args.AccountOutputs[0].To) {
+		return newUTXOGasResult(neededGas), nil
+	}
+
+	// the account input pays the fee, which limits the gas to the balance left
+	gasPrice := big.NewInt(types.ParGasPrice)
+	hi := uint64(types.MaxGasLimit * 2)
+	if kind&types.Ain == types.Ain {
+		balance := state.GetTokenBalance(args.From, args.TokenID)
+		if balance.Cmp(accountInput) < 0 {
+			return nil, types.ErrInsufficientFunds
+		}
+		allowance := new(big.Int).Div(new(big.Int).Sub(balance, accountInput), gasPrice)
+		if allowance.IsUint64() && allowance.Uint64() < hi {
+			hi = allowance.Uint64()
+		}
+	}
+	if hi < neededGas {
+		return nil, types.ErrInsufficientFunds
+	}
+
+	callArgs := CallArgs{
+		From:         args.From,
+		TokenAddress: args.TokenID,
+		GasPrice:     hexutil.Big(*gasPrice),
+		UTXOKind:     kind,
+		Outputs:      args.AccountOutputs,
+	}
+	if args.Nonce != nil {
+		callArgs.Nonce = *args.Nonce
+	}
+	executable := func(gas uint64) (bool, error) {
+		callArgs.Gas = hexutil.Uint64(gas)
+		if kind&types.Ain == types.Ain {
+			fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas))
+			callArgs.Value = hexutil.Big(*fee.Add(fee, accountInput))
+		}
+		_, _, _, failed, err := s.doCall(ctx, callArgs, rpc.PendingBlockNumber, evm.Config{}, 0)
+		if err == evm.ErrOutOfGas {
+			// the gas does not cover the intrinsic gas of the call
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return !failed, nil
+	}
+	if ok, err := executable(hi); !ok {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("gas required exceeds allowance or always failing transaction")
+	}
+
+	// the call needs at least the intrinsic gas, so no gas always fails
+	lo := uint64(0)
+	if neededGas > 0 {
+		lo = neededGas - 1
+	}
+	for lo+1 < hi {
+		mid := (lo + hi) / 2
+		ok, err := executable(mid)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	log.Debug("EstimateUTXOGas", "kind", kind, "neededGas", neededGas, "estimateGas", hi)
+	return newUTXOGasResult(hi), nil
+}
+
+func newUTXOGasResult(gas uint64) *UTXOGasResult {
+	fee := new(big.Int).Mul(big.NewInt(types.ParGasPrice), new(big.Int).SetUint64(gas))
+	return &UTXOGasResult{Gas: hexutil.Uint64(gas), Fee: (*hexutil.Big)(fee)}
+}
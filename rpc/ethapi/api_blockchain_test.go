@@ -1,13 +1,20 @@
 package ethapi
 
 import (
+	"context"
 	"math/big"
 	"testing"
 
+	cfg "github.com/lianxiangcloud/linkchain/config"
 	"github.com/lianxiangcloud/linkchain/libs/common"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
 	"github.com/lianxiangcloud/linkchain/libs/rpc"
 	"github.com/lianxiangcloud/linkchain/rpc/rtypes"
+	"github.com/lianxiangcloud/linkchain/state"
 	"github.com/lianxiangcloud/linkchain/types"
+	"github.com/lianxiangcloud/linkchain/vm"
+	"github.com/lianxiangcloud/linkchain/vm/evm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -51,6 +58,116 @@ func TestGetBlockBy(t *testing.T) {
 
 }
 
+func TestEstimateUTXOGasArgs(t *testing.T) {
+	b := &MockBackend{}
+	s := NewPublicBlockChainAPI(b)
+	b.On("EVMAllowed").Return(true)
+
+	assert := assert.New(t)
+	amount := (*hexutil.Big)(big.NewInt(1))
+	to := common.HexToAddress("0x1")
+
+	tests := []struct {
+		args UTXOGasArgs
+		kind types.UTXOKind
+	}{
+		{UTXOGasArgs{UTXOInputs: 2, UTXOOutputs: 3}, types.UinUout},
+		{UTXOGasArgs{AccountInput: amount, UTXOOutputs: 1}, types.AinUout},
+		{UTXOGasArgs{UTXOInputs: 1, AccountOutputs: []types.OutputData{{To: to, Amount: big.NewInt(1)}}}, types.UinAout},
+		{UTXOGasArgs{UTXOInputs: 1, AccountInput: amount, AccountOutputs: []types.OutputData{{To: to, Amount: big.NewInt(1)}}}, types.MixinAout},
+	}
+	for i, tt := range tests {
+		assert.Equal(tt.kind, tt.args.kind(), "test %d", i)
+	}
+
+	for i, args := range []UTXOGasArgs{
+		{UTXOOutputs: 1},
+		{UTXOInputs: 1},
+		{UTXOInputs: 1, UTXOOutputs: hexutil.Uint64(types.BULLETPROOF_MAX_OUTPUTS + 1)},
+		{UTXOInputs: 1, AccountOutputs: []types.OutputData{{To: to}}},
+	} {
+		_, err := s.EstimateUTXOGas(nil, args)
+		assert.NotNil(err, "test %d", i)
+	}
+}
+
+// utxoCallVM is a VM whose contract calls succeed with callGas gas or more
+type utxoCallVM struct {
+	vm.VmInterface
+	state   *state.StateDB
+	callGas uint64
+}
+
+func (v *utxoCallVM) Reset(types.Message)         {}
+func (v *utxoCallVM) Cancel()                     {}
+func (v *utxoCallVM) SetToken(common.Address)     {}
+func (v *utxoCallVM) AddOtx(types.BalanceRecord)  {}
+func (v *utxoCallVM) GetCoinbase() common.Address { return common.EmptyAddress }
+func (v *utxoCallVM) GetBlockNumber() *big.Int    { return big.NewInt(1) }
+func (v *utxoCallVM) GasRate() uint64             { return 1 }
+func (v *utxoCallVM) GetStateDB() types.StateDB   { return v.state }
+func (v *utxoCallVM) UTXOCall(caller types.ContractRef, addr, token common.Address, input []byte, gas uint64, value *big.Int) ([]byte, uint64, uint64, error) {
+	if gas < v.callGas {
+		return nil, 0, 0, evm.ErrOutOfGas
+	}
+	return nil, gas - v.callGas, 0, nil
+}
+
+func TestEstimateUTXOGas(t *testing.T) {
+	var (
+		from     = common.HexToAddress("0xf")
+		contract = common.HexToAddress("0xc")
+		input    = big.NewInt(1e18)
+		callGas  = uint64(30000)
+		gasPrice = big.NewInt(types.ParGasPrice)
+		balance  *big.Int
+	)
+	b := &MockBackend{}
+	s := NewPublicBlockChainAPI(b)
+	b.On("EVMAllowed").Return(true)
+	b.On("StateAndHeaderByNumber", mock.Anything, mock.Anything).Return(func(ctx context.Context, blockNr rpc.BlockNumber) *state.StateDB {
+		st, _ := state.New(common.EmptyHash, state.NewDatabase(dbm.NewMemDB()))
+		st.SetCode(contract, []byte{1})
+		st.SetTokenBalance(from, common.EmptyAddress, balance)
+		return st
+	}, &types.Header{Height: 1}, nil)
+	b.On("GetVM", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(func(ctx context.Context, msg types.Message, st *state.StateDB, header *types.Header, vmCfg evm.Config) vm.VmInterface {
+		return &utxoCallVM{state: st, callGas: callGas}
+	}, func() error { return nil }, nil)
+
+	assert := assert.New(t)
+	args := UTXOGasArgs{
+		From:           from,
+		AccountInput:   (*hexutil.Big)(input),
+		AccountOutputs: []types.OutputData{{To: contract, Amount: big.NewInt(0)}},
+	}
+	// the intrinsic gas, the gas of the transferred value and the gas of the call
+	want := cfg.TxGas + types.CalNewAmountGas(input) + callGas
+	allowance := func(gas uint64) *big.Int {
+		return new(big.Int).Add(input, new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas)))
+	}
+
+	balance = allowance(types.MaxGasLimit)
+	res, err := s.EstimateUTXOGas(context.Background(), args)
+	assert.Nil(err)
+	assert.Equal(hexutil.Uint64(want), res.Gas)
+	assert.Equal(0, res.Fee.ToInt().Cmp(new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(want))))
+
+	// the balance left pays the fee of exactly the gas needed
+	balance = allowance(want)
+	res, err = s.EstimateUTXOGas(context.Background(), args)
+	assert.Nil(err)
+	assert.Equal(hexutil.Uint64(want), res.Gas)
+
+	balance = allowance(want - 1)
+	_, err = s.EstimateUTXOGas(context.Background(), args)
+	assert.EqualError(err, "gas required exceeds allowance or always failing transaction")
+
+	balance = new(big.Int).Sub(input, big.NewInt(1))
+	_, err = s.EstimateUTXOGas(context.Background(), args)
+	assert.Equal(types.ErrInsufficientFunds, err)
+}
+
 func getTestBlock() *types.Block {
 	txs, _ := getTestTxs()
 
@@ -0,0 +1,46 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lianxiangcloud/linkchain/contract/verify"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/rpc"
+)
+
+// PublicContractAPI provides an API to verify the deployed contracts against
+// their sources, and to read the ABIs of the verified contracts.
+type PublicContractAPI struct {
+	b Backend
+}
+
+// NewPublicContractAPI creates a new contract verification API.
+func NewPublicContractAPI(b Backend) *PublicContractAPI {
+	return &PublicContractAPI{b}
+}
+
+// Verify verifies the contract against its code in the latest state, by
+// compiling the sources or by comparing the artifact of the request, and keeps
+// the verified contract on the node.
+func (s *PublicContractAPI) Verify(ctx context.Context, req verify.Request) (*verify.Contract, error) {
+	verifier := s.b.ContractVerifier()
+	if verifier == nil {
+		return nil, errors.New("contract verifier not available")
+	}
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	return verifier.Verify(ctx, &req, state.GetCode(req.Address), header.Height)
+}
+
+// GetVerified returns the verified contract at addr with its sources and ABI,
+// or nil if the contract is not verified.
+func (s *PublicContractAPI) GetVerified(ctx context.Context, addr common.Address) (*verify.Contract, error) {
+	verifier := s.b.ContractVerifier()
+	if verifier == nil {
+		return nil, errors.New("contract verifier not available")
+	}
+	return verifier.Get(addr), nil
+}
@@ -0,0 +1,381 @@
+package ethapi
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/lianxiangcloud/linkchain/app"
+	"github.com/lianxiangcloud/linkchain/config"
+	"github.com/lianxiangcloud/linkchain/contract/gov"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
+	"github.com/lianxiangcloud/linkchain/libs/rpc"
+	"github.com/lianxiangcloud/linkchain/rpc/rtypes"
+	"github.com/lianxiangcloud/linkchain/state"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+// Proposal status filters of gov_getProposals.
+const (
+	ProposalStatusOpen     = "open"
+	ProposalStatusFinished = "finished"
+)
+
+// PublicGovAPI provides an API to read the built-in governance contracts,
+// pledges, proposals, coefficients and awards.
+type PublicGovAPI struct {
+	b Backend
+}
+
+// NewPublicGovAPI creates a new governance API.
+func NewPublicGovAPI(b Backend) *PublicGovAPI {
+	return &PublicGovAPI{b}
+}
+
+// RPCElector is the pledge state of an elector.
+type RPCElector struct {
+	Address common.Address `json:"address"`
+	*gov.ElectorInfo
+	StatusName string `json:"statusName"`
+}
+
+// RPCDepositorRecord is a pledge record of a depositor with the elector it pledged to.
+type RPCDepositorRecord struct {
+	Elector common.Address `json:"elector"`
+	*gov.PledgeRecord
+}
+
+// RPCProposal is a committee proposal with its tally.
+type RPCProposal struct {
+	*gov.Proposal
+	Tally *gov.Tally `json:"tally"`
+}
+
+// RPCValidatorCoefficient is the ranking coefficients of a validator.
+type RPCValidatorCoefficient struct {
+	Address     string         `json:"address"`
+	CoinBase    common.Address `json:"coinbase"`
+	VotingPower int64          `json:"votingPower"`
+	Deposit     *big.Int       `json:"deposit"`
+	VoteCnts    *big.Int       `json:"voteCnts"`
+	ShareRate   uint32         `json:"shareRate"`
+	Score       *uint64        `json:"score"` // nil if the validator is not a candidate
+}
+
+// RPCValidatorCoefficients is the coefficients of the chain and of each validator.
+type RPCValidatorCoefficients struct {
+	Coefficient *types.Coefficient         `json:"coefficient"`
+	Validators  []*RPCValidatorCoefficient `json:"validators"`
+}
+
+// RPCCandidateAdjust is the extra deposit and score of a candidate simulated
+// by gov_previewValidators.
+type RPCCandidateAdjust struct {
+	CoinBase common.Address `json:"coinbase"`
+	Deposit  *hexutil.Big   `json:"deposit"` // wei, the votes pledged to the candidate included
+	Score    int64          `json:"score"`
+}
+
+// RPCBlockAwards is the award distribution of a block decoded from its balance records.
+type RPCBlockAwards struct {
+	Height   hexutil.Uint64 `json:"height"`
+	Coinbase common.Address `json:"coinbase"`
+	// Poceeds is the fees paid to the foundation, which are added to the award pool of the coinbase.
+	Poceeds *hexutil.Big              `json:"poceeds"`
+	Fees    []rtypes.RPCBalanceRecord `json:"fees"`
+	// AllocHeight reports whether the awards are allocated at the block.
+	AllocHeight bool `json:"allocHeight"`
+	// Awards is the transfers out of the foundation.
+	Awards []rtypes.RPCBalanceRecord `json:"awards"`
+}
+
+func (s *PublicGovAPI) stateAt(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	if !s.b.EVMAllowed() {
+		return nil, nil, types.ErrMempoolIsFull
+	}
+	st, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if st == nil || header == nil {
+		return nil, nil, fmt.Errorf("block %d not found", blockNr)
+	}
+	return st, header, nil
+}
+
+// electors returns the winout electors and the coinbases of the candidates.
+func (s *PublicGovAPI) electors(st *state.StateDB) ([]common.Address, error) {
+	deposits, err := gov.Pledge.Deposits(st)
+	if err != nil {
+		return nil, err
+	}
+	cands, err := gov.Candidates.AllCandidates(st)
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[common.Address]struct{}, len(deposits)+len(cands))
+	for addr := range deposits {
+		set[addr] = struct{}{}
+	}
+	for _, cand := range cands {
+		set[cand.CoinBase] = struct{}{}
+	}
+	addrs := make([]common.Address, 0, len(set))
+	for addr := range set {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].String() < addrs[j].String() })
+	return addrs, nil
+}
+
+// GetElectors returns the pledge state of the winout electors and the candidates.
+func (s *PublicGovAPI) GetElectors(ctx context.Context, blockNr rpc.BlockNumber) ([]*RPCElector, error) {
+	st, _, err := s.stateAt(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := s.electors(st)
+	if err != nil {
+		return nil, err
+	}
+	electors := make([]*RPCElector, 0, len(addrs))
+	for _, addr := range addrs {
+		info, err := gov.Pledge.ElectorInfo(st, addr)
+		if err != nil {
+			return nil, err
+		}
+		electors = append(electors, &RPCElector{Address: addr, ElectorInfo: info, StatusName: info.Status.String()})
+	}
+	return electors, nil
+}
+
+// GetElector returns the pledge state of the elector.
+func (s *PublicGovAPI) GetElector(ctx context.Context, elector common.Address, blockNr rpc.BlockNumber) (*RPCElector, error) {
+	st, _, err := s.stateAt(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	info, err := gov.Pledge.ElectorInfo(st, elector)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCElector{Address: elector, ElectorInfo: info, StatusName: info.Status.String()}, nil
+}
+
+// GetPledgeRecordsByElector returns the pledge records of the elector.
+func (s *PublicGovAPI) GetPledgeRecordsByElector(ctx context.Context, elector common.Address, blockNr rpc.BlockNumber) ([]*gov.PledgeRecord, error) {
+	st, _, err := s.stateAt(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	return gov.Pledge.PledgeRecords(st, elector)
+}
+
+// GetPledgeRecordsByDepositor returns the pledge records of the depositor to the
+// winout electors and the candidates.
+func (s *PublicGovAPI) GetPledgeRecordsByDepositor(ctx context.Context, depositor common.Address, blockNr rpc.BlockNumber) ([]*RPCDepositorRecord, error) {
+	st, _, err := s.stateAt(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := s.electors(st)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*RPCDepositorRecord, 0)
+	for _, addr := range addrs {
+		rs, err := gov.Pledge.PledgeRecords(st, addr)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rs {
+			if r.Sender == depositor {
+				records = append(records, &RPCDepositorRecord{Elector: addr, PledgeRecord: r})
+			}
+		}
+	}
+	return records, nil
+}
+
+// GetCommittee returns the members of the committee.
+func (s *PublicGovAPI) GetCommittee(ctx context.Context, blockNr rpc.BlockNumber) ([]common.Address, error) {
+	st, _, err := s.stateAt(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	return gov.Committee.Members(st)
+}
+
+// GetProposals returns the committee proposals with their tallies, status filters
+// the proposals by "open" or "finished", all the proposals are returned if empty.
+func (s *PublicGovAPI) GetProposals(ctx context.Context, status string, blockNr rpc.BlockNumber) ([]*RPCProposal, error) {
+	if status != "" && status != ProposalStatusOpen && status != ProposalStatusFinished {
+		return nil, fmt.Errorf("invalid proposal status %q", status)
+	}
+	st, _, err := s.stateAt(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	members, err := gov.Committee.Members(st)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := gov.Committee.ProposalIDs(st)
+	if err != nil {
+		return nil, err
+	}
+	proposals := make([]*RPCProposal, 0, len(ids))
+	for _, id := range ids {
+		p, err := gov.Committee.Proposal(st, id)
+		if err != nil {
+			return nil, err
+		}
+		if (status == ProposalStatusOpen && p.Finished) || (status == ProposalStatusFinished && !p.Finished) {
+			continue
+		}
+		proposals = append(proposals, &RPCProposal{Proposal: p, Tally: gov.NewTally(p, members)})
+	}
+	return proposals, nil
+}
+
+// GetProposal returns the committee proposal with its tally.
+func (s *PublicGovAPI) GetProposal(ctx context.Context, id string, blockNr rpc.BlockNumber) (*RPCProposal, error) {
+	st, _, err := s.stateAt(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	members, err := gov.Committee.Members(st)
+	if err != nil {
+		return nil, err
+	}
+	p, err := gov.Committee.Proposal(st, id)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCProposal{Proposal: p, Tally: gov.NewTally(p, members)}, nil
+}
+
+// GetCoefficients returns the coefficients of the chain and the ranking
+// coefficients of each validator.
+func (s *PublicGovAPI) GetCoefficients(ctx context.Context, blockNr rpc.BlockNumber) (*RPCValidatorCoefficients, error) {
+	st, header, err := s.stateAt(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	co, err := gov.Coefficient.Coefficient(st)
+	if err != nil {
+		return nil, err
+	}
+	height := header.Height
+	vals, err := s.b.Validators(&height)
+	if err != nil {
+		return nil, err
+	}
+	cands, err := gov.Candidates.AllCandidates(st)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[common.Address]uint64, len(cands))
+	for _, cand := range cands {
+		scores[cand.CoinBase] = cand.Score
+	}
+
+	ret := &RPCValidatorCoefficients{Coefficient: co, Validators: make([]*RPCValidatorCoefficient, 0, len(vals.Validators))}
+	for _, val := range vals.Validators {
+		info, err := gov.Pledge.ElectorInfo(st, val.CoinBase)
+		if err != nil {
+			return nil, err
+		}
+		vc := &RPCValidatorCoefficient{
+			Address:     val.Address.String(),
+			CoinBase:    val.CoinBase,
+			VotingPower: val.VotingPower,
+			Deposit:     info.TotalAmount,
+			VoteCnts:    info.VoteCnts,
+			ShareRate:   info.ShareRate,
+		}
+		if score, ok := scores[val.CoinBase]; ok {
+			vc.Score = &score
+		}
+		ret.Validators = append(ret.Validators, vc)
+	}
+	return ret, nil
+}
+
+// GetBlockAwards returns the award distribution of the block decoded from its
+// balance records: the fees paid to the foundation for the coinbase, and the
+// transfers out of the foundation.
+func (s *PublicGovAPI) GetBlockAwards(ctx context.Context, blockNr rpc.BlockNumber) (*RPCBlockAwards, error) {
+	st, header, err := s.stateAt(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	bbr, err := s.b.BalanceRecordByNumber(ctx, rpc.BlockNumber(header.Height))
+	if err != nil {
+		return nil, err
+	}
+
+	poceeds := new(big.Int)
+	awards := &RPCBlockAwards{
+		Height:   hexutil.Uint64(header.Height),
+		Coinbase: header.Coinbase,
+		Fees:     make([]rtypes.RPCBalanceRecord, 0),
+		Awards:   make([]rtypes.RPCBalanceRecord, 0),
+	}
+	rbbr := rtypes.NewRPCBlockBalanceRecord(bbr)
+	for _, tx := range rbbr.TxRecords {
+		for _, br := range tx.Records {
+			switch {
+			case br.Type == types.TxFee && br.To == config.ContractFoundationAddr:
+				poceeds.Add(poceeds, br.Amount.ToInt())
+				awards.Fees = append(awards.Fees, br)
+			case br.From == config.ContractFoundationAddr:
+				awards.Awards = append(awards.Awards, br)
+			}
+		}
+	}
+	awards.Poceeds = (*hexutil.Big)(poceeds)
+
+	if co, err := gov.Coefficient.Coefficient(st); err == nil && co.VotePeriod > 0 {
+		awards.AllocHeight = header.Height%(10*co.VotePeriod) == 0
+	}
+	return awards, nil
+}
+
+// GetPoceeds returns the award pool of each coinbase.
+func (s *PublicGovAPI) GetPoceeds(ctx context.Context, blockNr rpc.BlockNumber) (map[common.Address]*big.Int, error) {
+	st, _, err := s.stateAt(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	return gov.Foundation.Poceeds(st)
+}
+
+// GetLastAward returns the award the supporter got from the coinbase in the last allocation.
+func (s *PublicGovAPI) GetLastAward(ctx context.Context, coinbase, supporter common.Address, blockNr rpc.BlockNumber) (*big.Int, error) {
+	st, _, err := s.stateAt(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	return gov.Foundation.LastAward(st, coinbase, supporter)
+}
+
+// PreviewValidators returns the validators elected at the next election computed
+// from the pending state, with the ranking inputs of the candidates. The adjusts
+// simulate extra deposits or scores of the candidates.
+func (s *PublicGovAPI) PreviewValidators(ctx context.Context, adjusts []RPCCandidateAdjust) (*app.ValidatorsPreview, error) {
+	if !s.b.EVMAllowed() {
+		return nil, types.ErrMempoolIsFull
+	}
+	adjs := make([]*app.CandidateAdjust, 0, len(adjusts))
+	for _, adj := range adjusts {
+		adjs = append(adjs, &app.CandidateAdjust{
+			CoinBase: adj.CoinBase,
+			Deposit:  adj.Deposit.ToInt(),
+			Score:    adj.Score,
+		})
+	}
+	return s.b.PreviewValidators(adjs)
+}
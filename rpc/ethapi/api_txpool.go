@@ -10,6 +10,7 @@ import (
 	"github.com/lianxiangcloud/linkchain/libs/log"
 	"github.com/lianxiangcloud/linkchain/libs/rpc"
 	"github.com/lianxiangcloud/linkchain/libs/ser"
+	"github.com/lianxiangcloud/linkchain/libs/trace"
 	"github.com/lianxiangcloud/linkchain/rpc/rtypes"
 	"github.com/lianxiangcloud/linkchain/types"
 )
@@ -315,7 +316,11 @@ func (s *PublicTransactionPoolAPI) SendRawTx(ctx context.Context, encodedTx hexu
 		return common.EmptyHash, err
 	}
 
-	return submitTransaction(ctx, s.b, tx)
+	span := trace.StartTx(tx.Hash(), "rpc.SendRawTx", "type", txType)
+	hash, err := submitTransaction(ctx, s.b, tx)
+	span.SetError(err)
+	span.Finish()
+	return hash, err
 }
 
 // @Todo: implement it
@@ -22,7 +22,11 @@ import (
 	"math/big"
 
 	"github.com/lianxiangcloud/linkchain/accounts"
+	"github.com/lianxiangcloud/linkchain/app"
+	"github.com/lianxiangcloud/linkchain/blockchain"
+	"github.com/lianxiangcloud/linkchain/contract/verify"
 	"github.com/lianxiangcloud/linkchain/libs/common"
+	lktypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
 	"github.com/lianxiangcloud/linkchain/libs/rpc"
 	"github.com/lianxiangcloud/linkchain/rpc/rtypes"
 	"github.com/lianxiangcloud/linkchain/state"
@@ -49,6 +53,7 @@ type Backend interface {
 	DumpConsensusState() (*rtypes.ResultDumpConsensusState, error)
 	Validators(heightPtr *uint64) (*rtypes.ResultValidators, error)
 	Status() (*rtypes.ResultStatus, error)
+	SyncProgress() *blockchain.SyncProgress
 
 	// BlockChain API
 	GetTx(hash common.Hash) (types.Tx, *types.TxEntry)
@@ -56,6 +61,8 @@ type Backend interface {
 	HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error)
 	BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error)
 	BalanceRecordByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.BlockBalanceRecords, error)
+	ContractEvents(ctx context.Context, addr common.Address, topics []common.Hash, from, to uint64, cursor []byte, limit int) ([]*types.Log, []byte, error)
+	ContractVerifier() *verify.Verifier
 	StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error)
 	GetBlock(ctx context.Context, blockHash common.Hash) (*types.Block, error)
 	GetReceipts(ctx context.Context, blockNr uint64) types.Receipts
@@ -64,7 +71,9 @@ type Backend interface {
 	GetMaxOutputIndex(ctx context.Context, token common.Address) int64
 	GetBlockTokenOutputSeq(ctx context.Context, blockHeight uint64) map[string]int64
 	GetOutput(ctx context.Context, token common.Address, index uint64) (*types.UTXOOutputData, error)
+	IsKeyImageSpent(ctx context.Context, keyImage lktypes.Key) bool
 	GetUTXOGas() uint64
+	PreviewValidators(adjusts []*app.CandidateAdjust) (*app.ValidatorsPreview, error)
 
 	// TxPool API
 	SendTx(ctx context.Context, signedTx types.Tx) error
@@ -128,6 +137,16 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewPublicPrometheusMetricsAPI(apiBackend),
 			Public:    true,
+		}, {
+			Namespace: "gov",
+			Version:   "1.0",
+			Service:   NewPublicGovAPI(apiBackend),
+			Public:    true,
+		}, {
+			Namespace: "contract",
+			Version:   "1.0",
+			Service:   NewPublicContractAPI(apiBackend),
+			Public:    true,
 		},
 	}
 }
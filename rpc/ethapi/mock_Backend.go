@@ -3,10 +3,14 @@
 package ethapi
 
 import accounts "github.com/lianxiangcloud/linkchain/accounts"
+import app "github.com/lianxiangcloud/linkchain/app"
+import blockchain "github.com/lianxiangcloud/linkchain/blockchain"
 import big "math/big"
 import common "github.com/lianxiangcloud/linkchain/libs/common"
 import context "context"
+import verify "github.com/lianxiangcloud/linkchain/contract/verify"
 import evm "github.com/lianxiangcloud/linkchain/vm/evm"
+import lktypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
 import mock "github.com/stretchr/testify/mock"
 import rpc "github.com/lianxiangcloud/linkchain/libs/rpc"
 import rtypes "github.com/lianxiangcloud/linkchain/rpc/rtypes"
@@ -143,6 +147,54 @@ func (_m *MockBackend) ConsensusState() (*rtypes.ResultConsensusState, error) {
 	return r0, r1
 }
 
+// ContractEvents provides a mock function with given fields: ctx, addr, topics, from, to, cursor, limit
+func (_m *MockBackend) ContractEvents(ctx context.Context, addr common.Address, topics []common.Hash, from uint64, to uint64, cursor []byte, limit int) ([]*types.Log, []byte, error) {
+	ret := _m.Called(ctx, addr, topics, from, to, cursor, limit)
+
+	var r0 []*types.Log
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address, []common.Hash, uint64, uint64, []byte, int) []*types.Log); ok {
+		r0 = rf(ctx, addr, topics, from, to, cursor, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Log)
+		}
+	}
+
+	var r1 []byte
+	if rf, ok := ret.Get(1).(func(context.Context, common.Address, []common.Hash, uint64, uint64, []byte, int) []byte); ok {
+		r1 = rf(ctx, addr, topics, from, to, cursor, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]byte)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, common.Address, []common.Hash, uint64, uint64, []byte, int) error); ok {
+		r2 = rf(ctx, addr, topics, from, to, cursor, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ContractVerifier provides a mock function with given fields:
+func (_m *MockBackend) ContractVerifier() *verify.Verifier {
+	ret := _m.Called()
+
+	var r0 *verify.Verifier
+	if rf, ok := ret.Get(0).(func() *verify.Verifier); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*verify.Verifier)
+		}
+	}
+
+	return r0
+}
+
 // DumpConsensusState provides a mock function with given fields:
 func (_m *MockBackend) DumpConsensusState() (*rtypes.ResultDumpConsensusState, error) {
 	ret := _m.Called()
@@ -389,6 +441,20 @@ func (_m *MockBackend) GetTx(hash common.Hash) (types.Tx, *types.TxEntry) {
 	return r0, r1
 }
 
+// IsKeyImageSpent provides a mock function with given fields: ctx, keyImage
+func (_m *MockBackend) IsKeyImageSpent(ctx context.Context, keyImage lktypes.Key) bool {
+	ret := _m.Called(ctx, keyImage)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, lktypes.Key) bool); ok {
+		r0 = rf(ctx, keyImage)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // GetUTXOGas provides a mock function with given fields:
 func (_m *MockBackend) GetUTXOGas() uint64 {
 	ret := _m.Called()
@@ -495,6 +561,45 @@ func (_m *MockBackend) PrometheusMetrics() string {
 	return r0
 }
 
+// SyncProgress provides a mock function with given fields:
+func (_m *MockBackend) SyncProgress() *blockchain.SyncProgress {
+	ret := _m.Called()
+
+	var r0 *blockchain.SyncProgress
+	if rf, ok := ret.Get(0).(func() *blockchain.SyncProgress); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*blockchain.SyncProgress)
+		}
+	}
+
+	return r0
+}
+
+// PreviewValidators provides a mock function with given fields: adjusts
+func (_m *MockBackend) PreviewValidators(adjusts []*app.CandidateAdjust) (*app.ValidatorsPreview, error) {
+	ret := _m.Called(adjusts)
+
+	var r0 *app.ValidatorsPreview
+	if rf, ok := ret.Get(0).(func([]*app.CandidateAdjust) *app.ValidatorsPreview); ok {
+		r0 = rf(adjusts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*app.ValidatorsPreview)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]*app.CandidateAdjust) error); ok {
+		r1 = rf(adjusts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ProtocolVersion provides a mock function with given fields:
 func (_m *MockBackend) ProtocolVersion() string {
 	ret := _m.Called()
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"reflect"
 	"strings"
 	"time"
 
@@ -184,6 +185,40 @@ func NewBalanceRecordsWithBlockMsg(blockHeight uint64, bbr *types.BlockBalanceRe
 	}
 }
 
+// RPCEvidence is the evidence of a byzantine validator, Evidence is the evidence
+// encoded by ser, as submitted by evidence_submitEvidence.
+type RPCEvidence struct {
+	Type      string          `json:"type"`
+	Height    hexutil.Uint64  `json:"height"`
+	Validator common.Address  `json:"validator"`
+	Hash      hexutil.Bytes   `json:"hash"`
+	Evidence  json.RawMessage `json:"evidence"`
+}
+
+func NewRPCEvidence(ev types.Evidence) *RPCEvidence {
+	e := &RPCEvidence{
+		Type:   reflect.Indirect(reflect.ValueOf(ev)).Type().Name(),
+		Height: hexutil.Uint64(ev.Height()),
+		Hash:   ev.Hash(),
+	}
+	// the fault validators evidence of a proposer fault has no validator
+	if fve, ok := ev.(*types.FaultValidatorsEvidence); !ok || fve.FaultVal != nil {
+		e.Validator = common.BytesToAddress(ev.Address())
+	}
+	if bz, err := ser.MarshalJSON(ev); err == nil {
+		e.Evidence = bz
+	}
+	return e
+}
+
+func NewRPCEvidenceList(evl []types.Evidence) []*RPCEvidence {
+	list := make([]*RPCEvidence, 0, len(evl))
+	for _, ev := range evl {
+		list = append(list, NewRPCEvidence(ev))
+	}
+	return list
+}
+
 type ITX interface{}
 type txsAlias Txs
 type Txs []ITX
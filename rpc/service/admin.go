@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/lianxiangcloud/linkchain/libs/log"
+	"github.com/lianxiangcloud/linkchain/vm/wasm"
+)
+
+// AdminApi provides an API to administrate the node while running, it is served
+// on the IPC endpoint, and on HTTP or websocket only if "admin" is in the modules.
+type AdminApi struct {
+	s *Service
+}
+
+// SetLogLevel changes the per-module log levels, level is in the form of the
+// log_level config, e.g. "consensus:debug,mempool:error,*:info".
+func (aa *AdminApi) SetLogLevel(level string) (string, error) {
+	if err := log.SetLogLevel(level); err != nil {
+		return "", err
+	}
+	aa.s.logger.Info("log level changed", "level", level)
+	return log.GetLogLevel(), nil
+}
+
+// GetLogLevel returns the per-module log levels.
+func (aa *AdminApi) GetLogLevel() string {
+	return log.GetLogLevel()
+}
+
+// StartWasmProfile starts profiling the host functions of the wasm contract
+// calls, keeping the latest maxCalls calls, at most wasm.MaxProfileCalls.
+func (aa *AdminApi) StartWasmProfile(maxCalls *int) error {
+	n := wasm.DefaultProfileCalls
+	if maxCalls != nil {
+		n = *maxCalls
+	}
+	_, err := wasm.StartProfile(n)
+	return err
+}
+
+// StopWasmProfile stops profiling the wasm host functions and returns the report,
+// the host functions with a wall time per gas above ratio times the median are
+// reported as under-priced.
+func (aa *AdminApi) StopWasmProfile(ratio *float64) (*wasm.ProfileReport, error) {
+	p, err := wasm.StopProfile()
+	if err != nil {
+		return nil, err
+	}
+	return p.Report(floatOrZero(ratio)), nil
+}
+
+// WasmProfile returns the report of the running profile of the wasm host functions.
+func (aa *AdminApi) WasmProfile(ratio *float64) (*wasm.ProfileReport, error) {
+	p := wasm.ActiveProfiler()
+	if p == nil {
+		return nil, errors.New("wasm profile not running")
+	}
+	return p.Report(floatOrZero(ratio)), nil
+}
+
+func floatOrZero(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
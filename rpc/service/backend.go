@@ -10,10 +10,14 @@ import (
 	"github.com/lianxiangcloud/linkchain/libs/bloombits"
 
 	"github.com/lianxiangcloud/linkchain/accounts"
+	"github.com/lianxiangcloud/linkchain/app"
+	"github.com/lianxiangcloud/linkchain/blockchain"
 	"github.com/lianxiangcloud/linkchain/config"
 	cs "github.com/lianxiangcloud/linkchain/consensus"
+	"github.com/lianxiangcloud/linkchain/contract/verify"
 	"github.com/lianxiangcloud/linkchain/libs/common"
 	cmn "github.com/lianxiangcloud/linkchain/libs/common"
+	lktypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
 	"github.com/lianxiangcloud/linkchain/libs/math"
 	"github.com/lianxiangcloud/linkchain/libs/rpc"
 	"github.com/lianxiangcloud/linkchain/metrics"
@@ -207,6 +211,13 @@ func (b *ApiBackend) Status() (*rtypes.ResultStatus, error) {
 	return result, nil
 }
 
+func (b *ApiBackend) SyncProgress() *blockchain.SyncProgress {
+	if b.context().bcReactor == nil {
+		return nil
+	}
+	return b.context().bcReactor.SyncProgress()
+}
+
 func (b *ApiBackend) validatorAtHeight(h uint64) *types.Validator {
 	privValAddress := b.context().pubKey.Address()
 
@@ -351,6 +362,18 @@ func (b *ApiBackend) BalanceRecordByNumber(ctx context.Context, blockNr rpc.Bloc
 	return bbr, nil
 }
 
+func (b *ApiBackend) ContractEvents(ctx context.Context, addr common.Address, topics []common.Hash, from, to uint64, cursor []byte, limit int) ([]*types.Log, []byte, error) {
+	eis := b.context().eis
+	if eis == nil || !eis.IsOpen() {
+		return nil, nil, errors.New("contract event index not open")
+	}
+	return eis.Events(addr, topics, from, to, cursor, limit)
+}
+
+func (b *ApiBackend) ContractVerifier() *verify.Verifier {
+	return b.context().verifier
+}
+
 func (b *ApiBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
 	if blockNr == rpc.PendingBlockNumber {
 		// @Todo: lack of header
@@ -467,6 +490,11 @@ func (b *ApiBackend) GetOutput(ctx context.Context, token common.Address, index
 	return b.context().utxo.GetUtxoOutput(token, index)
 }
 
+// IsKeyImageSpent report whether the key image has been spent on chain
+func (b *ApiBackend) IsKeyImageSpent(ctx context.Context, keyImage lktypes.Key) bool {
+	return b.context().utxo.HaveTxKeyimgAsSpent(&keyImage)
+}
+
 // TxPool API
 func (b *ApiBackend) SendTx(ctx context.Context, signedTx types.Tx) error {
 	return b.context().mempool.AddTx("", signedTx)
@@ -513,3 +541,7 @@ func (b *ApiBackend) PrometheusMetrics() string {
 func (b *ApiBackend) GetUTXOGas() uint64 {
 	return b.context().app.GetUTXOGas()
 }
+
+func (b *ApiBackend) PreviewValidators(adjusts []*app.CandidateAdjust) (*app.ValidatorsPreview, error) {
+	return b.context().app.PreviewValidators(adjusts)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	cs "github.com/lianxiangcloud/linkchain/consensus"
+	"github.com/lianxiangcloud/linkchain/libs/rpc"
+)
+
+var errNoConsensus = errors.New("consensus not available")
+
+// ConsensusApi provides an API to look into the consensus of the recent heights.
+type ConsensusApi struct {
+	s *Service
+}
+
+func (ca *ConsensusApi) context() *Context {
+	return ca.s.context()
+}
+
+// GetHeightTimeline returns the consensus events of the height in the order they
+// arrived, "pending" for the height in consensus.
+func (ca *ConsensusApi) GetHeightTimeline(ctx context.Context, blockNr rpc.BlockNumber) (*cs.HeightTimeline, error) {
+	consensus := ca.context().consensusState
+	if consensus == nil {
+		return nil, errNoConsensus
+	}
+	var height uint64
+	switch blockNr {
+	case rpc.LatestBlockNumber:
+		height = ca.context().blockStore.Height()
+	case rpc.PendingBlockNumber:
+		height = consensus.GetState().LastBlockHeight + 1
+	default:
+		height = uint64(blockNr)
+	}
+	timeline := consensus.GetHeightTimeline(height)
+	if timeline == nil {
+		return nil, fmt.Errorf("timeline of height %d not recorded", height)
+	}
+	return timeline, nil
+}
@@ -4,9 +4,13 @@ import (
 	"math/big"
 
 	"github.com/lianxiangcloud/linkchain/accounts"
+	"github.com/lianxiangcloud/linkchain/app"
+	"github.com/lianxiangcloud/linkchain/blockchain"
 	cs "github.com/lianxiangcloud/linkchain/consensus"
+	"github.com/lianxiangcloud/linkchain/contract/verify"
 	"github.com/lianxiangcloud/linkchain/libs/common"
 	"github.com/lianxiangcloud/linkchain/libs/crypto"
+	lktypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
 	dbm "github.com/lianxiangcloud/linkchain/libs/db"
 	"github.com/lianxiangcloud/linkchain/libs/log"
 	"github.com/lianxiangcloud/linkchain/libs/p2p"
@@ -22,6 +26,7 @@ type App interface {
 	GetLatestStateDB() *state.StateDB
 	GetPendingBlock() *types.Block
 	GetUTXOGas() uint64
+	PreviewValidators(adjusts []*app.CandidateAdjust) (*app.ValidatorsPreview, error)
 }
 
 type Mempool interface {
@@ -35,6 +40,7 @@ type Consensus interface {
 	GetValidators() (uint64, []*types.Validator)
 	GetRoundStateJSON() ([]byte, error)
 	GetRoundStateSimpleJSON() ([]byte, error)
+	GetHeightTimeline(height uint64) *cs.HeightTimeline
 }
 
 type BlockStore interface {
@@ -57,11 +63,27 @@ type BalanceRecordStore interface {
 	Get(blockHeight uint64) *types.BlockBalanceRecords
 }
 
+type EventIndexStore interface {
+	IsOpen() bool
+	Events(addr common.Address, topics []common.Hash, from, to uint64, cursor []byte, limit int) ([]*types.Log, []byte, error)
+}
+
+type BlockchainReactor interface {
+	SyncProgress() *blockchain.SyncProgress
+}
+
+type EvidencePool interface {
+	PendingEvidence() []types.Evidence
+	CommittedEvidence(address []byte) []types.Evidence
+	AddEvidence(evidence types.Evidence) error
+}
+
 //UtxoStore utxo storage
 type UtxoStore interface {
 	GetUtxoOutput(token common.Address, index uint64) (*types.UTXOOutputData, error)
 	GetMaxUtxoOutputSeq(token common.Address) int64
 	GetBlockTokenUtxoOutputSeq(blockHeight uint64) map[string]int64
+	HaveTxKeyimgAsSpent(kImg *lktypes.Key) bool
 }
 
 type Context struct {
@@ -70,6 +92,9 @@ type Context struct {
 	stateDB    dbm.DB
 	blockStore BlockStore
 	brs        BalanceRecordStore
+	eis        EventIndexStore
+	verifier   *verify.Verifier
+	evpool     EvidencePool
 	mempool    Mempool
 	app        App
 	triedb     state.Database
@@ -80,6 +105,7 @@ type Context struct {
 
 	consensusState   Consensus
 	consensusReactor *cs.ConsensusReactor
+	bcReactor        BlockchainReactor
 
 	// objects
 	accManager *accounts.Manager
@@ -123,6 +149,10 @@ func (c *Context) GetConsensusReactor() *cs.ConsensusReactor {
 	return c.consensusReactor
 }
 
+func (c *Context) SetBlockchainReactor(bcR BlockchainReactor) {
+	c.bcReactor = bcR
+}
+
 func (c *Context) SetBlockstore(bs BlockStore) {
 	c.blockStore = bs
 }
@@ -131,6 +161,18 @@ func (c *Context) SetBalanceRecordStore(brs BalanceRecordStore) {
 	c.brs = brs
 }
 
+func (c *Context) SetEventIndexStore(eis EventIndexStore) {
+	c.eis = eis
+}
+
+func (c *Context) SetContractVerifier(v *verify.Verifier) {
+	c.verifier = v
+}
+
+func (c *Context) SetEvidencePool(evpool EvidencePool) {
+	c.evpool = evpool
+}
+
 func (c *Context) SetMempool(mem Mempool) {
 	c.mempool = mem
 }
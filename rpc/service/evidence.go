@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
+	"github.com/lianxiangcloud/linkchain/libs/log"
+	"github.com/lianxiangcloud/linkchain/libs/rpc"
+	"github.com/lianxiangcloud/linkchain/libs/ser"
+	"github.com/lianxiangcloud/linkchain/rpc/rtypes"
+	"github.com/lianxiangcloud/linkchain/types"
+)
+
+var errNoEvidencePool = errors.New("evidence pool not available")
+
+// EvidenceApi provides an API to query the evidence of byzantine validators, to
+// submit the evidence caught outside the node and to subscribe the new evidence.
+type EvidenceApi struct {
+	s *Service
+}
+
+func (ea *EvidenceApi) context() *Context {
+	return ea.s.context()
+}
+
+// PendingEvidence returns the verified evidence not committed yet.
+func (ea *EvidenceApi) PendingEvidence(ctx context.Context) ([]*rtypes.RPCEvidence, error) {
+	evpool := ea.context().evpool
+	if evpool == nil {
+		return nil, errNoEvidencePool
+	}
+	return rtypes.NewRPCEvidenceList(evpool.PendingEvidence()), nil
+}
+
+// GetEvidenceByHeight returns the evidence committed in the block of the given height.
+func (ea *EvidenceApi) GetEvidenceByHeight(ctx context.Context, blockNr rpc.BlockNumber) ([]*rtypes.RPCEvidence, error) {
+	block, err := ea.s.apiBackend().BlockByNumber(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", blockNr)
+	}
+	return rtypes.NewRPCEvidenceList(block.Evidence.Evidence), nil
+}
+
+// GetEvidenceByValidator returns the committed evidence of the validator, sorted by height.
+func (ea *EvidenceApi) GetEvidenceByValidator(ctx context.Context, addr common.Address) ([]*rtypes.RPCEvidence, error) {
+	evpool := ea.context().evpool
+	if evpool == nil {
+		return nil, errNoEvidencePool
+	}
+	return rtypes.NewRPCEvidenceList(evpool.CommittedEvidence(addr.Bytes())), nil
+}
+
+// SubmitEvidence verifies the duplicate vote evidence encoded by ser and adds it
+// to the evidence pool, it returns the hash of the evidence.
+func (ea *EvidenceApi) SubmitEvidence(ctx context.Context, data hexutil.Bytes) (hexutil.Bytes, error) {
+	evpool := ea.context().evpool
+	if evpool == nil {
+		return nil, errNoEvidencePool
+	}
+	var ev types.Evidence
+	if err := ser.DecodeBytes(data, &ev); err != nil {
+		return nil, fmt.Errorf("invalid evidence: %v", err)
+	}
+	// only the duplicate votes are verifiable by the signatures of the validator
+	dve, ok := ev.(*types.DuplicateVoteEvidence)
+	if !ok || dve.PubKey == nil || dve.VoteA == nil || dve.VoteB == nil {
+		return nil, errors.New("invalid evidence: duplicate vote evidence required")
+	}
+	if err := evpool.AddEvidence(dve); err != nil {
+		return nil, err
+	}
+	log.Info("rpc: evidence submitted", "evidence", dve)
+	return dve.Hash(), nil
+}
+
+// EvidenceSubscribe subscribes the new evidence added to the evidence pool.
+func (ea *EvidenceApi) EvidenceSubscribe(ctx context.Context) (*rpc.Subscription, error) {
+	if ea.context().eventBus == nil {
+		// @Note: Should not happen!
+		log.Error("rpc: eventbus nil, not support Subscribetion!!!")
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	subscription := notifier.CreateSubscription()
+	suberName := fmt.Sprintf("rpc-evidence-suber-%s", subscription.ID)
+	ebCtx := context.Background()
+	evCh := make(chan interface{}, 128)
+	if err := ea.context().eventBus.Subscribe(ebCtx, suberName, types.EventQueryNewEvidence, evCh); err != nil {
+		log.Warn("rpc: Subscribe fail", "err", err)
+		return nil, err
+	}
+
+	go func() {
+		defer func() {
+			ea.context().eventBus.Unsubscribe(ebCtx, suberName, types.EventQueryNewEvidence)
+		}()
+
+		for {
+			select {
+			case e := <-evCh:
+				ne := e.(types.EventDataNewEvidence)
+				if ne.Evidence == nil {
+					continue
+				}
+				if err := notifier.Notify(subscription.ID, rtypes.NewRPCEvidence(ne.Evidence)); err != nil {
+					log.Error("rpc: notify failed", "err", err, "suber", suberName, "evidence", ne.Evidence)
+					return
+				}
+				log.Info("rpc: notify success", "sub", suberName, "evidence", ne.Evidence)
+
+			case <-notifier.Closed():
+				log.Info("rpc EvidenceSubscribe: unsubscribe", "suber", suberName)
+				return
+			case err := <-subscription.Err():
+				if err != nil {
+					log.Error("rpc subscription: error", "suber", suberName, "err", err)
+				} else {
+					log.Info("rpc subscription: exit", "suber", suberName)
+				}
+				return
+			}
+		}
+	}()
+
+	log.Info("rpc EvidenceSubscribe: ok", "name", suberName)
+	return subscription, nil
+}
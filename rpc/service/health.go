@@ -0,0 +1,176 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lianxiangcloud/linkchain/blockchain"
+	"github.com/lianxiangcloud/linkchain/libs/rpc"
+)
+
+// the probe endpoints of the orchestrators
+const (
+	healthPath = "/health"
+	readyPath  = "/ready"
+	syncPath   = "/sync"
+)
+
+var healthCheckKey = []byte("healthCheck")
+
+// healthCacheTime is how long the result of the database check is served
+// before the database is written again.
+const healthCacheTime = 10 * time.Second
+
+// healthCache keeps the result of the last database check, the /health probe
+// writes the database at most once per healthCacheTime however often polled.
+type healthCache struct {
+	mu     sync.Mutex
+	at     time.Time
+	status *HealthStatus
+}
+
+// HealthStatus is the result of the /health probe.
+type HealthStatus struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ReadyStatus is the result of the /ready probe, Reasons tell why the node is
+// not ready.
+type ReadyStatus struct {
+	Ready             bool      `json:"ready"`
+	Reasons           []string  `json:"reasons,omitempty"`
+	CatchingUp        bool      `json:"catchingUp"`
+	LatestBlockHeight uint64    `json:"latestBlockHeight"`
+	LatestBlockTime   time.Time `json:"latestBlockTime"`
+	MaxPeerHeight     uint64    `json:"maxPeerHeight"`
+	Peers             int       `json:"peers"`
+}
+
+// SyncStatus is the result of the /sync probe.
+type SyncStatus struct {
+	CatchingUp        bool                           `json:"catchingUp"`
+	LatestBlockHeight uint64                         `json:"latestBlockHeight"`
+	LatestBlockTime   time.Time                      `json:"latestBlockTime"`
+	MaxPeerHeight     uint64                         `json:"maxPeerHeight"`
+	Peers             []*blockchain.PeerSyncProgress `json:"peers"`
+}
+
+// ProbeRoutes returns the plain HTTP probes of the node for the orchestrators:
+// /health, /ready and /sync. They respond 503 if the node is not healthy or not
+// ready.
+func (s *Service) ProbeRoutes() []rpc.HTTPRoute {
+	return []rpc.HTTPRoute{
+		{Path: healthPath, Handler: probeHandler(s.health)},
+		{Path: readyPath, Handler: probeHandler(s.ready)},
+		{Path: syncPath, Handler: probeHandler(s.sync)},
+	}
+}
+
+// probeHandler serves the result of the probe as JSON.
+func probeHandler(probe func() (interface{}, bool)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		result, ok := probe()
+		w.Header().Set("Content-Type", "application/json")
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+}
+
+// health checks the database is writable, the result is cached for
+// healthCacheTime.
+func (s *Service) health() (interface{}, bool) {
+	c := &s.healthCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.status == nil || time.Since(c.at) >= healthCacheTime {
+		c.status, c.at = s.checkDB(), time.Now()
+	}
+	return c.status, c.status.Healthy
+}
+
+// checkDB writes and deletes a key in the state database.
+func (s *Service) checkDB() *HealthStatus {
+	status := &HealthStatus{Healthy: true}
+	db := s.context().stateDB
+	if db == nil {
+		return status
+	}
+	err := db.Put(healthCheckKey, []byte(time.Now().String()))
+	if err == nil {
+		err = db.Del(healthCheckKey)
+	}
+	if err != nil {
+		status.Healthy = false
+		status.Error = fmt.Sprintf("database not writable: %v", err)
+	}
+	return status
+}
+
+// ready checks the node is caught up with the peers by the thresholds of the
+// config.
+func (s *Service) ready() (interface{}, bool) {
+	sync := s.syncStatus()
+	status := &ReadyStatus{
+		CatchingUp:        sync.CatchingUp,
+		LatestBlockHeight: sync.LatestBlockHeight,
+		LatestBlockTime:   sync.LatestBlockTime,
+		MaxPeerHeight:     sync.MaxPeerHeight,
+	}
+	if sw := s.context().p2pSwitch; sw != nil {
+		status.Peers = sw.Peers().Size()
+	}
+
+	if status.CatchingUp {
+		status.Reasons = append(status.Reasons, "fast syncing")
+	}
+	if status.MaxPeerHeight > status.LatestBlockHeight+s.conf.ReadyMaxHeightLag {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("%d blocks behind the peers", status.MaxPeerHeight-status.LatestBlockHeight))
+	}
+	if status.Peers < s.conf.ReadyMinPeers {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("%d peers, less than %d", status.Peers, s.conf.ReadyMinPeers))
+	}
+	if s.conf.ReadyMaxBlockAge > 0 {
+		if age := time.Since(status.LatestBlockTime); age > s.conf.ReadyMaxBlockAge {
+			status.Reasons = append(status.Reasons, fmt.Sprintf("latest block is %v old", age.Round(time.Second)))
+		}
+	}
+	status.Ready = len(status.Reasons) == 0
+	return status, status.Ready
+}
+
+// sync returns the heights of the node and the peers.
+func (s *Service) sync() (interface{}, bool) {
+	return s.syncStatus(), true
+}
+
+func (s *Service) syncStatus() *SyncStatus {
+	ctx := s.context()
+	status := &SyncStatus{Peers: []*blockchain.PeerSyncProgress{}}
+	if ctx.consensusReactor != nil {
+		status.CatchingUp = ctx.consensusReactor.FastSync()
+	}
+	if ctx.blockStore != nil {
+		status.LatestBlockHeight = ctx.blockStore.Height()
+		if meta := ctx.blockStore.LoadBlockMeta(status.LatestBlockHeight); meta != nil {
+			status.LatestBlockTime = time.Unix(int64(meta.Header.Time), 0)
+		}
+	}
+	if ctx.bcReactor != nil {
+		progress := ctx.bcReactor.SyncProgress()
+		status.MaxPeerHeight = uint64(progress.MaxPeerHeight)
+		if progress.Peers != nil {
+			status.Peers = progress.Peers
+		}
+	}
+	return status
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lianxiangcloud/linkchain/libs/db"
+)
+
+func TestProbeRoutes(t *testing.T) {
+	conf := testRPCConfig()
+	conf.ReadyMinPeers = 1
+	ctx := NewContext()
+	ctx.SetStateDB(db.NewMemDB())
+	ctx.SetBlockstore(newTestBlockStore())
+	s := &Service{conf: conf, ctx: ctx}
+
+	mux := http.NewServeMux()
+	for _, route := range s.ProbeRoutes() {
+		mux.Handle(route.Path, route.Handler)
+	}
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + healthPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("health: want %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	resp, err = http.Get(srv.URL + readyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ready ReadyStatus
+	err = json.NewDecoder(resp.Body).Decode(&ready)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable || ready.Ready || len(ready.Reasons) != 1 {
+		t.Fatalf("ready without peers: got %d %+v", resp.StatusCode, ready)
+	}
+
+	conf.ReadyMinPeers = 0
+	if _, ok := s.ready(); !ok {
+		t.Fatal("ready: want ready without the peers limit")
+	}
+}
+
+// putCounter counts the writes to the database.
+type putCounter struct {
+	db.DB
+	puts int
+}
+
+func (c *putCounter) Put(key, value []byte) error {
+	c.puts++
+	return c.DB.Put(key, value)
+}
+
+func TestHealthCache(t *testing.T) {
+	counter := &putCounter{DB: db.NewMemDB()}
+	ctx := NewContext()
+	ctx.SetStateDB(counter)
+	s := &Service{conf: testRPCConfig(), ctx: ctx}
+
+	for i := 0; i < 3; i++ {
+		if _, ok := s.health(); !ok {
+			t.Fatal("health: want healthy")
+		}
+	}
+	if counter.puts != 1 {
+		t.Fatalf("health wrote the database %d times, want once within %v", counter.puts, healthCacheTime)
+	}
+
+	s.healthCache.at = time.Now().Add(-healthCacheTime)
+	s.health()
+	if counter.puts != 2 {
+		t.Fatalf("health wrote the database %d times, want again after %v", counter.puts, healthCacheTime)
+	}
+}
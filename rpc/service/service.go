@@ -25,10 +25,11 @@ type Service struct {
 	wsListener    net.Listener // Websocket RPC listener socket to server API requests
 	wsHandler     *rpc.Server  // Websocket RPC request handler to process the API requests
 
-	apis     []rpc.API
-	pubsub   *PubsubApi
-	bloom    *BloomService
-	evmLimit *rate.Limiter
+	apis        []rpc.API
+	pubsub      *PubsubApi
+	bloom       *BloomService
+	evmLimit    *rate.Limiter
+	healthCache healthCache
 }
 
 // New new rpc service
@@ -55,6 +56,27 @@ func New(conf *config.RPCConfig, ctx *Context) *Service {
 		Public:    true,
 	}
 	s.apis = append(s.apis, api)
+
+	s.apis = append(s.apis, rpc.API{
+		Namespace: "evidence",
+		Version:   "1.0",
+		Service:   &EvidenceApi{s: s},
+		Public:    true,
+	})
+
+	s.apis = append(s.apis, rpc.API{
+		Namespace: "consensus",
+		Version:   "1.0",
+		Service:   &ConsensusApi{s: s},
+		Public:    true,
+	})
+
+	s.apis = append(s.apis, rpc.API{
+		Namespace: "admin",
+		Version:   "1.0",
+		Service:   &AdminApi{s: s},
+		Public:    false,
+	})
 	return s
 }
 
@@ -139,7 +161,7 @@ func (s *Service) startHTTP() error {
 		return nil
 	}
 
-	listener, handler, err := rpc.StartHTTPEndpoint(s.conf.HTTPEndpoint, s.apis, s.conf.HTTPModules, s.conf.HTTPCores, s.conf.VHosts)
+	listener, handler, err := rpc.StartHTTPEndpoint(s.conf.HTTPEndpoint, s.apis, s.conf.HTTPModules, s.conf.HTTPCores, s.conf.VHosts, s.ProbeRoutes()...)
 	if err != nil {
 		return err
 	}
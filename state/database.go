@@ -90,9 +90,11 @@ func (db *cachingDB) OpenTrie(root common.Hash) (Trie, error) {
 
 	for i := len(db.pastTries) - 1; i >= 0; i-- {
 		if db.pastTries[i].Hash() == root {
+			stateMetrics.CacheHits.With("cache", cachePastTries).Add(1)
 			return cachedTrie{db.pastTries[i].Copy(), db}, nil
 		}
 	}
+	stateMetrics.CacheMisses.With("cache", cachePastTries).Add(1)
 	tr, err := trie.NewSecure(root, db.db, MaxTrieCacheGen)
 	if err != nil {
 		return nil, err
@@ -141,8 +143,10 @@ func (db *cachingDB) ContractCode(addrHash, codeHash common.Hash) ([]byte, error
 // ContractCodeSize retrieves a particular contracts code's size.
 func (db *cachingDB) ContractCodeSize(addrHash, codeHash common.Hash) (int, error) {
 	if cached, ok := db.codeSizeCache.Get(codeHash); ok {
+		stateMetrics.CacheHits.With("cache", cacheCodeSize).Add(1)
 		return cached.(int), nil
 	}
+	stateMetrics.CacheMisses.With("cache", cacheCodeSize).Add(1)
 	code, err := db.ContractCode(addrHash, codeHash)
 	return len(code), err
 }
@@ -0,0 +1,63 @@
+package state
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Number of the hits of the trie caches, by cache.
+	CacheHits metrics.Counter
+	// Number of the misses of the trie caches, by cache.
+	CacheMisses metrics.Counter
+	// Time of committing the state.
+	CommitSeconds metrics.Histogram
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+func PrometheusMetrics() *Metrics {
+	return &Metrics{
+		CacheHits: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Subsystem: "state",
+			Name:      "cache_hits",
+			Help:      "Number of the hits of the trie caches, by cache.",
+		}, []string{"cache"}),
+		CacheMisses: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Subsystem: "state",
+			Name:      "cache_misses",
+			Help:      "Number of the misses of the trie caches, by cache.",
+		}, []string{"cache"}),
+		CommitSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Subsystem: "state",
+			Name:      "commit_seconds",
+			Help:      "Time of committing the state.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+		}, []string{}),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		CacheHits:     discard.NewCounter(),
+		CacheMisses:   discard.NewCounter(),
+		CommitSeconds: discard.NewHistogram(),
+	}
+}
+
+// the caches of the trie
+const (
+	cachePastTries = "past_tries"
+	cacheCodeSize  = "code_size"
+)
+
+var stateMetrics = NopMetrics()
+
+// SetMetrics sets the metrics of the state databases.
+func SetMetrics(metrics *Metrics) {
+	stateMetrics = metrics
+}
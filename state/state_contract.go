@@ -3,8 +3,10 @@ package state
 import (
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/lianxiangcloud/linkchain/config"
 	"github.com/lianxiangcloud/linkchain/libs/common"
@@ -210,14 +212,246 @@ func (st *StateDB) GetCandidatesDeposit(addrs []common.Address, logger log.Logge
 	return deposits
 }
 
+//SlashingJSON used for json encoded slashing state of a candidate in the storage of config.SlashingStateAddr
+type SlashingJSON struct {
+	JailHeight uint64 `json:"jail_height"` // not elected before the height
+	Tombstoned bool   `json:"tombstoned"`  // never elected again
+}
+
+func slashingKey(pubkey crypto.PubKey) common.Hash {
+	return crypto.Keccak256Hash(pubkey.Bytes())
+}
+
+//GetSlashingState get the slashing state of the candidate
+func (st *StateDB) GetSlashingState(pubkey crypto.PubKey, logger log.Logger) SlashingJSON {
+	var slashing SlashingJSON
+	buff := st.GetState(config.SlashingStateAddr, slashingKey(pubkey))
+	if len(buff) == 0 {
+		return slashing
+	}
+	if err := json.Unmarshal(buff, &slashing); err != nil {
+		logger.Error("GetSlashingState: JSON Unmarshal slashing", "err", err)
+	}
+	return slashing
+}
+
+//SetSlashingState set the slashing state of the candidate
+func (st *StateDB) SetSlashingState(pubkey crypto.PubKey, slashing SlashingJSON) {
+	// the nonce keeps the account holding only storage from being deleted as empty
+	if st.GetNonce(config.SlashingStateAddr) == 0 {
+		st.SetNonce(config.SlashingStateAddr, 1)
+	}
+	buff, _ := json.Marshal(slashing)
+	st.SetState(config.SlashingStateAddr, slashingKey(pubkey), buff)
+}
+
+//ConfiscateDeposit confiscate rate/config.SlashRateDenominator of the deposit of addr in pledge
+//contract to the foundation, and return the amount. Every pledge record of addr not withdrawn
+//yet is cut by the rate and totalAmount of addr drops by the sum of the cuts, so withDraw of
+//pledge contract still pays each depositor the record left out of the balance of the contract.
+//
+//The confiscate action of pledge contract takes the whole deposit of a DETAIN elector, so a
+//share is cut here by rewriting the TLV storage of contract/v1/pledge/pledge.cpp: ElectorsMap
+//"electorsMap" (ElectorInfo, totalAmount first), pledgeRecordIndex "recordIndex" and
+//pledgeRecordInfo "pledgeRecordInfo" (PledgeRecord). A change of those storages must change
+//this function too, TestPledgeConfiscateDeposit of contract/test runs the contract against it.
+func (st *StateDB) ConfiscateDeposit(addr common.Address, rate uint64, logger log.Logger) *big.Int {
+	amount := big.NewInt(0)
+	key := crypto.Keccak256Hash(packStringkey("electorsMap", addr.String()+"\x00"))
+	value := st.GetState(config.ContractPledgeAddr, key)
+	if len(value) <= 4 || rate == 0 {
+		return amount
+	}
+	size := uint64(binary.LittleEndian.Uint16(value[2:4])) //1:tagObj 2:tagString 3,4:stringLen
+	if size == 0 || uint64(len(value)) < 4+size {
+		logger.Error("ConfiscateDeposit: invalid elector info", "addr", addr.String())
+		return amount
+	}
+	deposit, ok := big.NewInt(0).SetString(string(value[4:4+size-1]), 0)
+	if !ok {
+		logger.Error("ConfiscateDeposit: get deposit fail", "addr", addr.String())
+		return amount
+	}
+
+	indexKey := crypto.Keccak256Hash(packStringkey("recordIndex", addr.String()+"\x00"))
+	orderIDs, err := decodeUint64Set(st.GetState(config.ContractPledgeAddr, indexKey))
+	if err != nil {
+		logger.Error("ConfiscateDeposit: invalid record index", "addr", addr.String(), "err", err)
+		return amount
+	}
+	type recordUpdate struct {
+		key   common.Hash
+		value []byte
+	}
+	updates := make([]recordUpdate, 0, len(orderIDs))
+	for _, orderID := range orderIDs {
+		recordKey := pledgeRecordKey(orderID)
+		record, err := decodePledgeRecord(st.GetState(config.ContractPledgeAddr, recordKey))
+		if err != nil {
+			logger.Error("ConfiscateDeposit: invalid pledge record", "addr", addr.String(), "orderid", orderID, "err", err)
+			return big.NewInt(0)
+		}
+		if record.HasWithdraw {
+			continue
+		}
+		recordAmount, ok := big.NewInt(0).SetString(record.Amount, 10)
+		if !ok || recordAmount.Sign() < 0 {
+			logger.Error("ConfiscateDeposit: invalid pledge amount", "addr", addr.String(), "orderid", orderID, "amount", record.Amount)
+			return big.NewInt(0)
+		}
+		cut := big.NewInt(0).Mul(recordAmount, big.NewInt(0).SetUint64(rate))
+		cut.Div(cut, big.NewInt(config.SlashRateDenominator))
+		if cut.Sign() == 0 {
+			continue
+		}
+		record.Amount = recordAmount.Sub(recordAmount, cut).String()
+		updates = append(updates, recordUpdate{recordKey, encodePledgeRecord(record)})
+		amount.Add(amount, cut)
+	}
+	if amount.Sign() == 0 {
+		return amount
+	}
+	if amount.Cmp(deposit) > 0 || st.GetBalance(config.ContractPledgeAddr).Cmp(amount) < 0 {
+		logger.Error("ConfiscateDeposit: pledge records exceed the deposit", "addr", addr.String(),
+			"deposit", deposit, "confiscate", amount)
+		return big.NewInt(0)
+	}
+
+	for _, update := range updates {
+		st.SetState(config.ContractPledgeAddr, update.key, update.value)
+	}
+	left := big.NewInt(0).Sub(deposit, amount).String()
+	buff := make([]byte, 2)
+	binary.LittleEndian.PutUint16(buff, uint16(len(left)+1))
+	newValue := make([]byte, 0, len(value)+len(left))
+	newValue = append(newValue, value[0:2]...)
+	newValue = append(newValue, buff...)
+	newValue = append(newValue, left...)
+	newValue = append(newValue, byte(0))
+	newValue = append(newValue, value[4+size:]...)
+	st.SetState(config.ContractPledgeAddr, key, newValue)
+
+	st.SubBalance(config.ContractPledgeAddr, amount)
+	st.AddBalance(config.ContractFoundationAddr, amount)
+	return amount
+}
+
+//PledgeRecord is the PledgeRecord struct of pledge contract
+type PledgeRecord struct {
+	OrderID     uint64
+	Sender      string // hex address ends with 0
+	Amount      string
+	Timestamp   uint64
+	HasWithdraw bool
+}
+
+func pledgeRecordKey(orderID uint64) common.Hash {
+	key := make([]byte, 0, len("pledgeRecordInfo")+9)
+	key = append(key, "pledgeRecordInfo"...)
+	key = append(key, TagUint64)
+	key = appendUint64(key, orderID)
+	return crypto.Keccak256Hash(key)
+}
+
+//decodePledgeRecord decode the TLV struct:
+//tagStruct orderid:uint64 sender:string amount:string timestamp:uint64 hasWithdraw:bool tagStructEnd
+func decodePledgeRecord(val []byte) (*PledgeRecord, error) {
+	var (
+		r   = &PledgeRecord{}
+		pos uint64
+		err error
+	)
+	if err = expectTag(val, &pos, TagStruct); err != nil {
+		return nil, err
+	}
+	if r.OrderID, err = readUint64(val, &pos); err != nil {
+		return nil, err
+	}
+	if r.Sender, err = readCheckedString(val, &pos); err != nil {
+		return nil, err
+	}
+	if r.Amount, err = readCheckedString(val, &pos); err != nil {
+		return nil, err
+	}
+	r.Amount = strings.TrimSuffix(r.Amount, "\x00")
+	if r.Timestamp, err = readUint64(val, &pos); err != nil {
+		return nil, err
+	}
+	if err = expectTag(val, &pos, TagBool); err != nil {
+		return nil, err
+	}
+	if pos >= uint64(len(val)) {
+		return nil, errTLVShort
+	}
+	r.HasWithdraw = val[pos] != 0
+	pos++
+	if err = expectTag(val, &pos, TagStructEnd); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func encodePledgeRecord(r *PledgeRecord) []byte {
+	val := make([]byte, 0, 32+len(r.Sender)+len(r.Amount))
+	val = append(val, TagStruct, TagUint64)
+	val = appendUint64(val, r.OrderID)
+	val = appendString(val, r.Sender)
+	val = appendString(val, r.Amount+"\x00")
+	val = append(val, TagUint64)
+	val = appendUint64(val, r.Timestamp)
+	val = append(val, TagBool, 0)
+	if r.HasWithdraw {
+		val[len(val)-1] = 1
+	}
+	return append(val, TagStructEnd)
+}
+
+//decodeUint64Set decode the TLV std::set<uint64>, empty value is an empty set
+func decodeUint64Set(val []byte) ([]uint64, error) {
+	if len(val) == 0 {
+		return nil, nil
+	}
+	var pos uint64
+	if err := expectTag(val, &pos, TagArray); err != nil {
+		return nil, err
+	}
+	if pos+2 > uint64(len(val)) {
+		return nil, errTLVShort
+	}
+	size := readSize(val, &pos)
+	set := make([]uint64, 0, size)
+	for i := uint16(0); i < size; i++ {
+		v, err := readUint64(val, &pos)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, v)
+	}
+	return set, nil
+}
+
 //beblow to parse the contract slice json codes
 
+//TagBool in TLV
+var TagBool = byte(1)
+
+//TagUint64 in TLV
+var TagUint64 = byte(4)
+
 //TagArray in TLV
 var TagArray = byte(7)
 
 //TagString in TLV
 var TagString = byte(6)
 
+//TagStruct in TLV
+var TagStruct = byte(0x0d)
+
+//TagStructEnd in TLV
+var TagStructEnd = byte(0x0e)
+
+var errTLVShort = errors.New("tlv value too short")
+
 func readTag(data []byte, pos *uint64) byte {
 	t := data[*pos]
 	*pos = *pos + 1
@@ -263,6 +497,55 @@ func readStringV(val []byte) []string {
 	return strSlice
 }
 
+func expectTag(val []byte, pos *uint64, tag byte) error {
+	if *pos >= uint64(len(val)) {
+		return errTLVShort
+	}
+	if t := readTag(val, pos); t != tag {
+		return fmt.Errorf("tlv tag %d, want %d", t, tag)
+	}
+	return nil
+}
+
+func readUint64(val []byte, pos *uint64) (uint64, error) {
+	if err := expectTag(val, pos, TagUint64); err != nil {
+		return 0, err
+	}
+	if *pos+8 > uint64(len(val)) {
+		return 0, errTLVShort
+	}
+	v := binary.LittleEndian.Uint64(val[*pos:])
+	*pos = *pos + 8
+	return v, nil
+}
+
+func readCheckedString(val []byte, pos *uint64) (string, error) {
+	if err := expectTag(val, pos, TagString); err != nil {
+		return "", err
+	}
+	if *pos+2 > uint64(len(val)) {
+		return "", errTLVShort
+	}
+	if size := uint64(val[*pos]) + uint64(val[*pos+1])<<8; *pos+2+size > uint64(len(val)) {
+		return "", errTLVShort
+	}
+	return readString(val, pos), nil
+}
+
+func appendUint64(val []byte, v uint64) []byte {
+	buff := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buff, v)
+	return append(val, buff...)
+}
+
+func appendString(val []byte, s string) []byte {
+	buff := make([]byte, 2)
+	binary.LittleEndian.PutUint16(buff, uint16(len(s)))
+	val = append(val, TagString)
+	val = append(val, buff...)
+	return append(val, s...)
+}
+
 //packStringkey pack the key1 and key2 for tlv encode; key2 should end with 0
 func packStringkey(key1, key2 string) []byte {
 	var (
@@ -0,0 +1,159 @@
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/config"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/crypto"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/libs/log"
+)
+
+// electorInfo encodes ElectorInfo{totalAmount, status: GOING, voteCnts: "0", shareRate: 10}
+func electorInfo(totalAmount string) []byte {
+	val := []byte{TagStruct}
+	val = appendString(val, totalAmount+"\x00")
+	val = append(val, 0x03, 0x02, 0x00, 0x00, 0x00)
+	val = appendString(val, "0\x00")
+	val = append(val, 0x03, 0x0a, 0x00, 0x00, 0x00)
+	return append(val, TagStructEnd)
+}
+
+// deposit stores the pledge records of the elector as deposit of pledge contract does
+func deposit(st *StateDB, elector common.Address, records []*PledgeRecord) {
+	total := big.NewInt(0)
+	index := []byte{TagArray, byte(len(records)), 0x00}
+	for _, r := range records {
+		st.SetState(config.ContractPledgeAddr, pledgeRecordKey(r.OrderID), encodePledgeRecord(r))
+		index = append(index, TagUint64)
+		index = appendUint64(index, r.OrderID)
+		if !r.HasWithdraw {
+			amount, _ := big.NewInt(0).SetString(r.Amount, 10)
+			total.Add(total, amount)
+			st.AddBalance(config.ContractPledgeAddr, amount)
+		}
+	}
+	st.SetState(config.ContractPledgeAddr, crypto.Keccak256Hash(packStringkey("recordIndex", elector.String()+"\x00")), index)
+	st.SetState(config.ContractPledgeAddr, crypto.Keccak256Hash(packStringkey("electorsMap", elector.String()+"\x00")), electorInfo(total.String()))
+}
+
+// withDraw pays every pledge record of the elector back as withDraw of pledge contract does
+func withDraw(t *testing.T, st *StateDB, elector common.Address) {
+	indexKey := crypto.Keccak256Hash(packStringkey("recordIndex", elector.String()+"\x00"))
+	orderIDs, err := decodeUint64Set(st.GetState(config.ContractPledgeAddr, indexKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := st.GetCandidatesDeposit([]common.Address{elector}, log.NewNopLogger())[0]
+	for _, orderID := range orderIDs {
+		r, err := decodePledgeRecord(st.GetState(config.ContractPledgeAddr, pledgeRecordKey(orderID)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.HasWithdraw {
+			continue
+		}
+		amount, _ := big.NewInt(0).SetString(r.Amount, 10)
+		if st.GetBalance(config.ContractPledgeAddr).Cmp(amount) < 0 {
+			t.Fatalf("pledge contract insolvent paying order %d of %s", orderID, elector.String())
+		}
+		total.Sub(total, amount)
+		st.SubBalance(config.ContractPledgeAddr, amount)
+		st.AddBalance(common.HexToAddress(strings.TrimSuffix(r.Sender, "\x00")), amount)
+		r.HasWithdraw = true
+		st.SetState(config.ContractPledgeAddr, pledgeRecordKey(orderID), encodePledgeRecord(r))
+	}
+	if total.Sign() != 0 {
+		t.Errorf("totalAmount of %s is %v after withdraw, want 0", elector.String(), total)
+	}
+}
+
+func TestDecodePledgeRecord(t *testing.T) {
+	// PledgeRecord{1, "0x…a1", 10, 1565078742, false} written by pledge contract
+	val := common.Hex2Bytes("0d040100000000000000062b00" +
+		common.Bytes2Hex([]byte("0x00000000000000000000000000000000000000a1\x00")) +
+		"060300313000" + "04d634495d00000000" + "0100" + "0e")
+	r, err := decodePledgeRecord(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PledgeRecord{1, "0x00000000000000000000000000000000000000a1\x00", "10", 1565078742, false}
+	if *r != want {
+		t.Fatalf("record mismatch: have %+v, want %+v", *r, want)
+	}
+	if enc := encodePledgeRecord(r); !bytes.Equal(enc, val) {
+		t.Errorf("encoded record mismatch: have %x, want %x", enc, val)
+	}
+	if _, err := decodePledgeRecord(val[:len(val)-3]); err == nil {
+		t.Error("decoded truncated record")
+	}
+}
+
+func TestConfiscateDeposit(t *testing.T) {
+	statedb, _ := New(common.EmptyHash, NewDatabase(dbm.NewMemDB()))
+	elector := common.BytesToAddress([]byte("elector"))
+	other := common.BytesToAddress([]byte("other"))
+	logger := log.NewNopLogger()
+	sender := func(b byte) string { return common.BytesToAddress([]byte{b}).String() + "\x00" }
+
+	deposit(statedb, elector, []*PledgeRecord{
+		{1, sender(0xa1), "600000", 1565078742, false},
+		{2, sender(0xa2), "399999", 1565078743, false},
+		{3, sender(0xa3), "500000", 1565078744, true},
+		{4, sender(0xa1), "1", 1565078745, false},
+	})
+	deposit(statedb, other, []*PledgeRecord{
+		{5, sender(0xb1), "700000", 1565078746, false},
+	})
+
+	// 10% of each record: 60000 + 39999 + 0
+	amount := statedb.ConfiscateDeposit(elector, 1000, logger)
+	if amount.Cmp(big.NewInt(99999)) != 0 {
+		t.Fatalf("confiscated amount mismatch: have %v, want 99999", amount)
+	}
+	if deposits := statedb.GetCandidatesDeposit([]common.Address{elector}, logger); deposits[0].Cmp(big.NewInt(900001)) != 0 {
+		t.Errorf("deposit mismatch: have %v, want 900001", deposits[0])
+	}
+	if balance := statedb.GetBalance(config.ContractFoundationAddr); balance.Cmp(amount) != 0 {
+		t.Errorf("foundation balance mismatch: have %v, want %v", balance, amount)
+	}
+	r, _ := decodePledgeRecord(statedb.GetState(config.ContractPledgeAddr, pledgeRecordKey(3)))
+	if r.Amount != "500000" {
+		t.Errorf("withdrawn record confiscated: %+v", r)
+	}
+
+	withDraw(t, statedb, elector)
+	withDraw(t, statedb, other)
+	if balance := statedb.GetBalance(config.ContractPledgeAddr); balance.Sign() != 0 {
+		t.Errorf("pledge balance after every withdraw: have %v, want 0", balance)
+	}
+	if balance := statedb.GetBalance(common.BytesToAddress([]byte{0xb1})); balance.Cmp(big.NewInt(700000)) != 0 {
+		t.Errorf("depositor of other elector paid %v, want 700000", balance)
+	}
+	if balance := statedb.GetBalance(common.BytesToAddress([]byte{0xa1})); balance.Cmp(big.NewInt(540001)) != 0 {
+		t.Errorf("depositor of slashed elector paid %v, want 540001", balance)
+	}
+
+	if amount := statedb.ConfiscateDeposit(common.BytesToAddress([]byte("none")), 1000, logger); amount.Sign() != 0 {
+		t.Errorf("confiscated %v of no deposit", amount)
+	}
+}
+
+func TestSlashingState(t *testing.T) {
+	statedb, _ := New(common.EmptyHash, NewDatabase(dbm.NewMemDB()))
+	pubkey := crypto.GenPrivKeyEd25519().PubKey()
+	logger := log.NewNopLogger()
+
+	if slashing := statedb.GetSlashingState(pubkey, logger); slashing != (SlashingJSON{}) {
+		t.Fatalf("unexpected slashing state %+v", slashing)
+	}
+	want := SlashingJSON{JailHeight: 100, Tombstoned: true}
+	statedb.SetSlashingState(pubkey, want)
+	if slashing := statedb.GetSlashingState(pubkey, logger); slashing != want {
+		t.Errorf("slashing state mismatch: have %+v, want %+v", slashing, want)
+	}
+}
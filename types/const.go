@@ -22,6 +22,8 @@ const (
 	TxCreateContract = "create_contract"
 	TxSuicide        = "sucicide"
 	TxFee            = "fee"
+	TxAward          = "award"
+	TxSlash          = "slash"
 
 	//----UTXO input/output type
 	InUTXO       = "utin"
@@ -92,6 +92,10 @@ func (b *EventBus) PublishEventProposalHeartbeat(event EventDataProposalHeartbea
 	return b.Publish(EventProposalHeartbeat, event)
 }
 
+func (b *EventBus) PublishEventNewEvidence(event EventDataNewEvidence) error {
+	return b.Publish(EventNewEvidence, event)
+}
+
 //--- EventDataRoundState events
 
 func (b *EventBus) PublishEventNewRoundStep(event EventDataRoundState) error {
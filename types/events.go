@@ -18,6 +18,7 @@ const (
 	EventLock              = "Lock"
 	EventNewBlock          = "NewBlock"
 	EventNewBlockHeader    = "NewBlockHeader"
+	EventNewEvidence       = "NewEvidence"
 	EventNewRound          = "NewRound"
 	EventNewRoundStep      = "NewRoundStep"
 	EventPolka             = "Polka"
@@ -50,6 +51,7 @@ func (_ EventDataRoundState) AssertIsTMEventData()        {}
 func (_ EventDataVote) AssertIsTMEventData()              {}
 func (_ EventDataProposalHeartbeat) AssertIsTMEventData() {}
 func (_ EventDataString) AssertIsTMEventData()            {}
+func (_ EventDataNewEvidence) AssertIsTMEventData()       {}
 
 func RegisterEventDatas() {
 	ser.RegisterInterface((*TMEventData)(nil), nil)
@@ -60,6 +62,7 @@ func RegisterEventDatas() {
 	ser.RegisterConcrete(EventDataVote{}, "event/Vote", nil)
 	ser.RegisterConcrete(EventDataProposalHeartbeat{}, "event/ProposalHeartbeat", nil)
 	ser.RegisterConcrete(EventDataString(""), "event/ProposalString", nil)
+	ser.RegisterConcrete(EventDataNewEvidence{}, "event/NewEvidence", nil)
 }
 
 // Most event messages are basic types (a block, a transaction)
@@ -98,6 +101,11 @@ type EventDataVote struct {
 
 type EventDataString string
 
+// EventDataNewEvidence is the verified evidence added to the evidence pool.
+type EventDataNewEvidence struct {
+	Evidence Evidence `json:"evidence"`
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // PUBSUB
 ///////////////////////////////////////////////////////////////////////////////
@@ -121,6 +129,7 @@ var (
 	EventQueryFork              = QueryForEvent(EventFork)
 	EventQueryNewBlock          = QueryForEvent(EventNewBlock)
 	EventQueryNewBlockHeader    = QueryForEvent(EventNewBlockHeader)
+	EventQueryNewEvidence       = QueryForEvent(EventNewEvidence)
 	EventQueryNewRound          = QueryForEvent(EventNewRound)
 	EventQueryNewRoundStep      = QueryForEvent(EventNewRoundStep)
 	EventQueryTimeoutPropose    = QueryForEvent(EventTimeoutPropose)
@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/lianxiangcloud/linkchain/config"
 	cmn "github.com/lianxiangcloud/linkchain/libs/common"
 	"github.com/lianxiangcloud/linkchain/libs/crypto"
 	"github.com/lianxiangcloud/linkchain/libs/ser"
@@ -34,6 +35,7 @@ type GenesisDoc struct {
 	ConsensusParams *ConsensusParams          `json:"consensus_params,omitempty"`
 	Validators      []GenesisValidator        `json:"validators"`
 	AllocAccounts   map[string]GenesisAccount `json:"accounts,omitempty"`
+	ChainConfig     *config.ChainConfig       `json:"chain_config,omitempty"`
 }
 
 // SaveAs is a utility method for saving GenensisDoc as a JSON file.
@@ -61,6 +63,20 @@ func (genDoc *GenesisDoc) ValidateAndComplete() error {
 		}
 	}
 
+	if genDoc.ChainConfig == nil {
+		genDoc.ChainConfig = config.DefaultChainConfig
+	} else {
+		if err := genDoc.ChainConfig.CheckConfigForkOrder(); err != nil {
+			return err
+		}
+		if err := genDoc.ChainConfig.CheckWasmGasSchedules(); err != nil {
+			return err
+		}
+		if err := genDoc.ChainConfig.CheckSlashing(); err != nil {
+			return err
+		}
+	}
+
 	if len(genDoc.Validators) == 0 {
 		return cmn.NewError("The genesis file must have at least one validator")
 	}
@@ -78,6 +78,20 @@ func (_m *MockTxCensor) GetUTXOGas() uint64 {
 	return r0
 }
 
+// Height provides a mock function with given fields:
+func (_m *MockTxCensor) Height() uint64 {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	return r0
+}
+
 // IsWasmContract provides a mock function with given fields: data
 func (_m *MockTxCensor) IsWasmContract(data []byte) bool {
 	ret := _m.Called(data)
@@ -497,7 +497,8 @@ func (tx *Transaction) CheckBasic(censor TxCensor) error {
 		return ErrInvalidSender
 	}
 
-	intrGas, err := intrinsicGas(tx.Data(), false, true) // homestead == true
+	rules := cfg.GetChainConfig().Rules(censor.Height() + 1)
+	intrGas, err := intrinsicGas(tx.Data(), false, true, rules.IsIstanbul) // homestead == true
 	if err != nil {
 		return ErrOutOfGas
 	}
@@ -549,7 +550,7 @@ func (tx *Transaction) CheckState(censor TxCensor) error {
 }
 
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func intrinsicGas(data []byte, contractCreation, homestead bool) (gas uint64, err error) {
+func intrinsicGas(data []byte, contractCreation, homestead, istanbul bool) (gas uint64, err error) {
 	// Set the starting gas for the raw transaction
 	if contractCreation && homestead {
 		gas = cfg.TxGasContractCreation
@@ -567,11 +568,15 @@ func intrinsicGas(data []byte, contractCreation, homestead bool) (gas uint64, er
 			nz++
 		}
 	}
+	nonZeroGas := cfg.TxDataNonZeroGas
+	if istanbul {
+		nonZeroGas = cfg.TxDataNonZeroGasEIP2028
+	}
 	// Make sure we don't exceed uint64 for all data combinations
-	if (math.MaxUint64-gas)/cfg.TxDataNonZeroGas < nz {
+	if (math.MaxUint64-gas)/nonZeroGas < nz {
 		return 0, ErrOutOfGas
 	}
-	gas += nz * cfg.TxDataNonZeroGas
+	gas += nz * nonZeroGas
 
 	z := uint64(len(data)) - nz
 	if (math.MaxUint64-gas)/cfg.TxDataZeroGas < z {
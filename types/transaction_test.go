@@ -283,6 +283,7 @@ func TestTransactionCheckBasic(t *testing.T) {
 	censor.On("State").Return(state)
 	censor.On("LockState").Return()
 	censor.On("UnlockState").Return()
+	censor.On("Height").Return(uint64(0))
 	state.On("IsContract", mock.Anything).Return(false)
 
 	to := common.HexToAddress("0x01")
@@ -159,6 +159,15 @@ func (txs Txs) IndexByHash(hash common.Hash) int {
 	return -1
 }
 
+// Hashes returns the hashes of the transactions.
+func (txs Txs) Hashes() []common.Hash {
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
+	return hashes
+}
+
 // TxProof represents a Merkle proof of the presence of a transaction in the Merkle tree.
 type TxProof struct {
 	Index, Total int
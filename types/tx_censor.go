@@ -56,4 +56,5 @@ type TxCensor interface {
 	UTXOStore() UTXOStore
 	Mempool() Mempool
 	GetUTXOGas() uint64
+	Height() uint64
 }
@@ -23,6 +23,7 @@ import (
 	"math/big"
 	"sync/atomic"
 
+	cfg "github.com/lianxiangcloud/linkchain/config"
 	"github.com/lianxiangcloud/linkchain/libs/common"
 	"github.com/lianxiangcloud/linkchain/libs/crypto"
 	"github.com/lianxiangcloud/linkchain/libs/log"
@@ -395,7 +396,8 @@ func (tx *TokenTransaction) CheckBasic(censor TxCensor) error {
 		return ErrInvalidSender
 	}
 
-	intrGas, err := intrinsicGas(tx.Data(), false, true) // homestead == true
+	rules := cfg.GetChainConfig().Rules(censor.Height() + 1)
+	intrGas, err := intrinsicGas(tx.Data(), false, true, rules.IsIstanbul) // homestead == true
 	if err != nil {
 		return ErrOutOfGas
 	}
@@ -111,6 +111,7 @@ func TestTokenTransactionCheckBasic(t *testing.T) {
 	censor.On("State").Return(state)
 	censor.On("LockState").Return()
 	censor.On("UnlockState").Return()
+	censor.On("Height").Return(uint64(0))
 
 	to := common.HexToAddress("0x01")
 	var tx *TokenTransaction
@@ -76,6 +76,8 @@ var (
 	ErrCheckAccountOutputsIllegal = errors.New("account outputs illegal")
 	ErrMixRingMemberNotSupport    = errors.New("mix ring member not support")
 	ErrRingCTSignaturesInvalid    = errors.New("rct signature invalid")
+	ErrMultisigShortRing          = errors.New("multisig not support short ring")
+	ErrMultisigKLRkiSizeNotMatch  = errors.New("multisig kLRki size does not match inputs")
 )
 
 const (
@@ -1513,11 +1515,22 @@ func Hash2BigInt(key types.Key) *big.Int {
 //5 compute RangeBulletproof, utxo commitment, account commitment, ring signature
 func NewUinTransaction(acc *types.AccountKey, keyIndex map[types.PublicKey]uint64, utxoSources []*UTXOSourceEntry,
 	dests []DestEntry, tokenID common.Address, refundAddr common.Address, extra []byte) (*UTXOTransaction, []*UTXOInputEphemeral, types.KeyV, *types.Key, error) {
-	rSecKey, rPubKey := xcrypto.SkpkGen()
 	utxoInEphs, err := GenerateKeyImage(acc, keyIndex, utxoSources)
 	if err != nil {
 		return nil, nil, types.KeyV{}, nil, err
 	}
+	utxoTrans, mKeys, rSecKey, err := NewUinTransactionWithInputs(utxoSources, utxoInEphs, dests, tokenID, refundAddr, extra)
+	if err != nil {
+		return nil, nil, types.KeyV{}, nil, err
+	}
+	return utxoTrans, utxoInEphs, mKeys, rSecKey, nil
+}
+
+//NewUinTransactionWithInputs return a UTXOTransaction for utxo input only,
+//key images and input secret keys are provided by caller (eg. multisig wallet)
+func NewUinTransactionWithInputs(utxoSources []*UTXOSourceEntry, utxoInEphs []*UTXOInputEphemeral, dests []DestEntry,
+	tokenID common.Address, refundAddr common.Address, extra []byte) (*UTXOTransaction, types.KeyV, *types.Key, error) {
+	rSecKey, rPubKey := xcrypto.SkpkGen()
 	var utxoDests []*UTXODestEntry
 	for _, dest := range dests {
 		if TypeUTXODest == dest.Type() {
@@ -1526,17 +1539,17 @@ func NewUinTransaction(acc *types.AccountKey, keyIndex map[types.PublicKey]uint6
 	}
 	utxoOuts, mKeys, err := GenerateOneTimeAddress(rSecKey, utxoDests)
 	if err != nil {
-		return nil, nil, types.KeyV{}, nil, err
+		return nil, types.KeyV{}, nil, err
 	}
 	additionalKeys, err := GenerateAdditionalKeys(rSecKey, utxoDests)
 	if err != nil {
-		return nil, nil, types.KeyV{}, nil, err
+		return nil, types.KeyV{}, nil, err
 	}
 	utxoTrans, err := constructUinTrans(rPubKey, utxoSources, utxoInEphs, dests, utxoOuts, additionalKeys, mKeys, tokenID, refundAddr, extra)
 	if err != nil {
-		return nil, nil, types.KeyV{}, nil, err
+		return nil, types.KeyV{}, nil, err
 	}
-	return utxoTrans, utxoInEphs, mKeys, &rSecKey, nil
+	return utxoTrans, mKeys, &rSecKey, nil
 }
 
 func constructUinTrans(rPubKey types.Key, sources []*UTXOSourceEntry, utxoIns []*UTXOInputEphemeral, dests []DestEntry, utxoOuts []*UTXOOutput,
@@ -1620,6 +1633,30 @@ func constructUinTrans(rPubKey types.Key, sources []*UTXOSourceEntry, utxoIns []
 
 func UInTransWithRctSig(utxoTrans *UTXOTransaction, sources []*UTXOSourceEntry, utxoIns []*UTXOInputEphemeral,
 	dests []DestEntry, mkeys types.KeyV) error {
+	return uInTransWithRctSig(utxoTrans, sources, utxoIns, dests, mkeys, nil, nil)
+}
+
+//UInTransWithRctSigMultisig sign the utxo inputs with the aggregated multisig nonces,
+//return the MLSAG challenge of every input which the other signers need to finish the signature
+func UInTransWithRctSigMultisig(utxoTrans *UTXOTransaction, sources []*UTXOSourceEntry, utxoIns []*UTXOInputEphemeral,
+	dests []DestEntry, mkeys types.KeyV, kLRki []types.MultisigKLRki) (types.KeyV, error) {
+	if len(kLRki) != len(sources) {
+		return nil, ErrMultisigKLRkiSizeNotMatch
+	}
+	for i := 0; i < len(sources); i++ {
+		if len(sources[i].Ring) == SHORT_RING_MEMBER_NUM {
+			return nil, ErrMultisigShortRing
+		}
+	}
+	msout := make(types.KeyV, len(sources))
+	if err := uInTransWithRctSig(utxoTrans, sources, utxoIns, dests, mkeys, kLRki, msout); err != nil {
+		return nil, err
+	}
+	return msout, nil
+}
+
+func uInTransWithRctSig(utxoTrans *UTXOTransaction, sources []*UTXOSourceEntry, utxoIns []*UTXOInputEphemeral,
+	dests []DestEntry, mkeys types.KeyV, kLRki []types.MultisigKLRki, msout types.KeyV) error {
 	outAmounts := make([]types.Key, 0)
 	for _, dest := range dests {
 		if TypeUTXODest == dest.Type() {
@@ -1718,7 +1755,15 @@ func UInTransWithRctSig(utxoTrans *UTXOTransaction, sources []*UTXOSourceEntry,
 			}
 			utxoTrans.RCTSig.P.Ss[i] = *ssig
 		} else {
-			mgSig, err := ringct.ProveRctMGSimple(hash, rings[i], inSKey[i], ra[i], utxoTrans.RCTSig.P.PseudoOuts[i], nil, nil, indexs[i])
+			var (
+				mscout *types.Key
+				klrki  *types.MultisigKLRki
+			)
+			if kLRki != nil {
+				mscout = &msout[i]
+				klrki = &kLRki[i]
+			}
+			mgSig, err := ringct.ProveRctMGSimple(hash, rings[i], inSKey[i], ra[i], utxoTrans.RCTSig.P.PseudoOuts[i], mscout, klrki, indexs[i])
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,50 @@
+package utxo
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Number of the utxo outputs, by token.
+	Outputs metrics.Gauge
+	// Number of the key image lookups, by whether the key image is spent.
+	KeyImageLookups metrics.Counter
+	// Time of picking the random utxo outputs.
+	RandomOutputsSeconds metrics.Histogram
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+func PrometheusMetrics() *Metrics {
+	return &Metrics{
+		Outputs: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Subsystem: "utxo",
+			Name:      "outputs",
+			Help:      "Number of the utxo outputs, by token.",
+		}, []string{"token"}),
+		KeyImageLookups: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Subsystem: "utxo",
+			Name:      "key_image_lookups",
+			Help:      "Number of the key image lookups, by whether the key image is spent.",
+		}, []string{"spent"}),
+		RandomOutputsSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Subsystem: "utxo",
+			Name:      "random_outputs_seconds",
+			Help:      "Time of picking the random utxo outputs.",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1},
+		}, []string{}),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		Outputs:              discard.NewGauge(),
+		KeyImageLookups:      discard.NewCounter(),
+		RandomOutputsSeconds: discard.NewHistogram(),
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/lianxiangcloud/linkchain/libs/log"
 	"github.com/lianxiangcloud/linkchain/libs/ser"
 	"sync"
+	"time"
 )
 
 const (
@@ -31,16 +32,33 @@ type UtxoStore struct {
 	mapMutex                 sync.Mutex
 	logger                   log.Logger
 	blockHeight              uint64
+	metrics                  *Metrics
 }
 
-func NewUtxoStore(utxoDB dbm.DB, utxoOutputDB dbm.DB, utxoOutputTokenDB dbm.DB) *UtxoStore {
+// StoreOption sets an optional parameter on the UtxoStore.
+type StoreOption func(*UtxoStore)
+
+// WithMetrics sets the metrics.
+func WithMetrics(metrics *Metrics) StoreOption {
+	return func(u *UtxoStore) { u.metrics = metrics }
+}
+
+func NewUtxoStore(utxoDB dbm.DB, utxoOutputDB dbm.DB, utxoOutputTokenDB dbm.DB, options ...StoreOption) *UtxoStore {
 	tokenMaxSeqMap := loadTokenUtxoStoreMaxUtxoOutputSeqMap(utxoDB)
-	return &UtxoStore{
+	u := &UtxoStore{
 		utxoDB:                   utxoDB,
 		utxoOutputDB:             utxoOutputDB,
 		utxoOutputTokenDB:        utxoOutputTokenDB,
 		maxUtxoOutputSeqTokenMap: tokenMaxSeqMap,
+		metrics:                  NopMetrics(),
 	}
+	for _, option := range options {
+		option(u)
+	}
+	for tokenId, seq := range tokenMaxSeqMap {
+		u.metrics.Outputs.With("token", tokenId).Set(float64(seq + 1))
+	}
+	return u
 }
 
 func loadTokenUtxoStoreMaxUtxoOutputSeqMap(utxoDB dbm.DB) map[string]int64 {
@@ -118,6 +136,7 @@ func (u *UtxoStore)saveTokenUtxoOutputSeq(tokenSeqMap map[string]int64) error {
 		}
 		tokenOutputSeqs[tokenId] = initBlockSeq
 		u.maxUtxoOutputSeqTokenMap[tokenId] = seq
+		u.metrics.Outputs.With("token", tokenId).Set(float64(seq + 1))
 	}
 	u.mapMutex.Unlock()
 
@@ -151,10 +170,9 @@ func (u *UtxoStore) SaveUtxo(kImgs []*lctypes.Key, utxoOutputs []*types.UTXOOutp
 
 func (u *UtxoStore) HaveTxKeyimgAsSpent(kImg *lctypes.Key) bool {
 	val := u.utxoDB.Get(kImg[:])
-	if len(val) != 0 {
-		return true
-	}
-	return false
+	spent := len(val) != 0
+	u.metrics.KeyImageLookups.With("spent", strconv.FormatBool(spent)).Add(1)
+	return spent
 }
 
 func (u *UtxoStore) SaveKImages(kImgs []*lctypes.Key) error {
@@ -257,6 +275,9 @@ func (u *UtxoStore) GetUtxoOutputs(seqs []uint64, tokenId common.Address) ([]*ty
 }
 
 func (u *UtxoStore) GetRandomUtxoOutputs(counts int, tokenId common.Address) []*types.UTXOOutputData  {
+	defer func(start time.Time) {
+		u.metrics.RandomOutputsSeconds.Observe(time.Since(start).Seconds())
+	}(time.Now())
 	u.mapMutex.Lock()
 	if int64(counts) > u.maxUtxoOutputSeqTokenMap[tokenId.String()] + 1 {
 		u.logger.Error("GetRandomUtxoOutputs failed. err: counts>maxUtxoOutputSeq",
@@ -159,7 +159,7 @@ func UnsafeTransfer(db types.StateDB, recipient, token common.Address, amount *b
 func run(evm *EVM, c types.Contract, input []byte, readOnly bool) ([]byte, error) {
 	contract := c.(*Contract)
 	if contract.CodeAddr != nil {
-		precompiles := PrecompiledContractsHomestead
+		precompiles := PrecompiledContracts(evm.chainRules)
 		if p := precompiles[*contract.CodeAddr]; p != nil {
 			return RunPrecompiledContract(p, input, contract)
 		}
@@ -197,6 +197,8 @@ type EVM struct {
 	// available gas is calculated in gasCall* according to the 63/64 rule and later
 	// applied in opCall*.
 	callGasTemp uint64
+	// chainRules are the protocol rules of the block being processed
+	chainRules cfg.Rules
 
 	otxs []types.BalanceRecord
 }
@@ -208,16 +210,22 @@ func NewEVM(c types.Context, statedb types.StateDB, vmc types.VmConfig) *EVM {
 	vmConfig := vmc.(Config)
 
 	evm := &EVM{
-		Context:  ctx,
-		StateDB:  statedb,
-		vmConfig: vmConfig,
-		otxs:     make([]types.BalanceRecord, 0),
+		Context:    ctx,
+		StateDB:    statedb,
+		vmConfig:   vmConfig,
+		chainRules: cfg.RulesAt(ctx.BlockNumber),
+		otxs:       make([]types.BalanceRecord, 0),
 	}
 
 	evm.interpreter = NewInterpreter(evm, vmConfig)
 	return evm
 }
 
+// ChainRules returns the protocol rules the EVM runs with.
+func (evm *EVM) ChainRules() cfg.Rules {
+	return evm.chainRules
+}
+
 func (evm *EVM) Reset(msg types.Message) {
 	evm.depth = 0
 	evm.abort = 0
@@ -254,7 +262,7 @@ func (evm *EVM) UTXOCall(c types.ContractRef, addr, token common.Address, input
 		snapshot = evm.StateDB.Snapshot()
 	)
 	if !evm.StateDB.Exist(addr) {
-		precompiles := PrecompiledContractsHomestead
+		precompiles := PrecompiledContracts(evm.chainRules)
 		if precompiles[addr] == nil && value.Sign() == 0 {
 			// Calling a non existing account, don't do antything
 			return nil, gas, 0, nil
@@ -318,7 +326,7 @@ func (evm *EVM) Call(c types.ContractRef, addr, token common.Address, input []by
 		snapshot = evm.StateDB.Snapshot()
 	)
 	if !evm.StateDB.Exist(addr) {
-		precompiles := PrecompiledContractsHomestead
+		precompiles := PrecompiledContracts(evm.chainRules)
 		if precompiles[addr] == nil && value.Sign() == 0 {
 			// Calling a non existing account, don't do antything, but ping the tracer
 			if evm.vmConfig.Debug && evm.depth == 0 {
@@ -530,6 +538,11 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	evm.StateDB.SetNonce(caller.Address(), nonce+1)
 
 	contractAddr = crypto.CreateAddress(caller.Address(), evm.Nonce, codeAndHash.code)
+	// We add this to the access list _before_ taking a snapshot. Even if the creation fails,
+	// the access-list change should not be rolled back
+	if evm.chainRules.IsBerlin {
+		evm.StateDB.AddAddressToAccessList(contractAddr)
+	}
 	contractHash := evm.StateDB.GetCodeHash(contractAddr)
 	if evm.StateDB.GetNonce(contractAddr) != 0 || (contractHash != common.EmptyHash && contractHash != emptyCodeHash) {
 		return nil, common.EmptyAddress, 0, ErrContractAddressCollision
@@ -564,6 +577,11 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 
 	// check whether the max code size has been exceeded
 	maxCodeSizeExceeded := len(ret) > cfg.MaxCodeSize
+
+	// Reject code starting with 0xEF if EIP-3541 is enabled.
+	if err == nil && len(ret) >= 1 && ret[0] == 0xEF && evm.chainRules.IsLondon {
+		err = ErrInvalidCode
+	}
 	// if the contract creation ran successfully and no errors were returned
 	// calculate the gas required to store the code. If the code could not
 	// be stored due to not enough gas set an error and let it be handled
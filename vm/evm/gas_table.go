@@ -17,6 +17,8 @@
 package evm
 
 import (
+	"errors"
+
 	cfg "github.com/lianxiangcloud/linkchain/config"
 	"github.com/lianxiangcloud/linkchain/libs/common"
 	"github.com/lianxiangcloud/linkchain/libs/math"
@@ -137,6 +139,53 @@ func gasSStore(gt cfg.GasTable, evm *EVM, contract *Contract, stack *Stack, mem
 	}
 }
 
+// gasSStoreEIP2200 calculates the SSTORE gas of the net gas metering, see EIP-2200.
+// A no-op or a write to a dirty slot costs SLOAD_GAS, the first write to a slot in
+// the transaction costs SSTORE_SET_GAS or SSTORE_RESET_GAS, and the refund counter
+// tracks clears and resets to the original value. The call fails if no more than
+// the 2300 stipend is left.
+func gasSStoreEIP2200(gt cfg.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	// If we fail the minimum gas availability invariant, fail (0)
+	if contract.Gas <= cfg.SstoreSentryGasEIP2200 {
+		return 0, errors.New("not enough gas for reentrancy sentry")
+	}
+	// Gas sentry honoured, do the actual gas calculation based on the stored value
+	var (
+		y, x    = stack.Back(1), stack.Back(0)
+		slot    = common.BigToHash(x)
+		current = common.BytesToHash(evm.StateDB.GetState(contract.Address(), slot))
+		value   = common.BigToHash(y)
+	)
+	if current == value { // noop (1)
+		return cfg.SloadGasEIP2200, nil
+	}
+	original := common.BytesToHash(evm.StateDB.GetCommittedState(contract.Address(), slot))
+	if original == current {
+		if original == (common.Hash{}) { // create slot (2.1.1)
+			return cfg.SstoreSetGasEIP2200, nil
+		}
+		if value == (common.Hash{}) { // delete slot (2.1.2b)
+			evm.StateDB.AddRefund(cfg.SstoreClearsScheduleRefundEIP2200)
+		}
+		return cfg.SstoreResetGasEIP2200, nil // write existing slot (2.1.2)
+	}
+	if original != (common.Hash{}) {
+		if current == (common.Hash{}) { // recreate slot (2.2.1.1)
+			evm.StateDB.SubRefund(cfg.SstoreClearsScheduleRefundEIP2200)
+		} else if value == (common.Hash{}) { // delete slot (2.2.1.2)
+			evm.StateDB.AddRefund(cfg.SstoreClearsScheduleRefundEIP2200)
+		}
+	}
+	if original == value {
+		if original == (common.Hash{}) { // reset to original inexistent slot (2.2.2.1)
+			evm.StateDB.AddRefund(cfg.SstoreSetGasEIP2200 - cfg.SloadGasEIP2200)
+		} else { // reset to original existing slot (2.2.2.2)
+			evm.StateDB.AddRefund(cfg.SstoreResetGasEIP2200 - cfg.SloadGasEIP2200)
+		}
+	}
+	return cfg.SloadGasEIP2200, nil // dirty update (2.2)
+}
+
 func makeGasLog(n uint64) gasFunc {
 	return func(gt cfg.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
 		requestedSize, overflow := bigUint64(stack.Back(1))
@@ -64,7 +64,7 @@ func NewInterpreter(evm *EVM, cfg Config) *Interpreter {
 	// we'll set the default jump table.
 	if !cfg.JumpTable[STOP].valid {
 		// cfg.JumpTable = byzantiumInstructionSet
-		cfg.JumpTable = constantinopleInstructionSet
+		cfg.JumpTable = instructionSetForRules(evm.chainRules)
 	}
 
 	return &Interpreter{
@@ -55,8 +55,93 @@ var (
 	homesteadInstructionSet      = newHomesteadInstructionSet()
 	byzantiumInstructionSet      = newByzantiumInstructionSet()
 	constantinopleInstructionSet = newConstantinopleInstructionSet()
+	istanbulInstructionSet       = newIstanbulInstructionSet()
+	berlinInstructionSet         = newBerlinInstructionSet()
+	londonInstructionSet         = newLondonInstructionSet()
 )
 
+// instructionSetForRules returns the instruction set in effect under the rules
+// of the block being processed.
+func instructionSetForRules(rules cfg.Rules) [256]operation {
+	switch {
+	case rules.IsLondon:
+		return londonInstructionSet
+	case rules.IsBerlin:
+		return berlinInstructionSet
+	case rules.IsIstanbul:
+		return istanbulInstructionSet
+	default:
+		return constantinopleInstructionSet
+	}
+}
+
+// newLondonInstructionSet returns the frontier, homestead, byzantium,
+// contantinople, istanbul, berlin and london instructions.
+func newLondonInstructionSet() [256]operation {
+	// instructions that can be executed during the berlin phase.
+	instructionSet := newBerlinInstructionSet()
+	instructionSet[BASEFEE] = operation{
+		execute:       opBaseFee,
+		gasCost:       constGasFunc(GasQuickStep),
+		validateStack: makeStackFunc(0, 1),
+		valid:         true,
+	}
+	// PUSH0 (EIP-3855) is scheduled with london on this chain.
+	instructionSet[PUSH0] = operation{
+		execute:       opPush0,
+		gasCost:       constGasFunc(GasQuickStep),
+		validateStack: makeStackFunc(0, 1),
+		valid:         true,
+	}
+	// EIP-3529 removes the refund of SELFDESTRUCT and reduces the one of SSTORE.
+	instructionSet[SSTORE].gasCost = gasSStoreEIP3529
+	instructionSet[SELFDESTRUCT].gasCost = gasSelfdestructEIP3529
+	return instructionSet
+}
+
+// newBerlinInstructionSet returns the frontier, homestead, byzantium,
+// contantinople, istanbul and berlin instructions.
+func newBerlinInstructionSet() [256]operation {
+	// instructions that can be executed during the istanbul phase.
+	instructionSet := newIstanbulInstructionSet()
+	// EIP-2929 charges the first access of an account or a storage slot.
+	instructionSet[SLOAD].gasCost = gasSLoadEIP2929
+	instructionSet[SSTORE].gasCost = gasSStoreEIP2929
+	instructionSet[EXTCODECOPY].gasCost = gasExtCodeCopyEIP2929
+	instructionSet[EXTCODESIZE].gasCost = makeGasAccountCheckEIP2929(gasExtCodeSize, 0)
+	instructionSet[EXTCODEHASH].gasCost = makeGasAccountCheckEIP2929(gasExtCodeHash, 0)
+	instructionSet[BALANCE].gasCost = makeGasAccountCheckEIP2929(gasBalance, 0)
+	instructionSet[BALANCETOKEN].gasCost = makeGasAccountCheckEIP2929(gasBalance, 1)
+	instructionSet[CALL].gasCost = gasCallEIP2929
+	instructionSet[CALLCODE].gasCost = gasCallCodeEIP2929
+	instructionSet[STATICCALL].gasCost = gasStaticCallEIP2929
+	instructionSet[DELEGATECALL].gasCost = gasDelegateCallEIP2929
+	instructionSet[SELFDESTRUCT].gasCost = gasSelfdestructEIP2929
+	return instructionSet
+}
+
+// newIstanbulInstructionSet returns the frontier, homestead, byzantium,
+// contantinople and istanbul instructions.
+func newIstanbulInstructionSet() [256]operation {
+	// instructions that can be executed during the constantinople phase.
+	instructionSet := newConstantinopleInstructionSet()
+	instructionSet[CHAINID] = operation{
+		execute:       opChainID,
+		gasCost:       constGasFunc(GasQuickStep),
+		validateStack: makeStackFunc(0, 1),
+		valid:         true,
+	}
+	instructionSet[SELFBALANCE] = operation{
+		execute:       opSelfBalance,
+		gasCost:       constGasFunc(GasFastStep),
+		validateStack: makeStackFunc(0, 1),
+		valid:         true,
+	}
+	// EIP-2200 net gas metering of SSTORE.
+	instructionSet[SSTORE].gasCost = gasSStoreEIP2200
+	return instructionSet
+}
+
 // NewConstantinopleInstructionSet returns the frontier, homestead
 // byzantium and contantinople instructions.
 func newConstantinopleInstructionSet() [256]operation {
@@ -101,6 +101,9 @@ const (
 	NUMBER
 	DIFFICULTY
 	GASLIMIT
+	CHAINID
+	SELFBALANCE
+	BASEFEE
 )
 
 // 0x50 range - 'storage' and execution.
@@ -117,6 +120,7 @@ const (
 	MSIZE
 	GAS
 	JUMPDEST
+	PUSH0 OpCode = 0x5f
 )
 
 // 0x60 range.
@@ -280,12 +284,15 @@ var opCodeToString = map[OpCode]string{
 	EXTCODEHASH:    "EXTCODEHASH",
 
 	// 0x40 range - block operations.
-	BLOCKHASH:  "BLOCKHASH",
-	COINBASE:   "COINBASE",
-	TIMESTAMP:  "TIMESTAMP",
-	NUMBER:     "NUMBER",
-	DIFFICULTY: "DIFFICULTY",
-	GASLIMIT:   "GASLIMIT",
+	BLOCKHASH:   "BLOCKHASH",
+	COINBASE:    "COINBASE",
+	TIMESTAMP:   "TIMESTAMP",
+	NUMBER:      "NUMBER",
+	DIFFICULTY:  "DIFFICULTY",
+	GASLIMIT:    "GASLIMIT",
+	CHAINID:     "CHAINID",
+	SELFBALANCE: "SELFBALANCE",
+	BASEFEE:     "BASEFEE",
 
 	// 0x50 range - 'storage' and execution.
 	POP: "POP",
@@ -302,6 +309,7 @@ var opCodeToString = map[OpCode]string{
 	MSIZE:    "MSIZE",
 	GAS:      "GAS",
 	JUMPDEST: "JUMPDEST",
+	PUSH0:    "PUSH0",
 
 	// 0x60 range - push.
 	PUSH1:  "PUSH1",
@@ -459,6 +467,9 @@ var stringToOp = map[string]OpCode{
 	"NUMBER":           NUMBER,
 	"DIFFICULTY":       DIFFICULTY,
 	"GASLIMIT":         GASLIMIT,
+	"CHAINID":          CHAINID,
+	"SELFBALANCE":      SELFBALANCE,
+	"BASEFEE":          BASEFEE,
 	"POP":              POP,
 	"MLOAD":            MLOAD,
 	"MSTORE":           MSTORE,
@@ -471,6 +482,7 @@ var stringToOp = map[string]OpCode{
 	"MSIZE":            MSIZE,
 	"GAS":              GAS,
 	"JUMPDEST":         JUMPDEST,
+	"PUSH0":            PUSH0,
 	"PUSH1":            PUSH1,
 	"PUSH2":            PUSH2,
 	"PUSH3":            PUSH3,
@@ -14,5 +14,6 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
-// Package runtime provides a basic execution model for executing EVM code.
+// Package runtime provides a basic execution model for executing EVM code, and
+// an in-memory chain for testing the WASM contracts.
 package runtime
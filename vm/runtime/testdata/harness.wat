@@ -0,0 +1,32 @@
+;; Source of harness.wasm, the contract exercised by TestWasmChain.
+;;
+;; Init stores "inited", any other action stores "counter", logs the Paid event,
+;; transfers 100 to 0x...aa and issues 1000 tokens of the contract.
+(module
+  (import "env" "TC_StorageSetString" (func $storageSet (param i32 i32)))
+  (import "env" "TC_Log1" (func $log1 (param i32 i32)))
+  (import "env" "TC_Transfer" (func $transfer (param i32 i32)))
+  (import "env" "TC_Issue" (func $issue (param i32)))
+  (memory (export "memory") 1)
+  (global (export "__heap_base") i32 (i32.const 16480))
+  (global (export "__data_end") i32 (i32.const 16473))
+  (data (i32.const 16384)
+    "ok\00"
+    "inited\00"
+    "1\00"
+    "counter\00"
+    "{\"0\":\"100\"}\00"
+    "Paid\00"
+    "0x00000000000000000000000000000000000000aa\00"
+    "100\00"
+    "1000\00")
+  (func (export "thunderchain_main") (param $action i32) (param $args i32) (result i32)
+    (if (i32.eq (i32.load8_u (local.get $action)) (i32.const 73)) ;; 'I'
+      (then
+        (call $storageSet (i32.const 16387) (i32.const 16394))
+        (return (i32.const 16384))))
+    (call $storageSet (i32.const 16396) (i32.const 16394))
+    (call $log1 (i32.const 16404) (i32.const 16416))
+    (call $transfer (i32.const 16421) (i32.const 16464))
+    (call $issue (i32.const 16468))
+    (i32.const 16384)))
@@ -0,0 +1,293 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/lianxiangcloud/linkchain/accounts/wasmabi"
+	"github.com/lianxiangcloud/linkchain/config"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/crypto"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/state"
+	"github.com/lianxiangcloud/linkchain/types"
+	"github.com/lianxiangcloud/linkchain/vm/wasm"
+	"github.com/xunleichain/tc-wasm/vm"
+)
+
+// defaultWasmGasLimit is the default gas limit of the transactions.
+const defaultWasmGasLimit uint64 = 100000000
+
+// initArgsID marks the init args prepended to the wasm code of a deployment.
+var initArgsID = []byte("XLTC")
+
+// WasmChain is an in-memory chain running the wasm contracts over a memdb
+// backed StateDB, for the contracts to be unit tested in Go. The transactions
+// are executed in the current block, whose height and time the tests advance.
+type WasmChain struct {
+	State    *state.StateDB
+	Height   uint64
+	Time     uint64
+	Coinbase common.Address
+	GasLimit uint64 // gas limit of each transaction
+	GasPrice *big.Int
+
+	txIndex int // index of the next transaction in the block
+}
+
+// NewWasmChain returns a chain at height 1 with an empty state.
+func NewWasmChain() *WasmChain {
+	st, _ := state.New(common.EmptyHash, state.NewDatabase(dbm.NewMemDB()))
+	return &WasmChain{
+		State:    st,
+		Height:   1,
+		Time:     1565078742,
+		GasLimit: defaultWasmGasLimit,
+		GasPrice: big.NewInt(types.ParGasPrice),
+	}
+}
+
+// Advance moves the chain forward by blocks blocks and seconds seconds.
+func (c *WasmChain) Advance(blocks, seconds uint64) {
+	c.Height += blocks
+	c.Time += seconds
+	if blocks > 0 {
+		c.txIndex = 0
+	}
+}
+
+// BlockHash returns the hash of the block at height.
+func (c *WasmChain) BlockHash(height uint64) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("block-%d", height)))
+}
+
+// GetHeader implements wasm.ChainContext.
+func (c *WasmChain) GetHeader(height uint64) *types.Header {
+	if height > c.Height {
+		return nil
+	}
+	return &types.Header{Height: height, Time: c.Time, ParentHash: c.BlockHash(height - 1)}
+}
+
+// Fund adds amount to the balance of addr.
+func (c *WasmChain) Fund(addr common.Address, amount *big.Int) {
+	c.State.AddBalance(addr, amount)
+}
+
+// Balance returns the balance of addr.
+func (c *WasmChain) Balance(addr common.Address) *big.Int {
+	return c.State.GetBalance(addr)
+}
+
+// TokenBalance returns the balance of the token held by addr, the token issued by
+// a contract is the contract address.
+func (c *WasmChain) TokenBalance(addr, token common.Address) *big.Int {
+	return c.State.GetTokenBalance(addr, token)
+}
+
+// WasmResult is the result of a wasm transaction.
+type WasmResult struct {
+	Return    []byte
+	GasUsed   uint64
+	Logs      []*types.Log
+	Transfers []types.BalanceRecord
+}
+
+// Issued returns the amount of the tokens issued by the transaction.
+func (r *WasmResult) Issued() *big.Int {
+	issued := new(big.Int)
+	for _, br := range r.Transfers {
+		if br.From == common.EmptyAddress && br.FromAddressType == types.NoAddress && br.Type == types.TxContract {
+			issued.Add(issued, br.Amount)
+		}
+	}
+	return issued
+}
+
+// Deploy deploys the wasm code from the sender, args are the args of the Init
+// action of the contract. The contract packs the calls with abi, or encodes the
+// args as JSON if abi is nil.
+func (c *WasmChain) Deploy(from common.Address, code []byte, abi *wasmabi.ABI, value *big.Int, args ...interface{}) (*WasmContract, *WasmResult, error) {
+	if !wasm.IsWasmContract(code) {
+		return nil, nil, fmt.Errorf("not a wasm code")
+	}
+	contract := &WasmContract{chain: c, ABI: abi}
+
+	data := code
+	if len(args) > 0 {
+		input, err := contract.Pack("Init", args...)
+		if err != nil {
+			return nil, nil, err
+		}
+		initArgs := input[len("Init"+wasmabi.InputSeparator):]
+		if len(initArgs) > math.MaxUint16 {
+			return nil, nil, fmt.Errorf("init args too long: %d", len(initArgs))
+		}
+		var buf bytes.Buffer
+		buf.Write(vm.WasmBytes)
+		buf.Write(initArgsID)
+		binary.Write(&buf, binary.BigEndian, uint16(len(initArgs)))
+		buf.Write(initArgs)
+		buf.Write(code)
+		data = buf.Bytes()
+	}
+
+	var addr common.Address
+	res, err := c.execute(from, nil, value, data, func(w *wasm.WASM, gas uint64, value *big.Int) ([]byte, uint64, error) {
+		ret, contractAddr, leftOverGas, err := w.Create(wasm.AccountRef(from), data, gas, value)
+		addr = contractAddr
+		return ret, leftOverGas, err
+	})
+	if err != nil {
+		return nil, res, err
+	}
+	contract.Address = addr
+	return contract, res, nil
+}
+
+// At returns the contract deployed at addr.
+func (c *WasmChain) At(addr common.Address, abi *wasmabi.ABI) *WasmContract {
+	return &WasmContract{chain: c, Address: addr, ABI: abi}
+}
+
+// Call sends the transaction calling the contract with the raw input.
+func (c *WasmChain) Call(from, to common.Address, value *big.Int, input []byte) (*WasmResult, error) {
+	res, err := c.execute(from, &to, value, input, func(w *wasm.WASM, gas uint64, value *big.Int) ([]byte, uint64, error) {
+		ret, leftOverGas, _, err := w.Call(wasm.AccountRef(from), to, common.EmptyAddress, input, gas, value)
+		return ret, leftOverGas, err
+	})
+	if err == nil {
+		c.State.SetNonce(from, c.State.GetNonce(from)+1)
+	}
+	return res, err
+}
+
+// execute runs the transaction in the current block, the state is reverted if
+// the transaction fails.
+func (c *WasmChain) execute(from common.Address, to *common.Address, value *big.Int, input []byte,
+	run func(w *wasm.WASM, gas uint64, value *big.Int) ([]byte, uint64, error)) (*WasmResult, error) {
+	if value == nil {
+		value = new(big.Int)
+	}
+	header := &types.Header{
+		Height:     c.Height,
+		Time:       c.Time,
+		Coinbase:   c.Coinbase,
+		GasLimit:   c.GasLimit,
+		ParentHash: c.BlockHash(c.Height - 1),
+	}
+	ctx := wasm.NewWASMContext(header, c, nil, config.WasmGasRate)
+	w := wasm.NewWASM(ctx, c.State, nil)
+	w.Reset(types.NewMessage(from, to, common.EmptyAddress, c.State.GetNonce(from), value, c.GasLimit, c.GasPrice, input))
+	wasm.Inject(&w.Context, c.State, w)
+
+	txHash := crypto.Keccak256Hash([]byte(fmt.Sprintf("tx-%d-%d", c.Height, c.txIndex)))
+	c.State.Prepare(txHash, c.BlockHash(c.Height), c.txIndex)
+	c.txIndex++
+
+	snapshot := c.State.Snapshot()
+	ret, leftOverGas, err := run(w, c.GasLimit, value)
+	res := &WasmResult{
+		Return:  ret,
+		GasUsed: c.GasLimit - leftOverGas,
+	}
+	if err != nil {
+		c.State.RevertToSnapshot(snapshot)
+		return res, err
+	}
+	res.Logs = c.State.GetLogs(txHash)
+	res.Transfers = w.GetOTxs()
+	return res, nil
+}
+
+// WasmContract is a contract deployed on the WasmChain.
+type WasmContract struct {
+	Address common.Address
+	ABI     *wasmabi.ABI
+
+	chain *WasmChain
+}
+
+// Pack packs the input calling the action with args, they are type checked by
+// the ABI of the contract, or encoded as JSON if the contract has no ABI.
+func (c *WasmContract) Pack(action string, args ...interface{}) ([]byte, error) {
+	// the ABI may not describe the Init action of the contract
+	if c.ABI != nil && (action != "Init" || hasAction(c.ABI, action)) {
+		return c.ABI.Pack(action, args...)
+	}
+	raws := make([]json.RawMessage, len(args))
+	for i, arg := range args {
+		raw, err := json.Marshal(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %v", i, err)
+		}
+		raws[i] = raw
+	}
+	return wasmabi.PackRaw(action, raws)
+}
+
+func hasAction(abi *wasmabi.ABI, action string) bool {
+	_, ok := abi.Actions[action]
+	return ok
+}
+
+// Call sends the transaction calling the action with args.
+func (c *WasmContract) Call(from common.Address, value *big.Int, action string, args ...interface{}) (*WasmResult, error) {
+	input, err := c.Pack(action, args...)
+	if err != nil {
+		return nil, err
+	}
+	return c.chain.Call(from, c.Address, value, input)
+}
+
+// Storage returns the value stored by TC_StorageSetString or TC_StorageSetBytes
+// under key.
+func (c *WasmContract) Storage(key string) []byte {
+	return c.PureStorage([]byte(key))
+}
+
+// PureStorage returns the value stored by TC_StoragePureSetString or
+// TC_StoragePureSetBytes under key.
+func (c *WasmContract) PureStorage(key []byte) []byte {
+	return c.chain.State.GetState(c.Address, crypto.Keccak256Hash(key))
+}
+
+// Issued returns the tokens issued by the contract and held by addr.
+func (c *WasmContract) Issued(addr common.Address) *big.Int {
+	return c.chain.TokenBalance(addr, c.Address)
+}
+
+// WasmEvent is an event log decoded by the ABI.
+type WasmEvent struct {
+	Name string
+	Args []interface{}
+	Log  *types.Log
+}
+
+// Events decodes the logs of the contract in res by its ABI, the logs of other
+// contracts and the logs of unknown events are skipped.
+func (c *WasmContract) Events(res *WasmResult) ([]*WasmEvent, error) {
+	if c.ABI == nil {
+		return nil, fmt.Errorf("contract %s has no abi", c.Address.String())
+	}
+	var events []*WasmEvent
+	for _, l := range res.Logs {
+		if l.Address != c.Address || len(l.Topics) == 0 {
+			continue
+		}
+		event := c.ABI.EventByTopic(l.Topics[0])
+		if event == nil {
+			continue
+		}
+		args, err := c.ABI.UnpackEvent(event.Name, l.Data)
+		if err != nil {
+			return nil, fmt.Errorf("event %s: %v", event.Name, err)
+		}
+		events = append(events, &WasmEvent{Name: event.Name, Args: args, Log: l})
+	}
+	return events, nil
+}
@@ -0,0 +1,138 @@
+package runtime
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/accounts/wasmabi"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+)
+
+func TestWasmContractPack(t *testing.T) {
+	c := NewWasmChain().At(common.BytesToAddress([]byte{1}), nil)
+	input, err := c.Pack("set", "key", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `set|{"0":"key","1":10}`; string(input) != want {
+		t.Errorf("input mismatch: have %s, want %s", input, want)
+	}
+
+	abi, err := wasmabi.JSON(strings.NewReader(`{"actions": [{"name": "set", "inputs": [{"name": "key", "type": "string"}]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.ABI = &abi
+	if _, err := c.Pack("set", 10); err == nil {
+		t.Error("expected type error packing int as string")
+	}
+	if input, err = c.Pack("Init", 1); err != nil || string(input) != `Init|{"0":1}` {
+		t.Errorf("Init not described by the abi should be packed as json: %s %v", input, err)
+	}
+}
+
+const harnessABI = `{
+	"actions": [{"name": "pay", "inputs": []}],
+	"events": [{"name": "Paid", "inputs": [{"name": "amount", "type": "bint"}]}]
+}`
+
+func TestWasmChain(t *testing.T) {
+	code, err := ioutil.ReadFile("testdata/harness.wasm")
+	if err != nil {
+		t.Fatalf("read wasm code fail: %v", err)
+	}
+	abi, err := wasmabi.JSON(strings.NewReader(harnessABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := NewWasmChain()
+	sender := common.BytesToAddress([]byte{114})
+	recipient := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	chain.Fund(sender, big.NewInt(1000000))
+
+	c, _, err := chain.Deploy(sender, code, &abi, nil)
+	if err != nil {
+		t.Fatalf("deploy fail: %v", err)
+	}
+	if !bytes.Equal(chain.State.GetCode(c.Address), code) {
+		t.Error("code not stored")
+	}
+	if v := c.Storage("inited"); string(v) != "1" {
+		t.Errorf("init storage mismatch: have %q, want %q", v, "1")
+	}
+
+	chain.Advance(1, 5)
+	if chain.Height != 2 {
+		t.Errorf("height mismatch: have %d, want 2", chain.Height)
+	}
+
+	// the contract can not transfer without a balance, the call is reverted
+	if _, err := c.Call(sender, nil, "pay"); err == nil {
+		t.Fatal("expected transfer error calling without value")
+	}
+	if v := c.Storage("counter"); len(v) != 0 {
+		t.Errorf("storage of the failed call not reverted: %q", v)
+	}
+	if n := c.Issued(c.Address); n.Sign() != 0 {
+		t.Errorf("tokens of the failed call not reverted: %v", n)
+	}
+
+	res, err := c.Call(sender, big.NewInt(1000), "pay")
+	if err != nil {
+		t.Fatalf("call fail: %v", err)
+	}
+	if string(res.Return) != "ok" {
+		t.Errorf("return mismatch: have %q, want %q", res.Return, "ok")
+	}
+
+	// storage
+	if v := c.Storage("counter"); string(v) != "1" {
+		t.Errorf("storage mismatch: have %q, want %q", v, "1")
+	}
+
+	// TCLog events
+	if len(res.Logs) != 1 {
+		t.Fatalf("log count mismatch: have %d, want 1", len(res.Logs))
+	}
+	if l := res.Logs[0]; l.BlockNumber != chain.Height || l.BlockTime != chain.Time {
+		t.Errorf("log block mismatch: have %d/%d, want %d/%d", l.BlockNumber, l.BlockTime, chain.Height, chain.Time)
+	}
+	events, err := c.Events(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Name != "Paid" {
+		t.Fatalf("events mismatch: %v", events)
+	}
+	if amount, ok := events[0].Args[0].(*big.Int); !ok || amount.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("event amount mismatch: have %v, want 100", events[0].Args[0])
+	}
+
+	// transfers
+	if b := chain.Balance(recipient); b.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("recipient balance mismatch: have %v, want 100", b)
+	}
+	if b := chain.Balance(c.Address); b.Cmp(big.NewInt(900)) != 0 {
+		t.Errorf("contract balance mismatch: have %v, want 900", b)
+	}
+	var transferred bool
+	for _, br := range res.Transfers {
+		if br.From == c.Address && br.To == recipient && br.Amount.Cmp(big.NewInt(100)) == 0 {
+			transferred = true
+		}
+	}
+	if !transferred {
+		t.Errorf("transfer not recorded: %v", res.Transfers)
+	}
+
+	// issued tokens
+	if n := res.Issued(); n.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("issued mismatch: have %v, want 1000", n)
+	}
+	if n := c.Issued(c.Address); n.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("token balance mismatch: have %v, want 1000", n)
+	}
+}
@@ -61,6 +61,8 @@ func init() {
 	env.RegisterFunc("TC_TokenAddress", &TCTokenAddress{})
 	env.RegisterFunc("TC_GetMsgValue", &TCGetMsgValue{})
 	env.RegisterFunc("TC_GetMsgTokenValue", &TCGetMsgTokenValue{})
+
+	profileHostFuncs(env)
 }
 
 func Inject(context *Context, stateDB types.StateDB, w *WASM) {
@@ -77,7 +79,7 @@ func (t *TCNotify) Call(index int64, ops interface{}, args []uint64) (uint64, er
 }
 func (t *TCNotify) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
 	eng := ops.(*vm.Engine)
-	return vm.GasNotify(eng, index, args)
+	return gasNotify(eng, index, args)
 }
 
 //c: void TC_Notify(char* eventID, char* data)
@@ -118,7 +120,7 @@ func (t *TCStorageSetBytes) Call(index int64, ops interface{}, args []uint64) (u
 }
 func (t *TCStorageSetBytes) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
 	eng := ops.(*vm.Engine)
-	return vm.GasStorageSetBytes(eng, index, args)
+	return gasStorageSetBytes(eng, index, args)
 }
 
 //c: void TC_StorageSetBytes(const char* key, const uint8_t* val, uint32_t size);
@@ -147,7 +149,7 @@ func (t *TCStoragePureSetString) Call(index int64, ops interface{}, args []uint6
 }
 func (t *TCStoragePureSetString) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
 	eng := ops.(*vm.Engine)
-	return vm.GasStoragePureSetString(eng, index, args)
+	return gasStoragePureSetString(eng, index, args)
 }
 
 //c:void TC_StoragePureSetString(const uint8_t* key, uint32_t size1, const char* val);
@@ -176,7 +178,7 @@ func (t *TCStoragePureSetBytes) Call(index int64, ops interface{}, args []uint64
 }
 func (t *TCStoragePureSetBytes) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
 	eng := ops.(*vm.Engine)
-	return vm.GasStoragePureSetBytes(eng, index, args)
+	return gasStoragePureSetBytes(eng, index, args)
 }
 
 //c: void TC_StoragePureSetBytes(const uint8_t* key, uint32_t size1, const uint8_t* val, uint32_t size2);
@@ -204,8 +206,7 @@ func (t *TCStoragePureGet) Call(index int64, ops interface{}, args []uint64) (ui
 	return tcStoragePureGet(eng, index, args)
 }
 func (t *TCStoragePureGet) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasStoragePureGet(eng, index, args)
+	return hostGas("TC_StoragePureGetString", 0, 0)
 }
 
 //char* TC_StoragePureGetString(const uint8_t* key, uint32_t size);
@@ -235,8 +236,7 @@ func (t *TCStorageGet) Call(index int64, ops interface{}, args []uint64) (uint64
 	return tcStorageGet(eng, index, args)
 }
 func (t *TCStorageGet) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasStorageGet(eng, index, args)
+	return hostGas("TC_StorageGetString", 0, 0)
 }
 
 // c: char * TC_StorageGet(char *key) removed
@@ -268,8 +268,7 @@ func (t *TCContractStoragePureGet) Call(index int64, ops interface{}, args []uin
 }
 
 func (t *TCContractStoragePureGet) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasContractStoragePureGet(eng, index, args)
+	return hostGas("TC_ContractStoragePureGet", 0, 0)
 }
 
 // c: char * TC_ContractStoragePureGet(address contract, uint8_t* key, uint32_t size)
@@ -302,8 +301,7 @@ func (t *TCContractStorageGet) Call(index int64, ops interface{}, args []uint64)
 	return tcContractStorageGet(eng, index, args)
 }
 func (t *TCContractStorageGet) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasContractStorageGet(eng, index, args)
+	return hostGas("TC_ContractStorageGet", 0, 0)
 }
 
 // c: char * TC_ContractStorageGet(address contract, char *key)
@@ -339,7 +337,7 @@ func (t *TCStorageSet) Call(index int64, ops interface{}, args []uint64) (uint64
 
 func (t *TCStorageSet) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
 	eng := ops.(*vm.Engine)
-	return vm.GasStorageSet(eng, index, args)
+	return gasStorageSet(eng, index, args)
 }
 
 //c: void TC_StorageSetString(const char* key, const char* val);
@@ -367,8 +365,7 @@ func (t *TCStorageDel) Call(index int64, ops interface{}, args []uint64) (uint64
 	return tcStorageDel(eng, index, args)
 }
 func (t *TCStorageDel) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasStorageDel(eng, index, args)
+	return hostGas("TC_StorageDel", 0, 0)
 }
 
 // c: void TC_StorageDel(char *key)
@@ -393,8 +390,7 @@ func (t *TCBlockHash) Call(index int64, ops interface{}, args []uint64) (uint64,
 	return tcBlockHash(eng, index, args)
 }
 func (t *TCBlockHash) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasBlockHash(eng, index, args)
+	return hostGas("TC_BlockHash", 0, 0)
 }
 
 //char *TC_blockhash(long long blockNumber)
@@ -423,8 +419,7 @@ func (t *TCGetCoinbase) Call(index int64, ops interface{}, args []uint64) (uint6
 	return tcGetCoinbase(eng, index, args)
 }
 func (t *TCGetCoinbase) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasGetCoinbase(eng, index, args)
+	return hostGas("TC_GetCoinbase", 0, 0)
 }
 
 // char *TC_get_coinbase()
@@ -452,8 +447,7 @@ func (t *TCGetGasLimit) Call(index int64, ops interface{}, args []uint64) (uint6
 	return tcGetGasLimit(eng, index, args)
 }
 func (t *TCGetGasLimit) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasGetGasLimit(eng, index, args)
+	return hostGas("TC_GetGasLimit", 0, 0)
 }
 
 // long long TC_get_gaslimit()
@@ -473,8 +467,7 @@ func (t *TCGetNumber) Call(index int64, ops interface{}, args []uint64) (uint64,
 	return tcGetNumber(eng, index, args)
 }
 func (t *TCGetNumber) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasGetNumber(eng, index, args)
+	return hostGas("TC_GetNumber", 0, 0)
 }
 
 // long long TC_get_number()
@@ -492,8 +485,7 @@ func (t *TCGetTimestamp) Call(index int64, ops interface{}, args []uint64) (uint
 	return tcGetTimestamp(eng, index, args)
 }
 func (t *TCGetTimestamp) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasGetTimestamp(eng, index, args)
+	return hostGas("TC_Now", 0, 0)
 }
 
 // long long TC_get_timestamp()
@@ -511,8 +503,7 @@ func (t *TCNow) Call(index int64, ops interface{}, args []uint64) (uint64, error
 	return tcNow(eng, index, args)
 }
 func (t *TCNow) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasNow(eng, index, args)
+	return hostGas("TC_Now", 0, 0)
 }
 
 // long long TC_now()
@@ -530,8 +521,7 @@ func (t *TCGetTxGasPrice) Call(index int64, ops interface{}, args []uint64) (uin
 	return tcGetTxGasPrice(eng, index, args)
 }
 func (t *TCGetTxGasPrice) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasGetTxGasPrice(eng, index, args)
+	return hostGas("TC_GetTxGasPrice", 0, 0)
 }
 
 // long long TC_get_tx_gasprice()
@@ -549,8 +539,7 @@ func (t *TCGetTxOrigin) Call(index int64, ops interface{}, args []uint64) (uint6
 	return tcGetTxOrigin(eng, index, args)
 }
 func (t *TCGetTxOrigin) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasGetTxOrigin(eng, index, args)
+	return hostGas("TC_GetTxOrigin", 0, 0)
 }
 
 // char *TC_get_tx_origin()
@@ -576,8 +565,7 @@ func (t *TCGetBalance) Call(index int64, ops interface{}, args []uint64) (uint64
 	return tcGetBalance(eng, index, args)
 }
 func (t *TCGetBalance) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasGetBalance(eng, index, args)
+	return hostGas("TC_GetBalance", 0, 0)
 }
 
 //char* TC_GetBalance(char *address)
@@ -601,8 +589,7 @@ func (t *TCTransfer) Call(index int64, ops interface{}, args []uint64) (uint64,
 	return tcTransfer(eng, index, args)
 }
 func (t *TCTransfer) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasTransfer(eng, index, args)
+	return hostGas("TC_Transfer", 0, 0)
 }
 
 //void TC_Transfer(char *address, char* amount)
@@ -649,8 +636,7 @@ func (t *TCTransferToken) Call(index int64, ops interface{}, args []uint64) (uin
 	return tcTransferToken(eng, index, args)
 }
 func (t *TCTransferToken) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasTransferToken(eng, index, args)
+	return hostGas("TC_TransferToken", 0, 0)
 }
 
 //void TC_TransferToken(char *address, char* tokenAddress, char* amount)
@@ -715,8 +701,7 @@ func (t *TCSelfDestruct) Call(index int64, ops interface{}, args []uint64) (uint
 	return tcSelfDestruct(eng, index, args)
 }
 func (t *TCSelfDestruct) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasSelfDestruct(eng, index, args)
+	return hostGas("TC_SelfDestruct", 0, 0)
 }
 
 //char *TC_SelfDestruct(char* recipient)
@@ -751,8 +736,7 @@ func (t *TCLog0) Call(index int64, ops interface{}, args []uint64) (uint64, erro
 }
 func (t *TCLog0) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
 	eng := ops.(*vm.Engine)
-	gasFunc := vm.MakeGasLog(0)
-	return gasFunc(eng, index, args)
+	return gasLog(eng, "TC_Log0", args)
 }
 
 //void TC_Log0(char* data)
@@ -785,8 +769,7 @@ func (t *TCLog1) Call(index int64, ops interface{}, args []uint64) (uint64, erro
 }
 func (t *TCLog1) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
 	eng := ops.(*vm.Engine)
-	gasFunc := vm.MakeGasLog(1)
-	return gasFunc(eng, index, args)
+	return gasLog(eng, "TC_Log1", args)
 }
 
 //void TC_Log1(char* data, char* topic)
@@ -827,8 +810,7 @@ func (t *TCLog2) Call(index int64, ops interface{}, args []uint64) (uint64, erro
 }
 func (t *TCLog2) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
 	eng := ops.(*vm.Engine)
-	gasFunc := vm.MakeGasLog(2)
-	return gasFunc(eng, index, args)
+	return gasLog(eng, "TC_Log2", args)
 }
 
 //void TC_Log2(char* data, char* topic1, char* topic2)
@@ -869,8 +851,7 @@ func (t *TCLog3) Call(index int64, ops interface{}, args []uint64) (uint64, erro
 }
 func (t *TCLog3) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
 	eng := ops.(*vm.Engine)
-	gasFunc := vm.MakeGasLog(3)
-	return gasFunc(eng, index, args)
+	return gasLog(eng, "TC_Log3", args)
 }
 
 //void TC_Log3(char* data, char* topic1, char* topic2, char* topic3)
@@ -911,8 +892,7 @@ func (t *TCLog4) Call(index int64, ops interface{}, args []uint64) (uint64, erro
 }
 func (t *TCLog4) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
 	eng := ops.(*vm.Engine)
-	gasFunc := vm.MakeGasLog(4)
-	return gasFunc(eng, index, args)
+	return gasLog(eng, "TC_Log4", args)
 }
 
 //void TC_Log4(char* data, char* topic1, char* topic2, char* topic3, char* topic4)
@@ -952,8 +932,7 @@ func (t *TCIssue) Call(index int64, ops interface{}, args []uint64) (uint64, err
 	return tcIssue(eng, index, args)
 }
 func (t *TCIssue) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasIssue(eng, index, args)
+	return hostGas("TC_Issue", 0, 0)
 }
 
 //void TC_Issue(char* amount);
@@ -990,8 +969,7 @@ func (t *TCTokenBalance) Call(index int64, ops interface{}, args []uint64) (uint
 	return tcTokenBalance(eng, index, args)
 }
 func (t *TCTokenBalance) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasTokenBalance(eng, index, args)
+	return hostGas("TC_TokenBalance", 0, 0)
 }
 
 //char* TC_TokenBalance(char* addr, char* token);
@@ -1025,8 +1003,7 @@ func (t *TCTokenAddress) Call(index int64, ops interface{}, args []uint64) (uint
 	return tcTokenAddress(eng, index, args)
 }
 func (t *TCTokenAddress) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasTokenAddress(eng, index, args)
+	return hostGas("TC_TokenAddress", 0, 0)
 }
 
 //char* TC_TokenAddress();
@@ -1078,15 +1055,7 @@ func gasGetMsgValue(eng *vm.Engine, index int64, args []uint64) (uint64, error)
 	if ctx.Token == common.EmptyAddress {
 		valLen = len(eng.Contract.Value().String())
 	}
-	gas := vm.GasExtStep
-	wordGas, overflow := vm.SafeMul(vm.ToWordSize(uint64(valLen)), vm.CopyGas)
-	if overflow {
-		return 0, vm.ErrGasOverflow
-	}
-	if gas, overflow = vm.SafeAdd(gas, wordGas); overflow {
-		return 0, vm.ErrGasOverflow
-	}
-	return gas, nil
+	return hostGas("TC_GetMsgValue", uint64(valLen), 0)
 }
 
 type TCGetMsgTokenValue struct{}
@@ -1128,15 +1097,7 @@ func gasGetMsgTokenValue(eng *vm.Engine, index int64, args []uint64) (uint64, er
 	if ctx.Token == common.EmptyAddress {
 		valLen = 1
 	}
-	gas := vm.GasExtStep
-	wordGas, overflow := vm.SafeMul(vm.ToWordSize(uint64(valLen)), vm.CopyGas)
-	if overflow {
-		return 0, vm.ErrGasOverflow
-	}
-	if gas, overflow = vm.SafeAdd(gas, wordGas); overflow {
-		return 0, vm.ErrGasOverflow
-	}
-	return gas, nil
+	return hostGas("TC_GetMsgTokenValue", uint64(valLen), 0)
 }
 
 type TCCheckSign struct{}
@@ -1146,8 +1107,7 @@ func (t *TCCheckSign) Call(index int64, ops interface{}, args []uint64) (uint64,
 	return tcCheckSign(eng, index, args)
 }
 func (t *TCCheckSign) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasCheckSign(eng, index, args)
+	return hostGas("TC_CheckSign", 0, 0)
 }
 
 //c: int TC_CheckSig(char * pubkey,char * data,char * sig)
@@ -1196,8 +1156,7 @@ func (t *TCEcrecover) Call(index int64, ops interface{}, args []uint64) (uint64,
 	return tcEcrecover(eng, index, args)
 }
 func (t *TCEcrecover) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
-	eng := ops.(*vm.Engine)
-	return vm.GasEcrecover(eng, index, args)
+	return hostGas("TC_Ecrecover", 0, 0)
 }
 
 //char *TC_Ecrecover(char* hash, char* v, char* r, char* s)
@@ -0,0 +1,102 @@
+package wasm
+
+import (
+	"fmt"
+
+	cfg "github.com/lianxiangcloud/linkchain/config"
+	"github.com/xunleichain/tc-wasm/vm"
+)
+
+// hostGas returns the gas of the host function in the wasm gas schedule active
+// at the height of the running block. words is the size of the data charged by
+// word, bytes is the size of the data charged by byte.
+func hostGas(name string, words, bytes uint64) (uint64, error) {
+	cost, ok := cfg.GetChainConfig().WasmGasCost(chainRules().Height, name)
+	if !ok {
+		return 0, fmt.Errorf("host function %s not priced", name)
+	}
+	gas, overflow := cost.Gas(words, bytes)
+	if overflow {
+		return 0, vm.ErrGasOverflow
+	}
+	return gas, nil
+}
+
+// chainRules returns the rules of the running block, the chain itself may run
+// the contracts without a WASM.
+func chainRules() cfg.Rules {
+	if mWasm != nil {
+		return mWasm.ChainRules()
+	}
+	if ctx != nil {
+		return cfg.RulesAt(ctx.BlockNumber)
+	}
+	return cfg.RulesAt(nil)
+}
+
+func strlen(eng *vm.Engine, ptr uint64) (uint64, error) {
+	app, _ := eng.RunningAppFrame()
+	n, err := app.VM.VMemory().Strlen(ptr)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(n), nil
+}
+
+// c: void TC_Notify(char* eventID, char* data)
+func gasNotify(eng *vm.Engine, index int64, args []uint64) (uint64, error) {
+	eventIDLen, err := strlen(eng, args[0])
+	if err != nil {
+		return 0, err
+	}
+	dataLen, err := strlen(eng, args[1])
+	if err != nil {
+		return 0, err
+	}
+	return hostGas("TC_Notify", eventIDLen, dataLen)
+}
+
+// c: void TC_StorageSetString(const char* key, const char* val)
+func gasStorageSet(eng *vm.Engine, index int64, args []uint64) (uint64, error) {
+	keyLen, err := strlen(eng, args[0])
+	if err != nil {
+		return 0, err
+	}
+	valLen, err := strlen(eng, args[1])
+	if err != nil {
+		return 0, err
+	}
+	return hostGas("TC_StorageSetString", keyLen+valLen, 0)
+}
+
+// c: void TC_StorageSetBytes(const char* key, const uint8_t* val, uint32_t size)
+func gasStorageSetBytes(eng *vm.Engine, index int64, args []uint64) (uint64, error) {
+	keyLen, err := strlen(eng, args[0])
+	if err != nil {
+		return 0, err
+	}
+	return hostGas("TC_StorageSetBytes", keyLen+args[2], 0)
+}
+
+// c: void TC_StoragePureSetString(const uint8_t* key, uint32_t size, const char* val)
+func gasStoragePureSetString(eng *vm.Engine, index int64, args []uint64) (uint64, error) {
+	valLen, err := strlen(eng, args[2])
+	if err != nil {
+		return 0, err
+	}
+	return hostGas("TC_StoragePureSetString", args[1]+valLen, 0)
+}
+
+// c: void TC_StoragePureSetBytes(const uint8_t* key, uint32_t keySize, const uint8_t* val, uint32_t valSize)
+func gasStoragePureSetBytes(eng *vm.Engine, index int64, args []uint64) (uint64, error) {
+	return hostGas("TC_StoragePureSetBytes", args[1]+args[3], 0)
+}
+
+// c: void TC_Log0(char* data), TC_Log1(char* data, char* topic)...
+func gasLog(eng *vm.Engine, name string, args []uint64) (uint64, error) {
+	dataLen, err := strlen(eng, args[0])
+	if err != nil {
+		return 0, err
+	}
+	return hostGas(name, 0, dataLen)
+}
@@ -0,0 +1,288 @@
+package wasm
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	wagon "github.com/go-interpreter/wagon/wasm"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/xunleichain/tc-wasm/vm"
+)
+
+const (
+	// DefaultProfileCalls is the number of the latest contract calls a profiler keeps.
+	DefaultProfileCalls = 256
+	// MaxProfileCalls caps the number of the latest contract calls a profiler keeps.
+	MaxProfileCalls = 4096
+	// DefaultUnderPricedRatio is the ratio of the wall time per gas of a host
+	// function to the median of all the host functions, above which the host
+	// function is reported as under-priced.
+	DefaultUnderPricedRatio = 2.0
+)
+
+var (
+	errProfileRunning    = errors.New("wasm profile already running")
+	errProfileNotRunning = errors.New("wasm profile not running")
+)
+
+var (
+	profileMu sync.Mutex
+	profiler  atomic.Value // *Profiler
+)
+
+func init() {
+	profiler.Store((*Profiler)(nil))
+}
+
+// StartProfile starts profiling the host functions of the contract calls, the
+// profiler keeps the latest maxCalls contract calls, at most MaxProfileCalls.
+func StartProfile(maxCalls int) (*Profiler, error) {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	if ActiveProfiler() != nil {
+		return nil, errProfileRunning
+	}
+	if maxCalls <= 0 {
+		maxCalls = DefaultProfileCalls
+	}
+	if maxCalls > MaxProfileCalls {
+		maxCalls = MaxProfileCalls
+	}
+	p := &Profiler{
+		maxCalls: maxCalls,
+		start:    time.Now(),
+		funcs:    make(map[string]*HostFuncStats),
+		running:  make(map[*vm.Engine]*CallProfile),
+	}
+	profiler.Store(p)
+	return p, nil
+}
+
+// StopProfile stops profiling and returns the stopped profiler.
+func StopProfile() (*Profiler, error) {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	p := ActiveProfiler()
+	if p == nil {
+		return nil, errProfileNotRunning
+	}
+	profiler.Store((*Profiler)(nil))
+	return p, nil
+}
+
+// ActiveProfiler returns the running profiler, or nil if not profiling.
+func ActiveProfiler() *Profiler {
+	return profiler.Load().(*Profiler)
+}
+
+// HostFuncStats is the usage of a host function.
+type HostFuncStats struct {
+	Name  string        `json:"name"`
+	Calls uint64        `json:"calls"`
+	Gas   uint64        `json:"gas"`  // gas charged
+	Time  time.Duration `json:"time"` // wall time in nanoseconds
+}
+
+// CallProfile is the host function usage of a contract call.
+type CallProfile struct {
+	Contract common.Address   `json:"contract"`
+	Height   uint64           `json:"height"`
+	GasUsed  uint64           `json:"gasUsed"` // gas used by the wasm engine
+	Time     time.Duration    `json:"time"`    // wall time in nanoseconds
+	Funcs    []*HostFuncStats `json:"funcs"`
+
+	start time.Time
+	funcs map[string]*HostFuncStats
+}
+
+// Profiler records the calls, the gas charged and the wall time of the host
+// functions, per contract call and in total since it started.
+type Profiler struct {
+	mu       sync.Mutex
+	maxCalls int
+	start    time.Time
+	calls    []*CallProfile // the latest contract calls, oldest first
+	total    uint64         // number of contract calls
+	funcs    map[string]*HostFuncStats
+	running  map[*vm.Engine]*CallProfile
+}
+
+func (p *Profiler) begin(eng *vm.Engine, contract common.Address, height uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running[eng] = &CallProfile{
+		Contract: contract,
+		Height:   height,
+		start:    time.Now(),
+		funcs:    make(map[string]*HostFuncStats),
+	}
+}
+
+func (p *Profiler) end(eng *vm.Engine) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	call, ok := p.running[eng]
+	if !ok {
+		return
+	}
+	delete(p.running, eng)
+
+	call.GasUsed = eng.GasUsed()
+	call.Time = time.Since(call.start)
+	call.Funcs = sortedStats(call.funcs)
+	p.total++
+	if len(p.calls) == p.maxCalls {
+		p.calls = p.calls[1:]
+	}
+	p.calls = append(p.calls, call)
+}
+
+// record adds the usage of the host function to the contract call run by eng,
+// host functions called out of a profiled contract call are ignored.
+func (p *Profiler) record(eng *vm.Engine, name string, calls, gas uint64, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	call, ok := p.running[eng]
+	if !ok {
+		return
+	}
+	for _, funcs := range []map[string]*HostFuncStats{call.funcs, p.funcs} {
+		stats, ok := funcs[name]
+		if !ok {
+			stats = &HostFuncStats{Name: name}
+			funcs[name] = stats
+		}
+		stats.Calls += calls
+		stats.Gas += gas
+		stats.Time += d
+	}
+}
+
+// HostFuncReport is the pricing of a host function.
+type HostFuncReport struct {
+	HostFuncStats
+	NsPerGas    float64 `json:"nsPerGas"`
+	Ratio       float64 `json:"ratio"` // NsPerGas to the median of the host functions, 0 if no gas charged
+	UnderPriced bool    `json:"underPriced"`
+}
+
+// ProfileReport reports the host functions of the profiled contract calls, the
+// under-priced host functions first.
+type ProfileReport struct {
+	Start    time.Time         `json:"start"`
+	Calls    uint64            `json:"calls"`    // number of the profiled contract calls
+	NsPerGas float64           `json:"nsPerGas"` // median of the host functions
+	Funcs    []*HostFuncReport `json:"funcs"`
+	Latest   []*CallProfile    `json:"latest"`
+}
+
+// Report reports the host functions whose wall time per gas is more than ratio
+// times the median of the host functions as under-priced, or the ones charging
+// no gas at all.
+func (p *Profiler) Report(ratio float64) *ProfileReport {
+	if ratio <= 0 {
+		ratio = DefaultUnderPricedRatio
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	report := &ProfileReport{
+		Start:  p.start,
+		Calls:  p.total,
+		Latest: append([]*CallProfile(nil), p.calls...),
+	}
+
+	rates := make([]float64, 0, len(p.funcs))
+	for _, stats := range sortedStats(p.funcs) {
+		f := &HostFuncReport{HostFuncStats: *stats}
+		if stats.Gas > 0 {
+			f.NsPerGas = float64(stats.Time) / float64(stats.Gas)
+			rates = append(rates, f.NsPerGas)
+		}
+		report.Funcs = append(report.Funcs, f)
+	}
+	report.NsPerGas = median(rates)
+
+	for _, f := range report.Funcs {
+		switch {
+		case f.Gas == 0:
+			f.UnderPriced = f.Time > 0
+		case report.NsPerGas > 0:
+			f.Ratio = f.NsPerGas / report.NsPerGas
+			f.UnderPriced = f.Ratio > ratio
+		}
+	}
+	sort.SliceStable(report.Funcs, func(i, j int) bool {
+		fi, fj := report.Funcs[i], report.Funcs[j]
+		if fi.UnderPriced != fj.UnderPriced {
+			return fi.UnderPriced
+		}
+		return fi.Ratio > fj.Ratio
+	})
+	return report
+}
+
+func sortedStats(funcs map[string]*HostFuncStats) []*HostFuncStats {
+	list := make([]*HostFuncStats, 0, len(funcs))
+	for _, stats := range funcs {
+		list = append(list, stats)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// profiledFunc wraps a host function to record its usage while profiling.
+type profiledFunc struct {
+	name string
+	fn   vm.EnvFunc
+}
+
+func (f *profiledFunc) Call(index int64, ops interface{}, args []uint64) (uint64, error) {
+	p := ActiveProfiler()
+	if p == nil {
+		return f.fn.Call(index, ops, args)
+	}
+	start := time.Now()
+	ret, err := f.fn.Call(index, ops, args)
+	if eng, ok := ops.(*vm.Engine); ok {
+		p.record(eng, f.name, 1, 0, time.Since(start))
+	}
+	return ret, err
+}
+
+func (f *profiledFunc) Gas(index int64, ops interface{}, args []uint64) (uint64, error) {
+	gas, err := f.fn.Gas(index, ops, args)
+	if p := ActiveProfiler(); p != nil && err == nil {
+		if eng, ok := ops.(*vm.Engine); ok {
+			p.record(eng, f.name, 0, gas, 0)
+		}
+	}
+	return gas, err
+}
+
+// profileHostFuncs wraps all the host functions of env, including the ones of
+// tc-wasm, to be profiled.
+func profileHostFuncs(env *vm.EnvTable) {
+	for _, name := range env.Exports.Names {
+		if env.Exports.Entries[name].Kind != wagon.ExternalFunction {
+			continue
+		}
+		env.RegisterFunc(name, &profiledFunc{name: name, fn: env.GetFuncByName(name)})
+	}
+}
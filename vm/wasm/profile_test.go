@@ -0,0 +1,21 @@
+package wasm
+
+import "testing"
+
+func TestStartProfileMaxCalls(t *testing.T) {
+	for _, test := range []struct{ maxCalls, want int }{
+		{0, DefaultProfileCalls},
+		{MaxProfileCalls + 1, MaxProfileCalls},
+	} {
+		p, err := StartProfile(test.maxCalls)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := StopProfile(); err != nil {
+			t.Fatal(err)
+		}
+		if p.maxCalls != test.want {
+			t.Errorf("StartProfile(%d) keeps %d calls, want %d", test.maxCalls, p.maxCalls, test.want)
+		}
+	}
+}
@@ -156,6 +156,10 @@ func run(wasm *WASM, c types.Contract, input []byte) ([]byte, uint64, error) {
 	innerContract.CreateCall = contract.CreateCall
 	eng := vm.NewEngine(innerContract, localMaxGas, wasm.StateDB, log.New("mod", "wasm"))
 	eng.SetTrace(false)
+	if p := ActiveProfiler(); p != nil {
+		p.begin(eng, contract.Address(), wasm.chainRules.Height)
+		defer p.end(eng)
+	}
 	addr := contract.CodeAddr
 	app, err := eng.NewApp(addr.String(), nil, false)
 	if err != nil {
@@ -218,6 +222,8 @@ type WASM struct {
 	// available gas is calculated in gasCall* according to the 63/64 rule and later
 	// applied in opCall*.
 	callGasTemp uint64
+	// chainRules are the protocol rules of the block being processed
+	chainRules cfg.Rules
 
 	env *vm.EnvTable
 	eng *vm.Engine
@@ -233,10 +239,11 @@ func NewWASM(c types.Context, statedb types.StateDB, vmc types.VmConfig) *WASM {
 	vmConfig := Config{}
 
 	return &WASM{
-		Context:  ctx,
-		StateDB:  statedb,
-		vmConfig: vmConfig,
-		otxs:     make([]types.BalanceRecord, 0),
+		Context:    ctx,
+		StateDB:    statedb,
+		vmConfig:   vmConfig,
+		chainRules: cfg.RulesAt(ctx.BlockNumber),
+		otxs:       make([]types.BalanceRecord, 0),
 	}
 }
 
@@ -639,6 +646,11 @@ func (wasm *WASM) GasRate() uint64 {
 	return wasm.WasmGasRate
 }
 
+// ChainRules returns the protocol rules the WASM runs with.
+func (wasm *WASM) ChainRules() cfg.Rules {
+	return wasm.chainRules
+}
+
 //StateDB
 func (wasm *WASM) GetStateDB() types.StateDB {
 	return wasm.StateDB
@@ -19,6 +19,7 @@ func AddNodeFlags(cmd *cobra.Command) {
 	// cmd.Flags().Int("kdf_rounds", config.BaseConfig.KdfRounds, "Number of rounds for the key derivation function")
 	cmd.Flags().Bool("detach", config.BaseConfig.Detach, "Run as daemon")
 	cmd.Flags().Int("max_concurrency", config.BaseConfig.MaxConcurrency, "Max number of threads to use for a parallel job")
+	cmd.Flags().Uint64("subaddress_lookahead", config.BaseConfig.SubaddressLookahead, "Number of subaddresses after the created ones looked for while scanning")
 	// cmd.Flags().String("pidfile", config.BaseConfig.Pidfile, "File path to write the daemon's PID to")
 	cmd.Flags().String("log_level", config.BaseConfig.LogLevel, "0-4 or categories")
 	cmd.Flags().String("home", config.BaseConfig.RootDir, "home")
@@ -23,8 +23,10 @@ type BaseConfig struct {
 	KdfRounds      int    `mapstructure:"kdf_rounds"`
 	Detach         bool   `mapstructure:"detach"`
 	MaxConcurrency int    `mapstructure:"max_concurrency"`
-	Pidfile        string `mapstructure:"pidfile"`
-	LogLevel       string `mapstructure:"log_level"`
+	// Number of subaddresses after the created ones looked for while scanning
+	SubaddressLookahead uint64 `mapstructure:"subaddress_lookahead"`
+	Pidfile             string `mapstructure:"pidfile"`
+	LogLevel            string `mapstructure:"log_level"`
 	// The root directory for all data.
 	// This should be set in viper so it can unmarshal into this struct
 	RootDir string `mapstructure:"home"`
@@ -42,15 +44,16 @@ type BaseConfig struct {
 // DefaultBaseConfig return default config
 func DefaultBaseConfig() BaseConfig {
 	return BaseConfig{
-		Password:       "",
-		KdfRounds:      1,
-		Detach:         false,
-		MaxConcurrency: 1,
-		LogLevel:       "debug",
-		DBBackend:      "leveldb",
-		DBPath:         defaultDataDir,
-		LogPath:        defaultLogDir,
-		KeyStorePath:   defaultKeyStoreDir,
+		Password:            "",
+		KdfRounds:           1,
+		Detach:              false,
+		MaxConcurrency:      1,
+		SubaddressLookahead: 50,
+		LogLevel:            "debug",
+		DBBackend:           "leveldb",
+		DBPath:              defaultDataDir,
+		LogPath:             defaultLogDir,
+		KeyStorePath:        defaultKeyStoreDir,
 	}
 }
 
@@ -10,6 +10,7 @@ import (
 	"github.com/lianxiangcloud/linkchain/accounts"
 	"github.com/lianxiangcloud/linkchain/accounts/keystore"
 	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/log"
 )
 
 const (
@@ -111,6 +112,31 @@ func (s *PrivateAccountAPI) UnlockAccount(addr common.Address, password string,
 	return err == nil, err
 }
 
+// ChangePassword change the password of the account, both the keystore file and the wallet db
+// are re-encrypted. The account must have been unlocked to be opened by the wallet.
+func (s *PrivateAccountAPI) ChangePassword(addr common.Address, password string, newPassword string) (bool, error) {
+	if len(newPassword) == 0 {
+		return false, fmt.Errorf("password is empty")
+	}
+	ks := fetchKeystore(s.am)
+	acc := accounts.Account{Address: addr}
+	cue, err := ks.GetCue(acc)
+	if err != nil {
+		return false, err
+	}
+	if err := s.wallet.ChangePassword(addr, password, newPassword); err != nil {
+		return false, err
+	}
+	if err := ks.Update(acc, password, newPassword, cue); err != nil {
+		// keep the wallet db with the same password as the keystore file
+		if e := s.wallet.ChangePassword(addr, newPassword, password); e != nil {
+			log.Error("ChangePassword restore wallet db password fail", "addr", addr, "err", e)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 // LockAccount will lock the account associated with the given address when it's unlocked.
 func (s *PrivateAccountAPI) LockAccount(addr common.Address) bool {
 	s.wallet.CloseWallet()
@@ -47,12 +47,21 @@ func (c *Context) SetLogger(logger log.Logger) {
 type Wallet interface {
 	CreateUTXOTransaction(from common.Address, nonce uint64, subaddrs []uint64, dests []types.DestEntry,
 		tokenID common.Address, refundAddr common.Address, extra []byte) ([]*types.UTXOTransaction, error)
+	CreateUTXOTransactionFromInputs(from common.Address, inputs []uint64, dests []types.DestEntry,
+		tokenID common.Address, refundAddr common.Address, extra []byte) ([]*types.UTXOTransaction, error)
+	ListUnspent(tokenID common.Address, subaddrs []uint64) ([]*wtypes.UnspentOutput, error)
+	FreezeOutputs(indices []uint64) error
+	ThawOutputs(indices []uint64) error
+	SweepAll(addr string, subaddrs []uint64, tokenID common.Address) ([]*types.UTXOTransaction, error)
+	SweepBelow(addr string, below *big.Int, subaddrs []uint64, tokenID common.Address) ([]*types.UTXOTransaction, error)
 	GetBalance(index uint64, token *common.Address) (*big.Int, error)
 	GetHeight() (localHeight uint64, remoteHeight uint64)
 	GetAddress(index uint64) (string, error)
 	Transfer(txs []string) (ret []wtypes.SendTxRet)
 	OpenWallet(walletfile string, password string) error
+	ChangePassword(addr common.Address, password string, newPassword string) error
 	CreateSubAccount(maxSub uint64) error
+	SetSubaddressLookahead(lookahead uint64) error
 	AutoRefreshBlockchain(autoRefresh bool) error
 	GetAccountInfo(tokenID *common.Address) (*wtypes.GetAccountInfoResult, error)
 	RescanBlockchain() error
@@ -64,6 +73,19 @@ type Wallet interface {
 	GetUTXOTx(hash common.Hash) (*types.UTXOTransaction, error)
 	SelectAddress(addr common.Address) error
 	SetRefreshBlockInterval(interval time.Duration) error
+	GetSpendProof(hash common.Hash, message string) (string, error)
+	CheckSpendProof(hash common.Hash, message string, signature string) (bool, error)
+	GetReserveProof(tokenID common.Address, amount *big.Int, message string) (string, error)
+	CheckReserveProof(addr string, message string, signature string) (*wtypes.CheckReserveProofRet, error)
+	PrepareMultisig() (string, error)
+	MakeMultisig(threshold uint64, infos []string) (*wtypes.MakeMultisigRet, error)
+	ExchangeMultisigKeys(infos []string) (*wtypes.MakeMultisigRet, error)
+	IsMultisig() (*wtypes.IsMultisigRet, error)
+	ExportMultisigInfo() (string, error)
+	ImportMultisigInfo(infos []string) (uint64, error)
+	TransferMultisig(dests []types.DestEntry, tokenID common.Address) (*wtypes.MultisigTxSetRet, error)
+	SignMultisig(txSet string) (*wtypes.MultisigTxSetRet, error)
+	SubmitMultisig(txSet string) (common.Hash, error)
 	// CheckTxKey(hash *common.Hash, txKey *lkctypes.Key, destAddr string) (*hexutil.Uint64, *hexutil.Big, error)
 	//
 	GetBlockTransactionCountByNumber(blockNr rpc.BlockNumber) (*hexutil.Uint, error)
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/lianxiangcloud/linkchain/libs/common"
@@ -44,26 +45,64 @@ func (s *PublicTransactionPoolAPI) signUTXOTransaction(ctx context.Context, args
 		return nil, fmt.Errorf("need more dests")
 	}
 
+	dests, err := toDestEntries(args.Dests)
+	if err != nil {
+		return nil, err
+	}
+
+	var txs []*types.UTXOTransaction
+	if len(args.Inputs) > 0 {
+		txs, err = s.wallet.CreateUTXOTransactionFromInputs(args.From, args.Inputs, dests, *args.TokenID, args.From, nil)
+	} else {
+		txs, err = s.wallet.CreateUTXOTransaction(args.From, uint64(*args.Nonce), args.SubAddrs, dests, *args.TokenID, args.From, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return encodeUTXOTransactions(txs)
+}
+
+// encodeUTXOTransactions encode signed utxo txs to raw
+func encodeUTXOTransactions(txs []*types.UTXOTransaction) (*wtypes.SignUTXOTransactionResult, error) {
+	var signedtxs []wtypes.SignUTXORet
+	for _, tx := range txs {
+		bz, err := ser.EncodeToBytes(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := tx.GetInputKeyImages()
+		for i := 0; i < len(keys); i++ {
+			log.Debug("signUTXOTransaction", "keyimage", keys[i])
+		}
+		gas := hexutil.Uint64(tx.Gas())
+		signedtxs = append(signedtxs, wtypes.SignUTXORet{Raw: fmt.Sprintf("0x%s", hex.EncodeToString(bz)), Hash: tx.Hash(), Gas: gas})
+	}
+	return &wtypes.SignUTXOTransactionResult{Txs: signedtxs}, nil
+}
+
+// toDestEntries convert rpc dests to utxo and account dest entries
+func toDestEntries(utxoDests []*types.UTXODest) ([]types.DestEntry, error) {
 	dests := make([]types.DestEntry, 0)
 	hasOneAccountOutput := false
 	utxoDestsCnt := 0
-	for i := 0; i < destsCnt; i++ {
-		toAddress := args.Dests[i].Addr
+	for i := 0; i < len(utxoDests); i++ {
+		toAddress := utxoDests[i].Addr
 		if len(toAddress) == 95 {
 			if utxoDestsCnt >= wtypes.UTXO_DESTS_MAX_NUM {
 				return nil, wtypes.ErrUTXODestsOverLimit
 			}
 			// utxo address
-			addr, err := wallet.StrToAddress(args.Dests[i].Addr)
+			addr, err := wallet.StrToAddress(utxoDests[i].Addr)
 			if err != nil {
 				return nil, fmt.Errorf("error dests addr:%s", toAddress)
 			}
 
 			var remark [32]byte
-			copy(remark[:], args.Dests[i].Remark[:])
-			log.Debug("signUTXOTransaction", "Remark", args.Dests[i].Remark, "len", len(args.Dests[i].Remark), "remark", remark)
+			copy(remark[:], utxoDests[i].Remark[:])
+			log.Debug("signUTXOTransaction", "Remark", utxoDests[i].Remark, "len", len(utxoDests[i].Remark), "remark", remark)
 
-			dests = append(dests, &types.UTXODestEntry{Addr: *addr, Amount: args.Dests[i].Amount.ToInt(), IsSubaddress: wallet.IsSubaddress(args.Dests[i].Addr), Remark: remark})
+			dests = append(dests, &types.UTXODestEntry{Addr: *addr, Amount: utxoDests[i].Amount.ToInt(), IsSubaddress: wallet.IsSubaddress(utxoDests[i].Addr), Remark: remark})
 			utxoDestsCnt++
 		} else {
 			if !common.IsHexAddress(toAddress) {
@@ -74,32 +113,12 @@ func (s *PublicTransactionPoolAPI) signUTXOTransaction(ctx context.Context, args
 				return nil, fmt.Errorf("account output too more")
 			}
 			addr := common.HexToAddress(toAddress)
-			dests = append(dests, &types.AccountDestEntry{To: addr, Amount: args.Dests[i].Amount.ToInt(), Data: args.Dests[i].Data})
+			dests = append(dests, &types.AccountDestEntry{To: addr, Amount: utxoDests[i].Amount.ToInt(), Data: utxoDests[i].Data})
 			hasOneAccountOutput = true
 		}
 
 	}
-
-	txs, err := s.wallet.CreateUTXOTransaction(args.From, uint64(*args.Nonce), args.SubAddrs, dests, *args.TokenID, args.From, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var signedtxs []wtypes.SignUTXORet
-	for _, tx := range txs {
-		bz, err := ser.EncodeToBytes(tx)
-		if err != nil {
-			return nil, err
-		}
-
-		keys := tx.GetInputKeyImages()
-		for i := 0; i < len(keys); i++ {
-			log.Debug("signUTXOTransaction", "args.SubAddrs", args.SubAddrs, "keyimage", keys[i])
-		}
-		gas := hexutil.Uint64(tx.Gas())
-		signedtxs = append(signedtxs, wtypes.SignUTXORet{Raw: fmt.Sprintf("0x%s", hex.EncodeToString(bz)), Hash: tx.Hash(), Gas: gas})
-	}
-	return &wtypes.SignUTXOTransactionResult{Txs: signedtxs}, nil
+	return dests, nil
 }
 
 // SignUTXOTransaction will sign the given transaction with the from account.
@@ -131,6 +150,10 @@ func (s *PublicTransactionPoolAPI) SendUTXOTransactionSplit(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	return s.sendSignedUTXOTransactions(signRet), nil
+}
+
+func (s *PublicTransactionPoolAPI) sendSignedUTXOTransactions(signRet *wtypes.SignUTXOTransactionResult) *wtypes.SendUTXOTransactionResult {
 	var signedRaw []string
 	for i := 0; i < len(signRet.Txs); i++ {
 		signedRaw = append(signedRaw, signRet.Txs[i].Raw)
@@ -139,7 +162,78 @@ func (s *PublicTransactionPoolAPI) SendUTXOTransactionSplit(ctx context.Context,
 	for index := 0; index < len(signRet.Txs); index++ {
 		ret[index].Gas = signRet.Txs[index].Gas
 	}
-	return &wtypes.SendUTXOTransactionResult{Txs: ret}, nil
+	return &wtypes.SendUTXOTransactionResult{Txs: ret}
+}
+
+// ListUnspent list unspent outputs with amount, height and key image, index of output can be used as input of SendUTXOTransaction
+func (s *PublicTransactionPoolAPI) ListUnspent(ctx context.Context, args wtypes.ListUnspentArgs) (*wtypes.ListUnspentRet, error) {
+	if args.TokenID == nil {
+		args.TokenID = &common.EmptyAddress
+	}
+	outputs, err := s.wallet.ListUnspent(*args.TokenID, args.SubAddrs)
+	if err != nil {
+		return nil, err
+	}
+	return &wtypes.ListUnspentRet{Outputs: outputs}, nil
+}
+
+// FreezeOutputs freeze outputs, frozen outputs are never spent until thawed
+func (s *PublicTransactionPoolAPI) FreezeOutputs(ctx context.Context, args wtypes.FreezeArgs) (bool, error) {
+	err := s.wallet.FreezeOutputs(args.Indices)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ThawOutputs thaw frozen outputs
+func (s *PublicTransactionPoolAPI) ThawOutputs(ctx context.Context, args wtypes.FreezeArgs) (bool, error) {
+	err := s.wallet.ThawOutputs(args.Indices)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SweepAll send all unfrozen outputs to the address
+func (s *PublicTransactionPoolAPI) SweepAll(ctx context.Context, args wtypes.SweepAllArgs) (*wtypes.SendUTXOTransactionResult, error) {
+	if args.TokenID == nil {
+		args.TokenID = &common.EmptyAddress
+	}
+	if *args.TokenID != common.EmptyAddress {
+		return nil, wtypes.ErrUTXONotSupportToken
+	}
+	txs, err := s.wallet.SweepAll(args.Addr, args.SubAddrs, *args.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	signRet, err := encodeUTXOTransactions(txs)
+	if err != nil {
+		return nil, err
+	}
+	return s.sendSignedUTXOTransactions(signRet), nil
+}
+
+// SweepBelow send unfrozen outputs with amount below the given amount to the address
+func (s *PublicTransactionPoolAPI) SweepBelow(ctx context.Context, args wtypes.SweepBelowArgs) (*wtypes.SendUTXOTransactionResult, error) {
+	if args.TokenID == nil {
+		args.TokenID = &common.EmptyAddress
+	}
+	if *args.TokenID != common.EmptyAddress {
+		return nil, wtypes.ErrUTXONotSupportToken
+	}
+	if args.Amount == nil {
+		return nil, fmt.Errorf("need amount")
+	}
+	txs, err := s.wallet.SweepBelow(args.Addr, args.Amount.ToInt(), args.SubAddrs, *args.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	signRet, err := encodeUTXOTransactions(txs)
+	if err != nil {
+		return nil, err
+	}
+	return s.sendSignedUTXOTransactions(signRet), nil
 }
 
 // BlockHeight get block height
@@ -176,6 +270,15 @@ func (s *PublicTransactionPoolAPI) CreateSubAccount(ctx context.Context, maxSub
 	return true, nil
 }
 
+// SetSubaddressLookahead set the number of subaddresses after the created ones looked for while scanning
+func (s *PublicTransactionPoolAPI) SetSubaddressLookahead(ctx context.Context, lookahead hexutil.Uint64) (bool, error) {
+	err := s.wallet.SetSubaddressLookahead(uint64(lookahead))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // Balance get account Balance
 func (s *PublicTransactionPoolAPI) AutoRefreshBlockchain(ctx context.Context, autoRefresh bool) (bool, error) {
 	err := s.wallet.AutoRefreshBlockchain(autoRefresh)
@@ -317,6 +420,125 @@ func (s *PublicTransactionPoolAPI) CheckProofKey(ctx context.Context, args wtype
 	return ret, nil
 }
 
+// GetSpendProof return a proof that the wallet spent the given tx
+func (s *PublicTransactionPoolAPI) GetSpendProof(ctx context.Context, args wtypes.SpendProofArgs) (*wtypes.SpendProofRet, error) {
+	signature, err := s.wallet.GetSpendProof(args.Hash, args.Message)
+	if err != nil {
+		return nil, err
+	}
+	return &wtypes.SpendProofRet{Signature: signature}, nil
+}
+
+// CheckSpendProof verify a spend proof
+func (s *PublicTransactionPoolAPI) CheckSpendProof(ctx context.Context, args wtypes.CheckSpendProofArgs) (*wtypes.CheckSpendProofRet, error) {
+	good, err := s.wallet.CheckSpendProof(args.Hash, args.Message, args.Signature)
+	if err != nil {
+		return nil, err
+	}
+	return &wtypes.CheckSpendProofRet{Good: good}, nil
+}
+
+// GetReserveProof return a proof of unspent outputs worth at least amount,
+// all unspent outputs of the token are proved if amount is not set
+func (s *PublicTransactionPoolAPI) GetReserveProof(ctx context.Context, args wtypes.ReserveProofArgs) (*wtypes.ReserveProofRet, error) {
+	if args.TokenID == nil {
+		args.TokenID = &common.EmptyAddress
+	}
+	var amount *big.Int
+	if args.Amount != nil {
+		amount = args.Amount.ToInt()
+		if amount.Sign() <= 0 {
+			return nil, wtypes.ErrArgsInvalid
+		}
+	}
+	signature, err := s.wallet.GetReserveProof(*args.TokenID, amount, args.Message)
+	if err != nil {
+		return nil, err
+	}
+	return &wtypes.ReserveProofRet{Signature: signature}, nil
+}
+
+// CheckReserveProof verify a reserve proof and return the proven and spent amounts
+func (s *PublicTransactionPoolAPI) CheckReserveProof(ctx context.Context, args wtypes.CheckReserveProofArgs) (*wtypes.CheckReserveProofRet, error) {
+	return s.wallet.CheckReserveProof(args.Addr, args.Message, args.Signature)
+}
+
+// PrepareMultisig return the info to make a multisig wallet with other signers
+func (s *PublicTransactionPoolAPI) PrepareMultisig(ctx context.Context) (*wtypes.PrepareMultisigRet, error) {
+	info, err := s.wallet.PrepareMultisig()
+	if err != nil {
+		return nil, err
+	}
+	return &wtypes.PrepareMultisigRet{MultisigInfo: info}, nil
+}
+
+// MakeMultisig make a M-of-N multisig wallet with the infos of the other signers
+func (s *PublicTransactionPoolAPI) MakeMultisig(ctx context.Context, args wtypes.MakeMultisigArgs) (*wtypes.MakeMultisigRet, error) {
+	if len(args.MultisigInfo) == 0 {
+		return nil, wtypes.ErrArgsInvalid
+	}
+	return s.wallet.MakeMultisig(uint64(args.Threshold), args.MultisigInfo)
+}
+
+// ExchangeMultisigKeys run a key exchange round of making multisig wallet
+func (s *PublicTransactionPoolAPI) ExchangeMultisigKeys(ctx context.Context, args wtypes.ExchangeMultisigKeysArgs) (*wtypes.MakeMultisigRet, error) {
+	if len(args.MultisigInfo) == 0 {
+		return nil, wtypes.ErrArgsInvalid
+	}
+	return s.wallet.ExchangeMultisigKeys(args.MultisigInfo)
+}
+
+// IsMultisig return multisig status of wallet
+func (s *PublicTransactionPoolAPI) IsMultisig(ctx context.Context) (*wtypes.IsMultisigRet, error) {
+	return s.wallet.IsMultisig()
+}
+
+// ExportMultisigInfo export partial key images and signing nonces for other signers
+func (s *PublicTransactionPoolAPI) ExportMultisigInfo(ctx context.Context) (*wtypes.ExportMultisigInfoRet, error) {
+	info, err := s.wallet.ExportMultisigInfo()
+	if err != nil {
+		return nil, err
+	}
+	return &wtypes.ExportMultisigInfoRet{Info: info}, nil
+}
+
+// ImportMultisigInfo import multisig infos exported by other signers
+func (s *PublicTransactionPoolAPI) ImportMultisigInfo(ctx context.Context, args wtypes.ImportMultisigInfoArgs) (*wtypes.ImportMultisigInfoRet, error) {
+	if len(args.Info) == 0 {
+		return nil, wtypes.ErrArgsInvalid
+	}
+	n, err := s.wallet.ImportMultisigInfo(args.Info)
+	if err != nil {
+		return nil, err
+	}
+	return &wtypes.ImportMultisigInfoRet{Outputs: hexutil.Uint64(n)}, nil
+}
+
+// TransferMultisig create a multisig tx set to be signed by other signers
+func (s *PublicTransactionPoolAPI) TransferMultisig(ctx context.Context, args wtypes.TransferMultisigArgs) (*wtypes.MultisigTxSetRet, error) {
+	if args.TokenID == nil {
+		args.TokenID = &common.EmptyAddress
+	}
+	if len(args.Dests) == 0 {
+		return nil, fmt.Errorf("need more dests")
+	}
+	dests, err := toDestEntries(args.Dests)
+	if err != nil {
+		return nil, err
+	}
+	return s.wallet.TransferMultisig(dests, *args.TokenID)
+}
+
+// SignMultisig sign a multisig tx set
+func (s *PublicTransactionPoolAPI) SignMultisig(ctx context.Context, args wtypes.MultisigTxSetArgs) (*wtypes.MultisigTxSetRet, error) {
+	return s.wallet.SignMultisig(args.TxSet)
+}
+
+// SubmitMultisig submit a fully signed multisig tx set
+func (s *PublicTransactionPoolAPI) SubmitMultisig(ctx context.Context, args wtypes.MultisigTxSetArgs) (common.Hash, error) {
+	return s.wallet.SubmitMultisig(args.TxSet)
+}
+
 // SelectAddress set wallet curr account
 func (s *PublicTransactionPoolAPI) SelectAddress(ctx context.Context, addr common.Address) (bool, error) {
 	err := s.wallet.SelectAddress(addr)
@@ -28,6 +28,7 @@ type SendUTXOTxArgs struct {
 	SubAddrs []uint64          `json:"subaddrs"`
 	Dests    []*types.UTXODest `json:"dests"`
 	TokenID  *common.Address   `json:"token"`
+	Inputs   []uint64          `json:"inputs"`
 }
 
 func (s *SendUTXOTxArgs) SetDefaults() {
@@ -145,6 +146,57 @@ type VerifyProofKeyRet struct {
 	Records []*VerifyProofKey `json:"records"`
 }
 
+type SpendProofArgs struct {
+	Hash    common.Hash `json:"hash"`
+	Message string      `json:"message"`
+}
+
+type SpendProofRet struct {
+	Signature string `json:"signature"`
+}
+
+type CheckSpendProofArgs struct {
+	Hash      common.Hash `json:"hash"`
+	Message   string      `json:"message"`
+	Signature string      `json:"signature"`
+}
+
+type CheckSpendProofRet struct {
+	Good bool `json:"good"`
+}
+
+type ReserveProofArgs struct {
+	TokenID *common.Address `json:"token"`
+	Amount  *hexutil.Big    `json:"amount"`
+	Message string          `json:"message"`
+}
+
+type ReserveProofRet struct {
+	Signature string `json:"signature"`
+}
+
+type CheckReserveProofArgs struct {
+	Addr      string `json:"addr"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+type ReserveProofRecord struct {
+	Hash     common.Hash    `json:"hash"`
+	OutIndex hexutil.Uint64 `json:"out_index"`
+	KeyImage string         `json:"key_image"`
+	Amount   *hexutil.Big   `json:"amount"`
+	Spent    bool           `json:"spent"`
+}
+
+type CheckReserveProofRet struct {
+	Good    bool                  `json:"good"`
+	TokenID common.Address        `json:"token"`
+	Total   *hexutil.Big          `json:"total"`
+	Spent   *hexutil.Big          `json:"spent"`
+	Records []*ReserveProofRecord `json:"records"`
+}
+
 type CheckTxKeyArgs struct {
 	TxHash   common.Hash  `json:"hash"`
 	TxKey    lkctypes.Key `json:"key"`
@@ -155,3 +207,102 @@ type CheckTxKeyResult struct {
 	BlockID hexutil.Uint64 `json:"height"`
 	Amount  *hexutil.Big   `json:"amount"`
 }
+
+type PrepareMultisigRet struct {
+	MultisigInfo string `json:"multisig_info"`
+}
+
+type MakeMultisigArgs struct {
+	Threshold    hexutil.Uint64 `json:"threshold"`
+	MultisigInfo []string       `json:"multisig_info"`
+}
+
+type ExchangeMultisigKeysArgs struct {
+	MultisigInfo []string `json:"multisig_info"`
+}
+
+type MakeMultisigRet struct {
+	Address      string `json:"address"`
+	MultisigInfo string `json:"multisig_info"`
+	Ready        bool   `json:"ready"`
+}
+
+type IsMultisigRet struct {
+	Multisig  bool           `json:"multisig"`
+	Ready     bool           `json:"ready"`
+	Threshold hexutil.Uint64 `json:"threshold"`
+	Total     hexutil.Uint64 `json:"total"`
+}
+
+type ExportMultisigInfoRet struct {
+	Info string `json:"info"`
+}
+
+type ImportMultisigInfoArgs struct {
+	Info []string `json:"info"`
+}
+
+type ImportMultisigInfoRet struct {
+	Outputs hexutil.Uint64 `json:"n_outputs"`
+}
+
+type TransferMultisigArgs struct {
+	Dests   []*types.UTXODest `json:"dests"`
+	TokenID *common.Address   `json:"token"`
+}
+
+type MultisigTxSetArgs struct {
+	TxSet string `json:"tx_set"`
+}
+
+type MultisigTxDest struct {
+	Addr   string       `json:"addr"`
+	Amount *hexutil.Big `json:"amount"`
+}
+
+type MultisigTxSetRet struct {
+	TxSet    string           `json:"tx_set"`
+	Hash     common.Hash      `json:"hash"`
+	Fee      *hexutil.Big     `json:"fee"`
+	Dests    []MultisigTxDest `json:"dests"`
+	Complete bool             `json:"complete"`
+}
+
+type ListUnspentArgs struct {
+	TokenID  *common.Address `json:"token"`
+	SubAddrs []uint64        `json:"subaddrs"`
+}
+
+type UnspentOutput struct {
+	Index        hexutil.Uint64 `json:"index"`
+	SubAddrIndex hexutil.Uint64 `json:"subaddr_index"`
+	TokenID      common.Address `json:"token"`
+	Amount       *hexutil.Big   `json:"amount"`
+	BlockHeight  hexutil.Uint64 `json:"height"`
+	TxID         common.Hash    `json:"hash"`
+	OutIndex     hexutil.Uint64 `json:"out_index"`
+	GlobalIndex  hexutil.Uint64 `json:"global_index"`
+	KeyImage     string         `json:"key_image"`
+	Frozen       bool           `json:"frozen"`
+}
+
+type ListUnspentRet struct {
+	Outputs []*UnspentOutput `json:"outputs"`
+}
+
+type FreezeArgs struct {
+	Indices []uint64 `json:"indices"`
+}
+
+type SweepAllArgs struct {
+	Addr     string          `json:"addr"`
+	SubAddrs []uint64        `json:"subaddrs"`
+	TokenID  *common.Address `json:"token"`
+}
+
+type SweepBelowArgs struct {
+	Addr     string          `json:"addr"`
+	Amount   *hexutil.Big    `json:"amount"`
+	SubAddrs []uint64        `json:"subaddrs"`
+	TokenID  *common.Address `json:"token"`
+}
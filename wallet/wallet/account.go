@@ -7,6 +7,7 @@ import (
 
 	"github.com/lianxiangcloud/linkchain/libs/common"
 	lkctypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
+	"github.com/lianxiangcloud/linkchain/libs/cryptonote/xcrypto"
 	"github.com/lianxiangcloud/linkchain/libs/log"
 	cfg "github.com/lianxiangcloud/linkchain/wallet/config"
 )
@@ -19,6 +20,7 @@ type AccountBase struct {
 	CurrIdx           uint64
 	MainSecKey        lkctypes.SecretKey
 	EthAddress        common.Address
+	LookaheadIdx      uint64 // KeyIndex holds subaddresses before LookaheadIdx, including not created ones
 }
 
 func (a *AccountBase) String() string {
@@ -191,3 +193,20 @@ func (a *AccountBase) CreateSubAccountN(cnt int) error {
 	}
 	return nil
 }
+
+// ExpandSubAccountLookahead put spend public keys of the next lookahead subaddresses after
+// the created ones into KeyIndex, so outputs to them can be found before they are created
+func (a *AccountBase) ExpandSubAccountLookahead(lookahead uint64) {
+	end := uint64(len(a.Keys)) + lookahead
+	idx := a.LookaheadIdx
+	if idx < uint64(len(a.Keys)) {
+		idx = uint64(len(a.Keys))
+	}
+	for ; idx < end; idx++ {
+		addr := xcrypto.GetSubaddress(a.Keys[0], uint32(idx))
+		a.KeyIndex[addr.SpendPublicKey] = idx
+	}
+	if end > a.LookaheadIdx {
+		a.LookaheadIdx = end
+	}
+}
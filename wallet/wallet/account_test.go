@@ -1,7 +1,103 @@
 package wallet
 
-import "testing"
+import (
+	"math/big"
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/cryptonote/ringct"
+	lktypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
+	"github.com/lianxiangcloud/linkchain/libs/cryptonote/xcrypto"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/libs/log"
+	"github.com/lianxiangcloud/linkchain/types"
+)
 
 func TestCreateSubAccount(t *testing.T) {
 	mockWallet.currAccount.account.CreateSubAccount()
 }
+
+func TestExpandSubAccountLookahead(t *testing.T) {
+	acc, err := RecoveryKeyToAccount(lktypes.SecretKey(ringct.SkGen()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc.ExpandSubAccountLookahead(3)
+	if acc.LookaheadIdx != 4 || len(acc.KeyIndex) != 4 {
+		t.Fatalf("LookaheadIdx %d with %d keys, want 4 with 4 keys", acc.LookaheadIdx, len(acc.KeyIndex))
+	}
+	for idx := uint64(1); idx < 4; idx++ {
+		addr := xcrypto.GetSubaddress(acc.Keys[0], uint32(idx))
+		if got, ok := acc.KeyIndex[addr.SpendPublicKey]; !ok || got != idx {
+			t.Fatalf("KeyIndex of subaddress %d got %d %v", idx, got, ok)
+		}
+	}
+
+	// the window follows the created sub accounts and never shrinks
+	if _, _, err := acc.CreateSubAccount(); err != nil {
+		t.Fatal(err)
+	}
+	acc.ExpandSubAccountLookahead(3)
+	if acc.LookaheadIdx != 5 || len(acc.KeyIndex) != 5 {
+		t.Fatalf("LookaheadIdx %d with %d keys, want 5 with 5 keys", acc.LookaheadIdx, len(acc.KeyIndex))
+	}
+	acc.ExpandSubAccountLookahead(1)
+	if acc.LookaheadIdx != 5 || len(acc.KeyIndex) != 5 {
+		t.Fatalf("LookaheadIdx %d with %d keys, want 5 with 5 keys", acc.LookaheadIdx, len(acc.KeyIndex))
+	}
+}
+
+func TestSubaddressLookahead(t *testing.T) {
+	acc, err := RecoveryKeyToAccount(lktypes.SecretKey(ringct.SkGen()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	la := &LinkAccount{
+		Logger:           log.Root(),
+		account:          acc,
+		walletDB:         dbm.NewMemDB(),
+		mainUTXOAddress:  acc.GetKeys().Address,
+		AccBalance:       make(map[common.Address]balanceMap),
+		utxoTotalBalance: make(map[common.Address]*big.Int),
+	}
+	if err := la.SetSubaddressLookahead(2); err != nil {
+		t.Fatal(err)
+	}
+
+	// an output to the last subaddress of the window is found by the scanning
+	keys := acc.GetKeys()
+	r := ringct.SkGen()
+	derivation, err := xcrypto.GenerateKeyDerivation(keys.Addr.ViewPublicKey, lktypes.SecretKey(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subaddr := xcrypto.GetSubaddress(acc.Keys[0], 2)
+	otaddr, err := xcrypto.DerivePublicKey(derivation, 0, subaddr.SpendPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rKey := lktypes.PublicKey(ringct.ScalarmultBase(r))
+	recvDerivation, err := xcrypto.GenerateKeyDerivation(rKey, keys.ViewSKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, subaddrIndex, err := types.IsOutputBelongToAccount(keys, acc.KeyIndex, lktypes.Key(otaddr), []lktypes.KeyDerivation{recvDerivation}, 0)
+	if err != nil || subaddrIndex != 2 {
+		t.Fatalf("IsOutputBelongToAccount got %d %v, want 2", subaddrIndex, err)
+	}
+
+	la.expandSubAccount(subaddrIndex)
+	if len(acc.Keys) != 3 || acc.LookaheadIdx != 5 {
+		t.Fatalf("expandSubAccount got %d sub accounts and LookaheadIdx %d, want 3 and 5", len(acc.Keys), acc.LookaheadIdx)
+	}
+	for idx := uint64(1); idx < 3; idx++ {
+		if b, ok := la.AccBalance[LinkToken][idx]; !ok || b.Sign() != 0 {
+			t.Fatalf("balance of sub account %d got %v %v, want 0", idx, b, ok)
+		}
+	}
+
+	la2 := &LinkAccount{Logger: log.Root(), walletDB: la.walletDB, mainUTXOAddress: la.mainUTXOAddress}
+	if err := la2.loadSubaddrLookahead(); err != nil || la2.subaddrLookahead != 2 {
+		t.Fatalf("loadSubaddrLookahead got %d %v, want 2", la2.subaddrLookahead, err)
+	}
+}
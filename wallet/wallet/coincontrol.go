@@ -0,0 +1,269 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/lianxiangcloud/linkchain/accounts"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
+	"github.com/lianxiangcloud/linkchain/types"
+	wtypes "github.com/lianxiangcloud/linkchain/wallet/types"
+)
+
+var (
+	ErrInputsEmpty         = errors.New("inputs empty")
+	ErrInputInvalid        = errors.New("input invalid")
+	ErrInputsNotSupport    = errors.New("explicit inputs only support utxo input")
+	ErrSweepNothing        = errors.New("no output to sweep")
+	ErrSweepAddrNotSupport = errors.New("sweep only support utxo address")
+)
+
+// ListUnspent return unspent outputs of the token, including frozen ones,
+// only outputs of subaddrs are returned if subaddrs is not empty
+func (la *LinkAccount) ListUnspent(tokenID common.Address, subaddrs []uint64) []*wtypes.UnspentOutput {
+	la.lock.Lock()
+	defer la.lock.Unlock()
+
+	subaddrMap := make(map[uint64]bool)
+	for _, subaddr := range subaddrs {
+		subaddrMap[subaddr] = true
+	}
+	outputs := make([]*wtypes.UnspentOutput, 0)
+	for i, uod := range la.Transfers {
+		if uod.Spent || uod.TokenID != tokenID {
+			continue
+		}
+		if len(subaddrMap) > 0 && !subaddrMap[uod.SubAddrIndex] {
+			continue
+		}
+		outputs = append(outputs, &wtypes.UnspentOutput{
+			Index:        hexutil.Uint64(i),
+			SubAddrIndex: hexutil.Uint64(uod.SubAddrIndex),
+			TokenID:      uod.TokenID,
+			Amount:       (*hexutil.Big)(new(big.Int).Set(uod.Amount)),
+			BlockHeight:  hexutil.Uint64(uod.BlockHeight),
+			TxID:         common.Hash(uod.TxID),
+			OutIndex:     hexutil.Uint64(uod.OutIndex),
+			GlobalIndex:  hexutil.Uint64(uod.GlobalIndex),
+			KeyImage:     fmt.Sprintf("%x", uod.KeyImage[:]),
+			Frozen:       uod.Frozen,
+		})
+	}
+	return outputs
+}
+
+// FreezeOutputs mark the outputs frozen or not, frozen outputs are never chosen as inputs
+// and not counted in balance
+func (la *LinkAccount) FreezeOutputs(indices []uint64, freeze bool) error {
+	la.lock.Lock()
+	defer la.lock.Unlock()
+
+	for _, idx := range indices {
+		if idx >= uint64(len(la.Transfers)) || la.Transfers[idx].Spent {
+			return ErrInputInvalid
+		}
+	}
+	tids := make([]int, 0, len(indices))
+	for _, idx := range indices {
+		uod := la.Transfers[idx]
+		if uod.Frozen == freeze {
+			continue
+		}
+		uod.Frozen = freeze
+		la.updateBalance(uod.TokenID, uod.SubAddrIndex, !freeze, uod.Amount)
+		tids = append(tids, int(idx))
+	}
+	if len(tids) == 0 {
+		return nil
+	}
+	batch := la.walletDB.NewBatch()
+	if la.saveTransfers(batch, tids) != nil || batch.Commit() != nil {
+		return fmt.Errorf("saveTransfers fail")
+	}
+	la.Logger.Info("FreezeOutputs", "indices", tids, "freeze", freeze)
+	return nil
+}
+
+// ListUnspent wallet rpc
+func (wallet *Wallet) ListUnspent(tokenID common.Address, subaddrs []uint64) ([]*wtypes.UnspentOutput, error) {
+	if wallet.IsWalletClosed() {
+		return nil, wtypes.ErrWalletNotOpen
+	}
+	return wallet.currAccount.ListUnspent(tokenID, subaddrs), nil
+}
+
+// FreezeOutputs wallet rpc
+func (wallet *Wallet) FreezeOutputs(indices []uint64) error {
+	if wallet.IsWalletClosed() {
+		return wtypes.ErrWalletNotOpen
+	}
+	return wallet.currAccount.FreezeOutputs(indices, true)
+}
+
+// ThawOutputs wallet rpc
+func (wallet *Wallet) ThawOutputs(indices []uint64) error {
+	if wallet.IsWalletClosed() {
+		return wtypes.ErrWalletNotOpen
+	}
+	return wallet.currAccount.FreezeOutputs(indices, false)
+}
+
+// CreateUTXOTransactionFromInputs return UTXOTransactions spending the given outputs only
+func (wallet *Wallet) CreateUTXOTransactionFromInputs(from common.Address, inputs []uint64, dests []types.DestEntry,
+	tokenID common.Address, refundAddr common.Address, extra []byte) ([]*types.UTXOTransaction, error) {
+	if wallet.IsWalletClosed() {
+		return nil, wtypes.ErrWalletNotOpen
+	}
+	if wallet.currAccount.isMultisig() {
+		return nil, ErrMultisigNotSupport
+	}
+	if from != common.EmptyAddress {
+		return nil, ErrInputsNotSupport
+	}
+	return wallet.createUinTransactionFromInputs(wallet.currAccount.getEthAddress(), "", inputs, dests, tokenID, refundAddr, extra)
+}
+
+func (wallet *Wallet) createUinTransactionFromInputs(from common.Address, passwd string, inputs []uint64, dests []types.DestEntry,
+	tokenID common.Address, refundAddr common.Address, extra []byte) ([]*types.UTXOTransaction, error) {
+	if 0 == len(inputs) {
+		return nil, ErrInputsEmpty
+	}
+	needMoney, _, err := wallet.checkDest(dests, tokenID, UTXOInputMode)
+	if err != nil {
+		return nil, err
+	}
+	acc := accounts.Account{Address: from}
+	w, err := wallet.accManager.Find(acc)
+	if err != nil {
+		wallet.Logger.Error("createUinTransactionFromInputs wallet.accManager.Find(acc)", "from", from, "err", err)
+		return nil, ErrAccountNotFound
+	}
+
+	selected := make(map[uint64]bool)
+	availableMoney := big.NewInt(0)
+	for _, idx := range inputs {
+		if idx >= uint64(len(wallet.currAccount.Transfers)) || selected[idx] {
+			return nil, ErrInputInvalid
+		}
+		output := wallet.currAccount.Transfers[idx]
+		if output.Spent || output.Frozen || output.TokenID != tokenID {
+			return nil, ErrInputInvalid
+		}
+		selected[idx] = true
+		availableMoney.Add(availableMoney, output.Amount)
+	}
+	wallet.Logger.Debug("createUinTransactionFromInputs", "needMoney", needMoney, "availableMoney", availableMoney, "inputs", fmt.Sprintf("%v", inputs))
+	if availableMoney.Cmp(needMoney) < 0 {
+		return nil, ErrBalanceNotEnough
+	}
+
+	// change goes to the subaddress of the first input, no output other than inputs can be chosen
+	chargeSubaddr := wallet.currAccount.Transfers[inputs[0]].SubAddrIndex
+	preferIndice := make([]uint64, len(inputs))
+	copy(preferIndice, inputs)
+	unspentIndice := make([]uint64, len(inputs))
+	copy(unspentIndice, inputs)
+	sortableSubaddrs := sortableSubaddrs{&subaddrBalance{Subaddr: chargeSubaddr, Balance: availableMoney}}
+	unspentIndicePerSubaddr := map[uint64][]uint64{chargeSubaddr: unspentIndice}
+
+	return wallet.createUinTransaction(w, acc, passwd, preferIndice, sortableSubaddrs, unspentIndicePerSubaddr, dests, tokenID, refundAddr, extra)
+}
+
+// SweepAll send all unfrozen outputs of the subaddrs to addr, outputs of all subaddrs are swept if subaddrs is empty
+func (wallet *Wallet) SweepAll(addr string, subaddrs []uint64, tokenID common.Address) ([]*types.UTXOTransaction, error) {
+	return wallet.sweep(addr, subaddrs, tokenID, nil)
+}
+
+// SweepBelow send unfrozen outputs with amount below the given amount to addr, to consolidate dust
+func (wallet *Wallet) SweepBelow(addr string, below *big.Int, subaddrs []uint64, tokenID common.Address) ([]*types.UTXOTransaction, error) {
+	if below == nil || below.Sign() <= 0 {
+		return nil, ErrOutputMoneyInvalid
+	}
+	return wallet.sweep(addr, subaddrs, tokenID, below)
+}
+
+// sweep spend outputs subaddress by subaddress, so that outputs of different subaddresses
+// are never linked in one transaction
+func (wallet *Wallet) sweep(addr string, subaddrs []uint64, tokenID common.Address, below *big.Int) ([]*types.UTXOTransaction, error) {
+	if wallet.IsWalletClosed() {
+		return nil, wtypes.ErrWalletNotOpen
+	}
+	if wallet.currAccount.isMultisig() {
+		return nil, ErrMultisigNotSupport
+	}
+	if len(addr) != 95 {
+		return nil, ErrSweepAddrNotSupport
+	}
+	to, err := StrToAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	isSubaddress := IsSubaddress(addr)
+
+	subaddrMap := make(map[uint64]bool)
+	for _, subaddr := range subaddrs {
+		subaddrMap[subaddr] = true
+	}
+	indicePerSubaddr := make(map[uint64][]uint64)
+	for i, output := range wallet.currAccount.Transfers {
+		if output.Spent || output.Frozen || output.TokenID != tokenID {
+			continue
+		}
+		if len(subaddrMap) > 0 && !subaddrMap[output.SubAddrIndex] {
+			continue
+		}
+		if below != nil && output.Amount.Cmp(below) >= 0 {
+			continue
+		}
+		indicePerSubaddr[output.SubAddrIndex] = append(indicePerSubaddr[output.SubAddrIndex], uint64(i))
+	}
+	sweepSubaddrs := make([]uint64, 0, len(indicePerSubaddr))
+	for subaddr := range indicePerSubaddr {
+		sweepSubaddrs = append(sweepSubaddrs, subaddr)
+	}
+	sort.Slice(sweepSubaddrs, func(i, j int) bool { return sweepSubaddrs[i] < sweepSubaddrs[j] })
+
+	maxInputs := 1
+	for estimateTxWeight(maxInputs+1, 2) < UTXOTRANSACTION_MAX_SIZE {
+		maxInputs++
+	}
+	fee := wallet.estimateUtxoTxFee()
+	changeRate := big.NewInt(types.UTXO_COMMITMENT_CHANGE_RATE)
+	txes := make([]*types.UTXOTransaction, 0)
+	for _, subaddr := range sweepSubaddrs {
+		indice := indicePerSubaddr[subaddr]
+		for len(indice) > 0 {
+			cnt := len(indice)
+			if cnt > maxInputs {
+				cnt = maxInputs
+			}
+			inputs := indice[:cnt]
+			indice = indice[cnt:]
+
+			amount := big.NewInt(0)
+			for _, idx := range inputs {
+				amount.Add(amount, wallet.currAccount.Transfers[idx].Amount)
+			}
+			amount.Sub(amount, fee)
+			amount.Sub(amount, new(big.Int).Mod(amount, changeRate))
+			if amount.Sign() <= 0 {
+				wallet.Logger.Info("sweep skip outputs not enough for fee", "subaddr", subaddr, "inputs", fmt.Sprintf("%v", inputs))
+				continue
+			}
+			dests := []types.DestEntry{&types.UTXODestEntry{Addr: *to, Amount: amount, IsSubaddress: isSubaddress}}
+			subTxes, err := wallet.createUinTransactionFromInputs(wallet.currAccount.getEthAddress(), "", inputs, dests, tokenID, common.EmptyAddress, nil)
+			if err != nil {
+				wallet.Logger.Error("sweep createUinTransactionFromInputs fail", "subaddr", subaddr, "inputs", fmt.Sprintf("%v", inputs), "err", err)
+				return nil, err
+			}
+			txes = append(txes, subTxes...)
+		}
+	}
+	if 0 == len(txes) {
+		return nil, ErrSweepNothing
+	}
+	return txes, nil
+}
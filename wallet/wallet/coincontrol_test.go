@@ -0,0 +1,209 @@
+package wallet
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/cryptonote/ringct"
+	lktypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/libs/log"
+	"github.com/lianxiangcloud/linkchain/types"
+	wtypes "github.com/lianxiangcloud/linkchain/wallet/types"
+)
+
+var coinControlToken = common.HexToAddress("0x1")
+
+func newCoinControlAccount(t *testing.T, walletDB dbm.DB) *LinkAccount {
+	acc, err := RecoveryKeyToAccount(lktypes.SecretKey(ringct.SkGen()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &LinkAccount{
+		Logger:           log.Root(),
+		account:          acc,
+		walletDB:         walletDB,
+		mainUTXOAddress:  acc.GetKeys().Address,
+		AccBalance:       make(map[common.Address]balanceMap),
+		utxoTotalBalance: make(map[common.Address]*big.Int),
+		keyImages:        make(map[lktypes.Key]int),
+		Transfers:        make(transferContainer, 0),
+	}
+}
+
+// newCoinControlWallet return a wallet with outputs:
+// 0: 1e18 of sub 0, 1: 2e18 of sub 1, 2: spent 3e18 of sub 0, 3: 4e18 of coinControlToken
+func newCoinControlWallet(t *testing.T) *Wallet {
+	la := newCoinControlAccount(t, dbm.NewMemDB())
+	outputs := []*types.UTXOOutputDetail{
+		{Amount: big.NewInt(1e18), TokenID: LinkToken},
+		{Amount: big.NewInt(2e18), TokenID: LinkToken, SubAddrIndex: 1},
+		{Amount: big.NewInt(3e18), TokenID: LinkToken, Spent: true},
+		{Amount: big.NewInt(4e18), TokenID: coinControlToken},
+	}
+	tids := make([]int, len(outputs))
+	for i, uod := range outputs {
+		uod.KeyImage = lktypes.Key(ringct.SkGen())
+		la.keyImages[uod.KeyImage] = i
+		la.Transfers = append(la.Transfers, uod)
+		if !uod.Spent {
+			la.updateBalance(uod.TokenID, uod.SubAddrIndex, true, uod.Amount)
+		}
+		tids[i] = i
+	}
+	batch := la.walletDB.NewBatch()
+	if err := la.saveTransfers(batch, tids); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return &Wallet{
+		Logger:      log.Root(),
+		currAccount: la,
+		utxoGas:     new(big.Int).Mul(new(big.Int).SetUint64(defaultUTXOGas), new(big.Int).SetInt64(1e11)),
+	}
+}
+
+func unspentIndice(outputs []*wtypes.UnspentOutput) []uint64 {
+	indice := make([]uint64, len(outputs))
+	for i, output := range outputs {
+		indice[i] = uint64(output.Index)
+	}
+	return indice
+}
+
+func equalIndice(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestListUnspent(t *testing.T) {
+	w := newCoinControlWallet(t)
+	tests := []struct {
+		tokenID  common.Address
+		subaddrs []uint64
+		want     []uint64
+	}{
+		{LinkToken, nil, []uint64{0, 1}},
+		{LinkToken, []uint64{1}, []uint64{1}},
+		{LinkToken, []uint64{2}, []uint64{}},
+		{coinControlToken, nil, []uint64{3}},
+	}
+	for _, test := range tests {
+		outputs, err := w.ListUnspent(test.tokenID, test.subaddrs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := unspentIndice(outputs); !equalIndice(got, test.want) {
+			t.Fatalf("ListUnspent of %s %v got %v, want %v", test.tokenID.String(), test.subaddrs, got, test.want)
+		}
+	}
+
+	outputs, _ := w.ListUnspent(LinkToken, []uint64{1})
+	uod := w.currAccount.Transfers[1]
+	if outputs[0].Amount.ToInt().Cmp(uod.Amount) != 0 || uint64(outputs[0].SubAddrIndex) != 1 || outputs[0].Frozen {
+		t.Fatalf("ListUnspent got %+v, want amount %s of sub 1", outputs[0], uod.Amount)
+	}
+
+	if _, err := (&Wallet{Logger: log.Root()}).ListUnspent(LinkToken, nil); err != wtypes.ErrWalletNotOpen {
+		t.Fatalf("ListUnspent of closed wallet got %v, want %v", err, wtypes.ErrWalletNotOpen)
+	}
+}
+
+func TestFreezeOutputs(t *testing.T) {
+	w := newCoinControlWallet(t)
+	la := w.currAccount
+
+	for _, indices := range [][]uint64{{2}, {4}, {0, 2}} {
+		if err := w.FreezeOutputs(indices); err != ErrInputInvalid {
+			t.Fatalf("FreezeOutputs of %v got %v, want %v", indices, err, ErrInputInvalid)
+		}
+	}
+	if la.Transfers[0].Frozen {
+		t.Fatal("output 0 frozen by a failed FreezeOutputs")
+	}
+
+	if err := w.FreezeOutputs([]uint64{0}); err != nil {
+		t.Fatal(err)
+	}
+	if b := la.getTokenBalanceBySubIndex(LinkToken, 0); b.Sign() != 0 {
+		t.Fatalf("balance of sub 0 got %s, want 0", b)
+	}
+	if b := la.utxoTotalBalance[LinkToken]; b.Cmp(big.NewInt(2e18)) != 0 {
+		t.Fatalf("total balance got %s, want 2e18", b)
+	}
+	outputs, _ := w.ListUnspent(LinkToken, []uint64{0})
+	if len(outputs) != 1 || !outputs[0].Frozen {
+		t.Fatalf("ListUnspent got %v, want the frozen output 0", unspentIndice(outputs))
+	}
+	if indice := w.unspentIndicePerSubaddr(LinkToken); len(indice[0]) != 0 || !equalIndice(indice[1], []uint64{1}) {
+		t.Fatalf("unspentIndicePerSubaddr got %v, want frozen output 0 excluded", indice)
+	}
+	// freezing twice changes nothing
+	if err := w.FreezeOutputs([]uint64{0}); err != nil {
+		t.Fatal(err)
+	}
+	if b := la.utxoTotalBalance[LinkToken]; b.Cmp(big.NewInt(2e18)) != 0 {
+		t.Fatalf("total balance got %s, want 2e18", b)
+	}
+
+	// the frozen flag is saved
+	la2 := newCoinControlAccount(t, la.walletDB)
+	la2.account, la2.mainUTXOAddress = la.account, la.mainUTXOAddress
+	if err := la2.loadTransfers(); err != nil {
+		t.Fatal(err)
+	}
+	if !la2.Transfers[0].Frozen || la2.getTokenBalanceBySubIndex(LinkToken, 0).Sign() != 0 {
+		t.Fatalf("loadTransfers got frozen %v balance %s, want frozen with balance 0", la2.Transfers[0].Frozen, la2.getTokenBalanceBySubIndex(LinkToken, 0))
+	}
+
+	if err := w.ThawOutputs([]uint64{0}); err != nil {
+		t.Fatal(err)
+	}
+	if b := la.getTokenBalanceBySubIndex(LinkToken, 0); b.Cmp(big.NewInt(1e18)) != 0 || la.Transfers[0].Frozen {
+		t.Fatalf("ThawOutputs got frozen %v balance %s, want balance 1e18", la.Transfers[0].Frozen, b)
+	}
+}
+
+func TestSweepNothing(t *testing.T) {
+	w := newCoinControlWallet(t)
+	addr := w.currAccount.account.GetKeys().Address
+
+	if _, err := w.SweepAll(common.HexToAddress("0x2").String(), nil, LinkToken); err != ErrSweepAddrNotSupport {
+		t.Fatalf("SweepAll to eth address got %v, want %v", err, ErrSweepAddrNotSupport)
+	}
+	if _, err := w.SweepBelow(addr, big.NewInt(0), nil, LinkToken); err != ErrOutputMoneyInvalid {
+		t.Fatalf("SweepBelow 0 got %v, want %v", err, ErrOutputMoneyInvalid)
+	}
+	if _, err := w.SweepBelow(addr, big.NewInt(1e18), nil, LinkToken); err != ErrSweepNothing {
+		t.Fatalf("SweepBelow 1e18 got %v, want %v", err, ErrSweepNothing)
+	}
+	if _, err := w.SweepAll(addr, []uint64{2}, LinkToken); err != ErrSweepNothing {
+		t.Fatalf("SweepAll of sub 2 got %v, want %v", err, ErrSweepNothing)
+	}
+	if err := w.FreezeOutputs([]uint64{0, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.SweepAll(addr, nil, LinkToken); err != ErrSweepNothing {
+		t.Fatalf("SweepAll of frozen outputs got %v, want %v", err, ErrSweepNothing)
+	}
+}
+
+func TestCreateUTXOTransactionFromInputs(t *testing.T) {
+	w := newCoinControlWallet(t)
+	if _, err := w.CreateUTXOTransactionFromInputs(common.HexToAddress("0x2"), []uint64{0}, nil, LinkToken, common.EmptyAddress, nil); err != ErrInputsNotSupport {
+		t.Fatalf("CreateUTXOTransactionFromInputs from account got %v, want %v", err, ErrInputsNotSupport)
+	}
+	if _, err := w.CreateUTXOTransactionFromInputs(common.EmptyAddress, nil, nil, LinkToken, common.EmptyAddress, nil); err != ErrInputsEmpty {
+		t.Fatalf("CreateUTXOTransactionFromInputs without inputs got %v, want %v", err, ErrInputsEmpty)
+	}
+}
@@ -0,0 +1,282 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/lianxiangcloud/linkchain/accounts/keystore"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/libs/ser"
+)
+
+var (
+	ErrDBPasswordInvalid = errors.New("wallet db password invalid")
+	ErrDBDecrypt         = errors.New("wallet db decrypt fail")
+)
+
+const (
+	keyDBCrypto   = "dbCrypto"
+	dbCryptoCheck = "linkchain wallet db"
+	dbSaltLen     = 32
+)
+
+// scrypt parameters of new db keys, tests lower them
+var (
+	dbScryptN = keystore.StandardScryptN
+	dbScryptP = keystore.StandardScryptP
+)
+
+// dbCrypto is saved in plaintext, values of the account in walletDB are encrypted
+// by AES-GCM with the key derived from the keystore password
+type dbCrypto struct {
+	ScryptN uint64
+	ScryptP uint64
+	Salt    []byte
+	Check   []byte
+}
+
+func newDBCipher(password string, scryptN, scryptP uint64, salt []byte) (cipher.AEAD, error) {
+	key, err := keystore.DeriveKey(password, salt, int(scryptN), int(scryptP))
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func sealDBValue(aead cipher.AEAD, val []byte) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(val)+aead.Overhead())
+	if _, err := io.ReadFull(crand.Reader, nonce); err != nil {
+		panic("reading from crypto/rand failed: " + err.Error())
+	}
+	return aead.Seal(nonce, nonce, val, nil)
+}
+
+func openDBValue(aead cipher.AEAD, val []byte) ([]byte, error) {
+	if len(val) < aead.NonceSize() {
+		return nil, ErrDBDecrypt
+	}
+	plain, err := aead.Open(nil, val[:aead.NonceSize()], val[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrDBDecrypt
+	}
+	return plain, nil
+}
+
+// newDBCrypto derive a new key from password with a fresh salt
+func newDBCrypto(password string) (*dbCrypto, cipher.AEAD, error) {
+	dc := &dbCrypto{
+		ScryptN: uint64(dbScryptN),
+		ScryptP: uint64(dbScryptP),
+		Salt:    make([]byte, dbSaltLen),
+	}
+	if _, err := io.ReadFull(crand.Reader, dc.Salt); err != nil {
+		return nil, nil, err
+	}
+	aead, err := newDBCipher(password, dc.ScryptN, dc.ScryptP, dc.Salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	dc.Check = sealDBValue(aead, []byte(dbCryptoCheck))
+	return dc, aead, nil
+}
+
+// cipher derive the key of dc from password and check it
+func (dc *dbCrypto) cipher(password string) (cipher.AEAD, error) {
+	aead, err := newDBCipher(password, dc.ScryptN, dc.ScryptP, dc.Salt)
+	if err != nil {
+		return nil, err
+	}
+	check, err := openDBValue(aead, dc.Check)
+	if err != nil || !bytes.Equal(check, []byte(dbCryptoCheck)) {
+		return nil, ErrDBPasswordInvalid
+	}
+	return aead, nil
+}
+
+// dbCrypto, keyed by eth address like multisig
+func (la *LinkAccount) getDBCryptoKey() []byte {
+	return []byte(fmt.Sprintf("%s_%s", la.getEthAddress().String(), keyDBCrypto))
+}
+
+func (la *LinkAccount) loadDBCrypto() (*dbCrypto, error) {
+	val := la.walletDB.Get(la.getDBCryptoKey())
+	if len(val) == 0 {
+		return nil, nil
+	}
+	var dc dbCrypto
+	if err := ser.DecodeBytes(val, &dc); err != nil {
+		la.Logger.Error("loadDBCrypto DecodeBytes fail", "err", err)
+		return nil, err
+	}
+	return &dc, nil
+}
+
+func (la *LinkAccount) saveDBCrypto(b dbm.Batch, dc *dbCrypto) error {
+	val, err := ser.EncodeToBytes(dc)
+	if err != nil {
+		la.Logger.Error("saveDBCrypto EncodeToBytes fail", "err", err)
+		return err
+	}
+	b.Set(la.getDBCryptoKey(), val)
+	return nil
+}
+
+// openDBCrypto set up the cipher of walletDB values. it returns true if the account
+// has values written in plaintext by old wallets, which should be migrated
+func (la *LinkAccount) openDBCrypto(password string) (bool, error) {
+	dc, err := la.loadDBCrypto()
+	if err != nil {
+		return false, err
+	}
+	if dc == nil {
+		return true, nil
+	}
+	aead, err := dc.cipher(password)
+	if err != nil {
+		la.Logger.Error("openDBCrypto fail", "err", err)
+		return false, err
+	}
+	la.dbCipher = aead
+	return false, nil
+}
+
+// dbKeyPrefixes return the prefixes of the walletDB keys of the account, keys are
+// "<eth address>_<name>" or "<utxo address>_<name>"
+func (la *LinkAccount) dbKeyPrefixes() [][]byte {
+	addrs := []string{la.getEthAddress().String(), la.baseUTXOAddress}
+	if la.isMultisig() {
+		addrs = append(addrs, la.multisigAccountBase().GetKeys().Address)
+	}
+	prefixes := make([][]byte, 0, len(addrs))
+	for _, addr := range addrs {
+		prefixes = append(prefixes, []byte(addr+"_"))
+	}
+	return prefixes
+}
+
+// isTxKeysKey report whether key is a tx key of the account, tx keys are
+// "<tx hash>_<utxo address>_txKeys"
+func (la *LinkAccount) isTxKeysKey(key []byte) bool {
+	if len(key) <= common.HashLength || key[common.HashLength] != '_' {
+		return false
+	}
+	name := key[common.HashLength+1:]
+	for _, addr := range []string{la.baseUTXOAddress, la.mainUTXOAddress} {
+		if string(name) == fmt.Sprintf("%s_%s", addr, keyTxKeys) {
+			return true
+		}
+	}
+	return false
+}
+
+// reencryptDB rewrite all values of the account with newCipher, values are read
+// in plaintext if oldCipher is nil
+func (la *LinkAccount) reencryptDB(oldCipher, newCipher cipher.AEAD, dc *dbCrypto) error {
+	cryptoKey := la.getDBCryptoKey()
+	batch := la.walletDB.NewBatch()
+	cnt := 0
+
+	for _, prefix := range la.dbKeyPrefixes() {
+		itr := dbm.IteratePrefix(la.walletDB, prefix)
+		err := la.reencryptKeys(batch, itr, func(key []byte) bool { return !bytes.Equal(key, cryptoKey) }, oldCipher, newCipher, &cnt)
+		if err != nil {
+			return err
+		}
+	}
+	// tx keys are keyed by the tx hash first, so they are looked for in the whole db
+	if err := la.reencryptKeys(batch, la.walletDB.Iterator(nil, nil), la.isTxKeysKey, oldCipher, newCipher, &cnt); err != nil {
+		return err
+	}
+
+	if err := la.saveDBCrypto(batch, dc); err != nil {
+		return err
+	}
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+	la.dbCipher = newCipher
+	la.Logger.Info("reencryptDB", "values", cnt)
+	return nil
+}
+
+// reencryptKeys put the values of the matched keys of itr sealed by newCipher into batch
+func (la *LinkAccount) reencryptKeys(batch dbm.Batch, itr dbm.Iterator, match func(key []byte) bool, oldCipher, newCipher cipher.AEAD, cnt *int) error {
+	defer itr.Close()
+	for ; itr.Valid(); itr.Next() {
+		key := itr.Key()
+		if !match(key) {
+			continue
+		}
+		val := itr.Value()
+		if oldCipher != nil {
+			plain, err := openDBValue(oldCipher, val)
+			if err != nil {
+				la.Logger.Error("reencryptDB decrypt fail", "key", string(key), "err", err)
+				return err
+			}
+			val = plain
+		}
+		batch.Set(append([]byte{}, key...), sealDBValue(newCipher, val))
+		*cnt++
+	}
+	return nil
+}
+
+// migrateDB encrypt the plaintext values written by old wallets
+func (la *LinkAccount) migrateDB(password string) error {
+	dc, aead, err := newDBCrypto(password)
+	if err != nil {
+		return err
+	}
+	return la.reencryptDB(nil, aead, dc)
+}
+
+// ChangePassword re-encrypt walletDB values of the account with the key derived from newPassword
+func (la *LinkAccount) ChangePassword(password string, newPassword string) error {
+	la.lock.Lock()
+	defer la.lock.Unlock()
+
+	dc, err := la.loadDBCrypto()
+	if err != nil {
+		return err
+	}
+	if dc == nil {
+		return ErrDBPasswordInvalid
+	}
+	oldCipher, err := dc.cipher(password)
+	if err != nil {
+		return err
+	}
+	newDC, newCipher, err := newDBCrypto(newPassword)
+	if err != nil {
+		return err
+	}
+	return la.reencryptDB(oldCipher, newCipher, newDC)
+}
+
+// dbGet return the decrypted value of key, nil if not found
+func (la *LinkAccount) dbGet(key []byte) ([]byte, error) {
+	val := la.walletDB.Get(key)
+	if len(val) == 0 || la.dbCipher == nil {
+		return val, nil
+	}
+	return openDBValue(la.dbCipher, val)
+}
+
+// dbSet put the encrypted value into batch
+func (la *LinkAccount) dbSet(b dbm.Batch, key []byte, val []byte) {
+	if la.dbCipher != nil {
+		val = sealDBValue(la.dbCipher, val)
+	}
+	b.Set(key, val)
+}
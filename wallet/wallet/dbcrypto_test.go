@@ -0,0 +1,156 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/accounts/keystore"
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/cryptonote/ringct"
+	lktypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/libs/log"
+)
+
+func TestDBCryptoCipher(t *testing.T) {
+	salt := bytes.Repeat([]byte{1}, dbSaltLen)
+	aead, err := newDBCipher("1234", keystore.LightScryptN, keystore.LightScryptP, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc := &dbCrypto{
+		ScryptN: keystore.LightScryptN,
+		ScryptP: keystore.LightScryptP,
+		Salt:    salt,
+		Check:   sealDBValue(aead, []byte(dbCryptoCheck)),
+	}
+	if _, err := dc.cipher("4321"); err != ErrDBPasswordInvalid {
+		t.Fatalf("cipher with wrong password got %v, want %v", err, ErrDBPasswordInvalid)
+	}
+	aead2, err := dc.cipher("1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	val := []byte("localHeight")
+	sealed := sealDBValue(aead, val)
+	if bytes.Contains(sealed, val) {
+		t.Fatal("sealed value contains plaintext")
+	}
+	opened, err := openDBValue(aead2, sealed)
+	if err != nil || !bytes.Equal(opened, val) {
+		t.Fatalf("openDBValue got %q %v, want %q", opened, err, val)
+	}
+	sealed[len(sealed)-1] ^= 1
+	if _, err := openDBValue(aead2, sealed); err != ErrDBDecrypt {
+		t.Fatalf("openDBValue of modified value got %v, want %v", err, ErrDBDecrypt)
+	}
+}
+
+func TestReencryptDBKeyPrefix(t *testing.T) {
+	ethAddr := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	la := &LinkAccount{
+		Logger:          log.Root(),
+		account:         &AccountBase{EthAddress: ethAddr},
+		walletDB:        dbm.NewMemDB(),
+		baseUTXOAddress: "utxoaddr",
+	}
+	owned := []string{
+		ethAddr.String() + "_localHeight",
+		"utxoaddr_transfers_0",
+	}
+	others := []string{
+		"x_" + ethAddr.String() + "_localHeight",
+		ethAddr.String() + "localHeight",
+		"utxoaddr2_transfers_0",
+		"x_utxoaddr_transfers_0",
+	}
+	val := []byte("value")
+	for _, key := range append(append([]string{}, owned...), others...) {
+		la.walletDB.Set([]byte(key), val)
+	}
+
+	aead, err := newDBCipher("1234", keystore.LightScryptN, keystore.LightScryptP, bytes.Repeat([]byte{1}, dbSaltLen))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc := &dbCrypto{ScryptN: keystore.LightScryptN, ScryptP: keystore.LightScryptP}
+	if err := la.reencryptDB(nil, aead, dc); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range owned {
+		stored := la.walletDB.Get([]byte(key))
+		if bytes.Equal(stored, val) {
+			t.Fatalf("value of %s is not encrypted", key)
+		}
+		if opened, err := openDBValue(aead, stored); err != nil || !bytes.Equal(opened, val) {
+			t.Fatalf("openDBValue of %s got %q %v, want %q", key, opened, err, val)
+		}
+	}
+	for _, key := range others {
+		if stored := la.walletDB.Get([]byte(key)); !bytes.Equal(stored, val) {
+			t.Fatalf("value of %s got %q, want plaintext %q", key, stored, val)
+		}
+	}
+	if la.walletDB.Get(la.getDBCryptoKey()) == nil {
+		t.Fatal("dbCrypto is not saved")
+	}
+}
+
+// useLightDBScrypt lower the scrypt parameters of new db keys
+func useLightDBScrypt() func() {
+	n, p := dbScryptN, dbScryptP
+	dbScryptN, dbScryptP = keystore.LightScryptN, keystore.LightScryptP
+	return func() { dbScryptN, dbScryptP = n, p }
+}
+
+func TestReencryptTxKeys(t *testing.T) {
+	defer useLightDBScrypt()()
+	acc, err := RecoveryKeyToAccount(lktypes.SecretKey(ringct.SkGen()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := acc.GetKeys().Address
+	la := &LinkAccount{
+		Logger:          log.Root(),
+		account:         acc,
+		walletDB:        dbm.NewMemDB(),
+		mainUTXOAddress: addr,
+		baseUTXOAddress: addr,
+	}
+
+	// tx keys of old wallets are saved in plaintext
+	hash := common.HexToHash("0x1")
+	txKey := lktypes.Key(ringct.SkGen())
+	if err := la.saveTxKeys(hash, &txKey); err != nil {
+		t.Fatal(err)
+	}
+	otherKey := append(append(append([]byte{}, hash[:]...), '_'), []byte("other_"+keyTxKeys)...)
+	la.walletDB.Set(otherKey, txKey[:])
+
+	checkTxKey := func(step string) {
+		got, err := la.GetTxKey(&hash)
+		if err != nil || *got != txKey {
+			t.Fatalf("GetTxKey after %s got %v %v, want %x", step, got, err, txKey)
+		}
+	}
+	if err := la.migrateDB("1234"); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(la.walletDB.Get(append(append(hash[:], '_'), la.getTxKeysKey()...)), txKey[:]) {
+		t.Fatal("tx key is not encrypted by migrateDB")
+	}
+	if !bytes.Equal(la.walletDB.Get(otherKey), txKey[:]) {
+		t.Fatal("tx key of other account is encrypted by migrateDB")
+	}
+	checkTxKey("migrateDB")
+
+	if err := la.ChangePassword("1234", "4321"); err != nil {
+		t.Fatal(err)
+	}
+	checkTxKey("ChangePassword")
+	if _, err := la.openDBCrypto("1234"); err != ErrDBPasswordInvalid {
+		t.Fatalf("old password got %v, want %v", err, ErrDBPasswordInvalid)
+	}
+}
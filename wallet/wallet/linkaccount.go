@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"crypto/cipher"
 	"fmt"
 	"math/big"
 	"sync"
@@ -41,6 +42,7 @@ type LinkAccount struct {
 	gOutIndex            map[common.Address]uint64     //key:tokenid
 	txKeys               map[common.Hash]lkctypes.Key  //key:txHash,value tx_key
 	mainUTXOAddress      string
+	baseUTXOAddress      string // utxo address of the keystore, differs from mainUTXOAddress for multisig
 	walletOpen           bool
 	autoRefresh          bool
 	account              *AccountBase
@@ -49,10 +51,13 @@ type LinkAccount struct {
 	stop                 chan int
 	walletDB             dbm.DB
 	refreshBlockInterval time.Duration
+	multisig             *multisigState
+	subaddrLookahead     uint64
+	dbCipher             cipher.AEAD
 }
 
 // NewLinkAccount return a LinkAccount
-func NewLinkAccount(walletDB dbm.DB, logger log.Logger, keystoreFile string, password string) (*LinkAccount, error) {
+func NewLinkAccount(walletDB dbm.DB, logger log.Logger, keystoreFile string, password string, subaddrLookahead uint64) (*LinkAccount, error) {
 	la := &LinkAccount{
 		remoteHeight:         0,
 		localHeight:          0,
@@ -68,6 +73,7 @@ func NewLinkAccount(walletDB dbm.DB, logger log.Logger, keystoreFile string, pas
 		stop:                 make(chan int, 1),
 		walletDB:             walletDB,
 		refreshBlockInterval: defaultRefreshBlockInterval,
+		subaddrLookahead:     subaddrLookahead,
 	}
 
 	la.account = NewUTXOAccount(keystoreFile, password)
@@ -78,27 +84,29 @@ func NewLinkAccount(walletDB dbm.DB, logger log.Logger, keystoreFile string, pas
 	la.Logger = logger.With("module", logModule)
 
 	la.mainUTXOAddress = la.account.GetKeys().Address
+	la.baseUTXOAddress = la.mainUTXOAddress
 	la.setTokenBalanceBySubIndex(LinkToken, 0, big.NewInt(0))
 
-	err := la.loadLocalHeight()
+	plainDB, err := la.openDBCrypto(password)
 	if err != nil {
 		return nil, err
 	}
-
-	err = la.loadGOutIndex()
+	err = la.loadMultisig()
 	if err != nil {
 		return nil, err
 	}
-
-	accSubCnt, err := la.loadAccountSubCnt()
-	if err != nil {
-		return nil, err
+	if plainDB {
+		err = la.migrateDB(password)
+		if err != nil {
+			la.Logger.Error("NewLinkAccount migrateDB fail", "err", err)
+			return nil, err
+		}
 	}
-	err = la.account.CreateSubAccountN(accSubCnt)
-	if err != nil {
-		return nil, err
+	if la.isMultisig() {
+		err = la.switchToMultisig()
+	} else {
+		err = la.loadAccountState()
 	}
-	err = la.loadTransfers()
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +123,34 @@ func NewLinkAccount(walletDB dbm.DB, logger log.Logger, keystoreFile string, pas
 	return la, nil
 }
 
+// loadAccountState load wallet state of the utxo account from walletDB
+func (la *LinkAccount) loadAccountState() error {
+	err := la.loadLocalHeight()
+	if err != nil {
+		return err
+	}
+
+	err = la.loadGOutIndex()
+	if err != nil {
+		return err
+	}
+
+	accSubCnt, err := la.loadAccountSubCnt()
+	if err != nil {
+		return err
+	}
+	err = la.account.CreateSubAccountN(accSubCnt)
+	if err != nil {
+		return err
+	}
+	err = la.loadSubaddrLookahead()
+	if err != nil {
+		return err
+	}
+	la.account.ExpandSubAccountLookahead(la.subaddrLookahead)
+	return la.loadTransfers()
+}
+
 // OnStart starts the Wallet. It implements cmn.Service.
 func (la *LinkAccount) OnStart() error {
 	la.Logger.Info("starting LinkAccount")
@@ -327,6 +363,9 @@ func (la *LinkAccount) processNewTransaction(tx *tctypes.UTXOTransaction, height
 				la.Logger.Error("IsOutputBelongToAccount fail", "ro.OTAddr", ro.OTAddr, "derivationKey", derivationKey, "recIdx", recIdx, "err", err)
 				continue
 			}
+			if subaddrIndex >= uint64(len(la.account.Keys)) {
+				la.expandSubAccount(subaddrIndex)
+			}
 			needSaveTx = true
 			la.Logger.Debug("processNewTransaction", "realDeriKey", realDeriKey)
 			var realRKey lkctypes.PublicKey
@@ -366,8 +405,13 @@ func (la *LinkAccount) processNewTransaction(tx *tctypes.UTXOTransaction, height
 			uod.Frozen = false
 			uod.SpentHeight = uint64(0)
 			uod.KeyImage = lkctypes.Key(keyImage)
+			if la.isMultisig() {
+				// multisig key image is made from partial key images of signers
+				uod.KeyImage = lkctypes.Key{}
+			}
 			uod.SubAddrIndex = subaddrIndex
 			uod.RKey = realRKey
+			uod.TokenID = tx.TokenID
 
 			// amount and mask
 			if height == 0 && tx.Extra[0] == byte(1) {
@@ -406,7 +450,9 @@ func (la *LinkAccount) processNewTransaction(tx *tctypes.UTXOTransaction, height
 			la.Transfers = append(la.Transfers, &uod)
 			tid := len(la.Transfers) - 1
 
-			la.keyImages[uod.KeyImage] = tid
+			if uod.KeyImage != (lkctypes.Key{}) {
+				la.keyImages[uod.KeyImage] = tid
+			}
 			tids = append(tids, tid)
 			la.Logger.Info("processNewTransaction output", "KeyImage", uod.KeyImage, "subaddrIndex", subaddrIndex, "tx.RKey", tx.RKey, "uod.Amount", uod.Amount.String())
 
@@ -437,7 +483,10 @@ func (la *LinkAccount) processNewTransaction(tx *tctypes.UTXOTransaction, height
 
 				txMoneySpentInIns = new(big.Int).Add(txMoneySpentInIns, amount)
 				tids = append(tids, iTransfer)
-				la.updateBalance(tx.TokenID, uod.SubAddrIndex, false, amount)
+				// frozen output is not counted in balance
+				if !uod.Frozen {
+					la.updateBalance(tx.TokenID, uod.SubAddrIndex, false, amount)
+				}
 				la.Logger.Info("processNewTransaction", "utxoTotalBalance", la.utxoTotalBalance, "iTransfer", iTransfer, "amount", amount.String())
 			}
 
@@ -516,11 +565,42 @@ func (la *LinkAccount) CreateSubAccount(maxSub uint64) error {
 		if la.saveAccountSubCnt(batch) != nil || batch.Commit() != nil {
 			return fmt.Errorf("saveAccountSubCnt fail")
 		}
+		la.account.ExpandSubAccountLookahead(la.subaddrLookahead)
 		la.Logger.Debug("CreateSubAccount", "account", la.account.String())
 	}
 	return nil
 }
 
+// expandSubAccount create sub accounts up to the found lookahead subaddress and move the lookahead window,
+// the new sub account count is saved with the block
+func (la *LinkAccount) expandSubAccount(subaddrIndex uint64) {
+	for uint64(len(la.account.Keys)) <= subaddrIndex {
+		_, idx, err := la.account.CreateSubAccount()
+		if err != nil {
+			la.Logger.Error("expandSubAccount CreateSubAccount fail", "subaddrIndex", subaddrIndex, "err", err)
+			return
+		}
+		la.setTokenBalanceBySubIndex(LinkToken, idx, big.NewInt(0))
+	}
+	la.account.ExpandSubAccountLookahead(la.subaddrLookahead)
+	la.Logger.Info("expandSubAccount", "subaddrIndex", subaddrIndex, "lookaheadIdx", la.account.LookaheadIdx)
+}
+
+// SetSubaddressLookahead set the number of subaddresses after the created ones looked for while scanning
+func (la *LinkAccount) SetSubaddressLookahead(lookahead uint64) error {
+	la.lock.Lock()
+	defer la.lock.Unlock()
+
+	la.subaddrLookahead = lookahead
+	la.account.ExpandSubAccountLookahead(lookahead)
+
+	batch := la.walletDB.NewBatch()
+	if la.saveSubaddrLookahead(batch) != nil || batch.Commit() != nil {
+		return fmt.Errorf("saveSubaddrLookahead fail")
+	}
+	return nil
+}
+
 // AutoRefreshBlockchain set autoRefresh
 func (la *LinkAccount) AutoRefreshBlockchain(autoRefresh bool) error {
 	// if !la.walletOpen {
@@ -643,6 +723,14 @@ func (la *LinkAccount) GetTxKey(hash *common.Hash) (*lkctypes.Key, error) {
 
 	for ; itr.Valid(); itr.Next() {
 		v := itr.Value()
+		if la.dbCipher != nil {
+			// tx key of other accounts can not be decrypted
+			plain, err := openDBValue(la.dbCipher, v)
+			if err != nil {
+				continue
+			}
+			v = plain
+		}
 		var key lkctypes.Key
 		copy(key[:], v)
 		return &key, nil
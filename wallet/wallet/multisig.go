@@ -0,0 +1,1291 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"sort"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/cryptonote/ringct"
+	lkctypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
+	"github.com/lianxiangcloud/linkchain/libs/cryptonote/xcrypto"
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
+	"github.com/lianxiangcloud/linkchain/libs/ser"
+	tctypes "github.com/lianxiangcloud/linkchain/types"
+	wtypes "github.com/lianxiangcloud/linkchain/wallet/types"
+)
+
+// M-of-N multisig utxo account.
+//
+// Every signer i holds a base secret b_i (B_i = b_i*G). The spend secret of the
+// shared account is split into one key k_T per subset T of N-M+1 signers, any M
+// signers together know all of them. k_T is built in rounds by DH exchanges:
+// k_{i,j} = H(b_i*B_j), k_{U+j} = H(k_U*B_j) where j is the greatest signer in
+// the subset. The shared spend public key is the sum of all K_T = k_T*G and the
+// shared view secret key is the sum of the view key shares of all signers.
+const (
+	multisigInfoHeader   = "MultisigV1"
+	multisigKeysHeader   = "MultisigxV1"
+	multisigExportHeader = "MultisigExportV1"
+	multisigTxSetHeader  = "MultisigTxSetV1"
+
+	multisigMaxSigners = 16
+)
+
+var (
+	ErrMultisigNotPrepared        = errors.New("multisig not prepared")
+	ErrMultisigAlreadyReady       = errors.New("wallet is already multisig")
+	ErrMultisigNotReady           = errors.New("wallet is not multisig or key exchange not finished")
+	ErrMultisigInfoInvalid        = errors.New("multisig info invalid")
+	ErrMultisigThresholdInvalid   = errors.New("multisig threshold invalid")
+	ErrMultisigSignerUnknown      = errors.New("multisig signer unknown")
+	ErrMultisigKeysNotMatch       = errors.New("multisig keys from signers not match")
+	ErrMultisigKeysMissing        = errors.New("multisig keys missing")
+	ErrMultisigKeyImageInvalid    = errors.New("multisig partial key image invalid")
+	ErrMultisigNotEnoughSigners   = errors.New("not enough multisig info imported from signers")
+	ErrMultisigNonceNotFound      = errors.New("multisig nonce not found, export multisig info again")
+	ErrMultisigTxSetInvalid       = errors.New("multisig tx set invalid")
+	ErrMultisigChallengeInvalid   = errors.New("multisig tx challenge not match the ring signatures")
+	ErrMultisigAlreadySigned      = errors.New("multisig tx already signed by this wallet")
+	ErrMultisigNotSigner          = errors.New("wallet is not a signer of multisig tx")
+	ErrMultisigTxNotComplete      = errors.New("multisig tx not fully signed")
+	ErrMultisigNotSupport         = errors.New("not support by multisig wallet")
+	ErrMultisigContractNotSupport = errors.New("multisig tx not support contract dest")
+)
+
+// multisigSubsetKey is the key of a signer subset, Subset is a bitmap of signer indexes
+type multisigSubsetKey struct {
+	Subset uint64
+	Key    lkctypes.Key
+}
+
+type multisigNonce struct {
+	OTAddr lkctypes.Key
+	SecKey lkctypes.Key
+}
+
+// multisigPartialKeyImage is the part k_T*Hp(P) of the key image of output P, with
+// the proof (C, S) that k_T is the secret of the public subset key K_T
+type multisigPartialKeyImage struct {
+	Subset uint64
+	Key    lkctypes.Key
+	C      lkctypes.Key
+	S      lkctypes.Key
+}
+
+// multisigOutputInfo is the partial key images and signing nonce of a signer for an output
+type multisigOutputInfo struct {
+	OTAddr           lkctypes.Key
+	PartialKeyImages []multisigPartialKeyImage
+	L                lkctypes.Key
+	R                lkctypes.Key
+}
+
+// multisigSignerInfo is exported by a signer and imported by the others before signing
+type multisigSignerInfo struct {
+	Signer   lkctypes.Key
+	Outputs  []multisigOutputInfo
+	KeyImage lkctypes.Key
+	Sig      lkctypes.Signature
+}
+
+// multisigKeyInfo is exchanged between signers while making the multisig account
+type multisigKeyInfo struct {
+	Signer   lkctypes.Key
+	ViewSKey lkctypes.Key
+	PubKeys  []multisigSubsetKey
+	KeyImage lkctypes.Key
+	Sig      lkctypes.Signature
+}
+
+type multisigState struct {
+	Threshold     uint64
+	Total         uint64
+	Ready         bool
+	SecKey        lkctypes.Key
+	ViewSKey      lkctypes.Key
+	Signers       []lkctypes.Key
+	Self          uint64
+	SubsetSize    uint64
+	SubsetKeys    []multisigSubsetKey
+	PubSubsetKeys []multisigSubsetKey // K_T of all subsets, set when ready
+	SpendPubKey   lkctypes.Key
+	Nonces        []multisigNonce
+	Infos         []multisigSignerInfo
+}
+
+type multisigTxInput struct {
+	OTAddr    lkctypes.Key
+	RingIndex uint64
+	C         lkctypes.Key
+}
+
+type multisigTxDest struct {
+	Addr   string
+	Amount *big.Int
+}
+
+// multisigTxSet is passed between signers until all of them signed the tx
+type multisigTxSet struct {
+	Tx         *tctypes.UTXOTransaction
+	Inputs     []multisigTxInput
+	Dests      []multisigTxDest
+	SigningSet uint64
+	Signed     uint64
+}
+
+// curveOrder is the order l of the ed25519 base point
+var curveOrder, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+
+func scalarToBig(k lkctypes.Key) *big.Int {
+	var be [32]byte
+	for i := 0; i < 32; i++ {
+		be[i] = k[31-i]
+	}
+	return new(big.Int).SetBytes(be[:])
+}
+
+func bigToScalar(b *big.Int) lkctypes.Key {
+	var k lkctypes.Key
+	be := new(big.Int).Mod(b, curveOrder).Bytes()
+	for i := 0; i < len(be); i++ {
+		k[i] = be[len(be)-1-i]
+	}
+	return k
+}
+
+// scMulSub return k - c*x mod l
+func scMulSub(c, x, k lkctypes.Key) lkctypes.Key {
+	cx := new(big.Int).Mul(scalarToBig(c), scalarToBig(x))
+	return bigToScalar(new(big.Int).Sub(scalarToBig(k), cx))
+}
+
+func scAdd(a, b lkctypes.Key) lkctypes.Key {
+	return ringct.ScAdd(lkctypes.EcScalar(a), lkctypes.EcScalar(b))
+}
+
+// subKeys return a - b, b is negated by multiplying with l-1
+func subKeys(a, b lkctypes.Key) (lkctypes.Key, error) {
+	negB, err := ringct.ScalarmultKey(b, bigToScalar(new(big.Int).Sub(curveOrder, big.NewInt(1))))
+	if err != nil {
+		return lkctypes.Key{}, err
+	}
+	return ringct.AddKeys(a, negB)
+}
+
+// mlsagChallenge walk the MLSAG mg of ring from its first challenge Cc through the
+// members before index as MLSAG verification does, and return the challenge of
+// member index. The second key of a member is its commitment minus pseudoOut.
+func mlsagChallenge(message lkctypes.Key, ring lkctypes.CtkeyV, pseudoOut, keyImage lkctypes.Key, mg *lkctypes.MgSig, index int) (lkctypes.Key, error) {
+	c := mg.Cc
+	for j := 0; j < index; j++ {
+		if len(mg.Ss[j]) != 2 {
+			return lkctypes.Key{}, ErrMultisigTxSetInvalid
+		}
+		ss, pk := mg.Ss[j], ring[j].Dest
+		l, err := ringct.AddKeys2(ss[0], c, pk)
+		if err != nil {
+			return lkctypes.Key{}, err
+		}
+		sHp, err := xcrypto.GenerateKeyImage(lkctypes.PublicKey(pk), lkctypes.SecretKey(ss[0]))
+		if err != nil {
+			return lkctypes.Key{}, err
+		}
+		cI, err := ringct.ScalarmultKey(keyImage, c)
+		if err != nil {
+			return lkctypes.Key{}, err
+		}
+		r, err := ringct.AddKeys(lkctypes.Key(sHp), cI)
+		if err != nil {
+			return lkctypes.Key{}, err
+		}
+		pkCommit, err := subKeys(ring[j].Mask, pseudoOut)
+		if err != nil {
+			return lkctypes.Key{}, err
+		}
+		lCommit, err := ringct.AddKeys2(ss[1], c, pkCommit)
+		if err != nil {
+			return lkctypes.Key{}, err
+		}
+		c = xcrypto.HashToScalar(lkctypes.KeyV{message, pk, l, r, pkCommit, lCommit})
+	}
+	return c, nil
+}
+
+// subsetsOfSize return all signer subsets of size n, optionally only the ones contain signer self
+func subsetsOfSize(total uint64, n uint64, self int) []uint64 {
+	subsets := make([]uint64, 0)
+	for mask := uint64(1); mask < uint64(1)<<total; mask++ {
+		if uint64(bits.OnesCount64(mask)) != n {
+			continue
+		}
+		if self >= 0 && mask&(uint64(1)<<uint(self)) == 0 {
+			continue
+		}
+		subsets = append(subsets, mask)
+	}
+	return subsets
+}
+
+// subsetOwner return the signer in charge of subset key while signing with signers in signingSet
+func subsetOwner(subset uint64, signingSet uint64) int {
+	return bits.TrailingZeros64(subset & signingSet)
+}
+
+func findSubsetKey(keys []multisigSubsetKey, subset uint64) (lkctypes.Key, bool) {
+	for _, k := range keys {
+		if k.Subset == subset {
+			return k.Key, true
+		}
+	}
+	return lkctypes.Key{}, false
+}
+
+// scalarOne is the scalar 1, the key image of a point with it is Hp(point)
+var scalarOne = lkctypes.Key{1}
+
+// provePartialKeyImage return the partial key image secKey*Hp(otaddr) with a proof
+// that log_G(secKey*G) == log_Hp(otaddr)(secKey*Hp(otaddr))
+func provePartialKeyImage(otaddr lkctypes.Key, subset uint64, secKey lkctypes.Key) (multisigPartialKeyImage, error) {
+	pki, err := xcrypto.GenerateKeyImage(lkctypes.PublicKey(otaddr), lkctypes.SecretKey(secKey))
+	if err != nil {
+		return multisigPartialKeyImage{}, err
+	}
+	nonce := ringct.SkGen()
+	r, err := xcrypto.GenerateKeyImage(lkctypes.PublicKey(otaddr), lkctypes.SecretKey(nonce))
+	if err != nil {
+		return multisigPartialKeyImage{}, err
+	}
+	c := xcrypto.HashToScalar(lkctypes.KeyV{otaddr, ringct.ScalarmultBase(secKey), lkctypes.Key(pki), ringct.ScalarmultBase(nonce), lkctypes.Key(r)})
+	return multisigPartialKeyImage{Subset: subset, Key: lkctypes.Key(pki), C: c, S: scMulSub(c, secKey, nonce)}, nil
+}
+
+// checkPartialKeyImage verify the partial key image of otaddr is made with the secret of pubKey
+func checkPartialKeyImage(otaddr lkctypes.Key, pubKey lkctypes.Key, pki *multisigPartialKeyImage) bool {
+	// s*G + c*K_T and s*Hp(P) + c*pki are the nonce commitments hashed into c
+	l, err := ringct.AddKeys2(pki.S, pki.C, pubKey)
+	if err != nil {
+		return false
+	}
+	hp, err := xcrypto.GenerateKeyImage(lkctypes.PublicKey(otaddr), lkctypes.SecretKey(scalarOne))
+	if err != nil {
+		return false
+	}
+	sHp, err := ringct.ScalarmultKey(lkctypes.Key(hp), pki.S)
+	if err != nil {
+		return false
+	}
+	cKi, err := ringct.ScalarmultKey(pki.Key, pki.C)
+	if err != nil {
+		return false
+	}
+	r, err := ringct.AddKeys(sHp, cKi)
+	if err != nil {
+		return false
+	}
+	return xcrypto.HashToScalar(lkctypes.KeyV{otaddr, pubKey, pki.Key, l, r}) == pki.C
+}
+
+// checkSignerInfo verify the partial key images exported by signer idx are of the
+// subsets it belongs to and made with their secrets
+func (ms *multisigState) checkSignerInfo(idx int, info *multisigSignerInfo) error {
+	for _, out := range info.Outputs {
+		for i := range out.PartialKeyImages {
+			pki := &out.PartialKeyImages[i]
+			if pki.Subset&(uint64(1)<<uint(idx)) == 0 {
+				return ErrMultisigKeyImageInvalid
+			}
+			pubKey, ok := findSubsetKey(ms.PubSubsetKeys, pki.Subset)
+			if !ok || !checkPartialKeyImage(out.OTAddr, pubKey, pki) {
+				return ErrMultisigKeyImageInvalid
+			}
+		}
+	}
+	return nil
+}
+
+// signMultisigMessage sign data hash with the signer base secret
+func signMultisigMessage(secKey lkctypes.Key, data interface{}) (lkctypes.Key, *lkctypes.Signature, error) {
+	pubKey := ringct.ScalarmultBase(secKey)
+	keyImage, err := xcrypto.GenerateKeyImage(lkctypes.PublicKey(pubKey), lkctypes.SecretKey(secKey))
+	if err != nil {
+		return lkctypes.Key{}, nil, err
+	}
+	bz, err := ser.EncodeToBytes(data)
+	if err != nil {
+		return lkctypes.Key{}, nil, err
+	}
+	sig, err := xcrypto.GenerateRingSignature(xcrypto.FastHash(bz), keyImage, []lkctypes.PublicKey{lkctypes.PublicKey(pubKey)}, lkctypes.SecretKey(secKey), 0)
+	if err != nil {
+		return lkctypes.Key{}, nil, err
+	}
+	return lkctypes.Key(keyImage), sig, nil
+}
+
+func checkMultisigMessage(signer lkctypes.Key, keyImage lkctypes.Key, sig *lkctypes.Signature, data interface{}) bool {
+	bz, err := ser.EncodeToBytes(data)
+	if err != nil {
+		return false
+	}
+	return xcrypto.CheckRingSignature(xcrypto.FastHash(bz), lkctypes.KeyImage(keyImage), []lkctypes.PublicKey{lkctypes.PublicKey(signer)}, sig)
+}
+
+func (ms *multisigState) encodeKeyInfo(header string, viewSKey lkctypes.Key, pubKeys []multisigSubsetKey) (string, error) {
+	info := multisigKeyInfo{
+		Signer:   ringct.ScalarmultBase(ms.SecKey),
+		ViewSKey: viewSKey,
+		PubKeys:  pubKeys,
+	}
+	keyImage, sig, err := signMultisigMessage(ms.SecKey, []interface{}{header, info.Signer, info.ViewSKey, info.PubKeys})
+	if err != nil {
+		return "", err
+	}
+	info.KeyImage = keyImage
+	info.Sig = *sig
+	return encodeProof(header, &info)
+}
+
+func decodeKeyInfos(header string, strs []string) ([]*multisigKeyInfo, error) {
+	infos := make([]*multisigKeyInfo, 0, len(strs))
+	for _, str := range strs {
+		var info multisigKeyInfo
+		if err := decodeProof(header, str, &info); err != nil {
+			return nil, ErrMultisigInfoInvalid
+		}
+		if !checkMultisigMessage(info.Signer, info.KeyImage, &info.Sig, []interface{}{header, info.Signer, info.ViewSKey, info.PubKeys}) {
+			return nil, ErrMultisigInfoInvalid
+		}
+		infos = append(infos, &info)
+	}
+	return infos, nil
+}
+
+func (ms *multisigState) signerIndex(signer lkctypes.Key) (int, bool) {
+	for i, s := range ms.Signers {
+		if s == signer {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// publicSubsetKeys return K_T of the current subset keys
+func (ms *multisigState) publicSubsetKeys() []multisigSubsetKey {
+	pubKeys := make([]multisigSubsetKey, len(ms.SubsetKeys))
+	for i, k := range ms.SubsetKeys {
+		pubKeys[i] = multisigSubsetKey{Subset: k.Subset, Key: ringct.ScalarmultBase(k.Key)}
+	}
+	return pubKeys
+}
+
+// spendSubsetSize return the size of signer subsets of the final spend keys
+func (ms *multisigState) spendSubsetSize() uint64 {
+	return ms.Total - ms.Threshold + 1
+}
+
+// nextSubsetKeys compute the subset keys one signer larger from the public keys of current subsets
+func (ms *multisigState) nextSubsetKeys(pubKeys map[uint64]lkctypes.Key) error {
+	next := make([]multisigSubsetKey, 0)
+	for _, subset := range subsetsOfSize(ms.Total, ms.SubsetSize+1, int(ms.Self)) {
+		top := uint(bits.Len64(subset) - 1)
+		rest := subset &^ (uint64(1) << top)
+		var (
+			dh  lkctypes.Key
+			err error
+		)
+		if uint64(top) == ms.Self {
+			pubKey, ok := pubKeys[rest]
+			if !ok {
+				return ErrMultisigKeysMissing
+			}
+			dh, err = ringct.ScalarmultKey(pubKey, ms.SecKey)
+		} else {
+			secKey, ok := findSubsetKey(ms.SubsetKeys, rest)
+			if !ok {
+				return ErrMultisigKeysMissing
+			}
+			dh, err = ringct.ScalarmultKey(ms.Signers[top], secKey)
+		}
+		if err != nil {
+			return err
+		}
+		next = append(next, multisigSubsetKey{Subset: subset, Key: xcrypto.HashToScalar(lkctypes.KeyV{dh})})
+	}
+	ms.SubsetKeys = next
+	ms.SubsetSize++
+	return nil
+}
+
+// outputSpendSecret return the part of the spend secret signer self provides when signing with signingSet
+func (ms *multisigState) outputSpendSecret(signingSet uint64) lkctypes.Key {
+	secKey := lkctypes.Key{}
+	for _, k := range ms.SubsetKeys {
+		if subsetOwner(k.Subset, signingSet) == int(ms.Self) {
+			secKey = scAdd(secKey, k.Key)
+		}
+	}
+	return secKey
+}
+
+func (ms *multisigState) takeNonce(otaddr lkctypes.Key) (lkctypes.Key, bool) {
+	for i, n := range ms.Nonces {
+		if n.OTAddr == otaddr {
+			ms.Nonces = append(ms.Nonces[:i], ms.Nonces[i+1:]...)
+			return n.SecKey, true
+		}
+	}
+	return lkctypes.Key{}, false
+}
+
+func (ms *multisigState) getNonce(otaddr lkctypes.Key) (lkctypes.Key, bool) {
+	for _, n := range ms.Nonces {
+		if n.OTAddr == otaddr {
+			return n.SecKey, true
+		}
+	}
+	return lkctypes.Key{}, false
+}
+
+func (info *multisigSignerInfo) findOutput(otaddr lkctypes.Key) (*multisigOutputInfo, int) {
+	for i := range info.Outputs {
+		if info.Outputs[i].OTAddr == otaddr {
+			return &info.Outputs[i], i
+		}
+	}
+	return nil, -1
+}
+
+func (la *LinkAccount) isMultisig() bool {
+	return la.multisig != nil && la.multisig.Ready
+}
+
+// multisigAccountBase return the utxo account of the shared multisig keys
+func (la *LinkAccount) multisigAccountBase() *AccountBase {
+	ms := la.multisig
+	acc := lkctypes.AccountKey{
+		Addr: lkctypes.AccountAddress{
+			SpendPublicKey: lkctypes.PublicKey(ms.SpendPubKey),
+			ViewPublicKey:  lkctypes.PublicKey(ringct.ScalarmultBase(ms.ViewSKey)),
+		},
+		ViewSKey: lkctypes.SecretKey(ms.ViewSKey),
+		SubIdx:   uint64(0),
+	}
+	acc.Address = AddressToStr(&acc, uint64(0))
+	ab := &AccountBase{
+		KeyIndex:          make(map[lkctypes.PublicKey]uint64),
+		CreationTimestamp: la.account.CreationTimestamp,
+		EthAddress:        la.account.EthAddress,
+	}
+	ab.Keys = append(ab.Keys, &acc)
+	ab.KeyIndex[acc.Addr.SpendPublicKey] = 0
+	ab.CurrIdx = uint64(0)
+	return ab
+}
+
+// switchToMultisig replace the account keys with the shared multisig keys
+// and reload the wallet state stored under the multisig address
+func (la *LinkAccount) switchToMultisig() error {
+	la.account = la.multisigAccountBase()
+	la.mainUTXOAddress = la.account.GetKeys().Address
+
+	la.localHeight = 0
+	la.AccBalance = make(map[common.Address]balanceMap)
+	la.utxoTotalBalance = make(map[common.Address]*big.Int)
+	la.gOutIndex = make(map[common.Address]uint64)
+	la.keyImages = make(map[lkctypes.Key]int)
+	la.Transfers = make(transferContainer, 0)
+	la.setTokenBalanceBySubIndex(LinkToken, 0, big.NewInt(0))
+	return la.loadAccountState()
+}
+
+// PrepareMultisig generate the base multisig key and return the info for other signers
+func (la *LinkAccount) PrepareMultisig() (string, error) {
+	la.lock.Lock()
+	defer la.lock.Unlock()
+
+	if la.isMultisig() {
+		return "", ErrMultisigAlreadyReady
+	}
+	keys := la.account.GetKeys()
+	secKey := xcrypto.HashToScalar(lkctypes.KeyV{lkctypes.Key(keys.SpendSKey), lkctypes.Key(keys.ViewSKey)})
+	ms := &multisigState{
+		SecKey:   secKey,
+		ViewSKey: xcrypto.HashToScalar(lkctypes.KeyV{lkctypes.Key(keys.ViewSKey), secKey}),
+	}
+	info, err := ms.encodeKeyInfo(multisigInfoHeader, ms.ViewSKey, nil)
+	if err != nil {
+		return "", err
+	}
+	la.multisig = ms
+	if err := la.saveMultisig(); err != nil {
+		return "", err
+	}
+	return info, nil
+}
+
+// MakeMultisig set up a threshold-of-N multisig account with the infos of the other N-1 signers,
+// return the address if ready, or the info for the next key exchange round
+func (la *LinkAccount) MakeMultisig(threshold uint64, infos []string) (*wtypes.MakeMultisigRet, error) {
+	la.lock.Lock()
+	defer la.lock.Unlock()
+
+	if la.multisig == nil || len(la.multisig.Signers) != 0 {
+		if la.isMultisig() {
+			return nil, ErrMultisigAlreadyReady
+		}
+		return nil, ErrMultisigNotPrepared
+	}
+	ms := la.multisig
+	keyInfos, err := decodeKeyInfos(multisigInfoHeader, infos)
+	if err != nil {
+		return nil, err
+	}
+	self := ringct.ScalarmultBase(ms.SecKey)
+	signers := []lkctypes.Key{self}
+	viewSKey := ms.ViewSKey
+	seen := map[lkctypes.Key]bool{self: true}
+	for _, info := range keyInfos {
+		if seen[info.Signer] {
+			continue
+		}
+		seen[info.Signer] = true
+		signers = append(signers, info.Signer)
+		viewSKey = scAdd(viewSKey, info.ViewSKey)
+	}
+	total := uint64(len(signers))
+	if total < 2 || total > multisigMaxSigners {
+		return nil, ErrMultisigInfoInvalid
+	}
+	if threshold < 2 || threshold > total {
+		return nil, ErrMultisigThresholdInvalid
+	}
+	sort.Slice(signers, func(i, j int) bool { return bytes.Compare(signers[i][:], signers[j][:]) < 0 })
+
+	newMs := *ms
+	newMs.Threshold = threshold
+	newMs.Total = total
+	newMs.Signers = signers
+	newMs.ViewSKey = viewSKey
+	idx, _ := newMs.signerIndex(self)
+	newMs.Self = uint64(idx)
+	newMs.SubsetSize = 1
+	newMs.SubsetKeys = []multisigSubsetKey{{Subset: uint64(1) << uint(idx), Key: ms.SecKey}}
+
+	if newMs.spendSubsetSize() == 1 {
+		pubKeys := make([]multisigSubsetKey, total)
+		for i, s := range signers {
+			pubKeys[i] = multisigSubsetKey{Subset: uint64(1) << uint(i), Key: s}
+		}
+		return la.finalizeMultisig(&newMs, pubKeys)
+	}
+
+	pubKeys := make(map[uint64]lkctypes.Key)
+	for i, s := range signers {
+		pubKeys[uint64(1)<<uint(i)] = s
+	}
+	if err := newMs.nextSubsetKeys(pubKeys); err != nil {
+		return nil, err
+	}
+	info, err := newMs.encodeKeyInfo(multisigKeysHeader, lkctypes.Key{}, newMs.publicSubsetKeys())
+	if err != nil {
+		return nil, err
+	}
+	la.multisig = &newMs
+	if err := la.saveMultisig(); err != nil {
+		return nil, err
+	}
+	return &wtypes.MakeMultisigRet{MultisigInfo: info}, nil
+}
+
+// ExchangeMultisigKeys run a key exchange round with the infos of the other signers
+func (la *LinkAccount) ExchangeMultisigKeys(infos []string) (*wtypes.MakeMultisigRet, error) {
+	la.lock.Lock()
+	defer la.lock.Unlock()
+
+	if la.isMultisig() {
+		return nil, ErrMultisigAlreadyReady
+	}
+	if la.multisig == nil || len(la.multisig.Signers) == 0 {
+		return nil, ErrMultisigNotPrepared
+	}
+	ms := *la.multisig
+	keyInfos, err := decodeKeyInfos(multisigKeysHeader, infos)
+	if err != nil {
+		return nil, err
+	}
+	pubKeys := make(map[uint64]lkctypes.Key)
+	for _, k := range ms.publicSubsetKeys() {
+		pubKeys[k.Subset] = k.Key
+	}
+	for _, info := range keyInfos {
+		idx, ok := ms.signerIndex(info.Signer)
+		if !ok {
+			return nil, ErrMultisigSignerUnknown
+		}
+		for _, k := range info.PubKeys {
+			if k.Subset&(uint64(1)<<uint(idx)) == 0 || uint64(bits.OnesCount64(k.Subset)) != ms.SubsetSize ||
+				k.Subset >= uint64(1)<<ms.Total {
+				return nil, ErrMultisigInfoInvalid
+			}
+			if pk, exist := pubKeys[k.Subset]; exist && pk != k.Key {
+				return nil, ErrMultisigKeysNotMatch
+			}
+			pubKeys[k.Subset] = k.Key
+		}
+	}
+
+	if ms.SubsetSize == ms.spendSubsetSize() {
+		subsets := subsetsOfSize(ms.Total, ms.SubsetSize, -1)
+		all := make([]multisigSubsetKey, 0, len(subsets))
+		for _, subset := range subsets {
+			pk, ok := pubKeys[subset]
+			if !ok {
+				return nil, ErrMultisigKeysMissing
+			}
+			all = append(all, multisigSubsetKey{Subset: subset, Key: pk})
+		}
+		return la.finalizeMultisig(&ms, all)
+	}
+
+	if err := ms.nextSubsetKeys(pubKeys); err != nil {
+		return nil, err
+	}
+	info, err := ms.encodeKeyInfo(multisigKeysHeader, lkctypes.Key{}, ms.publicSubsetKeys())
+	if err != nil {
+		return nil, err
+	}
+	la.multisig = &ms
+	if err := la.saveMultisig(); err != nil {
+		return nil, err
+	}
+	return &wtypes.MakeMultisigRet{MultisigInfo: info}, nil
+}
+
+func (la *LinkAccount) finalizeMultisig(ms *multisigState, pubKeys []multisigSubsetKey) (*wtypes.MakeMultisigRet, error) {
+	keys := make(lkctypes.KeyV, len(pubKeys))
+	for i, k := range pubKeys {
+		keys[i] = k.Key
+	}
+	spendPubKey, err := ringct.AddKeyV(keys)
+	if err != nil {
+		return nil, err
+	}
+	ms.SpendPubKey = spendPubKey
+	ms.PubSubsetKeys = pubKeys
+	ms.Ready = true
+	la.multisig = ms
+	if err := la.saveMultisig(); err != nil {
+		return nil, err
+	}
+	if err := la.switchToMultisig(); err != nil {
+		return nil, err
+	}
+	la.Logger.Info("MakeMultisig finished", "threshold", ms.Threshold, "total", ms.Total, "address", la.mainUTXOAddress)
+	return &wtypes.MakeMultisigRet{Address: la.mainUTXOAddress, Ready: true}, nil
+}
+
+// IsMultisig return multisig status of the account
+func (la *LinkAccount) IsMultisig() *wtypes.IsMultisigRet {
+	la.lock.Lock()
+	defer la.lock.Unlock()
+
+	ret := &wtypes.IsMultisigRet{}
+	if la.multisig != nil && len(la.multisig.Signers) > 0 {
+		ret.Multisig = true
+		ret.Ready = la.multisig.Ready
+		ret.Threshold = hexutil.Uint64(la.multisig.Threshold)
+		ret.Total = hexutil.Uint64(la.multisig.Total)
+	}
+	return ret
+}
+
+// getOutputOTAddr return the one-time address of an owned output
+func (la *LinkAccount) getOutputOTAddr(uod *tctypes.UTXOOutputDetail) (lkctypes.Key, error) {
+	tx := uod.Tx
+	if tx == nil {
+		var err error
+		if tx, err = la.loadUTXOTx(common.Hash(uod.TxID)); err != nil {
+			return lkctypes.Key{}, err
+		}
+	}
+	output, err := getUTXOOutput(tx, uod.OutIndex)
+	if err != nil {
+		return lkctypes.Key{}, err
+	}
+	return output.OTAddr, nil
+}
+
+// ExportMultisigInfo export partial key images and fresh signing nonces of unspent outputs
+func (la *LinkAccount) ExportMultisigInfo() (string, error) {
+	la.lock.Lock()
+	defer la.lock.Unlock()
+
+	if !la.isMultisig() {
+		return "", ErrMultisigNotReady
+	}
+	ms := la.multisig
+	info := multisigSignerInfo{
+		Signer:  ms.Signers[ms.Self],
+		Outputs: make([]multisigOutputInfo, 0),
+	}
+	for _, uod := range la.Transfers {
+		if uod.Spent {
+			continue
+		}
+		otaddr, err := la.getOutputOTAddr(uod)
+		if err != nil {
+			return "", err
+		}
+		out := multisigOutputInfo{OTAddr: otaddr}
+		for _, k := range ms.SubsetKeys {
+			pki, err := provePartialKeyImage(otaddr, k.Subset, k.Key)
+			if err != nil {
+				return "", err
+			}
+			out.PartialKeyImages = append(out.PartialKeyImages, pki)
+		}
+		nonce, ok := ms.getNonce(otaddr)
+		if !ok {
+			nonce = ringct.SkGen()
+			ms.Nonces = append(ms.Nonces, multisigNonce{OTAddr: otaddr, SecKey: nonce})
+		}
+		out.L = ringct.ScalarmultBase(nonce)
+		r, err := xcrypto.GenerateKeyImage(lkctypes.PublicKey(otaddr), lkctypes.SecretKey(nonce))
+		if err != nil {
+			return "", err
+		}
+		out.R = lkctypes.Key(r)
+		info.Outputs = append(info.Outputs, out)
+	}
+	keyImage, sig, err := signMultisigMessage(ms.SecKey, []interface{}{multisigExportHeader, info.Signer, info.Outputs})
+	if err != nil {
+		return "", err
+	}
+	info.KeyImage = keyImage
+	info.Sig = *sig
+	if err := la.saveMultisig(); err != nil {
+		return "", err
+	}
+	return encodeProof(multisigExportHeader, &info)
+}
+
+// ImportMultisigInfo import infos exported by other signers, complete the key images of
+// outputs and update their spent status, return the number of outputs with key image.
+// The infos with a partial key image not proven to be made with the subset key are rejected
+func (la *LinkAccount) ImportMultisigInfo(infos []string) (uint64, error) {
+	la.lock.Lock()
+	defer la.lock.Unlock()
+
+	if !la.isMultisig() {
+		return 0, ErrMultisigNotReady
+	}
+	ms := la.multisig
+	// nothing is imported if any info is invalid
+	imported := make([]multisigSignerInfo, 0, len(infos))
+	for _, str := range infos {
+		var info multisigSignerInfo
+		if err := decodeProof(multisigExportHeader, str, &info); err != nil {
+			return 0, ErrMultisigInfoInvalid
+		}
+		if !checkMultisigMessage(info.Signer, info.KeyImage, &info.Sig, []interface{}{multisigExportHeader, info.Signer, info.Outputs}) {
+			return 0, ErrMultisigInfoInvalid
+		}
+		idx, ok := ms.signerIndex(info.Signer)
+		if !ok {
+			return 0, ErrMultisigSignerUnknown
+		}
+		if uint64(idx) == ms.Self {
+			continue
+		}
+		if err := ms.checkSignerInfo(idx, &info); err != nil {
+			return 0, err
+		}
+		imported = append(imported, info)
+	}
+	for _, info := range imported {
+		replaced := false
+		for i := range ms.Infos {
+			if ms.Infos[i].Signer == info.Signer {
+				ms.Infos[i] = info
+				replaced = true
+			}
+		}
+		if !replaced {
+			ms.Infos = append(ms.Infos, info)
+		}
+	}
+
+	subsets := subsetsOfSize(ms.Total, ms.spendSubsetSize(), -1)
+	newKeyImages := make([]lkctypes.Key, 0)
+	newIDs := make([]int, 0)
+	for tid, uod := range la.Transfers {
+		if uod.KeyImage != (lkctypes.Key{}) {
+			continue
+		}
+		otaddr, err := la.getOutputOTAddr(uod)
+		if err != nil {
+			return 0, err
+		}
+		partials := make(map[uint64]lkctypes.Key)
+		for _, k := range ms.SubsetKeys {
+			pki, err := xcrypto.GenerateKeyImage(lkctypes.PublicKey(otaddr), lkctypes.SecretKey(k.Key))
+			if err != nil {
+				return 0, err
+			}
+			partials[k.Subset] = lkctypes.Key(pki)
+		}
+		for i := range ms.Infos {
+			out, _ := ms.Infos[i].findOutput(otaddr)
+			if out == nil {
+				continue
+			}
+			for _, pki := range out.PartialKeyImages {
+				partials[pki.Subset] = pki.Key
+			}
+		}
+		if len(partials) < len(subsets) {
+			continue
+		}
+		viewSecret, err := la.getOutputSecretKey(uod)
+		if err != nil {
+			return 0, err
+		}
+		ki, err := xcrypto.GenerateKeyImage(lkctypes.PublicKey(otaddr), viewSecret)
+		if err != nil {
+			return 0, err
+		}
+		keyImage := lkctypes.Key(ki)
+		for _, subset := range subsets {
+			pki, ok := partials[subset]
+			if !ok {
+				return 0, ErrMultisigKeysMissing
+			}
+			if keyImage, err = ringct.AddKeys(keyImage, pki); err != nil {
+				return 0, err
+			}
+		}
+		uod.KeyImage = keyImage
+		la.keyImages[keyImage] = tid
+		newKeyImages = append(newKeyImages, keyImage)
+		newIDs = append(newIDs, tid)
+	}
+
+	if len(newKeyImages) > 0 {
+		spents, err := GetKeyImagesSpentFromNode(newKeyImages)
+		if err != nil {
+			return 0, err
+		}
+		for i, spent := range spents {
+			uod := la.Transfers[newIDs[i]]
+			if spent && !uod.Spent {
+				uod.Spent = true
+				la.updateBalance(uod.TokenID, uod.SubAddrIndex, false, uod.Amount)
+			}
+		}
+	}
+	if err := la.saveMultisig(); err != nil {
+		return 0, err
+	}
+	if err := la.save(newIDs); err != nil {
+		return 0, err
+	}
+
+	cnt := uint64(0)
+	for _, uod := range la.Transfers {
+		if uod.KeyImage != (lkctypes.Key{}) {
+			cnt++
+		}
+	}
+	return cnt, nil
+}
+
+func (ms *multisigState) encodeTxSet(txSet *multisigTxSet) (string, error) {
+	return encodeProof(multisigTxSetHeader, txSet)
+}
+
+func decodeTxSet(str string) (*multisigTxSet, error) {
+	var txSet multisigTxSet
+	if err := decodeProof(multisigTxSetHeader, str, &txSet); err != nil {
+		return nil, ErrMultisigTxSetInvalid
+	}
+	if txSet.Tx == nil || len(txSet.Inputs) != len(txSet.Tx.Inputs) || len(txSet.Inputs) != len(txSet.Tx.RCTSig.P.MGs) {
+		return nil, ErrMultisigTxSetInvalid
+	}
+	return &txSet, nil
+}
+
+// checkMultisigChallenges recompute the challenge C of the real ring member of every
+// input from the rings on chain and the signatures of the members before it. A tx
+// set with a C of its own choosing would make a signer leak its spend secret key.
+func checkMultisigChallenges(txSet *multisigTxSet) error {
+	tx := txSet.Tx
+	rct := tx.RCTSig
+	if len(rct.P.PseudoOuts) != len(tx.Inputs) {
+		return ErrMultisigTxSetInvalid
+	}
+	rct.Message = tx.PrefixHash()
+	rct.MixRing = make([]lkctypes.CtkeyV, len(tx.Inputs))
+	for i, in := range tx.Inputs {
+		input, ok := in.(*tctypes.UTXOInput)
+		if !ok {
+			return ErrMultisigTxSetInvalid
+		}
+		ring, err := GetOutputsFromNode(keyOffsetsToAbsolute(input.KeyOffset), tx.TokenID)
+		if err != nil {
+			return err
+		}
+		rct.MixRing[i] = make(lkctypes.CtkeyV, len(ring))
+		for j, member := range ring {
+			rct.MixRing[i][j] = lkctypes.Ctkey{Dest: member.OTAddr, Mask: member.Commit}
+		}
+	}
+	message, err := ringct.GetPreMlsagHash(&rct)
+	if err != nil {
+		return ErrMultisigTxSetInvalid
+	}
+	for i, in := range txSet.Inputs {
+		ring, mg := rct.MixRing[i], &rct.P.MGs[i]
+		if len(mg.Ss) != len(ring) || in.RingIndex >= uint64(len(ring)) || ring[in.RingIndex].Dest != in.OTAddr {
+			return ErrMultisigTxSetInvalid
+		}
+		keyImage := tx.Inputs[i].(*tctypes.UTXOInput).KeyImage
+		c, err := mlsagChallenge(message, ring, rct.P.PseudoOuts[i], keyImage, mg, int(in.RingIndex))
+		if err != nil || c != in.C {
+			return ErrMultisigChallengeInvalid
+		}
+	}
+	return nil
+}
+
+func multisigTxSetRet(str string, txSet *multisigTxSet) *wtypes.MultisigTxSetRet {
+	ret := &wtypes.MultisigTxSetRet{
+		TxSet:    str,
+		Hash:     txSet.Tx.Hash(),
+		Fee:      (*hexutil.Big)(txSet.Tx.Fee),
+		Complete: txSet.Signed == txSet.SigningSet,
+	}
+	for _, dest := range txSet.Dests {
+		ret.Dests = append(ret.Dests, wtypes.MultisigTxDest{Addr: dest.Addr, Amount: (*hexutil.Big)(dest.Amount)})
+	}
+	return ret
+}
+
+func destToString(dest tctypes.DestEntry) string {
+	switch d := dest.(type) {
+	case *tctypes.UTXODestEntry:
+		prefix := wtypes.GetConfig().CRYPTONOTE_PUBLIC_ADDRESS_BASE58_PREFIX
+		if d.IsSubaddress {
+			prefix = wtypes.GetConfig().CRYPTONOTE_PUBLIC_SUBADDRESS_BASE58_PREFIX
+		}
+		return addressToStr(uint64(prefix), d.Addr)
+	case *tctypes.AccountDestEntry:
+		return d.To.String()
+	}
+	return ""
+}
+
+// TransferMultisig create a multisig tx signed by this wallet, the returned tx set
+// must be signed by threshold-1 other signers whose multisig info have been imported
+func (w *Wallet) TransferMultisig(dests []tctypes.DestEntry, tokenID common.Address) (*wtypes.MultisigTxSetRet, error) {
+	if w.IsWalletClosed() {
+		return nil, wtypes.ErrWalletNotOpen
+	}
+	la := w.currAccount
+	if !la.isMultisig() {
+		return nil, ErrMultisigNotReady
+	}
+	if tokenID != LinkToken {
+		return nil, wtypes.ErrUTXONotSupportToken
+	}
+	needMoney, hasContract, err := w.checkDest(dests, tokenID, UTXOInputMode)
+	if err != nil {
+		return nil, err
+	}
+	if hasContract {
+		return nil, ErrMultisigContractNotSupport
+	}
+
+	la.lock.Lock()
+	defer la.lock.Unlock()
+	ms := la.multisig
+
+	// choose the cosigners sharing nonces for most outputs
+	cosigners := make([]int, len(ms.Infos))
+	for i := range cosigners {
+		cosigners[i] = i
+	}
+	sort.SliceStable(cosigners, func(i, j int) bool {
+		return len(ms.Infos[cosigners[i]].Outputs) > len(ms.Infos[cosigners[j]].Outputs)
+	})
+	if uint64(len(cosigners)) < ms.Threshold-1 {
+		return nil, ErrMultisigNotEnoughSigners
+	}
+	cosigners = cosigners[:ms.Threshold-1]
+	signingSet := uint64(1) << ms.Self
+	for _, c := range cosigners {
+		idx, _ := ms.signerIndex(ms.Infos[c].Signer)
+		signingSet |= uint64(1) << uint(idx)
+	}
+
+	var (
+		selected  = make([]uint64, 0)
+		otaddrs   = make([]lkctypes.Key, 0)
+		available = big.NewInt(0)
+	)
+	for i, uod := range la.Transfers {
+		if available.Cmp(needMoney) >= 0 {
+			break
+		}
+		if uod.Spent || uod.Frozen || uod.TokenID != tokenID || uod.KeyImage == (lkctypes.Key{}) {
+			continue
+		}
+		otaddr, err := la.getOutputOTAddr(uod)
+		if err != nil {
+			return nil, err
+		}
+		covered := true
+		for _, c := range cosigners {
+			if out, _ := ms.Infos[c].findOutput(otaddr); out == nil {
+				covered = false
+				break
+			}
+		}
+		if !covered {
+			continue
+		}
+		selected = append(selected, uint64(i))
+		otaddrs = append(otaddrs, otaddr)
+		available.Add(available, uod.Amount)
+	}
+	if available.Cmp(needMoney) < 0 {
+		return nil, ErrBalanceNotEnough
+	}
+	paidDests := dests
+	if available.Cmp(needMoney) > 0 {
+		paidDests = append(paidDests, &tctypes.UTXODestEntry{
+			Amount:   big.NewInt(0).Sub(available, needMoney),
+			Addr:     la.account.Keys[0].Addr,
+			IsChange: true,
+		})
+	}
+	sources, err := w.constructSourceEntry(selected)
+	if err != nil {
+		return nil, err
+	}
+
+	inEphs := make([]*tctypes.UTXOInputEphemeral, len(selected))
+	kLRki := make([]lkctypes.MultisigKLRki, len(selected))
+	spendSecret := ms.outputSpendSecret(signingSet)
+	for i, idx := range selected {
+		uod := la.Transfers[idx]
+		viewSecret, err := la.getOutputSecretKey(uod)
+		if err != nil {
+			return nil, err
+		}
+		inEphs[i] = &tctypes.UTXOInputEphemeral{
+			OTAddr:   otaddrs[i],
+			SKey:     lkctypes.SecretKey(scAdd(lkctypes.Key(viewSecret), spendSecret)),
+			KeyImage: uod.KeyImage,
+		}
+		nonce := ringct.SkGen()
+		r, err := xcrypto.GenerateKeyImage(lkctypes.PublicKey(otaddrs[i]), lkctypes.SecretKey(nonce))
+		if err != nil {
+			return nil, err
+		}
+		klrki := lkctypes.MultisigKLRki{K: nonce, L: ringct.ScalarmultBase(nonce), R: lkctypes.Key(r), Ki: uod.KeyImage}
+		for _, c := range cosigners {
+			out, _ := ms.Infos[c].findOutput(otaddrs[i])
+			if klrki.L, err = ringct.AddKeys(klrki.L, out.L); err != nil {
+				return nil, err
+			}
+			if klrki.R, err = ringct.AddKeys(klrki.R, out.R); err != nil {
+				return nil, err
+			}
+		}
+		kLRki[i] = klrki
+	}
+
+	utxoTrans, mKeys, txKey, err := tctypes.NewUinTransactionWithInputs(sources, inEphs, paidDests, tokenID, la.getEthAddress(), nil)
+	if err != nil {
+		return nil, err
+	}
+	msout, err := tctypes.UInTransWithRctSigMultisig(utxoTrans, sources, inEphs, paidDests, mKeys, kLRki)
+	if err != nil {
+		return nil, err
+	}
+
+	txSet := &multisigTxSet{
+		Tx:         utxoTrans,
+		SigningSet: signingSet,
+		Signed:     uint64(1) << ms.Self,
+	}
+	for i := range selected {
+		txSet.Inputs = append(txSet.Inputs, multisigTxInput{OTAddr: otaddrs[i], RingIndex: sources[i].RingIndex, C: msout[i]})
+	}
+	for _, dest := range paidDests {
+		txSet.Dests = append(txSet.Dests, multisigTxDest{Addr: destToString(dest), Amount: dest.GetAmount()})
+	}
+
+	// cosigner nonces can only be used once
+	for _, c := range cosigners {
+		for _, otaddr := range otaddrs {
+			if _, i := ms.Infos[c].findOutput(otaddr); i >= 0 {
+				ms.Infos[c].Outputs = append(ms.Infos[c].Outputs[:i], ms.Infos[c].Outputs[i+1:]...)
+			}
+		}
+	}
+	if err := la.saveMultisig(); err != nil {
+		return nil, err
+	}
+	if err := la.saveTxKeys(utxoTrans.Hash(), txKey); err != nil {
+		return nil, err
+	}
+	str, err := ms.encodeTxSet(txSet)
+	if err != nil {
+		return nil, err
+	}
+	return multisigTxSetRet(str, txSet), nil
+}
+
+// SignMultisig add the signature of this wallet to a multisig tx set
+func (la *LinkAccount) SignMultisig(str string) (*wtypes.MultisigTxSetRet, error) {
+	la.lock.Lock()
+	defer la.lock.Unlock()
+
+	if !la.isMultisig() {
+		return nil, ErrMultisigNotReady
+	}
+	ms := la.multisig
+	txSet, err := decodeTxSet(str)
+	if err != nil {
+		return nil, err
+	}
+	self := uint64(1) << ms.Self
+	if txSet.SigningSet&self == 0 {
+		return nil, ErrMultisigNotSigner
+	}
+	if txSet.Signed&self != 0 {
+		return nil, ErrMultisigAlreadySigned
+	}
+	if err := checkMultisigChallenges(txSet); err != nil {
+		return nil, err
+	}
+
+	spendSecret := ms.outputSpendSecret(txSet.SigningSet)
+	nonces := make([]lkctypes.Key, len(txSet.Inputs))
+	for i, in := range txSet.Inputs {
+		input, ok := txSet.Tx.Inputs[i].(*tctypes.UTXOInput)
+		if !ok {
+			return nil, ErrMultisigTxSetInvalid
+		}
+		tid, ok := la.keyImages[input.KeyImage]
+		if !ok {
+			return nil, ErrInputNotOwned
+		}
+		otaddr, err := la.getOutputOTAddr(la.Transfers[tid])
+		if err != nil {
+			return nil, err
+		}
+		if otaddr != in.OTAddr {
+			return nil, ErrMultisigTxSetInvalid
+		}
+		mg := &txSet.Tx.RCTSig.P.MGs[i]
+		if in.RingIndex >= uint64(len(mg.Ss)) || len(mg.Ss[in.RingIndex]) == 0 {
+			return nil, ErrMultisigTxSetInvalid
+		}
+		nonce, ok := ms.getNonce(in.OTAddr)
+		if !ok {
+			return nil, ErrMultisigNonceNotFound
+		}
+		nonces[i] = nonce
+	}
+	for i, in := range txSet.Inputs {
+		ss := &txSet.Tx.RCTSig.P.MGs[i].Ss[in.RingIndex][0]
+		*ss = scAdd(*ss, scMulSub(in.C, spendSecret, nonces[i]))
+		ms.takeNonce(in.OTAddr)
+	}
+	txSet.Signed |= self
+	if err := la.saveMultisig(); err != nil {
+		return nil, err
+	}
+	ret, err := ms.encodeTxSet(txSet)
+	if err != nil {
+		return nil, err
+	}
+	return multisigTxSetRet(ret, txSet), nil
+}
+
+// PrepareMultisig wallet rpc
+func (w *Wallet) PrepareMultisig() (string, error) {
+	if w.IsWalletClosed() {
+		return "", wtypes.ErrWalletNotOpen
+	}
+	return w.currAccount.PrepareMultisig()
+}
+
+// MakeMultisig wallet rpc
+func (w *Wallet) MakeMultisig(threshold uint64, infos []string) (*wtypes.MakeMultisigRet, error) {
+	if w.IsWalletClosed() {
+		return nil, wtypes.ErrWalletNotOpen
+	}
+	return w.currAccount.MakeMultisig(threshold, infos)
+}
+
+// ExchangeMultisigKeys wallet rpc
+func (w *Wallet) ExchangeMultisigKeys(infos []string) (*wtypes.MakeMultisigRet, error) {
+	if w.IsWalletClosed() {
+		return nil, wtypes.ErrWalletNotOpen
+	}
+	return w.currAccount.ExchangeMultisigKeys(infos)
+}
+
+// IsMultisig wallet rpc
+func (w *Wallet) IsMultisig() (*wtypes.IsMultisigRet, error) {
+	if w.IsWalletClosed() {
+		return nil, wtypes.ErrWalletNotOpen
+	}
+	return w.currAccount.IsMultisig(), nil
+}
+
+// ExportMultisigInfo wallet rpc
+func (w *Wallet) ExportMultisigInfo() (string, error) {
+	if w.IsWalletClosed() {
+		return "", wtypes.ErrWalletNotOpen
+	}
+	return w.currAccount.ExportMultisigInfo()
+}
+
+// ImportMultisigInfo wallet rpc
+func (w *Wallet) ImportMultisigInfo(infos []string) (uint64, error) {
+	if w.IsWalletClosed() {
+		return 0, wtypes.ErrWalletNotOpen
+	}
+	return w.currAccount.ImportMultisigInfo(infos)
+}
+
+// SignMultisig wallet rpc
+func (w *Wallet) SignMultisig(txSet string) (*wtypes.MultisigTxSetRet, error) {
+	if w.IsWalletClosed() {
+		return nil, wtypes.ErrWalletNotOpen
+	}
+	return w.currAccount.SignMultisig(txSet)
+}
+
+// SubmitMultisig submit a fully signed multisig tx
+func (w *Wallet) SubmitMultisig(str string) (common.Hash, error) {
+	if w.IsWalletClosed() {
+		return common.Hash{}, wtypes.ErrWalletNotOpen
+	}
+	txSet, err := decodeTxSet(str)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if txSet.Signed != txSet.SigningSet {
+		return common.Hash{}, ErrMultisigTxNotComplete
+	}
+	w.Logger.Info("SubmitMultisig", "hash", txSet.Tx.Hash(), "signers", fmt.Sprintf("%x", txSet.Signed))
+	return w.SubmitUTXOTransaction(txSet.Tx)
+}
@@ -0,0 +1,337 @@
+package wallet
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/cryptonote/ringct"
+	lktypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
+	"github.com/lianxiangcloud/linkchain/libs/cryptonote/xcrypto"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/libs/log"
+	"github.com/lianxiangcloud/linkchain/types"
+	wtypes "github.com/lianxiangcloud/linkchain/wallet/types"
+)
+
+func TestSubsetsOfSize(t *testing.T) {
+	// 2-of-3: subsets of 2 signers
+	subsets := subsetsOfSize(3, 2, -1)
+	if len(subsets) != 3 {
+		t.Fatalf("subsetsOfSize(3,2) got %d subsets, want 3", len(subsets))
+	}
+	mine := subsetsOfSize(3, 2, 0)
+	if len(mine) != 2 {
+		t.Fatalf("subsetsOfSize(3,2,0) got %d subsets, want 2", len(mine))
+	}
+	for _, subset := range mine {
+		if subset&1 == 0 {
+			t.Fatalf("subset %b does not contain signer 0", subset)
+		}
+	}
+}
+
+func TestSubsetOwner(t *testing.T) {
+	// every subset of N-M+1 signers is owned by exactly one signer of any M signers
+	total, threshold := uint64(5), uint64(3)
+	for _, signingSet := range subsetsOfSize(total, threshold, -1) {
+		for _, subset := range subsetsOfSize(total, total-threshold+1, -1) {
+			owner := subsetOwner(subset, signingSet)
+			if owner >= int(total) || signingSet&(uint64(1)<<uint(owner)) == 0 || subset&(uint64(1)<<uint(owner)) == 0 {
+				t.Fatalf("subset %b signing set %b got bad owner %d", subset, signingSet, owner)
+			}
+		}
+	}
+}
+
+func TestScMulSub(t *testing.T) {
+	var c, x, k lktypes.Key
+	c[0], x[0], k[0] = 3, 5, 20
+	if r := scMulSub(c, x, k); scalarToBig(r).Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("scMulSub got %s, want 5", scalarToBig(r))
+	}
+	// negative result wraps around the curve order
+	k[0] = 14
+	want := new(big.Int).Sub(curveOrder, big.NewInt(1))
+	if r := scMulSub(c, x, k); scalarToBig(r).Cmp(want) != 0 {
+		t.Fatalf("scMulSub got %s, want %s", scalarToBig(r), want)
+	}
+}
+
+func othersOf(strs []string, i int) []string {
+	return append(append([]string{}, strs[:i]...), strs[i+1:]...)
+}
+
+// newMultisigAccounts make a threshold-of-total multisig account shared by total fresh wallets
+func newMultisigAccounts(t *testing.T, threshold, total int) []*LinkAccount {
+	las := make([]*LinkAccount, total)
+	infos := make([]string, total)
+	for i := range las {
+		acc, err := RecoveryKeyToAccount(lktypes.SecretKey(ringct.SkGen()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		las[i] = &LinkAccount{
+			Logger:           log.Root(),
+			account:          acc,
+			walletDB:         dbm.NewMemDB(),
+			mainUTXOAddress:  acc.GetKeys().Address,
+			AccBalance:       make(map[common.Address]balanceMap),
+			utxoTotalBalance: make(map[common.Address]*big.Int),
+			gOutIndex:        make(map[common.Address]uint64),
+			txKeys:           make(map[common.Hash]lktypes.Key),
+			keyImages:        make(map[lktypes.Key]int),
+			Transfers:        make(transferContainer, 0),
+		}
+		if infos[i], err = las[i].PrepareMultisig(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rets := make([]*wtypes.MakeMultisigRet, total)
+	for i, la := range las {
+		ret, err := la.MakeMultisig(uint64(threshold), othersOf(infos, i))
+		if err != nil {
+			t.Fatalf("MakeMultisig of signer %d: %v", i, err)
+		}
+		rets[i] = ret
+	}
+	for round := 0; !rets[0].Ready; round++ {
+		if round >= total {
+			t.Fatal("key exchange not finished")
+		}
+		for i := range rets {
+			infos[i] = rets[i].MultisigInfo
+		}
+		for i, la := range las {
+			ret, err := la.ExchangeMultisigKeys(othersOf(infos, i))
+			if err != nil {
+				t.Fatalf("ExchangeMultisigKeys of signer %d: %v", i, err)
+			}
+			rets[i] = ret
+		}
+	}
+	for i, ret := range rets {
+		if !ret.Ready || ret.Address != rets[0].Address {
+			t.Fatalf("signer %d got address %q ready %v, want %q", i, ret.Address, ret.Ready, rets[0].Address)
+		}
+	}
+	return las
+}
+
+// receiveMultisigOutput add an output paid to the multisig address to the wallets
+func receiveMultisigOutput(t *testing.T, las []*LinkAccount, amount *big.Int) lktypes.Key {
+	addr := las[0].account.GetKeys().Addr
+	r := ringct.SkGen()
+	derivation, err := xcrypto.GenerateKeyDerivation(addr.ViewPublicKey, lktypes.SecretKey(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otaddr, err := xcrypto.DerivePublicKey(derivation, 0, addr.SpendPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := &types.UTXOTransaction{
+		Outputs: []types.Output{&types.UTXOOutput{OTAddr: lktypes.Key(otaddr), Amount: big.NewInt(0)}},
+		Fee:     big.NewInt(0),
+	}
+	for _, la := range las {
+		la.Transfers = append(la.Transfers, &types.UTXOOutputDetail{
+			Tx:      tx,
+			RKey:    lktypes.PublicKey(ringct.ScalarmultBase(r)),
+			Amount:  amount,
+			TokenID: LinkToken,
+		})
+	}
+	return lktypes.Key(otaddr)
+}
+
+func TestMultisigRoundTrip(t *testing.T) {
+	n := newProofNode()
+	defer mockProofDaemon(t, n)()
+	las := newMultisigAccounts(t, 2, 3)
+	otaddr := receiveMultisigOutput(t, las, big.NewInt(1e18))
+
+	exports := make([]string, len(las))
+	for i, la := range las {
+		var err error
+		if exports[i], err = la.ExportMultisigInfo(); err != nil {
+			t.Fatalf("ExportMultisigInfo of signer %d: %v", i, err)
+		}
+	}
+	for i, la := range las {
+		n, err := la.ImportMultisigInfo(othersOf(exports, i))
+		if err != nil || n != 1 {
+			t.Fatalf("ImportMultisigInfo of signer %d got %d %v, want 1", i, n, err)
+		}
+	}
+
+	// the combined key image is made with the full spend secret of the output
+	viewSecret, err := las[0].getOutputSecretKey(las[0].Transfers[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	subsetKeys := make(map[uint64]lktypes.Key)
+	for _, la := range las {
+		for _, k := range la.multisig.SubsetKeys {
+			subsetKeys[k.Subset] = k.Key
+		}
+	}
+	secret := lktypes.Key(viewSecret)
+	for _, k := range subsetKeys {
+		secret = scAdd(secret, k)
+	}
+	if ringct.ScalarmultBase(secret) != otaddr {
+		t.Fatal("subset keys do not sum to the output secret")
+	}
+	keyImage, err := xcrypto.GenerateKeyImage(lktypes.PublicKey(otaddr), lktypes.SecretKey(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, la := range las {
+		if ki := la.Transfers[0].KeyImage; ki != lktypes.Key(keyImage) {
+			t.Fatalf("signer %d got key image %x, want %x", i, ki, keyImage)
+		}
+	}
+
+	// signer 0 makes the partial signature with the nonce exported by signer 1,
+	// signer 1 completes it
+	first, second := las[0], las[1]
+	signingSet := uint64(1)<<first.multisig.Self | uint64(1)<<second.multisig.Self
+	var cosigner *multisigOutputInfo
+	for i := range first.multisig.Infos {
+		if first.multisig.Infos[i].Signer == second.multisig.Signers[second.multisig.Self] {
+			cosigner, _ = first.multisig.Infos[i].findOutput(otaddr)
+		}
+	}
+	if cosigner == nil {
+		t.Fatal("nonce of signer 1 not imported")
+	}
+	nonce := ringct.SkGen()
+	nonceImage, err := xcrypto.GenerateKeyImage(lktypes.PublicKey(otaddr), lktypes.SecretKey(nonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	klrki := lktypes.MultisigKLRki{K: nonce, Ki: lktypes.Key(keyImage)}
+	if klrki.L, err = ringct.AddKeys(ringct.ScalarmultBase(nonce), cosigner.L); err != nil {
+		t.Fatal(err)
+	}
+	if klrki.R, err = ringct.AddKeys(lktypes.Key(nonceImage), cosigner.R); err != nil {
+		t.Fatal(err)
+	}
+
+	// the output is the second member of a ring of two on chain
+	inMask, a := ringct.SkGen(), ringct.SkGen()
+	n.outputs[3], n.commits[3] = ringct.ScalarmultBase(ringct.SkGen()), ringct.ScalarmultBase(ringct.SkGen())
+	n.outputs[7], n.commits[7] = otaddr, ringct.ScalarmultBase(inMask)
+	ring := lktypes.CtkeyV{{Dest: n.outputs[3], Mask: n.commits[3]}, {Dest: n.outputs[7], Mask: n.commits[7]}}
+	pseudoOut := ringct.ScalarmultBase(a)
+	tx := &types.UTXOTransaction{
+		Inputs:  []types.Input{&types.UTXOInput{KeyOffset: []uint64{3, 4}, KeyImage: lktypes.Key(keyImage)}},
+		TokenID: LinkToken,
+		Fee:     big.NewInt(0),
+	}
+	tx.RCTSig.Type = uint8(lktypes.RCTTypeBulletproof)
+	tx.RCTSig.P.PseudoOuts = lktypes.KeyV{pseudoOut}
+	rct := tx.RCTSig
+	rct.Message, rct.MixRing = tx.PrefixHash(), []lktypes.CtkeyV{ring}
+	message, err := ringct.GetPreMlsagHash(&rct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inSk := lktypes.Ctkey{Dest: scAdd(lktypes.Key(viewSecret), first.multisig.outputSpendSecret(signingSet)), Mask: inMask}
+	var c lktypes.Key
+	mg, err := ringct.ProveRctMGSimple(message, ring, inSk, a, pseudoOut, &c, &klrki, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.RCTSig.P.MGs = []lktypes.MgSig{*mg}
+	txSet := &multisigTxSet{
+		Tx:         tx,
+		Inputs:     []multisigTxInput{{OTAddr: otaddr, RingIndex: 1, C: c}},
+		SigningSet: signingSet,
+		Signed:     uint64(1) << first.multisig.Self,
+	}
+	str, err := first.multisig.encodeTxSet(txSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := las[2].SignMultisig(str); err != ErrMultisigNotSigner {
+		t.Fatalf("SignMultisig of signer out of the signing set got %v, want %v", err, ErrMultisigNotSigner)
+	}
+
+	// a challenge not made by the ring is refused and keeps the nonce
+	txSet.Inputs[0].C = ringct.SkGen()
+	tampered, err := first.multisig.encodeTxSet(txSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.SignMultisig(tampered); err != ErrMultisigChallengeInvalid {
+		t.Fatalf("SignMultisig of tampered challenge got %v, want %v", err, ErrMultisigChallengeInvalid)
+	}
+	if _, ok := second.multisig.getNonce(otaddr); !ok {
+		t.Fatal("nonce used by the tampered tx set")
+	}
+
+	ret, err := second.SignMultisig(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ret.Complete {
+		t.Fatal("tx set not complete after threshold signers")
+	}
+	if _, err := second.SignMultisig(ret.TxSet); err != ErrMultisigAlreadySigned {
+		t.Fatalf("SignMultisig twice got %v, want %v", err, ErrMultisigAlreadySigned)
+	}
+
+	// the combined signature closes the ring: walking all members returns to Cc
+	signed, err := decodeTxSet(ret.TxSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mg = &signed.Tx.RCTSig.P.MGs[0]
+	if got, err := mlsagChallenge(message, ring, pseudoOut, lktypes.Key(keyImage), mg, len(ring)); err != nil || got != mg.Cc {
+		t.Fatalf("combined MLSAG does not verify: %v", err)
+	}
+}
+
+func TestImportMultisigInfoBadKeyImage(t *testing.T) {
+	las := newMultisigAccounts(t, 2, 3)
+	receiveMultisigOutput(t, las, big.NewInt(1e18))
+	export, err := las[1].ExportMultisigInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(pki *multisigPartialKeyImage)
+	}{
+		{"mismatched image", func(pki *multisigPartialKeyImage) { pki.Key = ringct.ScalarmultBase(ringct.SkGen()) }},
+		{"foreign subset", func(pki *multisigPartialKeyImage) { pki.Subset = ^pki.Subset & (uint64(1)<<3 - 1) }},
+	}
+	for _, test := range tests {
+		var info multisigSignerInfo
+		if err := decodeProof(multisigExportHeader, export, &info); err != nil {
+			t.Fatal(err)
+		}
+		test.tamper(&info.Outputs[0].PartialKeyImages[0])
+		// signed again by the signer, only the key image proof is wrong
+		keyImage, sig, err := signMultisigMessage(las[1].multisig.SecKey, []interface{}{multisigExportHeader, info.Signer, info.Outputs})
+		if err != nil {
+			t.Fatal(err)
+		}
+		info.KeyImage, info.Sig = keyImage, *sig
+		str, err := encodeProof(multisigExportHeader, &info)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := las[0].ImportMultisigInfo([]string{str}); err != ErrMultisigKeyImageInvalid {
+			t.Errorf("%s: ImportMultisigInfo got %v, want %v", test.name, err, ErrMultisigKeyImageInvalid)
+		}
+		if len(las[0].multisig.Infos) != 0 || las[0].Transfers[0].KeyImage != (lktypes.Key{}) {
+			t.Errorf("%s: rejected info imported", test.name)
+		}
+	}
+}
@@ -692,3 +692,53 @@ func (w *Wallet) GetTransactionReceipt(hash common.Hash) (r map[string]interface
 	// w.Logger.Debug("GetTransactionReceipt", "result", string(jsonRes.Result), "cnt", cnt)
 	return r, nil
 }
+
+// GetKeyImagesSpentFromNode return whether each key image has been spent on chain
+func GetKeyImagesSpentFromNode(keyImages []lktypes.Key) ([]bool, error) {
+	if 0 == len(keyImages) {
+		return nil, nil
+	}
+	kis := make([]rtypes.RPCKey, len(keyImages))
+	for i, ki := range keyImages {
+		kis[i] = rtypes.RPCKey(ki)
+	}
+
+	p := make([]interface{}, 1)
+	p[0] = kis
+	body, err := daemon.CallJSONRPC("eth_getKeyImagesSpent", p)
+	if err != nil || body == nil || len(body) == 0 {
+		return nil, wtypes.ErrNoConnectionToDaemon
+	}
+	var jsonRes wtypes.RPCResponse
+	if err = json.Unmarshal(body, &jsonRes); err != nil {
+		return nil, err
+	}
+	if jsonRes.Error.Code != 0 {
+		return nil, fmt.Errorf("json RPC error:%v,body:[%s]", jsonRes.Error, string(body))
+	}
+	var spent []bool
+	if err = json.Unmarshal(jsonRes.Result, &spent); err != nil {
+		return nil, err
+	}
+	if len(spent) != len(keyImages) {
+		return nil, fmt.Errorf("key images spent result len mismatch, want %d got %d", len(keyImages), len(spent))
+	}
+	return spent, nil
+}
+
+// GetUTXOTxFromNode return the UTXOTransaction of hash from node
+func (w *Wallet) GetUTXOTxFromNode(hash common.Hash) (*types.UTXOTransaction, error) {
+	r, err := w.GetTransactionByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	rpcTx, ok := r.(*rtypes.RPCTx)
+	if !ok || rpcTx.Tx == nil {
+		return nil, wtypes.ErrTxNotFound
+	}
+	tx, ok := rpcTx.Tx.(*types.UTXOTransaction)
+	if !ok {
+		return nil, wtypes.ErrTxNotFound
+	}
+	return tx, nil
+}
@@ -0,0 +1,555 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/cryptonote/ringct"
+	lkctypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
+	"github.com/lianxiangcloud/linkchain/libs/cryptonote/xcrypto"
+	"github.com/lianxiangcloud/linkchain/libs/hexutil"
+	"github.com/lianxiangcloud/linkchain/libs/ser"
+	tctypes "github.com/lianxiangcloud/linkchain/types"
+	wtypes "github.com/lianxiangcloud/linkchain/wallet/types"
+)
+
+const (
+	spendProofHeader   = "SpendProofV1"
+	reserveProofHeader = "ReserveProofV2"
+)
+
+var (
+	ErrProofInvalid       = errors.New("proof invalid")
+	ErrNoUTXOInputInTx    = errors.New("no utxo input in tx")
+	ErrInputNotOwned      = errors.New("tx input not owned by wallet")
+	ErrOutputIndexInvalid = errors.New("output index invalid")
+	ErrRingMemberNotFound = errors.New("real output not found in ring")
+)
+
+// spendProof holds one ring signature per UTXO input of the proven tx,
+// each signed over the tx hash and user message with the input key image.
+type spendProof struct {
+	Sigs []lkctypes.Signature
+}
+
+// sharedSecretProof is a Chaum-Pedersen proof that the shared secret D = a*R
+// of an output is computed with the secret key a of the view public key A = a*G.
+type sharedSecretProof struct {
+	C lkctypes.Key
+	S lkctypes.Key
+}
+
+// reserveProofEntry proves that an output is paid to the proven address and owned
+// by the prover, and exposes its key image so verifiers can check it has not been spent.
+// RKeyIndex selects the tx public key R of the output: 0 is tx.RKey and i is tx.AddKeys[i-1].
+// SubAddrSecret is zero for the main address, the subaddress secret key otherwise.
+type reserveProofEntry struct {
+	TxID            common.Hash
+	OutIndex        uint64
+	KeyImage        lkctypes.Key
+	RKeyIndex       uint64
+	SharedSecret    lkctypes.Key
+	SharedSecretSig sharedSecretProof
+	SubAddrSecret   lkctypes.Key
+	KeyImageSig     lkctypes.Signature
+}
+
+// reserveProof is signed by the account main spend key over all entries.
+type reserveProof struct {
+	TokenID      common.Address
+	Entries      []reserveProofEntry
+	AddrKeyImage lkctypes.Key
+	Sig          lkctypes.Signature
+}
+
+func encodeProof(header string, proof interface{}) (string, error) {
+	bz, err := ser.EncodeToBytes(proof)
+	if err != nil {
+		return "", err
+	}
+	return header + xcrypto.Base58Encode(bz), nil
+}
+
+func decodeProof(header string, str string, proof interface{}) error {
+	if !strings.HasPrefix(str, header) || len(str) == len(header) {
+		return ErrProofInvalid
+	}
+	bz := xcrypto.Base58Decode(str[len(header):])
+	if len(bz) == 0 {
+		return ErrProofInvalid
+	}
+	if err := ser.DecodeBytes(bz, proof); err != nil {
+		return ErrProofInvalid
+	}
+	return nil
+}
+
+func spendProofPrefixHash(hash common.Hash, message string) lkctypes.Hash {
+	data := make([]byte, 0, len(hash)+len(message))
+	data = append(data, hash[:]...)
+	data = append(data, []byte(message)...)
+	return xcrypto.FastHash(data)
+}
+
+func reserveProofPrefixHash(message string, addr *lkctypes.AccountAddress, proof *reserveProof) lkctypes.Hash {
+	data := make([]byte, 0, 1024)
+	data = append(data, []byte(message)...)
+	data = append(data, addr.SpendPublicKey[:]...)
+	data = append(data, addr.ViewPublicKey[:]...)
+	data = append(data, proof.TokenID[:]...)
+	for _, e := range proof.Entries {
+		data = append(data, e.TxID[:]...)
+		data = append(data, new(big.Int).SetUint64(e.OutIndex).Bytes()...)
+		data = append(data, e.KeyImage[:]...)
+	}
+	return xcrypto.FastHash(data)
+}
+
+func sharedSecretChallenge(prefix lkctypes.Hash, A, R, D, X, Y lkctypes.Key) lkctypes.Key {
+	return xcrypto.HashToScalar(lkctypes.KeyV{lkctypes.Key(prefix), A, R, D, X, Y})
+}
+
+// generateSharedSecretProof prove that D = a*R for the view secret key a of A = a*G
+func generateSharedSecretProof(prefix lkctypes.Hash, A, R, D, a lkctypes.Key) (*sharedSecretProof, error) {
+	k := xcrypto.SkGen()
+	X := xcrypto.ScalarmultBase(k)
+	Y, err := xcrypto.ScalarmultKey(R, k)
+	if err != nil {
+		return nil, err
+	}
+	c := sharedSecretChallenge(prefix, A, R, D, X, Y)
+	return &sharedSecretProof{C: c, S: scMulSub(c, a, k)}, nil
+}
+
+// checkSharedSecretProof verify that D = a*R for the secret key a of A = a*G
+func checkSharedSecretProof(prefix lkctypes.Hash, A, R, D lkctypes.Key, proof *sharedSecretProof) bool {
+	// X = s*G + c*A, Y = s*R + c*D
+	X, err := xcrypto.AddKeys2(proof.S, proof.C, A)
+	if err != nil {
+		return false
+	}
+	sR, err := xcrypto.ScalarmultKey(R, proof.S)
+	if err != nil {
+		return false
+	}
+	cD, err := xcrypto.ScalarmultKey(D, proof.C)
+	if err != nil {
+		return false
+	}
+	Y, err := xcrypto.AddKeys(sR, cD)
+	if err != nil {
+		return false
+	}
+	return sharedSecretChallenge(prefix, A, R, D, X, Y) == proof.C
+}
+
+func keyOffsetsToAbsolute(off []uint64) []uint64 {
+	res := make([]uint64, len(off))
+	copy(res, off)
+	for i := 1; i < len(res); i++ {
+		res[i] += res[i-1]
+	}
+	return res
+}
+
+// getUTXOOutput return the outIndex-th utxo output of tx, outIndex only counts utxo outputs
+func getUTXOOutput(tx *tctypes.UTXOTransaction, outIndex uint64) (*tctypes.UTXOOutput, error) {
+	idx := uint64(0)
+	for _, output := range tx.Outputs {
+		if utxoOutput, ok := output.(*tctypes.UTXOOutput); ok {
+			if idx == outIndex {
+				return utxoOutput, nil
+			}
+			idx++
+		}
+	}
+	return nil, ErrOutputIndexInvalid
+}
+
+// getRingPubKeys return the ring members' one-time addresses of input
+func getRingPubKeys(input *tctypes.UTXOInput, tokenID common.Address) ([]lkctypes.PublicKey, error) {
+	ring, err := GetOutputsFromNode(keyOffsetsToAbsolute(input.KeyOffset), tokenID)
+	if err != nil {
+		return nil, err
+	}
+	pks := make([]lkctypes.PublicKey, len(ring))
+	for i, entry := range ring {
+		pks[i] = lkctypes.PublicKey(entry.OTAddr)
+	}
+	return pks, nil
+}
+
+// getOutputSecretKey return the one-time secret key of an owned output
+func (la *LinkAccount) getOutputSecretKey(uod *tctypes.UTXOOutputDetail) (lkctypes.SecretKey, error) {
+	keys := la.account.GetKeys()
+	derivationKey, err := xcrypto.GenerateKeyDerivation(uod.RKey, keys.ViewSKey)
+	if err != nil {
+		return lkctypes.SecretKey{}, err
+	}
+	secretKey, err := xcrypto.DeriveSecretKey(derivationKey, int(uod.OutIndex), keys.SpendSKey)
+	if err != nil {
+		return lkctypes.SecretKey{}, err
+	}
+	if uod.SubAddrIndex > 0 {
+		subaddrSk := xcrypto.GetSubaddressSecretKey(keys.ViewSKey, uint32(uod.SubAddrIndex))
+		secretKey = xcrypto.SecretAdd(secretKey, subaddrSk)
+	}
+	return secretKey, nil
+}
+
+// GetSpendProof generate a proof that the wallet spent the inputs of tx
+func (la *LinkAccount) GetSpendProof(hash common.Hash, message string) (string, error) {
+	if la.isMultisig() {
+		return "", ErrMultisigNotSupport
+	}
+	tx, err := la.loadUTXOTx(hash)
+	if err != nil {
+		return "", err
+	}
+	prefix := spendProofPrefixHash(hash, message)
+
+	la.lock.Lock()
+	defer la.lock.Unlock()
+
+	var proof spendProof
+	for _, input := range tx.Inputs {
+		ri, ok := input.(*tctypes.UTXOInput)
+		if !ok {
+			continue
+		}
+		tid, ok := la.keyImages[ri.KeyImage]
+		if !ok {
+			return "", ErrInputNotOwned
+		}
+		uod := la.Transfers[tid]
+		output, err := getUTXOOutput(uod.Tx, uod.OutIndex)
+		if err != nil {
+			return "", err
+		}
+		secretKey, err := la.getOutputSecretKey(uod)
+		if err != nil {
+			return "", err
+		}
+		pks, err := getRingPubKeys(ri, tx.TokenID)
+		if err != nil {
+			return "", err
+		}
+		realIdx := -1
+		for i, pk := range pks {
+			if pk == lkctypes.PublicKey(output.OTAddr) {
+				realIdx = i
+				break
+			}
+		}
+		if realIdx < 0 {
+			return "", ErrRingMemberNotFound
+		}
+		sig, err := xcrypto.GenerateRingSignature(prefix, lkctypes.KeyImage(ri.KeyImage), pks, secretKey, uint(realIdx))
+		if err != nil {
+			la.Logger.Error("GetSpendProof GenerateRingSignature fail", "hash", hash, "err", err)
+			return "", err
+		}
+		proof.Sigs = append(proof.Sigs, *sig)
+	}
+	if len(proof.Sigs) == 0 {
+		return "", ErrNoUTXOInputInTx
+	}
+	return encodeProof(spendProofHeader, &proof)
+}
+
+// newReserveProofEntry build the unsigned reserve proof entry of an owned output.
+// The shared secret a*R and the subaddress secret let verifiers rederive the
+// one-time address of the output from the view and spend keys of the address.
+func (la *LinkAccount) newReserveProofEntry(uod *tctypes.UTXOOutputDetail) (*reserveProofEntry, error) {
+	keys := la.account.GetKeys()
+	e := &reserveProofEntry{
+		TxID:     common.Hash(uod.TxID),
+		OutIndex: uod.OutIndex,
+		KeyImage: uod.KeyImage,
+	}
+	if uod.RKey != uod.Tx.RKey {
+		found := false
+		for i, addKey := range uod.Tx.AddKeys {
+			if addKey == uod.RKey {
+				e.RKeyIndex = uint64(i + 1)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrProofInvalid
+		}
+	}
+	sharedSecret, err := xcrypto.ScalarmultKey(lkctypes.Key(uod.RKey), lkctypes.Key(keys.ViewSKey))
+	if err != nil {
+		return nil, err
+	}
+	e.SharedSecret = sharedSecret
+	if uod.SubAddrIndex > 0 {
+		e.SubAddrSecret = lkctypes.Key(xcrypto.GetSubaddressSecretKey(keys.ViewSKey, uint32(uod.SubAddrIndex)))
+	}
+	return e, nil
+}
+
+// signReserveProofEntry sign the shared secret of e with the view key and its key image
+// with the one-time key of the output uod
+func (la *LinkAccount) signReserveProofEntry(prefix lkctypes.Hash, e *reserveProofEntry, uod *tctypes.UTXOOutputDetail) error {
+	keys := la.account.GetKeys()
+	output, err := getUTXOOutput(uod.Tx, uod.OutIndex)
+	if err != nil {
+		return err
+	}
+	ssProof, err := generateSharedSecretProof(prefix, lkctypes.Key(keys.Addr.ViewPublicKey), lkctypes.Key(uod.RKey), e.SharedSecret, lkctypes.Key(keys.ViewSKey))
+	if err != nil {
+		return err
+	}
+	e.SharedSecretSig = *ssProof
+
+	secretKey, err := la.getOutputSecretKey(uod)
+	if err != nil {
+		return err
+	}
+	sig, err := xcrypto.GenerateRingSignature(prefix, lkctypes.KeyImage(e.KeyImage), []lkctypes.PublicKey{lkctypes.PublicKey(output.OTAddr)}, secretKey, 0)
+	if err != nil {
+		return err
+	}
+	e.KeyImageSig = *sig
+	return nil
+}
+
+// signReserveProof sign the reserve proof with the main spend key of the account
+func (la *LinkAccount) signReserveProof(prefix lkctypes.Hash, proof *reserveProof) error {
+	keys := la.account.GetKeys()
+	addrKeyImage, err := xcrypto.GenerateKeyImage(keys.Addr.SpendPublicKey, keys.SpendSKey)
+	if err != nil {
+		return err
+	}
+	sig, err := xcrypto.GenerateRingSignature(prefix, addrKeyImage, []lkctypes.PublicKey{keys.Addr.SpendPublicKey}, keys.SpendSKey, 0)
+	if err != nil {
+		return err
+	}
+	proof.AddrKeyImage = lkctypes.Key(addrKeyImage)
+	proof.Sig = *sig
+	return nil
+}
+
+// GetReserveProof generate a proof that the account owns unspent outputs of tokenID
+// worth at least amount. A nil amount proves all unspent outputs.
+func (la *LinkAccount) GetReserveProof(tokenID common.Address, amount *big.Int, message string) (string, error) {
+	if la.isMultisig() {
+		return "", ErrMultisigNotSupport
+	}
+	la.lock.Lock()
+	defer la.lock.Unlock()
+
+	keys := la.account.GetKeys()
+	proof := reserveProof{TokenID: tokenID}
+	uods := make([]*tctypes.UTXOOutputDetail, 0)
+	total := big.NewInt(0)
+	for _, uod := range la.Transfers {
+		if amount != nil && total.Cmp(amount) >= 0 {
+			break
+		}
+		if uod.Spent || uod.Frozen || uod.TokenID != tokenID {
+			continue
+		}
+		if uod.SubAddrIndex >= uint64(len(la.account.Keys)) {
+			continue
+		}
+		e, err := la.newReserveProofEntry(uod)
+		if err != nil {
+			return "", err
+		}
+		proof.Entries = append(proof.Entries, *e)
+		uods = append(uods, uod)
+		total.Add(total, uod.Amount)
+	}
+	if len(proof.Entries) == 0 || (amount != nil && total.Cmp(amount) < 0) {
+		return "", ErrBalanceNotEnough
+	}
+
+	prefix := reserveProofPrefixHash(message, &keys.Addr, &proof)
+	for i := range proof.Entries {
+		if err := la.signReserveProofEntry(prefix, &proof.Entries[i], uods[i]); err != nil {
+			return "", err
+		}
+	}
+	if err := la.signReserveProof(prefix, &proof); err != nil {
+		return "", err
+	}
+	return encodeProof(reserveProofHeader, &proof)
+}
+
+// GetSpendProof return a proof that the current account spent tx
+func (w *Wallet) GetSpendProof(hash common.Hash, message string) (string, error) {
+	if w.IsWalletClosed() {
+		return "", wtypes.ErrWalletNotOpen
+	}
+	return w.currAccount.GetSpendProof(hash, message)
+}
+
+// GetReserveProof return a proof of the current account's unspent reserve
+func (w *Wallet) GetReserveProof(tokenID common.Address, amount *big.Int, message string) (string, error) {
+	if w.IsWalletClosed() {
+		return "", wtypes.ErrWalletNotOpen
+	}
+	return w.currAccount.GetReserveProof(tokenID, amount, message)
+}
+
+// CheckSpendProof verify a spend proof of tx against chain data
+func (w *Wallet) CheckSpendProof(hash common.Hash, message string, signature string) (bool, error) {
+	var proof spendProof
+	if err := decodeProof(spendProofHeader, signature, &proof); err != nil {
+		return false, err
+	}
+	tx, err := w.GetUTXOTxFromNode(hash)
+	if err != nil {
+		return false, err
+	}
+	prefix := spendProofPrefixHash(hash, message)
+
+	sigIdx := 0
+	for _, input := range tx.Inputs {
+		ri, ok := input.(*tctypes.UTXOInput)
+		if !ok {
+			continue
+		}
+		if sigIdx >= len(proof.Sigs) {
+			return false, nil
+		}
+		pks, err := getRingPubKeys(ri, tx.TokenID)
+		if err != nil {
+			return false, err
+		}
+		if len(pks) == 0 || !xcrypto.CheckRingSignature(prefix, lkctypes.KeyImage(ri.KeyImage), pks, &proof.Sigs[sigIdx]) {
+			return false, nil
+		}
+		sigIdx++
+	}
+	if sigIdx == 0 {
+		return false, ErrNoUTXOInputInTx
+	}
+	return sigIdx == len(proof.Sigs), nil
+}
+
+// CheckReserveProof verify a reserve proof for addr, and return the proven outputs
+// with their amounts and spent status queried from the node
+func (w *Wallet) CheckReserveProof(addr string, message string, signature string) (*wtypes.CheckReserveProofRet, error) {
+	address, err := StrToAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	var proof reserveProof
+	if err := decodeProof(reserveProofHeader, signature, &proof); err != nil {
+		return nil, err
+	}
+	ret := &wtypes.CheckReserveProofRet{
+		Good:    false,
+		TokenID: proof.TokenID,
+		Total:   (*hexutil.Big)(big.NewInt(0)),
+		Spent:   (*hexutil.Big)(big.NewInt(0)),
+		Records: make([]*wtypes.ReserveProofRecord, 0, len(proof.Entries)),
+	}
+	if len(proof.Entries) == 0 {
+		return ret, nil
+	}
+
+	prefix := reserveProofPrefixHash(message, address, &proof)
+	if !xcrypto.CheckRingSignature(prefix, lkctypes.KeyImage(proof.AddrKeyImage), []lkctypes.PublicKey{address.SpendPublicKey}, &proof.Sig) {
+		return ret, nil
+	}
+
+	keyImages := make([]lkctypes.Key, 0, len(proof.Entries))
+	for i := range proof.Entries {
+		e := &proof.Entries[i]
+		tx, err := w.GetUTXOTxFromNode(e.TxID)
+		if err != nil {
+			return nil, err
+		}
+		if tx.TokenID != proof.TokenID {
+			return ret, nil
+		}
+		output, err := getUTXOOutput(tx, e.OutIndex)
+		if err != nil || e.OutIndex >= uint64(len(tx.RCTSig.RctSigBase.EcdhInfo)) || e.OutIndex >= uint64(len(tx.RCTSig.OutPk)) {
+			return ret, nil
+		}
+		// the output must be paid to addr: the shared secret is bound to the view key
+		// of addr and the spend key is addr's or one of its subaddresses'
+		var rKey lkctypes.PublicKey
+		switch {
+		case e.RKeyIndex == 0:
+			rKey = tx.RKey
+		case e.RKeyIndex <= uint64(len(tx.AddKeys)):
+			rKey = tx.AddKeys[e.RKeyIndex-1]
+		default:
+			return ret, nil
+		}
+		if !checkSharedSecretProof(prefix, lkctypes.Key(address.ViewPublicKey), lkctypes.Key(rKey), e.SharedSecret, &e.SharedSecretSig) {
+			return ret, nil
+		}
+		derivation, err := xcrypto.Scalarmult8(e.SharedSecret)
+		if err != nil {
+			return ret, nil
+		}
+		spendPubKey := address.SpendPublicKey
+		if e.SubAddrSecret != (lkctypes.Key{}) {
+			subSpendKey, err := ringct.AddKeys(lkctypes.Key(address.SpendPublicKey), ringct.ScalarmultBase(e.SubAddrSecret))
+			if err != nil {
+				return ret, nil
+			}
+			spendPubKey = lkctypes.PublicKey(subSpendKey)
+		}
+		otAddr, err := xcrypto.DerivePublicKey(lkctypes.KeyDerivation(derivation), int(e.OutIndex), spendPubKey)
+		if err != nil || otAddr != lkctypes.PublicKey(output.OTAddr) {
+			return ret, nil
+		}
+		if !xcrypto.CheckRingSignature(prefix, lkctypes.KeyImage(e.KeyImage), []lkctypes.PublicKey{otAddr}, &e.KeyImageSig) {
+			return ret, nil
+		}
+
+		scalar, err := xcrypto.DerivationToScalar(lkctypes.KeyDerivation(derivation), int(e.OutIndex))
+		if err != nil {
+			return nil, err
+		}
+		ecdh := &lkctypes.EcdhTuple{
+			Mask:   tx.RCTSig.RctSigBase.EcdhInfo[e.OutIndex].Mask,
+			Amount: tx.RCTSig.RctSigBase.EcdhInfo[e.OutIndex].Amount,
+		}
+		if !xcrypto.EcdhDecode(ecdh, lkctypes.Key(scalar), false) {
+			return ret, nil
+		}
+		commit, err := ringct.AddKeys2(ecdh.Mask, ecdh.Amount, ringct.H)
+		if err != nil || commit != tx.RCTSig.OutPk[e.OutIndex].Mask {
+			return ret, nil
+		}
+		amount := new(big.Int).Mul(tctypes.Hash2BigInt(ecdh.Amount), big.NewInt(tctypes.UTXO_COMMITMENT_CHANGE_RATE))
+
+		ret.Records = append(ret.Records, &wtypes.ReserveProofRecord{
+			Hash:     e.TxID,
+			OutIndex: hexutil.Uint64(e.OutIndex),
+			KeyImage: fmt.Sprintf("%x", e.KeyImage[:]),
+			Amount:   (*hexutil.Big)(amount),
+		})
+		keyImages = append(keyImages, e.KeyImage)
+	}
+
+	spent, err := GetKeyImagesSpentFromNode(keyImages)
+	if err != nil {
+		return nil, err
+	}
+	total, spentTotal := big.NewInt(0), big.NewInt(0)
+	for i, record := range ret.Records {
+		record.Spent = spent[i]
+		total.Add(total, record.Amount.ToInt())
+		if record.Spent {
+			spentTotal.Add(spentTotal, record.Amount.ToInt())
+		}
+	}
+	ret.Good = true
+	ret.Total = (*hexutil.Big)(total)
+	ret.Spent = (*hexutil.Big)(spentTotal)
+	return ret, nil
+}
@@ -0,0 +1,363 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lianxiangcloud/linkchain/libs/common"
+	"github.com/lianxiangcloud/linkchain/libs/cryptonote/ringct"
+	lktypes "github.com/lianxiangcloud/linkchain/libs/cryptonote/types"
+	"github.com/lianxiangcloud/linkchain/libs/cryptonote/xcrypto"
+	dbm "github.com/lianxiangcloud/linkchain/libs/db"
+	"github.com/lianxiangcloud/linkchain/libs/log"
+	rtypes "github.com/lianxiangcloud/linkchain/rpc/rtypes"
+	"github.com/lianxiangcloud/linkchain/types"
+	"github.com/lianxiangcloud/linkchain/wallet/config"
+	"github.com/lianxiangcloud/linkchain/wallet/daemon"
+)
+
+// proofNode is the chain data served by mockProofDaemon
+type proofNode struct {
+	txs     map[common.Hash]*types.UTXOTransaction
+	outputs map[uint64]lktypes.Key
+	commits map[uint64]lktypes.Key // commitment of an output, the otaddr if missing
+	spent   map[lktypes.Key]bool
+}
+
+func newProofNode() *proofNode {
+	return &proofNode{
+		txs:     make(map[common.Hash]*types.UTXOTransaction),
+		outputs: make(map[uint64]lktypes.Key),
+		commits: make(map[uint64]lktypes.Key),
+		spent:   make(map[lktypes.Key]bool),
+	}
+}
+
+func (n *proofNode) result(method string, param json.RawMessage) (interface{}, error) {
+	switch method {
+	case "eth_getTransactionByHash":
+		var hash string
+		if err := json.Unmarshal(param, &hash); err != nil {
+			return nil, err
+		}
+		tx, ok := n.txs[common.HexToHash(hash)]
+		if !ok {
+			return nil, fmt.Errorf("tx %s not found", hash)
+		}
+		return &rtypes.RPCTx{TxType: types.TxUTXO, TxHash: common.HexToHash(hash), Tx: tx}, nil
+	case "eth_getOutputs":
+		var args []OutputArg
+		if err := json.Unmarshal(param, &args); err != nil {
+			return nil, err
+		}
+		outputs := make([]*RPCOutput, len(args))
+		for i, arg := range args {
+			otaddr, ok := n.outputs[uint64(arg.Index)]
+			if !ok {
+				return nil, fmt.Errorf("output %d not found", arg.Index)
+			}
+			commit, ok := n.commits[uint64(arg.Index)]
+			if !ok {
+				commit = otaddr
+			}
+			outputs[i] = &RPCOutput{Out: hex.EncodeToString(otaddr[:]), Commit: hex.EncodeToString(commit[:])}
+		}
+		return outputs, nil
+	case "eth_getKeyImagesSpent":
+		var kis []rtypes.RPCKey
+		if err := json.Unmarshal(param, &kis); err != nil {
+			return nil, err
+		}
+		spent := make([]bool, len(kis))
+		for i, ki := range kis {
+			spent[i] = n.spent[lktypes.Key(ki)]
+		}
+		return spent, nil
+	}
+	return nil, fmt.Errorf("unexpected method %s", method)
+}
+
+// mockProofDaemon answer the node queries of the proofs with the chain data of n
+func mockProofDaemon(t *testing.T, n *proofNode) func() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Params) != 1 {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		res, err := n.result(req.Method, req.Params[0])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		bz, err := json.Marshal(res)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":"0","result":%s}`, bz)
+	}))
+	daemon.InitDaemonClient(&config.DaemonConfig{PeerRPC: srv.URL})
+	return func() {
+		srv.Close()
+		daemon.InitDaemonClient(config.DefaultDaemonConfig())
+	}
+}
+
+func newProofAccount(t *testing.T) *LinkAccount {
+	acc, err := RecoveryKeyToAccount(lktypes.SecretKey(ringct.SkGen()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &LinkAccount{
+		Logger:          log.Root(),
+		account:         acc,
+		walletDB:        dbm.NewMemDB(),
+		mainUTXOAddress: acc.GetKeys().Address,
+		txKeys:          make(map[common.Hash]lktypes.Key),
+		keyImages:       make(map[lktypes.Key]int),
+		Transfers:       make(transferContainer, 0),
+	}
+}
+
+// receiveOutput add an output of amount paid to la, the output is put on the node
+// with global index gIdx
+func receiveOutput(t *testing.T, la *LinkAccount, n *proofNode, gIdx uint64, tokenID common.Address, amount *big.Int) *types.UTXOOutputDetail {
+	addr := la.account.GetKeys().Addr
+	r := ringct.SkGen()
+	derivation, err := xcrypto.GenerateKeyDerivation(addr.ViewPublicKey, lktypes.SecretKey(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otaddr, err := xcrypto.DerivePublicKey(derivation, 0, addr.SpendPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scalar, err := xcrypto.DerivationToScalar(derivation, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amountKey, err := types.BigInt2Hash(new(big.Int).Div(amount, big.NewInt(types.UTXO_COMMITMENT_CHANGE_RATE)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdh := lktypes.EcdhTuple{Mask: ringct.SkGen(), Amount: amountKey}
+	commit, err := ringct.AddKeys2(ecdh.Mask, ecdh.Amount, ringct.H)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !xcrypto.EcdhEncode(&ecdh, lktypes.Key(scalar), false) {
+		t.Fatal("EcdhEncode fail")
+	}
+	tx := &types.UTXOTransaction{
+		Outputs: []types.Output{&types.UTXOOutput{OTAddr: lktypes.Key(otaddr), Amount: big.NewInt(0)}},
+		TokenID: tokenID,
+		Fee:     big.NewInt(0),
+		RCTSig: lktypes.RctSig{RctSigBase: lktypes.RctSigBase{
+			EcdhInfo: []lktypes.EcdhTuple{ecdh},
+			OutPk:    lktypes.CtkeyV{{Dest: lktypes.Key(otaddr), Mask: commit}},
+		}},
+	}
+	txID := common.BigToHash(new(big.Int).SetUint64(gIdx + 1))
+	uod := &types.UTXOOutputDetail{
+		Tx:          tx,
+		TxID:        lktypes.Hash(txID),
+		GlobalIndex: gIdx,
+		RKey:        lktypes.PublicKey(ringct.ScalarmultBase(r)),
+		Amount:      amount,
+		TokenID:     tokenID,
+	}
+	secretKey, err := la.getOutputSecretKey(uod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyImage, err := xcrypto.GenerateKeyImage(lktypes.PublicKey(otaddr), secretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uod.KeyImage = lktypes.Key(keyImage)
+	la.keyImages[uod.KeyImage] = len(la.Transfers)
+	la.Transfers = append(la.Transfers, uod)
+
+	n.txs[txID] = tx
+	n.outputs[gIdx] = lktypes.Key(otaddr)
+	return uod
+}
+
+func TestProofEncode(t *testing.T) {
+	proof := &spendProof{Sigs: []lktypes.Signature{{}}}
+	proof.Sigs[0].C[0] = 1
+	str, err := encodeProof(spendProofHeader, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded spendProof
+	if err := decodeProof(spendProofHeader, str, &decoded); err != nil || len(decoded.Sigs) != 1 || decoded.Sigs[0] != proof.Sigs[0] {
+		t.Fatalf("decodeProof got %v %v, want %v", decoded, err, *proof)
+	}
+	for _, bad := range []string{"", spendProofHeader, reserveProofHeader + str[len(spendProofHeader):], spendProofHeader + "0OIl"} {
+		if err := decodeProof(spendProofHeader, bad, &decoded); err != ErrProofInvalid {
+			t.Fatalf("decodeProof of %q got %v, want %v", bad, err, ErrProofInvalid)
+		}
+	}
+
+	if abs := keyOffsetsToAbsolute([]uint64{3, 1, 4}); abs[0] != 3 || abs[1] != 4 || abs[2] != 8 {
+		t.Fatalf("keyOffsetsToAbsolute got %v, want [3 4 8]", abs)
+	}
+}
+
+func TestSpendProof(t *testing.T) {
+	n := newProofNode()
+	defer mockProofDaemon(t, n)()
+	la := newProofAccount(t)
+	uod := receiveOutput(t, la, n, 7, LinkToken, big.NewInt(1e18))
+	decoy := newProofAccount(t)
+	receiveOutput(t, decoy, n, 3, LinkToken, big.NewInt(1e18))
+
+	// the ring of the input is the outputs 3 and 7
+	tx := &types.UTXOTransaction{
+		Inputs:  []types.Input{&types.UTXOInput{KeyOffset: []uint64{3, 4}, KeyImage: uod.KeyImage}},
+		TokenID: LinkToken,
+		Fee:     big.NewInt(0),
+	}
+	if err := la.saveUTXOTx(tx); err != nil {
+		t.Fatal(err)
+	}
+	hash := tx.Hash()
+	n.txs[hash] = tx
+
+	proof, err := la.GetSpendProof(hash, "spent by me")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &Wallet{Logger: log.Root()}
+	if good, err := w.CheckSpendProof(hash, "spent by me", proof); err != nil || !good {
+		t.Fatalf("CheckSpendProof got %v %v, want true", good, err)
+	}
+	if good, err := w.CheckSpendProof(hash, "spent by other", proof); err != nil || good {
+		t.Fatalf("CheckSpendProof of other message got %v %v, want false", good, err)
+	}
+
+	// the decoy account does not own the input
+	if err := decoy.saveUTXOTx(tx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decoy.GetSpendProof(hash, "spent by me"); err != ErrInputNotOwned {
+		t.Fatalf("GetSpendProof of decoy got %v, want %v", err, ErrInputNotOwned)
+	}
+}
+
+func TestReserveProof(t *testing.T) {
+	n := newProofNode()
+	defer mockProofDaemon(t, n)()
+	la := newProofAccount(t)
+	receiveOutput(t, la, n, 0, LinkToken, big.NewInt(1e18))
+	spent := receiveOutput(t, la, n, 1, LinkToken, big.NewInt(2e18))
+	n.spent[spent.KeyImage] = true
+	// frozen outputs and outputs of other tokens are not proven
+	receiveOutput(t, la, n, 2, LinkToken, big.NewInt(4e18)).Frozen = true
+	receiveOutput(t, la, n, 3, common.HexToAddress("0x1"), big.NewInt(8e18))
+
+	if _, err := la.GetReserveProof(LinkToken, big.NewInt(4e18), "reserve"); err != ErrBalanceNotEnough {
+		t.Fatalf("GetReserveProof of 4e18 got %v, want %v", err, ErrBalanceNotEnough)
+	}
+	proof, err := la.GetReserveProof(LinkToken, nil, "reserve")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := &Wallet{Logger: log.Root()}
+	addr := la.account.GetKeys().Address
+	ret, err := w.CheckReserveProof(addr, "reserve", proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ret.Good || len(ret.Records) != 2 || ret.TokenID != LinkToken {
+		t.Fatalf("CheckReserveProof got good %v with %d records of %s, want 2 records of %s", ret.Good, len(ret.Records), ret.TokenID.String(), LinkToken.String())
+	}
+	if ret.Total.ToInt().Cmp(big.NewInt(3e18)) != 0 || ret.Spent.ToInt().Cmp(big.NewInt(2e18)) != 0 {
+		t.Fatalf("CheckReserveProof got total %s spent %s, want 3e18 2e18", ret.Total.ToInt(), ret.Spent.ToInt())
+	}
+	if ret.Records[0].Spent || !ret.Records[1].Spent {
+		t.Fatalf("CheckReserveProof got spent %v %v, want false true", ret.Records[0].Spent, ret.Records[1].Spent)
+	}
+
+	if ret, err := w.CheckReserveProof(addr, "other", proof); err != nil || ret.Good {
+		t.Fatalf("CheckReserveProof of other message got %+v %v, want not good", ret, err)
+	}
+	other := newProofAccount(t).account.GetKeys().Address
+	if ret, err := w.CheckReserveProof(other, "reserve", proof); err != nil || ret.Good {
+		t.Fatalf("CheckReserveProof of other address got %+v %v, want not good", ret, err)
+	}
+}
+
+func TestReserveProofForeignOutputs(t *testing.T) {
+	n := newProofNode()
+	defer mockProofDaemon(t, n)()
+	la := newProofAccount(t)
+	own := receiveOutput(t, la, n, 0, LinkToken, big.NewInt(1e18))
+	// the prover also owns the keys of lb, but its outputs are not paid to la
+	lb := newProofAccount(t)
+	foreign := receiveOutput(t, lb, n, 1, LinkToken, big.NewInt(2e18))
+
+	forge := func(accs []*LinkAccount, uods []*types.UTXOOutputDetail) string {
+		keys := la.account.GetKeys()
+		proof := reserveProof{TokenID: LinkToken}
+		for i, uod := range uods {
+			e, err := accs[i].newReserveProofEntry(uod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			proof.Entries = append(proof.Entries, *e)
+		}
+		prefix := reserveProofPrefixHash("reserve", &keys.Addr, &proof)
+		for i, uod := range uods {
+			if err := accs[i].signReserveProofEntry(prefix, &proof.Entries[i], uod); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := la.signReserveProof(prefix, &proof); err != nil {
+			t.Fatal(err)
+		}
+		str, err := encodeProof(reserveProofHeader, &proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return str
+	}
+
+	w := &Wallet{Logger: log.Root()}
+	addr := la.account.GetKeys().Address
+	proof := forge([]*LinkAccount{la}, []*types.UTXOOutputDetail{own})
+	if ret, err := w.CheckReserveProof(addr, "reserve", proof); err != nil || !ret.Good || len(ret.Records) != 1 {
+		t.Fatalf("CheckReserveProof of own outputs got %+v %v, want good", ret, err)
+	}
+	proof = forge([]*LinkAccount{la, lb}, []*types.UTXOOutputDetail{own, foreign})
+	if ret, err := w.CheckReserveProof(addr, "reserve", proof); err != nil || ret.Good {
+		t.Fatalf("CheckReserveProof with outputs of another wallet got %+v %v, want not good", ret, err)
+	}
+
+	// the shared secret of the foreign output signed with the view key of la does not
+	// derive its one-time address
+	keys := la.account.GetKeys()
+	e, err := lb.newReserveProofEntry(foreign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := reserveProofPrefixHash("reserve", &keys.Addr, &reserveProof{TokenID: LinkToken, Entries: []reserveProofEntry{*e}})
+	ssProof, err := generateSharedSecretProof(prefix, lktypes.Key(keys.Addr.ViewPublicKey), lktypes.Key(foreign.RKey), e.SharedSecret, lktypes.Key(keys.ViewSKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkSharedSecretProof(prefix, lktypes.Key(keys.Addr.ViewPublicKey), lktypes.Key(foreign.RKey), e.SharedSecret, ssProof) {
+		t.Fatal("checkSharedSecretProof of a shared secret of another view key got true, want false")
+	}
+}
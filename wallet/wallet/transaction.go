@@ -70,6 +70,9 @@ func (wallet *Wallet) CreateUTXOTransaction(from common.Address, nonce uint64, s
 	if wallet.IsWalletClosed() {
 		return nil, wtypes.ErrWalletNotOpen
 	}
+	if wallet.currAccount.isMultisig() {
+		return nil, ErrMultisigNotSupport
+	}
 	if from == common.EmptyAddress {
 		wallet.Logger.Debug("CreateUTXOTransaction from is EmptyAddress,use CreateUinTransaction")
 		return wallet.CreateUinTransaction(wallet.currAccount.getEthAddress(), "", subaddrs, dests, tokenID, refundAddr, extra)
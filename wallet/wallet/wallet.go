@@ -69,7 +69,7 @@ func (w *Wallet) OpenWallet(keystoreFile string, password string) error {
 	w.lock.Lock()
 	defer w.lock.Unlock()
 
-	la, err := NewLinkAccount(w.walletDB, w.Logger, keystoreFile, password)
+	la, err := NewLinkAccount(w.walletDB, w.Logger, keystoreFile, password, w.config.SubaddressLookahead)
 	if err != nil {
 		w.Logger.Error("OpenWallet NewLinkAccount fail", "err", err)
 		return err
@@ -94,6 +94,18 @@ func (w *Wallet) OpenWallet(keystoreFile string, password string) error {
 	return nil
 }
 
+// ChangePassword re-encrypt walletDB values of the opened account addr with the new password
+func (w *Wallet) ChangePassword(addr common.Address, password string, newPassword string) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	la, ok := w.addrMap[addr]
+	if !ok {
+		return wtypes.ErrWalletNotOpen
+	}
+	return la.ChangePassword(password, newPassword)
+}
+
 // IsWalletClosed return true if currAccount is nil
 func (w *Wallet) IsWalletClosed() bool {
 	return w.currAccount == nil
@@ -170,6 +182,14 @@ func (w *Wallet) CreateSubAccount(maxSub uint64) error {
 	return w.currAccount.CreateSubAccount(maxSub)
 }
 
+// SetSubaddressLookahead set the subaddress lookahead of current account
+func (w *Wallet) SetSubaddressLookahead(lookahead uint64) error {
+	if w.IsWalletClosed() {
+		return wtypes.ErrWalletNotOpen
+	}
+	return w.currAccount.SetSubaddressLookahead(lookahead)
+}
+
 // AutoRefreshBlockchain set autoRefresh
 func (w *Wallet) AutoRefreshBlockchain(autoRefresh bool) error {
 	if w.IsWalletClosed() {
@@ -22,6 +22,8 @@ const (
 	keyAccountSubCnt    = "accountSubCnt"
 	keyTxKeys           = "txKeys"
 	keyUTXOTx           = "utxoTx"
+	keyMultisig         = "multisig"
+	keySubaddrLookahead = "subaddrLookahead"
 )
 
 func (la *LinkAccount) save(ids []int) error {
@@ -50,7 +52,10 @@ func (la *LinkAccount) getLocalHeightKey() []byte {
 func (la *LinkAccount) loadLocalHeight() error {
 	key := la.getLocalHeightKey()
 
-	val := la.walletDB.Get(key[:])
+	val, err := la.dbGet(key[:])
+	if err != nil {
+		return err
+	}
 	if len(val) != 0 {
 		if err := ser.DecodeBytes(val, &la.localHeight); err != nil {
 			la.Logger.Error("loadLocalHeight DecodeBytes fail", "val", string(val), "err", err)
@@ -68,7 +73,7 @@ func (la *LinkAccount) saveLocalHeight(b dbm.Batch) error {
 		la.Logger.Error("saveLocalHeight EncodeToBytes fail", "err", err)
 		return err
 	}
-	b.Set(key, val)
+	la.dbSet(b, key, val)
 	return nil
 }
 
@@ -80,7 +85,10 @@ func (la *LinkAccount) getGOutIndexKey() []byte {
 func (la *LinkAccount) loadGOutIndex() error {
 	key := la.getGOutIndexKey()
 
-	val := la.walletDB.Get(key[:])
+	val, err := la.dbGet(key[:])
+	if err != nil {
+		return err
+	}
 	if len(val) != 0 {
 		if err := json.Unmarshal(val, &la.gOutIndex); err != nil {
 			la.Logger.Error("loadGOutIndex DecodeBytes fail", "val", string(val), "err", err)
@@ -99,7 +107,7 @@ func (la *LinkAccount) saveGOutIndex(b dbm.Batch) error {
 		la.Logger.Error("saveGOutIndex EncodeToBytes fail", "err", err)
 		return err
 	}
-	b.Set(key, val)
+	la.dbSet(b, key, val)
 	return nil
 }
 
@@ -115,7 +123,10 @@ func (la *LinkAccount) getTransfersCntKey() []byte {
 func (la *LinkAccount) loadTransfers() error {
 	var cnt int
 	key := la.getTransfersCntKey()
-	val := la.walletDB.Get(key[:])
+	val, err := la.dbGet(key[:])
+	if err != nil {
+		return err
+	}
 	if len(val) != 0 {
 		if err := ser.UnmarshalJSON(val, &cnt); err != nil {
 			la.Logger.Error("loadTransfers DecodeBytes fail", "val", string(val), "err", err)
@@ -131,7 +142,10 @@ func (la *LinkAccount) loadTransfers() error {
 	// }
 	for i := 0; i < cnt; i++ {
 		k := la.getTransfersKey(i)
-		v := la.walletDB.Get(k[:])
+		v, err := la.dbGet(k[:])
+		if err != nil {
+			return err
+		}
 		if len(v) != 0 {
 			var tx tctypes.UTXOOutputDetail
 			if err := ser.UnmarshalJSON(v, &tx); err != nil {
@@ -144,7 +158,10 @@ func (la *LinkAccount) loadTransfers() error {
 				la.updateBalance(tx.TokenID, tx.SubAddrIndex, true, tx.Amount)
 			}
 
-			la.keyImages[tx.KeyImage] = i
+			// key image of multisig output is unknown until multisig info imported
+			if tx.KeyImage != (lkctypes.Key{}) {
+				la.keyImages[tx.KeyImage] = i
+			}
 		}
 	}
 	return nil
@@ -158,7 +175,7 @@ func (la *LinkAccount) saveTransfers(b dbm.Batch, tids []int) error {
 		la.Logger.Error("saveTransfers EncodeToBytes fail", "err", err)
 		return err
 	}
-	b.Set(key, val)
+	la.dbSet(b, key, val)
 
 	for _, i := range tids {
 		k := la.getTransfersKey(i)
@@ -167,7 +184,7 @@ func (la *LinkAccount) saveTransfers(b dbm.Batch, tids []int) error {
 			la.Logger.Error("saveTransfers EncodeToBytes fail", "err", err)
 			return err
 		}
-		b.Set(k, val)
+		la.dbSet(b, k, val)
 	}
 	return nil
 }
@@ -180,7 +197,10 @@ func (la *LinkAccount) getAccountSubCntKey() []byte {
 func (la *LinkAccount) loadAccountSubCnt() (int, error) {
 	key := la.getAccountSubCntKey()
 
-	val := la.walletDB.Get(key[:])
+	val, err := la.dbGet(key[:])
+	if err != nil {
+		return 0, err
+	}
 	if len(val) != 0 {
 		var cnt int
 		if err := ser.UnmarshalJSON(val, &cnt); err != nil {
@@ -203,7 +223,41 @@ func (la *LinkAccount) saveAccountSubCnt(b dbm.Batch) error {
 		la.Logger.Error("saveAccountSubCnt EncodeToBytes fail", "err", err)
 		return err
 	}
-	b.Set(key, val)
+	la.dbSet(b, key, val)
+	return nil
+}
+
+// keySubaddrLookahead
+func (la *LinkAccount) getSubaddrLookaheadKey() []byte {
+	return []byte(la.addPrefixDBkey(keySubaddrLookahead))
+}
+
+func (la *LinkAccount) loadSubaddrLookahead() error {
+	key := la.getSubaddrLookaheadKey()
+
+	val, err := la.dbGet(key[:])
+	if err != nil {
+		return err
+	}
+	if len(val) != 0 {
+		var lookahead uint64
+		if err := ser.UnmarshalJSON(val, &lookahead); err != nil {
+			la.Logger.Error("loadSubaddrLookahead DecodeBytes fail", "val", string(val), "err", err)
+			return err
+		}
+		la.subaddrLookahead = lookahead
+	}
+	return nil
+}
+
+func (la *LinkAccount) saveSubaddrLookahead(b dbm.Batch) error {
+	key := la.getSubaddrLookaheadKey()
+	val, err := ser.MarshalJSON(la.subaddrLookahead)
+	if err != nil {
+		la.Logger.Error("saveSubaddrLookahead EncodeToBytes fail", "err", err)
+		return err
+	}
+	la.dbSet(b, key, val)
 	return nil
 }
 
@@ -254,7 +308,9 @@ func (la *LinkAccount) saveTxKeys(hash common.Hash, txKey *lkctypes.Key) error {
 	key = append(key, hash[:]...)
 	key = append(key, byte('_'))
 	key = append(key, la.getTxKeysKey()...)
-	return la.walletDB.Put(key, txKey[:])
+	batch := la.walletDB.NewBatch()
+	la.dbSet(batch, key, txKey[:])
+	return batch.Commit()
 }
 
 // UTXOTransaction
@@ -264,7 +320,10 @@ func (la *LinkAccount) getUTXOTxKey(hash common.Hash) []byte {
 
 func (la *LinkAccount) loadUTXOTx(hash common.Hash) (*tctypes.UTXOTransaction, error) {
 	key := la.getUTXOTxKey(hash)
-	val := la.walletDB.Get(key[:])
+	val, err := la.dbGet(key[:])
+	if err != nil {
+		return nil, err
+	}
 	if len(val) == 0 {
 		return nil, types.ErrTxNotFound
 	}
@@ -284,6 +343,41 @@ func (la *LinkAccount) saveUTXOTx(utxoTx *tctypes.UTXOTransaction) error {
 		return err
 	}
 	batch := la.walletDB.NewBatch()
-	batch.Set(key, val)
+	la.dbSet(batch, key, val)
+	return batch.Commit()
+}
+
+// multisig, keyed by eth address for the utxo address changes once multisig is made
+func (la *LinkAccount) getMultisigKey() []byte {
+	return []byte(fmt.Sprintf("%s_%s", la.getEthAddress().String(), keyMultisig))
+}
+
+func (la *LinkAccount) loadMultisig() error {
+	key := la.getMultisigKey()
+	val, err := la.dbGet(key[:])
+	if err != nil {
+		return err
+	}
+	if len(val) == 0 {
+		return nil
+	}
+	var ms multisigState
+	if err := ser.DecodeBytes(val, &ms); err != nil {
+		la.Logger.Error("loadMultisig DecodeBytes fail", "err", err)
+		return err
+	}
+	la.multisig = &ms
+	return nil
+}
+
+func (la *LinkAccount) saveMultisig() error {
+	key := la.getMultisigKey()
+	val, err := ser.EncodeToBytes(la.multisig)
+	if err != nil {
+		la.Logger.Error("saveMultisig EncodeToBytes fail", "err", err)
+		return err
+	}
+	batch := la.walletDB.NewBatch()
+	la.dbSet(batch, key, val)
 	return batch.Commit()
 }